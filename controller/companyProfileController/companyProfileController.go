@@ -0,0 +1,53 @@
+package companyProfileController
+
+import (
+	"Bea-Cukai/helper"
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/model"
+	"Bea-Cukai/service/companyProfileService"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type CompanyProfileController struct {
+	CompanyProfileService *companyProfileService.CompanyProfileService
+}
+
+func NewCompanyProfileController(svc *companyProfileService.CompanyProfileService) *CompanyProfileController {
+	return &CompanyProfileController{CompanyProfileService: svc}
+}
+
+// GET /admin/company-profile
+func (c *CompanyProfileController) Get(ctx *gin.Context) {
+	profile, err := c.CompanyProfileService.Get()
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get company profile", err, nil)
+		return
+	}
+	apiresponse.OK(ctx, profile, "ok", nil)
+}
+
+// PUT /admin/company-profile
+func (c *CompanyProfileController) Update(ctx *gin.Context) {
+	var req model.CompanyProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "fail bind data", err, nil)
+		return
+	}
+	if err := helper.NewValidator().Validate(req); err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "Invalid request format", err, nil)
+		return
+	}
+
+	userData := ctx.MustGet("userData").(jwt.MapClaims)
+	username, _ := userData["username"].(string)
+
+	profile, err := c.CompanyProfileService.Update(req, username)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "PROFILE_UPDATE_FAILED", "fail to update company profile", err, nil)
+		return
+	}
+	apiresponse.OK(ctx, profile, "ok", nil)
+}
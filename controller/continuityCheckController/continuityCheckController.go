@@ -0,0 +1,89 @@
+package continuityCheckController
+
+import (
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/model"
+	"Bea-Cukai/service/continuityCheckService"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxContinuityMonths membatasi jumlah bulan per request; tiap bulan = satu eksekusi CTE penuh.
+const maxContinuityMonths = 24
+
+type ContinuityCheckController struct {
+	ContinuityCheckService *continuityCheckService.ContinuityCheckService
+}
+
+func NewContinuityCheckController(svc *continuityCheckService.ContinuityCheckService) *ContinuityCheckController {
+	return &ContinuityCheckController{ContinuityCheckService: svc}
+}
+
+// ==========================
+// Validation endpoints
+// ==========================
+
+// GET /report/continuity-check?type=raw-material|finished-product&from=YYYY-MM&to=YYYY-MM&item_code=...&item_name=...
+func (c *ContinuityCheckController) Check(ctx *gin.Context) {
+	reportType := ctx.Query("type")
+	if reportType != model.ContinuityReportRawMaterial && reportType != model.ContinuityReportFinishedProduct {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REPORT_TYPE", "type must be raw-material or finished-product", nil, gin.H{
+			"type": reportType,
+		})
+		return
+	}
+
+	from, errFrom := time.Parse("2006-01", ctx.Query("from"))
+	to, errTo := time.Parse("2006-01", ctx.Query("to"))
+	if err := errors.Join(errFrom, errTo); err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_MONTH_RANGE", "from and to must be in YYYY-MM format", err, gin.H{
+			"from": ctx.Query("from"),
+			"to":   ctx.Query("to"),
+		})
+		return
+	}
+
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+	if months < 2 || months > maxContinuityMonths {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_MONTH_RANGE", fmt.Sprintf("month range must cover between 2 and %d months", maxContinuityMonths), nil, gin.H{
+			"from":      ctx.Query("from"),
+			"to":        ctx.Query("to"),
+			"months":    months,
+			"maxMonths": maxContinuityMonths,
+		})
+		return
+	}
+
+	itemCode := ctx.Query("item_code")
+	itemName := ctx.Query("item_name")
+
+	res, err := c.ContinuityCheckService.CheckContinuity(ctx.Request.Context(), model.ContinuityCheckRequest{
+		ReportType: reportType,
+		FromMonth:  from,
+		ToMonth:    to,
+		ItemCode:   itemCode,
+		ItemName:   itemName,
+	})
+	if err != nil {
+		apiresponse.QueryError(ctx, "CONTINUITY_CHECK_FAILED", "fail to check balance continuity", err, gin.H{
+			"type":      reportType,
+			"from":      from.Format("2006-01"),
+			"to":        to.Format("2006-01"),
+			"item_code": itemCode,
+			"item_name": itemName,
+		})
+		return
+	}
+
+	apiresponse.OK(ctx, res, "ok", gin.H{
+		"type":      reportType,
+		"from":      res.From,
+		"to":        res.To,
+		"item_code": itemCode,
+		"item_name": itemName,
+	})
+}
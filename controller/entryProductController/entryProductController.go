@@ -3,32 +3,37 @@ package entryProductController
 import (
 	"Bea-Cukai/helper/apiRequest"
 	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/customsSummary"
+	"Bea-Cukai/helper/exchangeRate"
+	"Bea-Cukai/helper/reportExport"
 	"Bea-Cukai/model"
 	"Bea-Cukai/repo/entryProductRepository"
+	"Bea-Cukai/service/companyProfileService"
 	"Bea-Cukai/service/entryProductService"
+	"context"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/xuri/excelize/v2"
 )
 
 type EntryProductController struct {
-	EntryProductService *entryProductService.EntryProductService
+	EntryProductService   *entryProductService.EntryProductService
+	CompanyProfileService *companyProfileService.CompanyProfileService
 }
 
-func NewEntryProductController(svc *entryProductService.EntryProductService) *EntryProductController {
-	return &EntryProductController{EntryProductService: svc}
+func NewEntryProductController(svc *entryProductService.EntryProductService, companyProfileSvc *companyProfileService.CompanyProfileService) *EntryProductController {
+	return &EntryProductController{EntryProductService: svc, CompanyProfileService: companyProfileSvc}
 }
 
 // ==========================
 // Report endpoints
 // ==========================
 
-// GET /report/entryProduct/all?from=YYYY-MM-DD&to=YYYY-MM-DD&pabeanType=...&productGroup=...&noPabean=...&productCode=...&productName=...&page=1&limit=10
+// GET /report/entryProduct/all?from=YYYY-MM-DD&to=YYYY-MM-DD&pabeanType=...&productGroup=A,B&noPabean=...&productCode=...&productName=...&page=1&limit=10
 func (c *EntryProductController) GetReport(ctx *gin.Context) {
-	from, to, err := apiRequest.GetRange(ctx)
+	period, err := apiRequest.GetPeriod(ctx)
 	if err != nil {
 		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
 			"from": ctx.Query("from"),
@@ -36,10 +41,11 @@ func (c *EntryProductController) GetReport(ctx *gin.Context) {
 		})
 		return
 	}
+	from, to := period.From, period.To
 
 	// Get optional filter parameters
 	pabeanType := ctx.Query("pabeanType")
-	productGroup := ctx.Query("productGroup")
+	productGroup := apiRequest.ParseList(ctx, "productGroup")
 	noPabean := ctx.Query("noPabean")
 	productCode := ctx.Query("productCode")
 	productName := ctx.Query("productName")
@@ -62,9 +68,9 @@ func (c *EntryProductController) GetReport(ctx *gin.Context) {
 		IsExport:     false,
 	}
 
-	res, totalCount, err := c.EntryProductService.GetReport(filter)
+	res, totalCount, err := c.EntryProductService.GetReport(ctx.Request.Context(), filter)
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail get entry products", err, gin.H{
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get entry products", err, gin.H{
 			"from":         from.Format("2006-01-02"),
 			"to":           to.Format("2006-01-02"),
 			"pabeanType":   pabeanType,
@@ -78,6 +84,25 @@ func (c *EntryProductController) GetReport(ctx *gin.Context) {
 		return
 	}
 
+	totals, err := c.EntryProductService.GetTotals(ctx.Request.Context(), filter)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get entry product totals", err, gin.H{
+			"from": from.Format("2006-01-02"),
+			"to":   to.Format("2006-01-02"),
+		})
+		return
+	}
+
+	// jenis_pabean yang tidak ada di ms_pabean / salah arah (untuk semua baris, bukan hanya halaman ini)
+	pabeanValidation, err := c.EntryProductService.GetPabeanValidation(ctx.Request.Context(), filter)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail validate entry product pabean types", err, gin.H{
+			"from": from.Format("2006-01-02"),
+			"to":   to.Format("2006-01-02"),
+		})
+		return
+	}
+
 	// Calculate pagination metadata
 	var totalPages int
 	var hasNext, hasPrev bool
@@ -96,11 +121,20 @@ func (c *EntryProductController) GetReport(ctx *gin.Context) {
 	apiresponse.OK(ctx, res, "ok", gin.H{
 		"from":         from.Format("2006-01-02"),
 		"to":           to.Format("2006-01-02"),
+		"period":       period.Meta(),
 		"pabeanType":   pabeanType,
 		"productGroup": productGroup,
 		"noPabean":     noPabean,
 		"productCode":  productCode,
 		"productName":  productName,
+		"totals": gin.H{
+			"by_currency": totals,
+			"idr":         exchangeRate.SumIdr(totals),
+		},
+		"pabeanValidation": gin.H{
+			"valid":   len(pabeanValidation) == 0,
+			"invalid": pabeanValidation,
+		},
 		"pagination": gin.H{
 			"page":       page,
 			"limit":      limit,
@@ -114,9 +148,11 @@ func (c *EntryProductController) GetReport(ctx *gin.Context) {
 	})
 }
 
-// GET /report/entryProduct/export?from=YYYY-MM-DD&to=YYYY-MM-DD&pabeanType=...&productGroup=...&noPabean=...&productCode=...&productName=...
-func (c *EntryProductController) ExportExcel(ctx *gin.Context) {
-	from, to, err := apiRequest.GetRange(ctx)
+// GET /report/entry-products/summary?from=YYYY-MM-DD&to=YYYY-MM-DD&groupBy=jenis_pabean|month|vendor|item|item_group|currency&limit=N&pabeanType=...&productGroup=A,B&noPabean=...&productCode=...&productName=...
+// Ringkasan per kelompok: jumlah dokumen, jumlah baris, kuantitas per satuan dan nilai per mata uang.
+// limit membatasi ke N kelompok teratas; meta.total berisi ringkasan seluruh baris yang cocok dengan filter.
+func (c *EntryProductController) GetSummary(ctx *gin.Context) {
+	period, err := apiRequest.GetPeriod(ctx)
 	if err != nil {
 		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
 			"from": ctx.Query("from"),
@@ -124,15 +160,24 @@ func (c *EntryProductController) ExportExcel(ctx *gin.Context) {
 		})
 		return
 	}
+	from, to := period.From, period.To
+
+	groupBy := apiRequest.ParseString(ctx, "groupBy", customsSummary.GroupByJenisPabean)
+	if err := entryProductRepository.SummarySpec.Validate(groupBy); err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_GROUP_BY", "invalid groupBy", err, gin.H{
+			"groupBy": groupBy,
+		})
+		return
+	}
+	limit := apiRequest.ParseInt(ctx, "limit", 0)
 
 	// Get optional filter parameters
 	pabeanType := ctx.Query("pabeanType")
-	productGroup := ctx.Query("productGroup")
+	productGroup := apiRequest.ParseList(ctx, "productGroup")
 	noPabean := ctx.Query("noPabean")
 	productCode := ctx.Query("productCode")
 	productName := ctx.Query("productName")
 
-	// For export, we don't use pagination - get all data
 	filter := entryProductRepository.GetReportFilter{
 		From:         from,
 		To:           to,
@@ -141,201 +186,138 @@ func (c *EntryProductController) ExportExcel(ctx *gin.Context) {
 		NoPabean:     noPabean,
 		ProductCode:  productCode,
 		ProductName:  productName,
-		Page:         0, // No pagination
-		Limit:        0, // No limit
-		IsExport:     true,
 	}
 
-	res, _, err := c.EntryProductService.GetReport(filter)
+	res, total, err := c.EntryProductService.GetSummary(ctx.Request.Context(), filter, groupBy, limit)
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail get entry products for export", err, gin.H{
-			"from":         from.Format("2006-01-02"),
-			"to":           to.Format("2006-01-02"),
-			"pabeanType":   pabeanType,
-			"productGroup": productGroup,
-			"noPabean":     noPabean,
-			"productCode":  productCode,
-			"productName":  productName,
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get entry product summary", err, gin.H{
+			"from":    from.Format("2006-01-02"),
+			"to":      to.Format("2006-01-02"),
+			"groupBy": groupBy,
 		})
 		return
 	}
 
-	// Generate Excel file
-	excelFile, err := c.generateExcelFile(res, from, to)
+	apiresponse.OK(ctx, res, "ok", gin.H{
+		"from":         from.Format("2006-01-02"),
+		"to":           to.Format("2006-01-02"),
+		"period":       period.Meta(),
+		"groupBy":      groupBy,
+		"limit":        limit,
+		"pabeanType":   pabeanType,
+		"productGroup": productGroup,
+		"noPabean":     noPabean,
+		"productCode":  productCode,
+		"productName":  productName,
+		"count":        len(res),
+		"total":        total,
+	})
+}
+
+// GET /report/entryProduct/export?from=YYYY-MM-DD&to=YYYY-MM-DD&pabeanType=...&productGroup=A,B&noPabean=...&productCode=...&productName=...&format=xlsx|csv|pdf
+func (c *EntryProductController) ExportExcel(ctx *gin.Context) {
+	format, err := reportExport.ParseFormat(ctx.Query("format"))
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "EXCEL_GENERATION_FAILED", "failed to generate Excel file", err, gin.H{
-			"from": from.Format("2006-01-02"),
-			"to":   to.Format("2006-01-02"),
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
 		})
 		return
 	}
-	defer excelFile.Close()
 
-	// Set headers for Excel file download
-	filename := fmt.Sprintf("laporan_pemasukan_barang_%s_%s.xlsx", 
-		from.Format("2006-01-02"), 
-		to.Format("2006-01-02"))
-	
-	ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	ctx.Header("Cache-Control", "no-cache")
-	
-	// Write Excel file to response
-	buffer, err := excelFile.WriteToBuffer()
+	period, err := apiRequest.GetPeriod(ctx)
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "EXCEL_WRITE_FAILED", "failed to write Excel file", err, gin.H{})
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
+			"from": ctx.Query("from"),
+			"to":   ctx.Query("to"),
+		})
 		return
 	}
-	
-	ctx.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buffer.Bytes())
-}
+	from, to := period.From, period.To
 
-// generateExcelFile creates a real XLSX file using excelize
-func (c *EntryProductController) generateExcelFile(data []model.EntryProduct, from, to time.Time) (*excelize.File, error) {
-	// Create a new Excel file
-	f := excelize.NewFile()
-	sheetName := "Laporan Pemasukan Barang"
-	index, err := f.NewSheet(sheetName)
-	if err != nil {
-		return nil, err
-	}
-	f.SetActiveSheet(index)
-
-	// Set title and header information
-	title1 := "LAPORAN PENERIMAAN BARANG PER DOKUMEN PABEAN"
-	title2 := "PT FUKUSUKE KOGYO INDONESIA"
-	title3 := fmt.Sprintf("PERIODE : %s S.D %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
-	
-	f.SetCellValue(sheetName, "A1", title1)
-	f.SetCellValue(sheetName, "A2", title2)
-	f.SetCellValue(sheetName, "A3", title3)
-
-	// Merge cells for titles
-	f.MergeCell(sheetName, "A1", "M1")
-	f.MergeCell(sheetName, "A2", "M2")
-	f.MergeCell(sheetName, "A3", "M3")
-
-	// Set title styles
-	titleStyle, _ := f.NewStyle(&excelize.Style{
-		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
-		Font:      &excelize.Font{Bold: true, Size: 14},
-	})
-	f.SetCellStyle(sheetName, "A1", "M3", titleStyle)
+	// Get optional filter parameters
+	pabeanType := ctx.Query("pabeanType")
+	productGroup := apiRequest.ParseList(ctx, "productGroup")
+	noPabean := ctx.Query("noPabean")
+	productCode := ctx.Query("productCode")
+	productName := ctx.Query("productName")
 
-	// Set table headers starting from row 5
-	headers := [][]string{
-		{"No.", "DOKUMEN PABEAN", "", "", "BUKTI PENERIMAAN BARANG", "", "PENGIRIM BARANG", "KODE BARANG", "NAMA BARANG", "JUMLAH", "SATUAN", "VALAS", "NILAI"},
-		{"", "JENIS", "NOMOR", "TANGGAL", "NOMOR", "TANGGAL", "", "", "", "", "", "", ""},
+	// For export, we don't use pagination - rows are streamed from a DB cursor
+	filter := entryProductRepository.GetReportFilter{
+		From:         from,
+		To:           to,
+		PabeanType:   pabeanType,
+		ProductGroup: productGroup,
+		NoPabean:     noPabean,
+		ProductCode:  productCode,
+		ProductName:  productName,
+		Page:         0, // No pagination
+		Limit:        0, // No limit
+		IsExport:     true,
 	}
 
-	// Set first header row (row 5)
-	for col, header := range headers[0] {
-		cell, _ := excelize.CoordinatesToCellName(col+1, 5)
-		f.SetCellValue(sheetName, cell, header)
+	totals, err := c.EntryProductService.GetTotals(ctx.Request.Context(), filter)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get entry product totals", err, gin.H{
+			"from": from.Format("2006-01-02"),
+			"to":   to.Format("2006-01-02"),
+		})
+		return
 	}
 
-	// Set second header row (row 6)
-	for col, header := range headers[1] {
-		if header != "" {
-			cell, _ := excelize.CoordinatesToCellName(col+1, 6)
-			f.SetCellValue(sheetName, cell, header)
-		}
+	doc := c.exportDocument(from, to)
+	doc.Totals = exchangeRate.ExportTotals(totals, "net_amount", "net_amount_idr")
+	doc.Stream = func(fn func(model.EntryProduct) error) error {
+		return c.EntryProductService.StreamReport(ctx.Request.Context(), filter, fn)
 	}
+	reportExport.Send(ctx, format, doc)
+}
 
-	// Merge header cells
-	f.MergeCell(sheetName, "A5", "A6") // No.
-	f.MergeCell(sheetName, "B5", "D5") // DOKUMEN PABEAN
-	f.MergeCell(sheetName, "E5", "F5") // BUKTI PENERIMAAN BARANG
-	f.MergeCell(sheetName, "G5", "G6") // PENGIRIM BARANG
-	f.MergeCell(sheetName, "H5", "H6") // KODE BARANG
-	f.MergeCell(sheetName, "I5", "I6") // NAMA BARANG
-	f.MergeCell(sheetName, "J5", "J6") // JUMLAH
-	f.MergeCell(sheetName, "K5", "K6") // SATUAN
-	f.MergeCell(sheetName, "L5", "L6") // VALAS
-	f.MergeCell(sheetName, "M5", "M6") // NILAI
-
-	// Set header style
-	headerStyle, _ := f.NewStyle(&excelize.Style{
-		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
-		Font:      &excelize.Font{Bold: true},
-		Border: []excelize.Border{
-			{Type: "left", Color: "000000", Style: 1},
-			{Type: "top", Color: "000000", Style: 1},
-			{Type: "bottom", Color: "000000", Style: 1},
-			{Type: "right", Color: "000000", Style: 1},
-		},
-		Fill: excelize.Fill{Type: "pattern", Color: []string{"F0F0F0"}, Pattern: 1},
-	})
-	f.SetCellStyle(sheetName, "A5", "M6", headerStyle)
-
-	// Add data rows starting from row 7
-	for i, entryProduct := range data {
-		row := i + 7
-		
-		// Set values for each column
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), i+1)                                    // No
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), entryProduct.JenisPabean)               // Jenis Pabean
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), entryProduct.NoPabean)                  // No Pabean
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), entryProduct.TglPabean.Format("2006-01-02")) // Tgl Pabean
-		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), entryProduct.VendDlvNo)                 // Vend Dlv No
-		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), entryProduct.TransDate.Format("2006-01-02")) // Trans Date
-		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), entryProduct.VendorName)                // Vendor Name
-		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), entryProduct.ItemCode)                  // Item Code
-		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), entryProduct.ItemName)                  // Item Name
-		// Convert decimal to float64 for proper number formatting in Excel
-		rcvQtyFloat, _ := entryProduct.RcvQty.Float64()
-		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), rcvQtyFloat)                            // Rcv Qty
-		f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), entryProduct.PchUnit)                   // Pch Unit
-		f.SetCellValue(sheetName, fmt.Sprintf("L%d", row), entryProduct.CurrCode)                  // Curr Code
-		// Convert decimal to float64 for proper number formatting in Excel
-		netAmountFloat, _ := entryProduct.NetAmount.Float64()
-		f.SetCellValue(sheetName, fmt.Sprintf("M%d", row), netAmountFloat)                         // Net Amount
+// BundleSheet adalah laporan pemasukan barang periode from..to tanpa filter tambahan (bundle LPJ).
+func (c *EntryProductController) BundleSheet(ctx context.Context, from, to time.Time) (reportExport.Sheet, error) {
+	filter := entryProductRepository.GetReportFilter{From: from, To: to, IsExport: true}
+	totals, err := c.EntryProductService.GetTotals(ctx, filter)
+	if err != nil {
+		return nil, err
 	}
-
-	// Set data style with borders
-	if len(data) > 0 {
-		lastRow := len(data) + 6
-		dataStyle, _ := f.NewStyle(&excelize.Style{
-			Border: []excelize.Border{
-				{Type: "left", Color: "000000", Style: 1},
-				{Type: "top", Color: "000000", Style: 1},
-				{Type: "bottom", Color: "000000", Style: 1},
-				{Type: "right", Color: "000000", Style: 1},
-			},
-		})
-		f.SetCellStyle(sheetName, "A7", fmt.Sprintf("M%d", lastRow), dataStyle)
-		
-		// Number format with thousand separator and 2 decimal places
-		numStyle, _ := f.NewStyle(&excelize.Style{
-			NumFmt: 4, // "#,##0.00" - format ribuan dengan 2 desimal
-			Border: []excelize.Border{
-				{Type: "left", Color: "000000", Style: 1},
-				{Type: "top", Color: "000000", Style: 1},
-				{Type: "bottom", Color: "000000", Style: 1},
-				{Type: "right", Color: "000000", Style: 1},
-			},
-		})
-		f.SetCellStyle(sheetName, "J7", fmt.Sprintf("J%d", lastRow), numStyle) // Rcv Qty
-		f.SetCellStyle(sheetName, "M7", fmt.Sprintf("M%d", lastRow), numStyle) // Net Amount
+	doc := c.exportDocument(from, to)
+	doc.Totals = exchangeRate.ExportTotals(totals, "net_amount", "net_amount_idr")
+	doc.Stream = func(fn func(model.EntryProduct) error) error {
+		return c.EntryProductService.StreamReport(ctx, filter, fn)
 	}
+	return doc, nil
+}
 
-	// Set column widths
-	f.SetColWidth(sheetName, "A", "A", 5)   // No
-	f.SetColWidth(sheetName, "B", "B", 12)  // Jenis Pabean
-	f.SetColWidth(sheetName, "C", "C", 20)  // No Pabean
-	f.SetColWidth(sheetName, "D", "D", 12)  // Tgl Pabean
-	f.SetColWidth(sheetName, "E", "E", 15)  // Vend Dlv No
-	f.SetColWidth(sheetName, "F", "F", 12)  // Trans Date
-	f.SetColWidth(sheetName, "G", "G", 25)  // Vendor Name
-	f.SetColWidth(sheetName, "H", "H", 15)  // Item Code
-	f.SetColWidth(sheetName, "I", "I", 30)  // Item Name
-	f.SetColWidth(sheetName, "J", "J", 12)  // Rcv Qty
-	f.SetColWidth(sheetName, "K", "K", 10)  // Pch Unit
-	f.SetColWidth(sheetName, "L", "L", 8)   // Curr Code
-	f.SetColWidth(sheetName, "M", "M", 15)  // Net Amount
-
-	// Delete default sheet if it exists
-	f.DeleteSheet("Sheet1")
+// exportDocument adalah kop dan kolom export laporan pemasukan barang; baris diisi pemanggil.
+func (c *EntryProductController) exportDocument(from, to time.Time) reportExport.Document[model.EntryProduct] {
+	return reportExport.Document[model.EntryProduct]{
+		FileName:  fmt.Sprintf("laporan_pemasukan_barang_%s_%s", from.Format("2006-01-02"), to.Format("2006-01-02")),
+		SheetName: "Laporan Pemasukan Barang",
+		Title:     "LAPORAN PENERIMAAN BARANG PER DOKUMEN PABEAN",
+		Period:    reportExport.PeriodRange(from, to),
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   exportColumns(),
+	}
+}
 
-	return f, nil
+// exportColumns adalah definisi kolom export (xlsx/csv/pdf) laporan pemasukan barang.
+func exportColumns() []reportExport.Column[model.EntryProduct] {
+	return []reportExport.Column[model.EntryProduct]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.EntryProduct) any { return i + 1 }},
+		{Key: "jenis_pabean", Group: "DOKUMEN PABEAN", Title: "JENIS", Width: 12, Value: func(_ int, r model.EntryProduct) any { return r.JenisPabean }},
+		{Key: "no_pabean", Group: "DOKUMEN PABEAN", Title: "NOMOR", Width: 15, Value: func(_ int, r model.EntryProduct) any { return r.NoPabean }},
+		{Key: "tgl_pabean", Group: "DOKUMEN PABEAN", Title: "TANGGAL", Width: 12, Value: func(_ int, r model.EntryProduct) any { return reportExport.Date(r.TglPabean) }},
+		{Key: "vend_dlv_no", Group: "BUKTI PENERIMAAN BARANG", Title: "NOMOR", Width: 15, Value: func(_ int, r model.EntryProduct) any { return r.VendDlvNo }},
+		{Key: "trans_date", Group: "BUKTI PENERIMAAN BARANG", Title: "TANGGAL", Width: 12, Value: func(_ int, r model.EntryProduct) any { return reportExport.Date(r.TransDate) }},
+		{Key: "vendor_name", Title: "PENGIRIM BARANG", Width: 25, Value: func(_ int, r model.EntryProduct) any { return r.VendorName }},
+		{Key: "item_code", Title: "KODE BARANG", Width: 15, Value: func(_ int, r model.EntryProduct) any { return r.ItemCode }},
+		{Key: "item_name", Title: "NAMA BARANG", Width: 30, Value: func(_ int, r model.EntryProduct) any { return r.ItemName }},
+		{Key: "item_group", Title: "GRUP", Width: 12, Value: func(_ int, r model.EntryProduct) any { return r.ItemGroup }},
+		{Key: "rcv_qty", Title: "JUMLAH", Width: 12, Number: true, Decimals: 2, Value: func(_ int, r model.EntryProduct) any { return reportExport.Decimal(r.RcvQty) }},
+		{Key: "pch_unit", Title: "SATUAN", Width: 8, Value: func(_ int, r model.EntryProduct) any { return r.PchUnit }},
+		{Key: "curr_code", Title: "VALAS", Width: 8, Value: func(_ int, r model.EntryProduct) any { return r.CurrCode }},
+		{Key: "net_amount", Title: "NILAI", Width: 15, Number: true, Decimals: 2, Value: func(_ int, r model.EntryProduct) any { return reportExport.Decimal(r.NetAmount) }},
+		{Key: "idr_rate", Title: "KURS", Width: 12, Number: true, Decimals: 2, Value: func(_ int, r model.EntryProduct) any { return reportExport.DecimalPtr(r.IdrRate) }},
+		{Key: "net_amount_idr", Title: "NILAI (IDR)", Width: 18, Number: true, Decimals: 2, Value: func(_ int, r model.EntryProduct) any { return reportExport.DecimalPtr(r.NetAmountIdr) }},
+		{Key: "pabean_status", Title: "VALIDASI DOKUMEN", Width: 16, Value: func(_ int, r model.EntryProduct) any { return r.PabeanStatus }},
+	}
 }
@@ -0,0 +1,219 @@
+package exchangeRateController
+
+import (
+	"Bea-Cukai/helper"
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/model"
+	"Bea-Cukai/service/exchangeRateService"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Ukuran maksimal file CSV import kurs.
+const maxImportSize = 5 << 20
+
+type ExchangeRateController struct {
+	ExchangeRateService *exchangeRateService.ExchangeRateService
+}
+
+func NewExchangeRateController(svc *exchangeRateService.ExchangeRateService) *ExchangeRateController {
+	return &ExchangeRateController{ExchangeRateService: svc}
+}
+
+// GET /admin/exchange-rates?curr_code=USD&date=YYYY-MM-DD&source=NDPBM&page=1&limit=20
+// date = hanya kurs yang berlaku pada tanggal tersebut.
+func (c *ExchangeRateController) GetAll(ctx *gin.Context) {
+	req := model.ExchangeRateListRequest{
+		CurrCode: ctx.Query("curr_code"),
+		Date:     ctx.Query("date"),
+		Source:   ctx.Query("source"),
+		Page:     apiRequest.ParseInt(ctx, "page", 1),
+		Limit:    apiRequest.ParseInt(ctx, "limit", 20),
+	}
+
+	rates, total, err := c.ExchangeRateService.GetAll(req)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get exchange rates", err, gin.H{
+			"curr_code": req.CurrCode,
+			"date":      req.Date,
+		})
+		return
+	}
+
+	totalPages := 1
+	if req.Limit > 0 {
+		totalPages = int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	}
+	apiresponse.OK(ctx, rates, "ok", gin.H{
+		"curr_code": req.CurrCode,
+		"date":      req.Date,
+		"source":    req.Source,
+		"pagination": gin.H{
+			"page":       req.Page,
+			"limit":      req.Limit,
+			"totalCount": total,
+			"totalPages": totalPages,
+			"count":      len(rates),
+			"hasNext":    req.Page < totalPages,
+			"hasPrev":    req.Page > 1,
+		},
+	})
+}
+
+// GET /admin/exchange-rates/:id
+func (c *ExchangeRateController) GetById(ctx *gin.Context) {
+	id, ok := parseId(ctx)
+	if !ok {
+		return
+	}
+
+	rate, err := c.ExchangeRateService.GetById(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apiresponse.Error(ctx, http.StatusNotFound, "RATE_NOT_FOUND", "exchange rate not found", err, gin.H{"id": id})
+		return
+	}
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get exchange rate", err, gin.H{"id": id})
+		return
+	}
+	apiresponse.OK(ctx, rate, "ok", nil)
+}
+
+// POST /admin/exchange-rates  body: {"curr_code": "USD", "rate": 16250.5, "valid_from": "2026-10-14", "valid_to": "2026-10-20", "source": "NDPBM"}
+func (c *ExchangeRateController) Create(ctx *gin.Context) {
+	req, ok := bindRequest(ctx)
+	if !ok {
+		return
+	}
+
+	rate, err := c.ExchangeRateService.Create(req, username(ctx))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "RATE_CREATE_FAILED", "fail to create exchange rate", err, gin.H{
+			"curr_code":  req.CurrCode,
+			"valid_from": req.ValidFrom,
+		})
+		return
+	}
+	apiresponse.Created(ctx, rate, "ok", nil)
+}
+
+// PUT /admin/exchange-rates/:id
+func (c *ExchangeRateController) Update(ctx *gin.Context) {
+	id, ok := parseId(ctx)
+	if !ok {
+		return
+	}
+	req, ok := bindRequest(ctx)
+	if !ok {
+		return
+	}
+
+	rate, err := c.ExchangeRateService.Update(id, req, username(ctx))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apiresponse.Error(ctx, http.StatusNotFound, "RATE_NOT_FOUND", "exchange rate not found", err, gin.H{"id": id})
+		return
+	}
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "RATE_UPDATE_FAILED", "fail to update exchange rate", err, gin.H{"id": id})
+		return
+	}
+	apiresponse.OK(ctx, rate, "ok", gin.H{"id": id})
+}
+
+// DELETE /admin/exchange-rates/:id
+func (c *ExchangeRateController) Delete(ctx *gin.Context) {
+	id, ok := parseId(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.ExchangeRateService.Delete(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apiresponse.Error(ctx, http.StatusNotFound, "RATE_NOT_FOUND", "exchange rate not found", err, gin.H{"id": id})
+		return
+	}
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "RATE_DELETE_FAILED", "fail to delete exchange rate", err, gin.H{"id": id})
+		return
+	}
+	apiresponse.OK(ctx, gin.H{"id": id}, "ok", nil)
+}
+
+// POST /admin/exchange-rates/import  multipart: file=<csv>
+// Header CSV: curr_code,rate,valid_from[,valid_to,source,notes] (pemisah , atau ;).
+// Bila ada baris yang salah tidak ada yang disimpan: 400 IMPORT_INVALID_ROWS dengan daftar barisnya.
+func (c *ExchangeRateController) Import(ctx *gin.Context) {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "file is required (multipart field \"file\")", err, nil)
+		return
+	}
+	if header.Size > maxImportSize {
+		apiresponse.Error(ctx, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "file is too large to import", errors.New("file exceeds 5 MB"), gin.H{
+			"file_size": header.Size,
+		})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "fail to read uploaded file", err, nil)
+		return
+	}
+	defer file.Close()
+
+	res, err := c.ExchangeRateService.ImportCSV(file, username(ctx))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "IMPORT_FAILED", "fail to import exchange rates", err, gin.H{
+			"file_name": header.Filename,
+		})
+		return
+	}
+	if len(res.Errors) > 0 {
+		apiresponse.Error(ctx, http.StatusBadRequest, "IMPORT_INVALID_ROWS", "some rows are invalid, nothing was imported", nil, gin.H{
+			"file_name": header.Filename,
+			"errors":    res.Errors,
+		})
+		return
+	}
+
+	apiresponse.OK(ctx, res, "ok", gin.H{
+		"file_name": header.Filename,
+		"created":   res.Created,
+		"updated":   res.Updated,
+	})
+}
+
+func parseId(ctx *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_ID", "invalid exchange rate id", err, gin.H{"id": ctx.Param("id")})
+		return 0, false
+	}
+	return id, true
+}
+
+func bindRequest(ctx *gin.Context) (model.ExchangeRateRequest, bool) {
+	var req model.ExchangeRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "fail bind data", err, nil)
+		return req, false
+	}
+	if err := helper.NewValidator().Validate(req); err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "Invalid request format", err, nil)
+		return req, false
+	}
+	return req, true
+}
+
+func username(ctx *gin.Context) string {
+	userData, _ := ctx.MustGet("userData").(jwt.MapClaims)
+	name, _ := userData["username"].(string)
+	return name
+}
@@ -0,0 +1,59 @@
+package exportDocumentController
+
+import (
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/service/exportDocumentService"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxVerifySize adalah batas ukuran file yang bisa diverifikasi (100 MB).
+const maxVerifySize = 100 << 20
+
+type ExportDocumentController struct {
+	ExportDocumentService *exportDocumentService.ExportDocumentService
+}
+
+func NewExportDocumentController(svc *exportDocumentService.ExportDocumentService) *ExportDocumentController {
+	return &ExportDocumentController{ExportDocumentService: svc}
+}
+
+// POST /exports/verify  multipart: file=<file export>, document_id=<opsional>
+// Selalu 200 bila file terbaca; hasil verifikasi ada di data.valid dan data.result
+// (match, modified, unknown).
+func (c *ExportDocumentController) Verify(ctx *gin.Context) {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "file is required (multipart field \"file\")", err, nil)
+		return
+	}
+	if header.Size > maxVerifySize {
+		apiresponse.Error(ctx, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "file is too large to verify", errors.New("file exceeds 100 MB"), gin.H{
+			"file_size": header.Size,
+		})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "fail to read uploaded file", err, nil)
+		return
+	}
+	defer file.Close()
+
+	res, err := c.ExportDocumentService.Verify(file, header.Size, ctx.PostForm("document_id"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "VERIFY_FAILED", "fail to verify export file", err, gin.H{
+			"file_name": header.Filename,
+		})
+		return
+	}
+
+	apiresponse.OK(ctx, res, "ok", gin.H{
+		"file_name": header.Filename,
+		"valid":     res.Valid,
+		"result":    res.Result,
+	})
+}
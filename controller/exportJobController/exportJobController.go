@@ -0,0 +1,150 @@
+package exportJobController
+
+import (
+	"Bea-Cukai/helper"
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/helper/signedUrl"
+	"Bea-Cukai/model"
+	"Bea-Cukai/service/exportJobService"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+type ExportJobController struct {
+	ExportJobService *exportJobService.ExportJobService
+}
+
+func NewExportJobController(svc *exportJobService.ExportJobService) *ExportJobController {
+	return &ExportJobController{ExportJobService: svc}
+}
+
+// POST /exports  body: {"report_type": "entry-products", "format": "xlsx", "filters": {"from": "2025-01-01", "to": "2025-12-31"}}
+func (c *ExportJobController) Create(ctx *gin.Context) {
+	var req model.ExportJobRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "fail bind data", err, nil)
+		return
+	}
+	if err := helper.NewValidator().Validate(req); err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "Invalid request format", err, gin.H{
+			"report_types": exportJobService.ReportTypes(),
+		})
+		return
+	}
+
+	userData := ctx.MustGet("userData").(jwt.MapClaims)
+	userId, _ := userData["id"].(string)
+	username, _ := userData["username"].(string)
+
+	job, err := c.ExportJobService.Enqueue(req, userId, username, ctx.ClientIP())
+	switch {
+	case errors.Is(err, exportJobService.ErrUnknownReportType):
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REPORT_TYPE", "unknown report type", err, gin.H{
+			"report_type":  req.ReportType,
+			"report_types": exportJobService.ReportTypes(),
+		})
+		return
+	case errors.Is(err, reportExport.ErrUnsupportedFormat), errors.Is(err, reportExport.ErrUnsupportedBundleFormat):
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{"format": req.Format})
+		return
+	case errors.Is(err, exportJobService.ErrQueueFull):
+		apiresponse.Error(ctx, http.StatusServiceUnavailable, "EXPORT_QUEUE_FULL", "export queue is full", err, gin.H{"id": job.Id})
+		return
+	case err != nil:
+		apiresponse.Error(ctx, http.StatusInternalServerError, "EXPORT_ENQUEUE_FAILED", "fail to queue export", err, gin.H{
+			"report_type": req.ReportType,
+		})
+		return
+	}
+
+	ctx.Header("Location", "/exports/"+job.Id)
+	apiresponse.Created(ctx, job, "ok", gin.H{"id": job.Id, "status": job.Status})
+}
+
+// GET /exports/:id
+func (c *ExportJobController) GetById(ctx *gin.Context) {
+	userData := ctx.MustGet("userData").(jwt.MapClaims)
+	userId, _ := userData["id"].(string)
+
+	job, err := c.ExportJobService.Get(ctx.Param("id"), userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apiresponse.Error(ctx, http.StatusNotFound, "EXPORT_NOT_FOUND", "export job not found", err, gin.H{"id": ctx.Param("id")})
+		return
+	}
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get export job", err, gin.H{"id": ctx.Param("id")})
+		return
+	}
+	apiresponse.OK(ctx, job, "ok", gin.H{"id": job.Id, "status": job.Status})
+}
+
+// GET /exports?report_type=...&status=...&page=1&limit=20 (job milik user yang login)
+func (c *ExportJobController) GetMine(ctx *gin.Context) {
+	userData := ctx.MustGet("userData").(jwt.MapClaims)
+	userId, _ := userData["id"].(string)
+	c.list(ctx, userId)
+}
+
+// GET /admin/exports?report_type=...&status=...&page=1&limit=20 (semua user)
+func (c *ExportJobController) GetAll(ctx *gin.Context) {
+	c.list(ctx, "")
+}
+
+func (c *ExportJobController) list(ctx *gin.Context, requestedById string) {
+	req := model.ExportJobListRequest{
+		ReportType: ctx.Query("report_type"),
+		Status:     ctx.Query("status"),
+		Page:       apiRequest.ParseInt(ctx, "page", 1),
+		Limit:      apiRequest.ParseInt(ctx, "limit", 20),
+	}
+
+	jobs, _, meta, err := c.ExportJobService.GetAll(requestedById, req)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get export jobs", err, gin.H{
+			"report_type": req.ReportType,
+			"status":      req.Status,
+		})
+		return
+	}
+
+	meta["report_type"] = req.ReportType
+	meta["status"] = req.Status
+	apiresponse.OK(ctx, jobs, "ok", meta)
+}
+
+// GET /exports/:id/download?expires=...&signature=...
+// Tidak memakai JWT: akses dijamin oleh signed URL dari GET /exports/:id.
+func (c *ExportJobController) Download(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	job, err := c.ExportJobService.Download(id, ctx.Query("expires"), ctx.Query("signature"))
+	switch {
+	case errors.Is(err, signedUrl.ErrInvalidSignature):
+		apiresponse.Error(ctx, http.StatusForbidden, "BAD_SIGNATURE", "invalid download link", err, gin.H{"id": id})
+		return
+	case errors.Is(err, signedUrl.ErrExpired):
+		apiresponse.Error(ctx, http.StatusForbidden, "LINK_EXPIRED", "download link has expired, request a new one from GET /exports/:id", err, gin.H{"id": id})
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apiresponse.Error(ctx, http.StatusNotFound, "EXPORT_NOT_FOUND", "export job not found", err, gin.H{"id": id})
+		return
+	case errors.Is(err, exportJobService.ErrNotReady):
+		apiresponse.Error(ctx, http.StatusConflict, "EXPORT_NOT_READY", "export is not ready", err, gin.H{"id": id, "status": job.Status})
+		return
+	case errors.Is(err, exportJobService.ErrFileExpired):
+		apiresponse.Error(ctx, http.StatusGone, "EXPORT_EXPIRED", "export file has expired", err, gin.H{"id": id})
+		return
+	case err != nil:
+		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get export job", err, gin.H{"id": id})
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.FileAttachment(job.FilePath, job.FileName)
+}
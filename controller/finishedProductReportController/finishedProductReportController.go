@@ -3,34 +3,38 @@ package finishedProductReportController
 import (
 	"Bea-Cukai/helper/apiRequest"
 	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/reportCache"
+	"Bea-Cukai/helper/reportCompare"
+	"Bea-Cukai/helper/reportExport"
 	"Bea-Cukai/model"
 	"Bea-Cukai/repo/finishedProductReportRepository"
+	"Bea-Cukai/service/companyProfileService"
 	"Bea-Cukai/service/finishedProductReportService"
+	"context"
 	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/xuri/excelize/v2"
 )
 
 type FinishedProductReportController struct {
 	FinishedProductReportService *finishedProductReportService.FinishedProductReportService
+	CompanyProfileService        *companyProfileService.CompanyProfileService
 }
 
-func NewFinishedProductReportController(svc *finishedProductReportService.FinishedProductReportService) *FinishedProductReportController {
-	return &FinishedProductReportController{FinishedProductReportService: svc}
+func NewFinishedProductReportController(svc *finishedProductReportService.FinishedProductReportService, companyProfileSvc *companyProfileService.CompanyProfileService) *FinishedProductReportController {
+	return &FinishedProductReportController{FinishedProductReportService: svc, CompanyProfileService: companyProfileSvc}
 }
 
 // ==========================
 // Report endpoints
 // ==========================
 
-// GET /report/finished-product?from=YYYY-MM-DD&to=YYYY-MM-DD&item_code=...&item_name=...&page=1&limit=10
+// GET /report/finished-product?from=YYYY-MM-DD&to=YYYY-MM-DD&item_code=...&item_name=...&sort=...&groupBy=location&location=...&page=1&limit=10
+// compare=previous|yoy|custom (compare_from, compare_to) → perbandingan per item dengan periode lain, lihat getComparison
 func (c *FinishedProductReportController) GetReport(ctx *gin.Context) {
-	from, to, err := apiRequest.GetRange(ctx)
+	period, err := apiRequest.GetPeriod(ctx)
 	if err != nil {
 		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
 			"from": ctx.Query("from"),
@@ -38,10 +42,32 @@ func (c *FinishedProductReportController) GetReport(ctx *gin.Context) {
 		})
 		return
 	}
+	from, to := period.From, period.To
+	if ctx.Query("compare") != "" {
+		c.getComparison(ctx, period)
+		return
+	}
 
 	// Get optional filter parameters
 	itemCode := ctx.Query("item_code")
 	itemName := ctx.Query("item_name")
+	groupBy := ctx.Query("groupBy")
+	location := ctx.Query("location")
+	if groupBy != "" && groupBy != finishedProductReportRepository.GroupByLocation {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_GROUP_BY", "groupBy must be empty or location", nil, gin.H{
+			"groupBy": groupBy,
+		})
+		return
+	}
+
+	// Get sort and filter options
+	opts, err := apiRequest.GetReportOptions(ctx, finishedProductReportRepository.ReportColumns)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REPORT_OPTIONS", "invalid sort or filter parameter", err, gin.H{
+			"sort": ctx.Query("sort"),
+		})
+		return
+	}
 
 	// Get pagination parameters
 	page := apiRequest.ParseInt(ctx, "page", 0)
@@ -52,16 +78,21 @@ func (c *FinishedProductReportController) GetReport(ctx *gin.Context) {
 		To:       to,
 		ItemCode: itemCode,
 		ItemName: itemName,
+		Options:  opts,
+		GroupBy:  groupBy,
+		Location: location,
 		Page:     page,
 		Limit:    limit,
 	}
-	res, totalCount, err := c.FinishedProductReportService.GetReport(filter)
+	res, totalCount, err := c.FinishedProductReportService.GetReport(ctx.Request.Context(), filter)
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get finished product report", err, gin.H{
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail to get finished product report", err, gin.H{
 			"from":      from.Format("2006-01-02"),
 			"to":        to.Format("2006-01-02"),
 			"item_code": itemCode,
 			"item_name": itemName,
+			"groupBy":   groupBy,
+			"location":  location,
 			"page":      page,
 			"limit":     limit,
 		})
@@ -85,8 +116,12 @@ func (c *FinishedProductReportController) GetReport(ctx *gin.Context) {
 	apiresponse.OK(ctx, res, "ok", gin.H{
 		"from":      from.Format("2006-01-02"),
 		"to":        to.Format("2006-01-02"),
+		"period":    period.Meta(),
 		"item_code": itemCode,
 		"item_name": itemName,
+		"options":   opts.Meta(),
+		"groupBy":   groupBy,
+		"location":  location,
 		"pagination": gin.H{
 			"page":       page,
 			"limit":      limit,
@@ -99,9 +134,17 @@ func (c *FinishedProductReportController) GetReport(ctx *gin.Context) {
 	})
 }
 
-// ExportExcel generates Excel file for finished product report
+// ExportExcel generates the finished product report export (format=xlsx|csv|pdf, default xlsx)
 func (c *FinishedProductReportController) ExportExcel(ctx *gin.Context) {
-	from, to, err := apiRequest.GetRange(ctx)
+	format, err := reportExport.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
+		})
+		return
+	}
+
+	period, err := apiRequest.GetPeriod(ctx)
 	if err != nil {
 		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
 			"from": ctx.Query("from"),
@@ -109,221 +152,243 @@ func (c *FinishedProductReportController) ExportExcel(ctx *gin.Context) {
 		})
 		return
 	}
+	from, to := period.From, period.To
+	if ctx.Query("compare") != "" {
+		c.exportComparison(ctx, format, period)
+		return
+	}
 
 	// Get optional filter parameters
 	itemCode := ctx.Query("item_code")
 	itemName := ctx.Query("item_name")
+	groupBy := ctx.Query("groupBy")
+	location := ctx.Query("location")
+	if groupBy != "" && groupBy != finishedProductReportRepository.GroupByLocation {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_GROUP_BY", "groupBy must be empty or location", nil, gin.H{
+			"groupBy": groupBy,
+		})
+		return
+	}
+
+	// Get sort and filter options
+	opts, err := apiRequest.GetReportOptions(ctx, finishedProductReportRepository.ReportColumns)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REPORT_OPTIONS", "invalid sort or filter parameter", err, gin.H{
+			"sort": ctx.Query("sort"),
+		})
+		return
+	}
 
 	filter := finishedProductReportRepository.GetReportFilter{
 		From:     from,
 		To:       to,
 		ItemCode: itemCode,
 		ItemName: itemName,
+		Options:  opts,
+		GroupBy:  groupBy,
+		Location: location,
 		Page:     0, // No pagination for export
 		Limit:    0, // Get all data
 	}
 	fmt.Println(filter)
 
-	res, _, err := c.FinishedProductReportService.GetReport(filter)
+	res, _, err := c.FinishedProductReportService.GetReport(ctx.Request.Context(), filter)
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get finished product report", err, gin.H{
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail to get finished product report", err, gin.H{
 			"from":      from.Format("2006-01-02"),
 			"to":        to.Format("2006-01-02"),
 			"item_code": itemCode,
 			"item_name": itemName,
+			"groupBy":   groupBy,
+			"location":  location,
 		})
 		return
 	}
 
-	// Generate Excel file
-	filename, err := c.generateExcelFile(res, from, to)
+	doc := c.exportDocument(from, to, filter.GroupBy != "" || filter.Location != "")
+	doc.Rows = res
+	reportExport.Send(ctx, format, doc)
+}
+
+// BundleSheet adalah laporan mutasi barang jadi periode from..to tanpa filter tambahan (bundle LPJ).
+func (c *FinishedProductReportController) BundleSheet(ctx context.Context, from, to time.Time) (reportExport.Sheet, error) {
+	res, _, err := c.FinishedProductReportService.GetReport(ctx, finishedProductReportRepository.GetReportFilter{From: from, To: to})
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "EXCEL_GENERATION_FAILED", "fail to generate excel file", err, nil)
-		return
+		return nil, err
 	}
 
-	// Set headers for file download
-	ctx.Header("Content-Description", "File Transfer")
-	ctx.Header("Content-Disposition", "attachment; filename="+filename)
-	ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	ctx.Header("Content-Transfer-Encoding", "binary")
-	ctx.Header("Expires", "0")
-	ctx.Header("Cache-Control", "must-revalidate")
-	ctx.Header("Pragma", "public")
-
-	// Stream the file
-	ctx.File(filename)
+	doc := c.exportDocument(from, to, false)
+	doc.Rows = res
+	return doc, nil
 }
 
-func (c *FinishedProductReportController) generateExcelFile(data []model.FinishedProductReportResponse, from, to time.Time) (string, error) {
-	// Create a new workbook
-	f := excelize.NewFile()
+// exportDocument adalah kop dan kolom export laporan mutasi barang jadi; baris diisi pemanggil.
+// byLocation menambah kolom LOKASI untuk export per lokasi.
+func (c *FinishedProductReportController) exportDocument(from, to time.Time, byLocation bool) reportExport.Document[model.FinishedProductReportResponse] {
+	return reportExport.Document[model.FinishedProductReportResponse]{
+		FileName:  fmt.Sprintf("laporan_mutasi_barang_jadi_%s_%s", from.Format("2006-01-02"), to.Format("2006-01-02")),
+		SheetName: "Laporan Barang Jadi",
+		Title:     "LAPORAN PERTANGGUNGJAWABAN MUTASI BARANG JADI",
+		Period:    reportExport.PeriodRange(from, to),
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   exportColumns(to, byLocation),
+	}
+}
 
-	// Create a new worksheet
-	sheetName := "Laporan Barang Jadi"
-	index, err := f.NewSheet(sheetName)
-	if err != nil {
-		return "", err
+// exportColumns adalah definisi kolom export (xlsx/csv/pdf) laporan barang jadi.
+// byLocation menambah kolom LOKASI untuk export per lokasi.
+func exportColumns(to time.Time, byLocation bool) []reportExport.Column[model.FinishedProductReportResponse] {
+	date := to.Format("02-01-2006")
+	columns := []reportExport.Column[model.FinishedProductReportResponse]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.FinishedProductReportResponse) any { return i + 1 }},
+		{Key: "item_code", Title: "KODE BARANG", Width: 15, Value: func(_ int, r model.FinishedProductReportResponse) any { return r.ItemCode }},
+		{Key: "item_name", Title: "NAMA BARANG", Width: 30, Value: func(_ int, r model.FinishedProductReportResponse) any { return r.ItemName }},
+		{Key: "unit_code", Title: "SAT", Width: 8, Value: func(_ int, r model.FinishedProductReportResponse) any { return r.UnitCode }},
+		{Key: "awal", Title: "SALDO AWAL", Width: 12, Number: true, Decimals: 0, Value: func(_ int, r model.FinishedProductReportResponse) any { return reportExport.Decimal(r.Awal) }},
+		{Key: "masuk", Title: "PEMASUKAN", Width: 12, Number: true, Decimals: 0, Value: func(_ int, r model.FinishedProductReportResponse) any { return reportExport.Decimal(r.Masuk) }},
+		{Key: "keluar", Title: "PENGELUARAN", Width: 12, Number: true, Decimals: 0, Value: func(_ int, r model.FinishedProductReportResponse) any { return reportExport.Decimal(r.Keluar) }},
+		{Key: "peny", Title: "PENYESUAIAN", Width: 12, Number: true, Decimals: 0, Value: func(_ int, r model.FinishedProductReportResponse) any { return reportExport.Decimal(r.Peny) }},
+		{Key: "akhir", Group: "SALDO AKHIR", Title: date, Width: 12, Number: true, Decimals: 0, Value: func(_ int, r model.FinishedProductReportResponse) any { return reportExport.Decimal(r.Akhir) }},
+		{Key: "opname", Group: "STOK OPNAME", Title: date, Width: 12, Number: true, Decimals: 0, Value: func(_ int, r model.FinishedProductReportResponse) any { return reportExport.Decimal(r.Opname) }},
+		{Key: "selisih", Title: "SELISIH", Width: 10, Number: true, Decimals: 0, Value: func(_ int, r model.FinishedProductReportResponse) any { return reportExport.Decimal(r.Selisih) }},
+		{Key: "keterangan", Title: "KETERANGAN", Width: 15, Value: func(_ int, r model.FinishedProductReportResponse) any { return "" }},
+	}
+	if !byLocation {
+		return columns
 	}
 
-	// Set active sheet
-	f.SetActiveSheet(index)
-
-	// Company header section
-	f.SetCellValue(sheetName, "A1", "PT FUKUSUKE KOGYO INDONESIA")
-	f.SetCellValue(sheetName, "A2", "LAPORAN PERTANGGUNGJAWABAN MUTASI BARANG JADI")
-	f.SetCellValue(sheetName, "A3", "") // Empty row
-	f.SetCellValue(sheetName, "A4", "Nama Kawasan Berikat")
-	f.SetCellValue(sheetName, "C4", ": PT FUKUSUKE KOGYO INDONESIA")
-	f.SetCellValue(sheetName, "A5", "NPWP")
-	f.SetCellValue(sheetName, "C5", ": 01.071.250.3-052.000")
-	f.SetCellValue(sheetName, "A6", "Alamat")
-	f.SetCellValue(sheetName, "C6", ": Blok M-3-2, Kawasan MM2100, Cikarang Barat, Bekasi, 17520")
-	f.SetCellValue(sheetName, "A7", "Periode Laporan")
-	f.SetCellValue(sheetName, "C7", fmt.Sprintf(": %s s.d %s", from.Format("02-01-2006"), to.Format("02-01-2006")))
-
-	// Merge cells for company info values (extend to column L for 12 columns)
-	f.MergeCell(sheetName, "A1", "L1") // Company name
-	f.MergeCell(sheetName, "A2", "L2") // Report title
-	f.MergeCell(sheetName, "C4", "L4") // Kawasan Berikat
-	f.MergeCell(sheetName, "C5", "L5") // NPWP
-	f.MergeCell(sheetName, "C6", "L6") // Alamat
-	f.MergeCell(sheetName, "C7", "L7") // Periode
-
-	// Set header info style (center for titles, left for info)
-	titleStyle, _ := f.NewStyle(&excelize.Style{
-		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
-		Font:      &excelize.Font{Bold: true, Size: 12},
-	})
-	f.SetCellStyle(sheetName, "A1", "A2", titleStyle)
+	// LOKASI ditambahkan setelah KETERANGAN; KETERANGAN tetap kosong seperti format LPJ.
+	return append(columns, reportExport.Column[model.FinishedProductReportResponse]{Key: "location_code", Title: "LOKASI", Width: 12, Value: func(_ int, r model.FinishedProductReportResponse) any { return r.LocationCode }})
+}
 
-	headerInfoStyle, _ := f.NewStyle(&excelize.Style{
-		Alignment: &excelize.Alignment{Horizontal: "left", Vertical: "center"},
-		Font:      &excelize.Font{Bold: true, Size: 10},
-	})
-	f.SetCellStyle(sheetName, "A4", "A7", headerInfoStyle)
+// ==========================
+// Period comparison (compare=previous|yoy|custom)
+// ==========================
+
+// comparisonFilter membaca periode pembanding dan filter laporan perbandingan. Perbandingan selalu
+// per item (tanpa groupBy/location); false berarti respons error sudah ditulis.
+func (c *FinishedProductReportController) comparisonFilter(ctx *gin.Context, period apiRequest.Period) (finishedProductReportRepository.GetReportFilter, string, apiRequest.Period, bool) {
+	var filter finishedProductReportRepository.GetReportFilter
 
-	// Table headers - first row (row 9)
-	headers1 := []string{"No.", "KODE BARANG", "NAMA BARANG", "SAT", "SALDO AWAL", "PEMASUKAN", "PENGELUARAN", "PENYESUAIAN", "SALDO AKHIR", "STOK OPNAME", "SELISIH", "KETERANGAN"}
-	for i, header := range headers1 {
-		cell := fmt.Sprintf("%c9", 'A'+i)
-		f.SetCellValue(sheetName, cell, header)
+	mode, base, err := apiRequest.GetComparePeriod(ctx, period)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_COMPARE", "invalid compare period", err, gin.H{
+			"compare":      ctx.Query("compare"),
+			"compare_from": ctx.Query("compare_from"),
+			"compare_to":   ctx.Query("compare_to"),
+		})
+		return filter, mode, base, false
+	}
+	if ctx.Query("groupBy") != "" || ctx.Query("location") != "" {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_GROUP_BY", "groupBy and location are not supported together with compare", nil, gin.H{
+			"groupBy":  ctx.Query("groupBy"),
+			"location": ctx.Query("location"),
+		})
+		return filter, mode, base, false
 	}
 
-	// Merge cells for headers that span both rows
-	f.MergeCell(sheetName, "A9", "A10") // No.
-	f.MergeCell(sheetName, "B9", "B10") // KODE BARANG
-	f.MergeCell(sheetName, "C9", "C10") // NAMA BARANG
-	f.MergeCell(sheetName, "D9", "D10") // SAT
-	f.MergeCell(sheetName, "E9", "E10") // SALDO AWAL
-	f.MergeCell(sheetName, "F9", "F10") // PEMASUKAN
-	f.MergeCell(sheetName, "G9", "G10") // PENGELUARAN
-	f.MergeCell(sheetName, "H9", "H10") // PENYESUAIAN
-	f.MergeCell(sheetName, "K9", "K10") // SELISIH
-	f.MergeCell(sheetName, "L9", "L10") // KETERANGAN
-
-	// Second row headers (row 10) - only for columns I and J
-	f.SetCellValue(sheetName, "I10", to.Format("02-01-2006"))
-	f.SetCellValue(sheetName, "J10", to.Format("02-01-2006"))
-
-	// Fill data starting from row 11
-	for i, item := range data {
-		row := i + 11
-
-		// Convert decimal.Decimal to float64 for proper number formatting in Excel
-		awalFloat, _ := item.Awal.Float64()
-		mskFloat, _ := item.Masuk.Float64()
-		keluarFloat, _ := item.Keluar.Float64()
-		penyFloat, _ := item.Peny.Float64()
-		AkhirFloat, _ := item.Akhir.Float64()
-		selisihFloat, _ := item.Selisih.Float64()
-		opnameFloat, _ := item.Opname.Float64()
-
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), i+1)
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), item.ItemCode)
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), item.ItemName)
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), item.UnitCode)
-		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), awalFloat)
-		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), mskFloat)
-		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), keluarFloat)
-		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), penyFloat)
-		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), AkhirFloat)
-		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), opnameFloat)
-		f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), selisihFloat)
-		f.SetCellValue(sheetName, fmt.Sprintf("L%d", row), "")
+	opts, err := apiRequest.GetCompareOptions(ctx)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REPORT_OPTIONS", "invalid sort or filter parameter", err, gin.H{
+			"sort": ctx.Query("sort"),
+		})
+		return filter, mode, base, false
 	}
 
-	// Apply styling
-	// Header style
-	headerStyle, _ := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{Bold: true, Size: 12},
-		Alignment: &excelize.Alignment{
-			Horizontal: "center",
-			Vertical:   "center",
-		},
-		Border: []excelize.Border{
-			{Type: "left", Color: "000000", Style: 1},
-			{Type: "top", Color: "000000", Style: 1},
-			{Type: "bottom", Color: "000000", Style: 1},
-			{Type: "right", Color: "000000", Style: 1},
-		},
-	})
+	filter = finishedProductReportRepository.GetReportFilter{
+		From:     period.From,
+		To:       period.To,
+		ItemCode: ctx.Query("item_code"),
+		ItemName: ctx.Query("item_name"),
+		Options:  opts,
+	}
+	return filter, mode, base, true
+}
 
-	// Data style
-	dataStyle, _ := f.NewStyle(&excelize.Style{
-		Border: []excelize.Border{
-			{Type: "left", Color: "000000", Style: 1},
-			{Type: "top", Color: "000000", Style: 1},
-			{Type: "bottom", Color: "000000", Style: 1},
-			{Type: "right", Color: "000000", Style: 1},
-		},
-	})
+// getComparison menjawab GET dengan compare=...: masuk, keluar dan akhir per item untuk periode
+// berjalan dan pembanding berdampingan, dengan selisih absolut dan persentase.
+// Default terurut perubahan saldo akhir terbesar (sort=-abs_delta_akhir).
+func (c *FinishedProductReportController) getComparison(ctx *gin.Context, period apiRequest.Period) {
+	filter, mode, base, ok := c.comparisonFilter(ctx, period)
+	if !ok {
+		return
+	}
+
+	rows, err := c.FinishedProductReportService.Compare(ctx.Request.Context(), filter, base.From, base.To)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail to compare finished product report", err, gin.H{
+			"from":         period.From.Format("2006-01-02"),
+			"to":           period.To.Format("2006-01-02"),
+			"compare":      mode,
+			"compare_from": base.From.Format("2006-01-02"),
+			"compare_to":   base.To.Format("2006-01-02"),
+		})
+		return
+	}
+
+	page := apiRequest.ParseInt(ctx, "page", 0)
+	limit := apiRequest.ParseInt(ctx, "limit", 0)
+	totalCount := len(rows)
+	totalPages := 1
+	if limit > 0 {
+		totalPages = (totalCount + limit - 1) / limit
+	}
+	res := reportCache.Page(rows, page, limit)
 
-	// Number style for numeric columns (without decimal places)
-	numberStyle, _ := f.NewStyle(&excelize.Style{
-		NumFmt: 3, // "#,##0" - format ribuan tanpa desimal
-		Border: []excelize.Border{
-			{Type: "left", Color: "000000", Style: 1},
-			{Type: "top", Color: "000000", Style: 1},
-			{Type: "bottom", Color: "000000", Style: 1},
-			{Type: "right", Color: "000000", Style: 1},
+	apiresponse.OK(ctx, res, "ok", gin.H{
+		"from":      period.From.Format("2006-01-02"),
+		"to":        period.To.Format("2006-01-02"),
+		"period":    period.Meta(),
+		"compare":   gin.H{"mode": mode, "period": base.Meta()},
+		"item_code": filter.ItemCode,
+		"item_name": filter.ItemName,
+		"options":   filter.Options.Meta(),
+		"totals":    reportCompare.Totals(rows),
+		"pagination": gin.H{
+			"page":       page,
+			"limit":      limit,
+			"totalCount": totalCount,
+			"totalPages": totalPages,
+			"count":      len(res),
+			"hasNext":    limit > 0 && page < totalPages,
+			"hasPrev":    limit > 0 && page > 1,
 		},
 	})
+}
 
-	// Apply header styles
-	f.SetCellStyle(sheetName, "A9", "L10", headerStyle)
-
-	// Apply data styles
-	if len(data) > 0 {
-		lastRow := len(data) + 10
-		f.SetCellStyle(sheetName, "A11", fmt.Sprintf("A%d", lastRow), dataStyle)   // No
-		f.SetCellStyle(sheetName, "B11", fmt.Sprintf("D%d", lastRow), dataStyle)   // Text columns
-		f.SetCellStyle(sheetName, "E11", fmt.Sprintf("K%d", lastRow), numberStyle) // Number columns
-		f.SetCellStyle(sheetName, "L11", fmt.Sprintf("L%d", lastRow), dataStyle)   // Keterangan
+// exportComparison adalah export laporan perbandingan (format=xlsx|csv|pdf).
+func (c *FinishedProductReportController) exportComparison(ctx *gin.Context, format reportExport.Format, period apiRequest.Period) {
+	filter, mode, base, ok := c.comparisonFilter(ctx, period)
+	if !ok {
+		return
 	}
 
-	// Set column widths
-	f.SetColWidth(sheetName, "A", "A", 5)  // No
-	f.SetColWidth(sheetName, "B", "B", 15) // Kode Barang
-	f.SetColWidth(sheetName, "C", "C", 25) // Nama Barang
-	f.SetColWidth(sheetName, "D", "D", 8)  // Satuan
-	f.SetColWidth(sheetName, "E", "K", 12) // Numeric columns
-	f.SetColWidth(sheetName, "L", "L", 15) // Keterangan
-
-	// Generate filename with timestamp
-	timestamp := time.Now().Format("20060102150405")
-	filename := fmt.Sprintf("Finished_Product_Report_%s.xlsx", timestamp)
-
-	// Save the file
-	dir := "file/export"
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		return "", err
-	}
-	filePath := filepath.Join(dir, filename)
-	if err = f.SaveAs(filePath); err != nil {
-		return "", err
+	rows, err := c.FinishedProductReportService.Compare(ctx.Request.Context(), filter, base.From, base.To)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail to compare finished product report for export", err, gin.H{
+			"from":    period.From.Format("2006-01-02"),
+			"to":      period.To.Format("2006-01-02"),
+			"compare": mode,
+		})
+		return
 	}
-	filename = filePath
 
-	return filename, nil
+	reportExport.Send(ctx, format, reportExport.Document[model.ReportComparison]{
+		FileName:  fmt.Sprintf("laporan_perbandingan_barang_jadi_%s_%s_vs_%s_%s", period.From.Format("2006-01-02"), period.To.Format("2006-01-02"), base.From.Format("2006-01-02"), base.To.Format("2006-01-02")),
+		SheetName: "Perbandingan Barang Jadi",
+		Title:     "PERBANDINGAN MUTASI BARANG JADI",
+		Period:    reportExport.PeriodRange(period.From, period.To) + " vs " + reportExport.PeriodRange(base.From, base.To),
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   reportCompare.ExportColumns(comparisonLabel(period), comparisonLabel(base)),
+		Rows:      rows,
+	})
+}
+
+// comparisonLabel adalah judul kolom periode di export perbandingan: label preset (mis. 09-2026,
+// 2026Q3) atau rentang tanggal.
+func comparisonLabel(p apiRequest.Period) string {
+	if p.Label != "" {
+		return p.Label
+	}
+	return p.From.Format("02-01-2006") + " s.d " + p.To.Format("02-01-2006")
 }
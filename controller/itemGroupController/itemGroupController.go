@@ -22,7 +22,7 @@ func NewItemGroupController(svc *itemGroupService.ItemGroupService) *ItemGroupCo
 
 // GET /item-groups
 func (c *ItemGroupController) GetAll(ctx *gin.Context) {
-	res, err := c.ItemGroupService.GetAll()
+	res, err := c.ItemGroupService.GetAll(ctx.Request.Context())
 	if err != nil {
 		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail get item groups", err, gin.H{})
 		return
@@ -0,0 +1,466 @@
+package lotTraceController
+
+import (
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/lotTraceRepository"
+	"Bea-Cukai/service/companyProfileService"
+	"Bea-Cukai/service/lotTraceService"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LotTraceController struct {
+	LotTraceService       *lotTraceService.LotTraceService
+	CompanyProfileService *companyProfileService.CompanyProfileService
+}
+
+func NewLotTraceController(svc *lotTraceService.LotTraceService, companyProfileSvc *companyProfileService.CompanyProfileService) *LotTraceController {
+	return &LotTraceController{LotTraceService: svc, CompanyProfileService: companyProfileSvc}
+}
+
+// ==========================
+// Traceability endpoints
+// ==========================
+
+// GET /report/traceability/lots?noPabean=...&pabeanType=...&transNo=...&dataNo=...&asOf=YYYY-MM-DD&page=1&limit=10
+// Telusur maju: lot penerimaan dari dokumen pabean / transaksi / baris penerimaan, sisa pada asOf
+// (default hari ini) dan setiap pemakaiannya. Minimal satu dari noPabean, transNo atau dataNo wajib.
+func (c *LotTraceController) GetLots(ctx *gin.Context) {
+	asOf, ok := parseDate(ctx, "asOf")
+	if !ok {
+		return
+	}
+	filter := lotTraceRepository.Filter{
+		AsOf:       asOf,
+		PabeanType: ctx.Query("pabeanType"),
+		NoPabean:   strings.TrimSpace(ctx.Query("noPabean")),
+		TransNo:    strings.TrimSpace(ctx.Query("transNo")),
+		DataNo:     strings.TrimSpace(ctx.Query("dataNo")),
+		Page:       apiRequest.ParseInt(ctx, "page", 0),
+		Limit:      apiRequest.ParseInt(ctx, "limit", 0),
+	}
+	meta := gin.H{
+		"asOf":       asOf.Format("2006-01-02"),
+		"pabeanType": filter.PabeanType,
+		"noPabean":   filter.NoPabean,
+		"transNo":    filter.TransNo,
+		"dataNo":     filter.DataNo,
+	}
+	if filter.NoPabean == "" && filter.TransNo == "" && filter.DataNo == "" {
+		apiresponse.Error(ctx, http.StatusBadRequest, "MISSING_LOT_KEY", "noPabean, transNo or dataNo is required", nil, meta)
+		return
+	}
+
+	lots, totalCount, err := c.LotTraceService.GetLots(ctx.Request.Context(), filter)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get lots", err, meta)
+		return
+	}
+
+	// Calculate pagination metadata
+	totalPages, hasNext, hasPrev := 1, false, false
+	if filter.Limit > 0 && filter.Page > 0 {
+		totalPages = int((totalCount + int64(filter.Limit) - 1) / int64(filter.Limit)) // ceil division
+		hasNext = filter.Page < totalPages
+		hasPrev = filter.Page > 1
+	}
+	meta["pagination"] = gin.H{
+		"page":       filter.Page,
+		"limit":      filter.Limit,
+		"totalCount": totalCount,
+		"totalPages": totalPages,
+		"count":      len(lots),
+		"hasNext":    hasNext,
+		"hasPrev":    hasPrev,
+	}
+	apiresponse.OK(ctx, lots, "ok", meta)
+}
+
+// GET /report/traceability/origin?itemCode=...&date=YYYY-MM-DD
+// Telusur mundur: dari dokumen pabean mana stok itemCode pada date (default hari ini) berasal,
+// yaitu lot yang masih bersisa urut penerimaan.
+func (c *LotTraceController) GetOrigin(ctx *gin.Context) {
+	date, ok := parseDate(ctx, "date")
+	if !ok {
+		return
+	}
+	itemCode := strings.TrimSpace(ctx.Query("itemCode"))
+	meta := gin.H{"itemCode": itemCode, "date": date.Format("2006-01-02")}
+	if itemCode == "" {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "itemCode is required", nil, meta)
+		return
+	}
+
+	origin, err := c.LotTraceService.GetOrigin(ctx.Request.Context(), itemCode, date)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get lot origin", err, meta)
+		return
+	}
+	meta["lotCount"] = len(origin.Lots)
+	apiresponse.OK(ctx, origin, "ok", meta)
+}
+
+// GET /report/traceability/lot-balance?from=YYYY-MM-DD&to=YYYY-MM-DD&asOf=YYYY-MM-DD&pabeanType=...&noPabean=...&itemCode=...&onlyRemaining=true
+// Saldo lot per dokumen pabean pemasukan dengan tgl_pabean dalam periode; pemakaian dihitung
+// sampai asOf (default akhir periode).
+func (c *LotTraceController) GetLotBalance(ctx *gin.Context) {
+	filter, meta, ok := lotBalanceFilter(ctx)
+	if !ok {
+		return
+	}
+
+	lots, err := c.LotTraceService.GetLotBalance(ctx.Request.Context(), filter)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get lot balance", err, meta)
+		return
+	}
+	meta["count"] = len(lots)
+	apiresponse.OK(ctx, lots, "ok", meta)
+}
+
+// GET /report/traceability/lot-balance/export?...&format=xlsx|csv|pdf (filter sama dengan GetLotBalance)
+func (c *LotTraceController) ExportLotBalance(ctx *gin.Context) {
+	format, err := reportExport.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
+		})
+		return
+	}
+
+	filter, meta, ok := lotBalanceFilter(ctx)
+	if !ok {
+		return
+	}
+
+	lots, err := c.LotTraceService.GetLotBalance(ctx.Request.Context(), filter)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get lot balance", err, meta)
+		return
+	}
+
+	reportExport.Send(ctx, format, reportExport.Document[model.Lot]{
+		FileName:  fmt.Sprintf("saldo_lot_bahan_baku_%s_%s", filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02")),
+		SheetName: "Saldo Lot Bahan Baku",
+		Title:     "SALDO LOT BAHAN BAKU PER DOKUMEN PABEAN PEMASUKAN",
+		Period:    fmt.Sprintf("%s (posisi %s)", reportExport.PeriodRange(filter.From, filter.To), filter.AsOf.Format("02-01-2006")),
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   lotBalanceColumns(),
+		Rows:      lots,
+	})
+}
+
+// GET /report/traceability/aging?asOf=YYYY-MM-DD&itemCode=...&itemGroup=A,B&pabeanType=...&noPabean=...
+// Umur bahan baku impor yang belum terpakai: lot berdokumen pabean yang masih bersisa pada asOf
+// (default hari ini), umur dihitung dari tanggal penerimaan, nilai sisa dalam IDR.
+func (c *LotTraceController) GetAging(ctx *gin.Context) {
+	filter, meta, ok := agingFilter(ctx)
+	if !ok {
+		return
+	}
+
+	lots, summary, err := c.LotTraceService.GetAging(ctx.Request.Context(), filter)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get lot aging", err, meta)
+		return
+	}
+	meta["summary"] = summary
+	apiresponse.OK(ctx, lots, "ok", meta)
+}
+
+// GET /report/traceability/aging/export?...&format=xlsx|csv|pdf (filter sama dengan GetAging)
+func (c *LotTraceController) ExportAging(ctx *gin.Context) {
+	format, err := reportExport.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
+		})
+		return
+	}
+
+	filter, meta, ok := agingFilter(ctx)
+	if !ok {
+		return
+	}
+
+	lots, summary, err := c.LotTraceService.GetAging(ctx.Request.Context(), filter)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get lot aging", err, meta)
+		return
+	}
+
+	reportExport.Send(ctx, format, reportExport.Document[model.LotAging]{
+		FileName:  fmt.Sprintf("umur_bahan_baku_impor_%s", filter.AsOf.Format("2006-01-02")),
+		SheetName: "Umur Bahan Baku Impor",
+		Title:     "LAPORAN UMUR BAHAN BAKU IMPOR PER LOT PENERIMAAN",
+		Period:    "Posisi " + filter.AsOf.Format("02-01-2006"),
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   agingColumns(),
+		Rows:      lots,
+		Totals:    agingTotals(summary),
+	})
+}
+
+// GET /report/traceability/aging/documents?... (filter sama dengan GetAging)
+// Umur sisa bahan baku impor per dokumen pabean pemasukan.
+func (c *LotTraceController) GetAgingDocuments(ctx *gin.Context) {
+	filter, meta, ok := agingFilter(ctx)
+	if !ok {
+		return
+	}
+
+	docs, summary, err := c.LotTraceService.GetAgingDocuments(ctx.Request.Context(), filter)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get lot aging per document", err, meta)
+		return
+	}
+	meta["count"] = len(docs)
+	meta["summary"] = summary
+	apiresponse.OK(ctx, docs, "ok", meta)
+}
+
+// GET /report/traceability/aging/documents/export?...&format=xlsx|csv|pdf (filter sama dengan GetAging)
+func (c *LotTraceController) ExportAgingDocuments(ctx *gin.Context) {
+	format, err := reportExport.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
+		})
+		return
+	}
+
+	filter, meta, ok := agingFilter(ctx)
+	if !ok {
+		return
+	}
+
+	docs, summary, err := c.LotTraceService.GetAgingDocuments(ctx.Request.Context(), filter)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get lot aging per document", err, meta)
+		return
+	}
+
+	reportExport.Send(ctx, format, reportExport.Document[model.AgingDocument]{
+		FileName:  fmt.Sprintf("umur_bahan_baku_impor_per_dokumen_%s", filter.AsOf.Format("2006-01-02")),
+		SheetName: "Umur per Dokumen",
+		Title:     "LAPORAN UMUR BAHAN BAKU IMPOR PER DOKUMEN PABEAN PEMASUKAN",
+		Period:    "Posisi " + filter.AsOf.Format("02-01-2006"),
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   agingDocumentColumns(),
+		Rows:      docs,
+		Totals:    agingDocumentTotals(summary),
+	})
+}
+
+// agingFilter membaca filter laporan umur bahan baku; ok = false bila respons error sudah dikirim.
+func agingFilter(ctx *gin.Context) (lotTraceRepository.Filter, gin.H, bool) {
+	asOf, ok := parseDate(ctx, "asOf")
+	if !ok {
+		return lotTraceRepository.Filter{}, nil, false
+	}
+
+	filter := lotTraceRepository.Filter{
+		AsOf:       asOf,
+		PabeanType: ctx.Query("pabeanType"),
+		NoPabean:   strings.TrimSpace(ctx.Query("noPabean")),
+		ItemCode:   strings.TrimSpace(ctx.Query("itemCode")),
+		ItemGroup:  apiRequest.ParseList(ctx, "itemGroup"),
+	}
+	return filter, gin.H{
+		"asOf":       asOf.Format("2006-01-02"),
+		"pabeanType": filter.PabeanType,
+		"noPabean":   filter.NoPabean,
+		"itemCode":   filter.ItemCode,
+		"itemGroup":  filter.ItemGroup,
+		"buckets":    model.AgingBuckets,
+	}, true
+}
+
+// lotBalanceFilter membaca filter laporan saldo lot; ok = false bila respons error sudah dikirim.
+func lotBalanceFilter(ctx *gin.Context) (lotTraceRepository.Filter, gin.H, bool) {
+	period, err := apiRequest.GetPeriod(ctx)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
+			"from": ctx.Query("from"),
+			"to":   ctx.Query("to"),
+		})
+		return lotTraceRepository.Filter{}, nil, false
+	}
+
+	asOf := period.To
+	if ctx.Query("asOf") != "" {
+		date, ok := parseDate(ctx, "asOf")
+		if !ok {
+			return lotTraceRepository.Filter{}, nil, false
+		}
+		if date.Before(period.From) {
+			apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "asOf must not be before from", nil, gin.H{
+				"from": period.From.Format("2006-01-02"),
+				"asOf": ctx.Query("asOf"),
+			})
+			return lotTraceRepository.Filter{}, nil, false
+		}
+		asOf = date
+	}
+
+	filter := lotTraceRepository.Filter{
+		AsOf:          asOf,
+		From:          period.From,
+		To:            period.To,
+		PabeanType:    ctx.Query("pabeanType"),
+		NoPabean:      strings.TrimSpace(ctx.Query("noPabean")),
+		ItemCode:      strings.TrimSpace(ctx.Query("itemCode")),
+		OnlyRemaining: ctx.Query("onlyRemaining") == "true",
+	}
+	return filter, gin.H{
+		"period":        period.Meta(),
+		"asOf":          asOf.Format("2006-01-02"),
+		"pabeanType":    filter.PabeanType,
+		"noPabean":      filter.NoPabean,
+		"itemCode":      filter.ItemCode,
+		"onlyRemaining": filter.OnlyRemaining,
+	}, true
+}
+
+// parseDate membaca tanggal YYYY-MM-DD dari query key (kosong = hari ini); ok = false bila
+// respons error sudah dikirim.
+func parseDate(ctx *gin.Context, key string) (time.Time, bool) {
+	v := strings.TrimSpace(ctx.Query(key))
+	if v == "" {
+		now := time.Now().In(apiRequest.Location())
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), true
+	}
+	date, err := time.ParseInLocation("2006-01-02", v, apiRequest.Location())
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE", key+" must be in YYYY-MM-DD format", errors.New("invalid date"), gin.H{
+			key: v,
+		})
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// lotBalanceColumns adalah definisi kolom export (xlsx/csv/pdf) saldo lot per dokumen pabean.
+func lotBalanceColumns() []reportExport.Column[model.Lot] {
+	return []reportExport.Column[model.Lot]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.Lot) any { return i + 1 }},
+		{Key: "jenis_pabean", Group: "DOKUMEN PABEAN", Title: "JENIS", Width: 10, Value: func(_ int, r model.Lot) any { return r.JenisPabean }},
+		{Key: "no_pabean", Group: "DOKUMEN PABEAN", Title: "NOMOR", Width: 14, Value: func(_ int, r model.Lot) any { return r.NoPabean }},
+		{Key: "tgl_pabean", Group: "DOKUMEN PABEAN", Title: "TANGGAL", Width: 12, Value: func(_ int, r model.Lot) any { return reportExport.DatePtr(r.TglPabean) }},
+		{Key: "vendor_name", Title: "PENGIRIM BARANG", Width: 22, Value: func(_ int, r model.Lot) any { return r.VendorName }},
+		{Key: "trans_no", Group: "PENERIMAAN", Title: "NO. TRANSAKSI", Width: 16, Value: func(_ int, r model.Lot) any { return r.TransNo }},
+		{Key: "data_no", Group: "PENERIMAAN", Title: "NO. LOT", Width: 12, Value: func(_ int, r model.Lot) any { return r.DataNo }},
+		{Key: "in_date", Group: "PENERIMAAN", Title: "TANGGAL", Width: 12, Value: func(_ int, r model.Lot) any { return reportExport.Date(r.InDate) }},
+		{Key: "item_code", Title: "KODE BARANG", Width: 15, Value: func(_ int, r model.Lot) any { return r.ItemCode }},
+		{Key: "item_name", Title: "NAMA BARANG", Width: 28, Value: func(_ int, r model.Lot) any { return r.ItemName }},
+		{Key: "unit", Title: "SATUAN", Width: 8, Value: func(_ int, r model.Lot) any { return r.Unit }},
+		{Key: "received_qty", Title: "DITERIMA", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.Lot) any { return reportExport.Decimal(r.ReceivedQty) }},
+		{Key: "consumed_qty", Title: "DIPAKAI", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.Lot) any { return reportExport.Decimal(r.ConsumedQty) }},
+		{Key: "remaining_qty", Title: "SISA", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.Lot) any { return reportExport.Decimal(r.RemainingQty) }},
+	}
+}
+
+// agingColumns adalah definisi kolom export umur bahan baku per lot; sisa qty ditulis di kolom
+// kelompok umurnya.
+func agingColumns() []reportExport.Column[model.LotAging] {
+	bucketQty := func(bucket string) func(int, model.LotAging) any {
+		return func(_ int, r model.LotAging) any {
+			if r.AgingBucket != bucket {
+				return nil
+			}
+			return reportExport.Decimal(r.RemainingQty)
+		}
+	}
+	return []reportExport.Column[model.LotAging]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.LotAging) any { return i + 1 }},
+		{Key: "jenis_pabean", Group: "DOKUMEN PABEAN", Title: "JENIS", Width: 10, Value: func(_ int, r model.LotAging) any { return r.JenisPabean }},
+		{Key: "no_pabean", Group: "DOKUMEN PABEAN", Title: "NOMOR", Width: 14, Value: func(_ int, r model.LotAging) any { return r.NoPabean }},
+		{Key: "tgl_pabean", Group: "DOKUMEN PABEAN", Title: "TANGGAL", Width: 12, Value: func(_ int, r model.LotAging) any { return reportExport.DatePtr(r.TglPabean) }},
+		{Key: "trans_no", Group: "PENERIMAAN", Title: "NO. TRANSAKSI", Width: 16, Value: func(_ int, r model.LotAging) any { return r.TransNo }},
+		{Key: "data_no", Group: "PENERIMAAN", Title: "NO. LOT", Width: 12, Value: func(_ int, r model.LotAging) any { return r.DataNo }},
+		{Key: "in_date", Group: "PENERIMAAN", Title: "TANGGAL", Width: 12, Value: func(_ int, r model.LotAging) any { return reportExport.Date(r.InDate) }},
+		{Key: "item_code", Title: "KODE BARANG", Width: 15, Value: func(_ int, r model.LotAging) any { return r.ItemCode }},
+		{Key: "item_name", Title: "NAMA BARANG", Width: 28, Value: func(_ int, r model.LotAging) any { return r.ItemName }},
+		{Key: "item_group", Title: "KELOMPOK", Width: 12, Value: func(_ int, r model.LotAging) any { return r.ItemGroup }},
+		{Key: "unit", Title: "SATUAN", Width: 8, Value: func(_ int, r model.LotAging) any { return r.Unit }},
+		{Key: "age_days", Title: "UMUR (HARI)", Width: 10, Number: true, Value: func(_ int, r model.LotAging) any { return r.AgeDays }},
+		{Key: "qty_0_90", Group: "SISA PER UMUR (HARI)", Title: model.AgingBucket0To90, Width: 13, Number: true, Decimals: 2, Value: bucketQty(model.AgingBucket0To90)},
+		{Key: "qty_91_180", Group: "SISA PER UMUR (HARI)", Title: model.AgingBucket91To180, Width: 13, Number: true, Decimals: 2, Value: bucketQty(model.AgingBucket91To180)},
+		{Key: "qty_181_365", Group: "SISA PER UMUR (HARI)", Title: model.AgingBucket181To365, Width: 13, Number: true, Decimals: 2, Value: bucketQty(model.AgingBucket181To365)},
+		{Key: "qty_over_365", Group: "SISA PER UMUR (HARI)", Title: model.AgingBucketOver365, Width: 13, Number: true, Decimals: 2, Value: bucketQty(model.AgingBucketOver365)},
+		{Key: "curr_code", Title: "VALUTA", Width: 8, Value: func(_ int, r model.LotAging) any { return r.CurrCode }},
+		{Key: "unit_price", Title: "HARGA SATUAN", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.LotAging) any { return reportExport.DecimalPtr(r.UnitPrice) }},
+		{Key: "idr_rate", Title: "KURS", Width: 12, Number: true, Decimals: 2, Value: func(_ int, r model.LotAging) any { return reportExport.DecimalPtr(r.IdrRate) }},
+		{Key: "remaining_value_idr", Title: "NILAI SISA (IDR)", Width: 18, Number: true, Decimals: 2, Value: func(_ int, r model.LotAging) any { return reportExport.DecimalPtr(r.RemainingValueIdr) }},
+	}
+}
+
+// agingDocumentColumns adalah definisi kolom export umur bahan baku per dokumen pabean.
+func agingDocumentColumns() []reportExport.Column[model.AgingDocument] {
+	return []reportExport.Column[model.AgingDocument]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.AgingDocument) any { return i + 1 }},
+		{Key: "jenis_pabean", Group: "DOKUMEN PABEAN", Title: "JENIS", Width: 10, Value: func(_ int, r model.AgingDocument) any { return r.JenisPabean }},
+		{Key: "no_pabean", Group: "DOKUMEN PABEAN", Title: "NOMOR", Width: 14, Value: func(_ int, r model.AgingDocument) any { return r.NoPabean }},
+		{Key: "tgl_pabean", Group: "DOKUMEN PABEAN", Title: "TANGGAL", Width: 12, Value: func(_ int, r model.AgingDocument) any { return reportExport.DatePtr(r.TglPabean) }},
+		{Key: "vendor_name", Title: "PENGIRIM BARANG", Width: 22, Value: func(_ int, r model.AgingDocument) any { return r.VendorName }},
+		{Key: "lot_count", Title: "JUMLAH LOT", Width: 10, Number: true, Value: func(_ int, r model.AgingDocument) any { return r.LotCount }},
+		{Key: "oldest_in_date", Title: "PENERIMAAN TERLAMA", Width: 14, Value: func(_ int, r model.AgingDocument) any { return reportExport.Date(r.OldestInDate) }},
+		{Key: "max_age_days", Title: "UMUR MAKS. (HARI)", Width: 12, Number: true, Value: func(_ int, r model.AgingDocument) any { return r.MaxAgeDays }},
+		{Key: "value_0_90", Group: "NILAI SISA (IDR) PER UMUR (HARI)", Title: model.AgingBucket0To90, Width: 16, Number: true, Decimals: 2, Value: func(_ int, r model.AgingDocument) any { return reportExport.Decimal(r.RemainingValueIdr.Days0To90) }},
+		{Key: "value_91_180", Group: "NILAI SISA (IDR) PER UMUR (HARI)", Title: model.AgingBucket91To180, Width: 16, Number: true, Decimals: 2, Value: func(_ int, r model.AgingDocument) any { return reportExport.Decimal(r.RemainingValueIdr.Days91To180) }},
+		{Key: "value_181_365", Group: "NILAI SISA (IDR) PER UMUR (HARI)", Title: model.AgingBucket181To365, Width: 16, Number: true, Decimals: 2, Value: func(_ int, r model.AgingDocument) any { return reportExport.Decimal(r.RemainingValueIdr.Days181To365) }},
+		{Key: "value_over_365", Group: "NILAI SISA (IDR) PER UMUR (HARI)", Title: model.AgingBucketOver365, Width: 16, Number: true, Decimals: 2, Value: func(_ int, r model.AgingDocument) any { return reportExport.Decimal(r.RemainingValueIdr.Over365) }},
+		{Key: "value_total", Group: "NILAI SISA (IDR) PER UMUR (HARI)", Title: "TOTAL", Width: 18, Number: true, Decimals: 2, Value: func(_ int, r model.AgingDocument) any { return reportExport.Decimal(r.RemainingValueIdr.Total) }},
+		{Key: "missing_value_count", Title: "LOT TANPA NILAI", Width: 10, Number: true, Value: func(_ int, r model.AgingDocument) any { return r.MissingValueCount }},
+	}
+}
+
+// agingTotals adalah baris total export per lot: nilai sisa IDR per kelompok umur lalu totalnya.
+func agingTotals(summary model.AgingSummary) []reportExport.TotalRow {
+	rows := make([]reportExport.TotalRow, 0, len(summary.Buckets)+1)
+	for _, b := range summary.Buckets {
+		rows = append(rows, reportExport.TotalRow{
+			Label:  fmt.Sprintf("TOTAL UMUR %s HARI (%d lot)", b.Bucket, b.LotCount),
+			Values: map[string]any{"remaining_value_idr": reportExport.Decimal(b.RemainingValueIdr)},
+		})
+	}
+	return append(rows, agingGrandTotal(summary, "remaining_value_idr"))
+}
+
+// agingDocumentTotals adalah baris total export per dokumen pabean.
+func agingDocumentTotals(summary model.AgingSummary) []reportExport.TotalRow {
+	keys := map[string]string{
+		model.AgingBucket0To90:    "value_0_90",
+		model.AgingBucket91To180:  "value_91_180",
+		model.AgingBucket181To365: "value_181_365",
+		model.AgingBucketOver365:  "value_over_365",
+	}
+	values := map[string]any{"lot_count": summary.LotCount}
+	for _, b := range summary.Buckets {
+		values[keys[b.Bucket]] = reportExport.Decimal(b.RemainingValueIdr)
+	}
+	total := agingGrandTotal(summary, "value_total")
+	for k, v := range values {
+		total.Values[k] = v
+	}
+	return []reportExport.TotalRow{total}
+}
+
+// agingGrandTotal adalah baris total nilai sisa IDR seluruh lot.
+func agingGrandTotal(summary model.AgingSummary, valueKey string) reportExport.TotalRow {
+	label := "TOTAL NILAI SISA DALAM IDR"
+	if !summary.Complete {
+		label += fmt.Sprintf(" (tidak termasuk %d lot tanpa harga / kurs)", summary.MissingValueCount)
+	}
+	return reportExport.TotalRow{Label: label, Values: map[string]any{
+		valueKey: reportExport.Decimal(summary.RemainingValueIdr),
+	}}
+}
@@ -0,0 +1,151 @@
+package lpjBundleController
+
+import (
+	"Bea-Cukai/controller/auxiliaryMaterialReportController"
+	"Bea-Cukai/controller/entryProductController"
+	"Bea-Cukai/controller/expenditureProductController"
+	"Bea-Cukai/controller/finishedProductReportController"
+	"Bea-Cukai/controller/machineToolReportController"
+	"Bea-Cukai/controller/rawMaterialReportController"
+	"Bea-Cukai/controller/rejectScrapReportController"
+	"Bea-Cukai/controller/wipPositionReportController"
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/service/companyProfileService"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LpjBundleController menggabungkan seluruh laporan LPJ satu periode dalam satu workbook
+// (cover + satu sheet per laporan) memakai layout export masing-masing controller laporan.
+
+// DefaultLap adalah item group laporan bahan penolong bila parameter lap tidak diisi.
+const DefaultLap = "AUXILIARY"
+
+type LpjBundleController struct {
+	EntryProduct            *entryProductController.EntryProductController
+	ExpenditureProduct      *expenditureProductController.ExpenditureProductController
+	RawMaterialReport       *rawMaterialReportController.RawMaterialReportController
+	FinishedProductReport   *finishedProductReportController.FinishedProductReportController
+	WipPositionReport       *wipPositionReportController.WipPositionReportController
+	MachineToolReport       *machineToolReportController.MachineToolReportController
+	RejectScrapReport       *rejectScrapReportController.RejectScrapReportController
+	AuxiliaryMaterialReport *auxiliaryMaterialReportController.AuxiliaryMaterialReportController
+	CompanyProfileService   *companyProfileService.CompanyProfileService
+}
+
+func NewLpjBundleController(
+	entryProduct *entryProductController.EntryProductController,
+	expenditureProduct *expenditureProductController.ExpenditureProductController,
+	rawMaterialReport *rawMaterialReportController.RawMaterialReportController,
+	finishedProductReport *finishedProductReportController.FinishedProductReportController,
+	wipPositionReport *wipPositionReportController.WipPositionReportController,
+	machineToolReport *machineToolReportController.MachineToolReportController,
+	rejectScrapReport *rejectScrapReportController.RejectScrapReportController,
+	auxiliaryMaterialReport *auxiliaryMaterialReportController.AuxiliaryMaterialReportController,
+	companyProfileSvc *companyProfileService.CompanyProfileService,
+) *LpjBundleController {
+	return &LpjBundleController{
+		EntryProduct:            entryProduct,
+		ExpenditureProduct:      expenditureProduct,
+		RawMaterialReport:       rawMaterialReport,
+		FinishedProductReport:   finishedProductReport,
+		WipPositionReport:       wipPositionReport,
+		MachineToolReport:       machineToolReport,
+		RejectScrapReport:       rejectScrapReport,
+		AuxiliaryMaterialReport: auxiliaryMaterialReport,
+		CompanyProfileService:   companyProfileSvc,
+	}
+}
+
+// bundleSource adalah satu laporan di bundle: nama (untuk pesan error) dan loader sheet-nya.
+type bundleSource struct {
+	Report string
+	Load   func() (reportExport.Sheet, error)
+}
+
+// GET /report/lpj-bundle/export?from=YYYY-MM-DD&to=YYYY-MM-DD&lap=AUXILIARY,...&format=xlsx|zip
+// format=zip menghasilkan ZIP berisi satu CSV per laporan dan index.csv.
+func (c *LpjBundleController) Export(ctx *gin.Context) {
+	format, err := reportExport.ParseBundleFormat(ctx.Query("format"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
+		})
+		return
+	}
+
+	period, err := apiRequest.GetPeriod(ctx)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
+			"from": ctx.Query("from"),
+			"to":   ctx.Query("to"),
+		})
+		return
+	}
+	from, to := period.From, period.To
+
+	laps := parseLaps(ctx.Query("lap"))
+	rctx := ctx.Request.Context()
+
+	sources := []bundleSource{
+		{"entry-products", func() (reportExport.Sheet, error) { return c.EntryProduct.BundleSheet(rctx, from, to) }},
+		{"expenditure-products", func() (reportExport.Sheet, error) { return c.ExpenditureProduct.BundleSheet(rctx, from, to) }},
+		{"raw-material", func() (reportExport.Sheet, error) { return c.RawMaterialReport.BundleSheet(rctx, from, to) }},
+		{"finished-product", func() (reportExport.Sheet, error) { return c.FinishedProductReport.BundleSheet(rctx, from, to) }},
+		{"wip-position", func() (reportExport.Sheet, error) { return c.WipPositionReport.BundleSheet(rctx, from, to) }},
+		{"machine-tool", func() (reportExport.Sheet, error) { return c.MachineToolReport.BundleSheet(rctx, from, to) }},
+		{"reject-scrap-product", func() (reportExport.Sheet, error) { return c.RejectScrapReport.BundleSheet(rctx, from, to) }},
+	}
+	for _, lap := range laps {
+		sources = append(sources, bundleSource{"auxiliary-material:" + lap, func() (reportExport.Sheet, error) {
+			return c.AuxiliaryMaterialReport.BundleSheet(rctx, from, to, lap)
+		}})
+	}
+
+	sheets := make([]reportExport.Sheet, 0, len(sources))
+	for _, src := range sources {
+		sheet, err := src.Load()
+		if err != nil {
+			apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail to get report for LPJ bundle", err, gin.H{
+				"report": src.Report,
+				"from":   from.Format("2006-01-02"),
+				"to":     to.Format("2006-01-02"),
+			})
+			return
+		}
+		sheets = append(sheets, sheet)
+	}
+
+	reportExport.SendBundle(ctx, format, reportExport.Bundle{
+		FileName: fmt.Sprintf("bundel_lpj_%s_%s", from.Format("2006-01-02"), to.Format("2006-01-02")),
+		Title:    "BUNDEL LAPORAN PERTANGGUNGJAWABAN (LPJ) KAWASAN BERIKAT",
+		Period:   reportExport.PeriodRange(from, to),
+		Profile:  c.CompanyProfileService.GetForExport(),
+		SignDate: time.Now(),
+		Sheets:   sheets,
+	})
+}
+
+// parseLaps membaca lap=A,B (item group bahan penolong); kosong = DefaultLap.
+func parseLaps(v string) []string {
+	var laps []string
+	seen := map[string]bool{}
+	for _, lap := range strings.Split(v, ",") {
+		lap = strings.ToUpper(strings.TrimSpace(lap))
+		if lap == "" || seen[lap] {
+			continue
+		}
+		seen[lap] = true
+		laps = append(laps, lap)
+	}
+	if len(laps) == 0 {
+		return []string{DefaultLap}
+	}
+	return laps
+}
@@ -3,35 +3,35 @@ package machineToolReportController
 import (
 	"Bea-Cukai/helper/apiRequest"
 	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/reportExport"
 	"Bea-Cukai/model"
 	"Bea-Cukai/repo/machineToolReportRepository"
+	"Bea-Cukai/service/companyProfileService"
 	"Bea-Cukai/service/machineToolReportService"
+	"context"
 	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/xuri/excelize/v2"
 )
 
 type MachineToolReportController struct {
 	MachineToolReportService *machineToolReportService.MachineToolReportService
+	CompanyProfileService    *companyProfileService.CompanyProfileService
 }
 
-func NewMachineToolReportController(svc *machineToolReportService.MachineToolReportService) *MachineToolReportController {
-	return &MachineToolReportController{MachineToolReportService: svc}
+func NewMachineToolReportController(svc *machineToolReportService.MachineToolReportService, companyProfileSvc *companyProfileService.CompanyProfileService) *MachineToolReportController {
+	return &MachineToolReportController{MachineToolReportService: svc, CompanyProfileService: companyProfileSvc}
 }
 
 // ==========================
 // Report endpoints
 // ==========================
 
-// GET /report/machine-tool?from=YYYY-MM-DD&to=YYYY-MM-DD&item_code=...&item_name=...&page=1&limit=10
+// GET /report/machine-tool?from=YYYY-MM-DD&to=YYYY-MM-DD&item_code=...&item_name=...&sort=...&page=1&limit=10
 func (c *MachineToolReportController) GetReport(ctx *gin.Context) {
-	from, to, err := apiRequest.GetRange(ctx)
+	period, err := apiRequest.GetPeriod(ctx)
 	if err != nil {
 		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
 			"from": ctx.Query("from"),
@@ -39,11 +39,21 @@ func (c *MachineToolReportController) GetReport(ctx *gin.Context) {
 		})
 		return
 	}
+	from, to := period.From, period.To
 
 	// Get optional filter parameters
 	itemCode := ctx.Query("item_code")
 	itemName := ctx.Query("item_name")
 
+	// Get sort and filter options
+	opts, err := apiRequest.GetReportOptions(ctx, machineToolReportRepository.ReportColumns)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REPORT_OPTIONS", "invalid sort or filter parameter", err, gin.H{
+			"sort": ctx.Query("sort"),
+		})
+		return
+	}
+
 	// Get pagination parameters
 	page := apiRequest.ParseInt(ctx, "page", 0)
 	limit := apiRequest.ParseInt(ctx, "limit", 0)
@@ -53,12 +63,13 @@ func (c *MachineToolReportController) GetReport(ctx *gin.Context) {
 		To:       to,
 		ItemCode: itemCode,
 		ItemName: itemName,
+		Options:  opts,
 		Page:     page,
 		Limit:    limit,
 	}
-	res, totalCount, err := c.MachineToolReportService.GetReport(filter)
+	res, totalCount, err := c.MachineToolReportService.GetReport(ctx.Request.Context(), filter)
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get machine and tool report", err, gin.H{
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail to get machine and tool report", err, gin.H{
 			"from":      from.Format("2006-01-02"),
 			"to":        to.Format("2006-01-02"),
 			"item_code": itemCode,
@@ -86,8 +97,10 @@ func (c *MachineToolReportController) GetReport(ctx *gin.Context) {
 	apiresponse.OK(ctx, res, "ok", gin.H{
 		"from":      from.Format("2006-01-02"),
 		"to":        to.Format("2006-01-02"),
+		"period":    period.Meta(),
 		"item_code": itemCode,
 		"item_name": itemName,
+		"options":   opts.Meta(),
 		"pagination": gin.H{
 			"page":       page,
 			"limit":      limit,
@@ -100,9 +113,17 @@ func (c *MachineToolReportController) GetReport(ctx *gin.Context) {
 	})
 }
 
-// ExportExcel generates Excel file for machine tool report
+// ExportExcel generates the machine tool report export (format=xlsx|csv|pdf, default xlsx)
 func (c *MachineToolReportController) ExportExcel(ctx *gin.Context) {
-	from, to, err := apiRequest.GetRange(ctx)
+	format, err := reportExport.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
+		})
+		return
+	}
+
+	period, err := apiRequest.GetPeriod(ctx)
 	if err != nil {
 		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
 			"from": ctx.Query("from"),
@@ -110,23 +131,34 @@ func (c *MachineToolReportController) ExportExcel(ctx *gin.Context) {
 		})
 		return
 	}
+	from, to := period.From, period.To
 
 	// Get optional filter parameters
 	itemCode := ctx.Query("item_code")
 	itemName := ctx.Query("item_name")
 
+	// Get sort and filter options
+	opts, err := apiRequest.GetReportOptions(ctx, machineToolReportRepository.ReportColumns)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REPORT_OPTIONS", "invalid sort or filter parameter", err, gin.H{
+			"sort": ctx.Query("sort"),
+		})
+		return
+	}
+
 	filter := machineToolReportRepository.GetReportFilter{
 		From:     from,
 		To:       to,
 		ItemCode: itemCode,
 		ItemName: itemName,
+		Options:  opts,
 		Page:     0, // No pagination for export
 		Limit:    0, // Get all data
 	}
 
-	res, _, err := c.MachineToolReportService.GetReport(filter)
+	res, _, err := c.MachineToolReportService.GetReport(ctx.Request.Context(), filter)
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get machine tool report", err, gin.H{
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail to get machine tool report", err, gin.H{
 			"from":      from.Format("2006-01-02"),
 			"to":        to.Format("2006-01-02"),
 			"item_code": itemCode,
@@ -135,208 +167,51 @@ func (c *MachineToolReportController) ExportExcel(ctx *gin.Context) {
 		return
 	}
 
-	// Generate Excel file
-	filename, err := c.generateExcelFile(res, from, to)
-	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "EXCEL_GENERATION_FAILED", "fail to generate excel file", err, nil)
-		return
-	}
-
-	// Set headers for file download
-	ctx.Header("Content-Description", "File Transfer")
-	ctx.Header("Content-Disposition", "attachment; filename="+filename)
-	ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	ctx.Header("Content-Transfer-Encoding", "binary")
-	ctx.Header("Expires", "0")
-	ctx.Header("Cache-Control", "must-revalidate")
-	ctx.Header("Pragma", "public")
-
-	// Stream the file
-	ctx.File(filename)
+	doc := c.exportDocument(from, to)
+	doc.Rows = res
+	reportExport.Send(ctx, format, doc)
 }
 
-func (c *MachineToolReportController) generateExcelFile(data []model.MachineToolReportResponse, from, to time.Time) (string, error) {
-	// Create a new workbook
-	f := excelize.NewFile()
-
-	// Create a new worksheet
-	sheetName := "Laporan Mesin dan Peralatan"
-	index, err := f.NewSheet(sheetName)
+// BundleSheet adalah laporan mutasi mesin dan peralatan periode from..to tanpa filter tambahan (bundle LPJ).
+func (c *MachineToolReportController) BundleSheet(ctx context.Context, from, to time.Time) (reportExport.Sheet, error) {
+	res, _, err := c.MachineToolReportService.GetReport(ctx, machineToolReportRepository.GetReportFilter{From: from, To: to})
 	if err != nil {
-		return "", err
-	}
-
-	// Set active sheet
-	f.SetActiveSheet(index)
-
-	// Company header section (similar to raw material report)
-	f.SetCellValue(sheetName, "A1", "PT FUKUSUKE KOGYO INDONESIA")
-	f.MergeCell(sheetName, "A1", "L1")
-
-	f.SetCellValue(sheetName, "A2", "LAPORAN PERTANGGUNGJAWABAN MUTASI MESIN DAN PERALATAN")
-	f.MergeCell(sheetName, "A2", "L2")
-
-	// Empty row
-	f.SetCellValue(sheetName, "A3", "")
-
-	// Company information section
-	f.SetCellValue(sheetName, "A4", "Nama Kawasan Berikat")
-	f.SetCellValue(sheetName, "C4", ": PT FUKUSUKE KOGYO INDONESIA")
-	f.MergeCell(sheetName, "C4", "L4")
-
-	f.SetCellValue(sheetName, "A5", "NPWP")
-	f.SetCellValue(sheetName, "C5", ": 01.071.250.3-052.000")
-	f.MergeCell(sheetName, "C5", "L5")
-
-	f.SetCellValue(sheetName, "A6", "Alamat")
-	f.SetCellValue(sheetName, "C6", ": Blok M-3-2, Kawasan MM2100, Cikarang Barat, Bekasi, 17520")
-	f.MergeCell(sheetName, "C6", "L6")
-
-	f.SetCellValue(sheetName, "A7", "Periode Laporan")
-	f.SetCellValue(sheetName, "C7", fmt.Sprintf(": %s s.d %s", from.Format("02-01-2006"), to.Format("02-01-2006")))
-	f.MergeCell(sheetName, "C7", "L7")
-
-	// Table headers - first row (row 9)
-	headers1 := []string{"No.", "KODE BARANG", "NAMA BARANG", "SAT", "SALDO AWAL", "PEMASUKAN", "PENGELUARAN", "PENYESUAIAN", "SALDO AKHIR", "STOK OPNAME", "SELISIH", "KETERANGAN"}
-	for i, header := range headers1 {
-		cell := fmt.Sprintf("%c9", 'A'+i)
-		f.SetCellValue(sheetName, cell, header)
-	}
-
-	// Merge cells for headers that span both rows
-	f.MergeCell(sheetName, "A9", "A10") // No.
-	f.MergeCell(sheetName, "B9", "B10") // KODE BARANG
-	f.MergeCell(sheetName, "C9", "C10") // NAMA BARANG
-	f.MergeCell(sheetName, "D9", "D10") // SAT
-	f.MergeCell(sheetName, "E9", "E10") // SALDO AWAL
-	f.MergeCell(sheetName, "F9", "F10") // PEMASUKAN
-	f.MergeCell(sheetName, "G9", "G10") // PENGELUARAN
-	f.MergeCell(sheetName, "H9", "H10") // PENYESUAIAN
-	f.MergeCell(sheetName, "K9", "K10") // SELISIH
-	f.MergeCell(sheetName, "L9", "L10") // KETERANGAN
-
-	// Second row headers (row 10) - only for columns I and J
-	f.SetCellValue(sheetName, "I10", to.Format("02-01-2006"))
-	f.SetCellValue(sheetName, "J10", to.Format("02-01-2006"))
-
-	// Fill data starting from row 11
-	for i, item := range data {
-		row := i + 11
-		
-		// Convert string values to float for proper display
-		awal, _ := strconv.ParseFloat(item.Awal, 64)
-		masuk, _ := strconv.ParseFloat(item.Masuk, 64)
-		kel, _ := strconv.ParseFloat(item.Kel, 64)
-		peny, _ := strconv.ParseFloat(item.Peny, 64)
-		opname, _ := strconv.ParseFloat(item.Opname, 64)
-		selisih, _ := strconv.ParseFloat(item.Selisih, 64)
-
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), i+1)
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), item.ItemCode)
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), item.ItemName)
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), item.UnitCode)
-		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), awal)
-		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), masuk)
-		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), kel)
-		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), peny)
-		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), opname)
-		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), opname)
-		f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), selisih)
-		f.SetCellValue(sheetName, fmt.Sprintf("L%d", row), "")
+		return nil, err
 	}
 
-	// Apply styling (similar to raw material report)
-	// Header style
-	headerStyle, _ := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{Bold: true, Size: 12},
-		Alignment: &excelize.Alignment{
-			Horizontal: "center",
-			Vertical:   "center",
-		},
-		Border: []excelize.Border{
-			{Type: "left", Color: "000000", Style: 1},
-			{Type: "top", Color: "000000", Style: 1},
-			{Type: "bottom", Color: "000000", Style: 1},
-			{Type: "right", Color: "000000", Style: 1},
-		},
-	})
-
-	// Company header style
-	companyHeaderStyle, _ := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{Bold: true, Size: 14},
-		Alignment: &excelize.Alignment{
-			Horizontal: "center",
-			Vertical:   "center",
-		},
-	})
-
-	// Company info style
-	companyInfoStyle, _ := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{Size: 11},
-		Alignment: &excelize.Alignment{
-			Horizontal: "left",
-			Vertical:   "center",
-		},
-	})
-
-	// Data style
-	dataStyle, _ := f.NewStyle(&excelize.Style{
-		Border: []excelize.Border{
-			{Type: "left", Color: "000000", Style: 1},
-			{Type: "top", Color: "000000", Style: 1},
-			{Type: "bottom", Color: "000000", Style: 1},
-			{Type: "right", Color: "000000", Style: 1},
-		},
-	})
-
-	// Number style for numeric columns
-	numberStyle, _ := f.NewStyle(&excelize.Style{
-		NumFmt: 1, // Number format
-		Border: []excelize.Border{
-			{Type: "left", Color: "000000", Style: 1},
-			{Type: "top", Color: "000000", Style: 1},
-			{Type: "bottom", Color: "000000", Style: 1},
-			{Type: "right", Color: "000000", Style: 1},
-		},
-	})
-
-	// Apply styles
-	f.SetCellStyle(sheetName, "A1", "L1", companyHeaderStyle)
-	f.SetCellStyle(sheetName, "A2", "L2", companyHeaderStyle)
-	f.SetCellStyle(sheetName, "A4", "L7", companyInfoStyle)
-	f.SetCellStyle(sheetName, "A9", "L10", headerStyle)
+	doc := c.exportDocument(from, to)
+	doc.Rows = res
+	return doc, nil
+}
 
-	// Apply data styles
-	if len(data) > 0 {
-		lastRow := len(data) + 10
-		f.SetCellStyle(sheetName, "A11", fmt.Sprintf("A%d", lastRow), dataStyle)  // No
-		f.SetCellStyle(sheetName, "B11", fmt.Sprintf("D%d", lastRow), dataStyle)  // Text columns
-		f.SetCellStyle(sheetName, "E11", fmt.Sprintf("K%d", lastRow), numberStyle) // Number columns
-		f.SetCellStyle(sheetName, "L11", fmt.Sprintf("L%d", lastRow), dataStyle)  // Keterangan
+// exportDocument adalah kop dan kolom export laporan mutasi mesin dan peralatan; baris diisi pemanggil.
+func (c *MachineToolReportController) exportDocument(from, to time.Time) reportExport.Document[model.MachineToolReportResponse] {
+	return reportExport.Document[model.MachineToolReportResponse]{
+		FileName:  fmt.Sprintf("laporan_mutasi_mesin_peralatan_%s_%s", from.Format("2006-01-02"), to.Format("2006-01-02")),
+		SheetName: "Laporan Mesin dan Peralatan",
+		Title:     "LAPORAN PERTANGGUNGJAWABAN MUTASI MESIN DAN PERALATAN",
+		Period:    reportExport.PeriodRange(from, to),
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   exportColumns(to),
 	}
+}
 
-	// Set column widths
-	f.SetColWidth(sheetName, "A", "A", 5)   // No
-	f.SetColWidth(sheetName, "B", "B", 15)  // Kode Barang
-	f.SetColWidth(sheetName, "C", "C", 25)  // Nama Barang
-	f.SetColWidth(sheetName, "D", "D", 8)   // Satuan
-	f.SetColWidth(sheetName, "E", "K", 12)  // Numeric columns
-	f.SetColWidth(sheetName, "L", "L", 15)  // Keterangan
-
-	// Generate filename with timestamp
-	timestamp := time.Now().Format("20060102150405")
-	filename := fmt.Sprintf("Machine_Tool_Report_%s.xlsx", timestamp)
-
-	// Save the file
-	dir := "file/export"
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		return "", err
-	}
-	filePath := filepath.Join(dir, filename)
-	if err = f.SaveAs(filePath); err != nil {
-		return "", err
+// exportColumns adalah definisi kolom export (xlsx/csv/pdf) laporan mesin dan peralatan.
+// PENGELUARAN memakai kolom kel dan SALDO AKHIR diisi stok opname.
+func exportColumns(to time.Time) []reportExport.Column[model.MachineToolReportResponse] {
+	date := to.Format("02-01-2006")
+	return []reportExport.Column[model.MachineToolReportResponse]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.MachineToolReportResponse) any { return i + 1 }},
+		{Key: "item_code", Title: "KODE BARANG", Width: 15, Value: func(_ int, r model.MachineToolReportResponse) any { return r.ItemCode }},
+		{Key: "item_name", Title: "NAMA BARANG", Width: 30, Value: func(_ int, r model.MachineToolReportResponse) any { return r.ItemName }},
+		{Key: "unit_code", Title: "SAT", Width: 8, Value: func(_ int, r model.MachineToolReportResponse) any { return r.UnitCode }},
+		{Key: "awal", Title: "SALDO AWAL", Width: 12, Number: true, Decimals: 0, Value: func(_ int, r model.MachineToolReportResponse) any { return reportExport.ParseNumber(r.Awal) }},
+		{Key: "masuk", Title: "PEMASUKAN", Width: 12, Number: true, Decimals: 0, Value: func(_ int, r model.MachineToolReportResponse) any { return reportExport.ParseNumber(r.Masuk) }},
+		{Key: "keluar", Title: "PENGELUARAN", Width: 12, Number: true, Decimals: 0, Value: func(_ int, r model.MachineToolReportResponse) any { return reportExport.ParseNumber(r.Kel) }},
+		{Key: "peny", Title: "PENYESUAIAN", Width: 12, Number: true, Decimals: 0, Value: func(_ int, r model.MachineToolReportResponse) any { return reportExport.ParseNumber(r.Peny) }},
+		{Key: "akhir", Group: "SALDO AKHIR", Title: date, Width: 12, Number: true, Decimals: 0, Value: func(_ int, r model.MachineToolReportResponse) any { return reportExport.ParseNumber(r.Opname) }},
+		{Key: "opname", Group: "STOK OPNAME", Title: date, Width: 12, Number: true, Decimals: 0, Value: func(_ int, r model.MachineToolReportResponse) any { return reportExport.ParseNumber(r.Opname) }},
+		{Key: "selisih", Title: "SELISIH", Width: 10, Number: true, Decimals: 0, Value: func(_ int, r model.MachineToolReportResponse) any { return reportExport.ParseNumber(r.Selisih) }},
+		{Key: "keterangan", Title: "KETERANGAN", Width: 15, Value: func(_ int, r model.MachineToolReportResponse) any { return "" }},
 	}
-	filename = filePath
-
-	return filename, nil
 }
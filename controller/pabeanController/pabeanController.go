@@ -1,11 +1,17 @@
 package pabeanController
 
 import (
+	"Bea-Cukai/helper"
 	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/model"
 	"Bea-Cukai/service/pabeanService"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
 type PabeanController struct {
@@ -22,7 +28,7 @@ func NewPabeanController(svc *pabeanService.PabeanService) *PabeanController {
 
 // GET /pabean
 func (c *PabeanController) GetAll(ctx *gin.Context) {
-	res, err := c.PabeanService.GetAll()
+	res, err := c.PabeanService.GetAll(ctx.Request.Context())
 	if err != nil {
 		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail get pabean documents", err, gin.H{})
 		return
@@ -32,3 +38,63 @@ func (c *PabeanController) GetAll(ctx *gin.Context) {
 		"count": len(res),
 	})
 }
+
+// POST /admin/pabean  body: {"pabean_code": "23", "pabean_name": "BC2.3", "direction": "IN", "duty_suspended": true, "lpj_columns": ["BAHAN_BAKU_MASUK"]}
+func (c *PabeanController) Create(ctx *gin.Context) {
+	req, ok := bindRequest(ctx)
+	if !ok {
+		return
+	}
+
+	pabean, err := c.PabeanService.Create(ctx.Request.Context(), req, username(ctx))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "PABEAN_CREATE_FAILED", "fail to create pabean document", err, gin.H{
+			"pabean_code": req.PabeanCode,
+		})
+		return
+	}
+	apiresponse.Created(ctx, pabean, "ok", nil)
+}
+
+// PUT /admin/pabean/:id (body sama dengan Create)
+func (c *PabeanController) Update(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_ID", "invalid pabean id", err, gin.H{"id": ctx.Param("id")})
+		return
+	}
+	req, ok := bindRequest(ctx)
+	if !ok {
+		return
+	}
+
+	pabean, err := c.PabeanService.Update(ctx.Request.Context(), id, req, username(ctx))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apiresponse.Error(ctx, http.StatusNotFound, "PABEAN_NOT_FOUND", "pabean document not found", err, gin.H{"id": id})
+		return
+	}
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "PABEAN_UPDATE_FAILED", "fail to update pabean document", err, gin.H{"id": id})
+		return
+	}
+	apiresponse.OK(ctx, pabean, "ok", gin.H{"id": id})
+}
+
+func bindRequest(ctx *gin.Context) (model.MsPabeanRequest, bool) {
+	var req model.MsPabeanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "fail bind data", err, nil)
+		return req, false
+	}
+	if err := helper.NewValidator().Validate(req); err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "Invalid request format", err, nil)
+		return req, false
+	}
+	return req, true
+}
+
+func username(ctx *gin.Context) string {
+	userData, _ := ctx.MustGet("userData").(jwt.MapClaims)
+	name, _ := userData["username"].(string)
+	return name
+}
@@ -0,0 +1,249 @@
+package pabeanDocumentController
+
+import (
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/pabeanDocumentRepository"
+	"Bea-Cukai/service/companyProfileService"
+	"Bea-Cukai/service/pabeanDocumentService"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PabeanDocumentController struct {
+	PabeanDocumentService *pabeanDocumentService.PabeanDocumentService
+	CompanyProfileService *companyProfileService.CompanyProfileService
+}
+
+func NewPabeanDocumentController(svc *pabeanDocumentService.PabeanDocumentService, companyProfileSvc *companyProfileService.CompanyProfileService) *PabeanDocumentController {
+	return &PabeanDocumentController{PabeanDocumentService: svc, CompanyProfileService: companyProfileSvc}
+}
+
+// ==========================
+// Document endpoints
+// ==========================
+
+// GET /pabean-documents?direction=inbound|outbound&from=YYYY-MM-DD&to=YYYY-MM-DD&pabeanType=...&noPabean=...&counterpart=...&page=1&limit=10
+// Satu item per dokumen pabean (bukan per baris barang) dengan total kuantitas dan nilainya.
+func (c *PabeanDocumentController) GetList(ctx *gin.Context) {
+	filter, period, ok := listFilter(ctx)
+	if !ok {
+		return
+	}
+	filter.Page = apiRequest.ParseInt(ctx, "page", 0)
+	filter.Limit = apiRequest.ParseInt(ctx, "limit", 0)
+
+	res, totalCount, err := c.PabeanDocumentService.GetList(ctx.Request.Context(), filter)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get pabean documents", err, filterMeta(filter))
+		return
+	}
+
+	// Calculate pagination metadata
+	totalPages, hasNext, hasPrev := 1, false, false
+	if filter.Limit > 0 {
+		totalPages = int((totalCount + int64(filter.Limit) - 1) / int64(filter.Limit)) // ceil division
+		hasNext = filter.Page < totalPages
+		hasPrev = filter.Page > 1
+	}
+
+	meta := filterMeta(filter)
+	meta["period"] = period.Meta()
+	meta["pagination"] = gin.H{
+		"page":       filter.Page,
+		"limit":      filter.Limit,
+		"totalCount": totalCount,
+		"totalPages": totalPages,
+		"count":      len(res),
+		"hasNext":    hasNext,
+		"hasPrev":    hasPrev,
+	}
+	apiresponse.OK(ctx, res, "ok", meta)
+}
+
+// GET /pabean-documents/:jenis/:no?direction=inbound|outbound
+// Header dokumen, semua baris barangnya dan totalnya. Tanpa direction dokumen dicari di pemasukan
+// dan pengeluaran; bila nomor yang sama ada di keduanya dijawab 409 agar client memilih direction.
+func (c *PabeanDocumentController) GetDocument(ctx *gin.Context) {
+	direction, ok := parseDirection(ctx)
+	if !ok {
+		return
+	}
+	jenisPabean, noPabean := ctx.Param("jenis"), ctx.Param("no")
+	meta := gin.H{"jenis_pabean": jenisPabean, "no_pabean": noPabean, "direction": direction}
+
+	docs, err := c.PabeanDocumentService.GetDocument(ctx.Request.Context(), direction, jenisPabean, noPabean)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get pabean document", err, meta)
+		return
+	}
+
+	switch len(docs) {
+	case 0:
+		apiresponse.Error(ctx, http.StatusNotFound, "DOCUMENT_NOT_FOUND", "pabean document not found", nil, meta)
+	case 1:
+		apiresponse.OK(ctx, docs[0], "ok", meta)
+	default:
+		directions := make([]string, len(docs))
+		for i, d := range docs {
+			directions[i] = d.Direction
+		}
+		meta["directions"] = directions
+		apiresponse.Error(ctx, http.StatusConflict, "AMBIGUOUS_DOCUMENT", "document exists as both inbound and outbound, specify direction", nil, meta)
+	}
+}
+
+// GET /pabean-documents/export?direction=...&from=YYYY-MM-DD&to=YYYY-MM-DD&pabeanType=...&noPabean=...&counterpart=...&format=xlsx|zip
+// Workbook dengan cover (daftar dokumen) dan satu sheet per dokumen; format=zip berisi satu CSV
+// per dokumen. Jumlah dokumen dibatasi PABEAN_DOCUMENT_EXPORT_MAX.
+func (c *PabeanDocumentController) ExportExcel(ctx *gin.Context) {
+	format, err := reportExport.ParseBundleFormat(ctx.Query("format"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
+		})
+		return
+	}
+
+	filter, period, ok := listFilter(ctx)
+	if !ok {
+		return
+	}
+
+	docs, totalCount, err := c.PabeanDocumentService.GetForExport(ctx.Request.Context(), filter)
+	if errors.Is(err, pabeanDocumentService.ErrTooManyDocuments) {
+		meta := filterMeta(filter)
+		meta["totalCount"] = totalCount
+		meta["max"] = c.PabeanDocumentService.MaxExportDocuments()
+		apiresponse.Error(ctx, http.StatusBadRequest, "TOO_MANY_DOCUMENTS", "too many documents for one export", err, meta)
+		return
+	}
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get pabean documents for export", err, filterMeta(filter))
+		return
+	}
+
+	profile := c.CompanyProfileService.GetForExport()
+	sheets := make([]reportExport.Sheet, len(docs))
+	for i, doc := range docs {
+		sheets[i] = exportDocument(doc, profile)
+	}
+
+	reportExport.SendBundle(ctx, format, reportExport.Bundle{
+		FileName: fmt.Sprintf("dokumen_pabean_%s_%s", period.From.Format("2006-01-02"), period.To.Format("2006-01-02")),
+		Title:    "DAFTAR DOKUMEN PABEAN",
+		Period:   reportExport.PeriodRange(period.From, period.To),
+		Profile:  profile,
+		SignDate: time.Now(),
+		Sheets:   sheets,
+	})
+}
+
+// listFilter membaca filter daftar dokumen; ok = false bila respons error sudah dikirim.
+func listFilter(ctx *gin.Context) (pabeanDocumentRepository.ListFilter, apiRequest.Period, bool) {
+	direction, ok := parseDirection(ctx)
+	if !ok {
+		return pabeanDocumentRepository.ListFilter{}, apiRequest.Period{}, false
+	}
+
+	period, err := apiRequest.GetPeriod(ctx)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
+			"from": ctx.Query("from"),
+			"to":   ctx.Query("to"),
+		})
+		return pabeanDocumentRepository.ListFilter{}, apiRequest.Period{}, false
+	}
+
+	return pabeanDocumentRepository.ListFilter{
+		Direction:   direction,
+		From:        period.From,
+		To:          period.To,
+		PabeanType:  ctx.Query("pabeanType"),
+		NoPabean:    strings.TrimSpace(ctx.Query("noPabean")),
+		Counterpart: strings.TrimSpace(ctx.Query("counterpart")),
+	}, period, true
+}
+
+// parseDirection membaca direction=inbound|outbound (kosong = keduanya).
+func parseDirection(ctx *gin.Context) (string, bool) {
+	direction := strings.ToLower(strings.TrimSpace(ctx.Query("direction")))
+	switch direction {
+	case "", model.PabeanDirectionInbound, model.PabeanDirectionOutbound:
+		return direction, true
+	}
+	apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DIRECTION", "direction must be inbound or outbound", nil, gin.H{
+		"direction": ctx.Query("direction"),
+	})
+	return "", false
+}
+
+func filterMeta(filter pabeanDocumentRepository.ListFilter) gin.H {
+	return gin.H{
+		"from":        filter.From.Format("2006-01-02"),
+		"to":          filter.To.Format("2006-01-02"),
+		"direction":   filter.Direction,
+		"pabeanType":  filter.PabeanType,
+		"noPabean":    filter.NoPabean,
+		"counterpart": filter.Counterpart,
+	}
+}
+
+// exportDocument adalah satu dokumen pabean sebagai sheet export: kop berisi jenis, nomor,
+// tanggal dan pengirim/penerima; baris berisi barang dokumen.
+func exportDocument(doc model.PabeanDocument, profile model.CompanyProfile) reportExport.Document[model.PabeanDocumentLine] {
+	counterpart := "PENERIMA"
+	if doc.Direction == model.PabeanDirectionInbound {
+		counterpart = "PENGIRIM"
+	}
+	fileName := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '/' || r == '\\' || r == '.' {
+			return '_'
+		}
+		return r
+	}, fmt.Sprintf("%s_%s", doc.JenisPabean, doc.NoPabean))
+
+	return reportExport.Document[model.PabeanDocumentLine]{
+		FileName:  fileName,
+		SheetName: fmt.Sprintf("%s %s", doc.JenisPabean, doc.NoPabean),
+		Title:     fmt.Sprintf("DOKUMEN PABEAN %s NOMOR %s", doc.JenisPabean, doc.NoPabean),
+		Period:    fmt.Sprintf("Tanggal %s, %s: %s", reportExport.Date(doc.TglPabean), counterpart, doc.CounterpartName),
+		Profile:   profile,
+		Columns:   exportColumns(doc.Direction),
+		Rows:      doc.Lines,
+	}
+}
+
+// exportColumns adalah kolom sheet satu dokumen; nomor surat jalan hanya ada di pemasukan.
+func exportColumns(direction string) []reportExport.Column[model.PabeanDocumentLine] {
+	proof := "BUKTI PENGELUARAN BARANG"
+	if direction == model.PabeanDirectionInbound {
+		proof = "BUKTI PENERIMAAN BARANG"
+	}
+
+	cols := []reportExport.Column[model.PabeanDocumentLine]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.PabeanDocumentLine) any { return i + 1 }},
+		{Key: "trans_no", Group: proof, Title: "NOMOR", Width: 15, Value: func(_ int, r model.PabeanDocumentLine) any { return r.TransNo }},
+		{Key: "trans_date", Group: proof, Title: "TANGGAL", Width: 12, Value: func(_ int, r model.PabeanDocumentLine) any { return reportExport.Date(r.TransDate) }},
+	}
+	if direction == model.PabeanDirectionInbound {
+		cols = append(cols, reportExport.Column[model.PabeanDocumentLine]{Key: "delivery_no", Title: "SURAT JALAN", Width: 15, Value: func(_ int, r model.PabeanDocumentLine) any { return r.DeliveryNo }})
+	}
+	return append(cols,
+		reportExport.Column[model.PabeanDocumentLine]{Key: "item_code", Title: "KODE BARANG", Width: 15, Value: func(_ int, r model.PabeanDocumentLine) any { return r.ItemCode }},
+		reportExport.Column[model.PabeanDocumentLine]{Key: "item_name", Title: "NAMA BARANG", Width: 30, Value: func(_ int, r model.PabeanDocumentLine) any { return r.ItemName }},
+		reportExport.Column[model.PabeanDocumentLine]{Key: "item_group", Title: "GRUP", Width: 12, Value: func(_ int, r model.PabeanDocumentLine) any { return r.ItemGroup }},
+		reportExport.Column[model.PabeanDocumentLine]{Key: "qty", Title: "JUMLAH", Width: 12, Number: true, Decimals: 2, Value: func(_ int, r model.PabeanDocumentLine) any { return reportExport.Decimal(r.Qty) }},
+		reportExport.Column[model.PabeanDocumentLine]{Key: "unit", Title: "SATUAN", Width: 8, Value: func(_ int, r model.PabeanDocumentLine) any { return r.Unit }},
+		reportExport.Column[model.PabeanDocumentLine]{Key: "curr_code", Title: "VALAS", Width: 8, Value: func(_ int, r model.PabeanDocumentLine) any { return r.CurrCode }},
+		reportExport.Column[model.PabeanDocumentLine]{Key: "net_price", Title: "HARGA", Width: 15, Number: true, Decimals: 2, Value: func(_ int, r model.PabeanDocumentLine) any { return reportExport.Decimal(r.NetPrice) }},
+		reportExport.Column[model.PabeanDocumentLine]{Key: "net_amount", Title: "NILAI", Width: 15, Number: true, Decimals: 2, Value: func(_ int, r model.PabeanDocumentLine) any { return reportExport.Decimal(r.NetAmount) }},
+	)
+}
@@ -3,32 +3,38 @@ package rawMaterialReportController
 import (
 	"Bea-Cukai/helper/apiRequest"
 	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/reportCache"
+	"Bea-Cukai/helper/reportCompare"
+	"Bea-Cukai/helper/reportExport"
 	"Bea-Cukai/model"
 	"Bea-Cukai/repo/rawMaterialReportRepository"
+	"Bea-Cukai/service/companyProfileService"
 	"Bea-Cukai/service/rawMaterialReportService"
+	"context"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/xuri/excelize/v2"
 )
 
 type RawMaterialReportController struct {
 	RawMaterialReportService *rawMaterialReportService.RawMaterialReportService
+	CompanyProfileService    *companyProfileService.CompanyProfileService
 }
 
-func NewRawMaterialReportController(svc *rawMaterialReportService.RawMaterialReportService) *RawMaterialReportController {
-	return &RawMaterialReportController{RawMaterialReportService: svc}
+func NewRawMaterialReportController(svc *rawMaterialReportService.RawMaterialReportService, companyProfileSvc *companyProfileService.CompanyProfileService) *RawMaterialReportController {
+	return &RawMaterialReportController{RawMaterialReportService: svc, CompanyProfileService: companyProfileSvc}
 }
 
 // ==========================
 // Report endpoints
 // ==========================
 
-// GET /report/raw-material?from=YYYY-MM-DD&to=YYYY-MM-DD&item_code=...&item_name=...&page=1&limit=10
+// GET /report/raw-material?from=YYYY-MM-DD&to=YYYY-MM-DD&item_code=...&item_name=...&sort=...&groupBy=location&location=...&page=1&limit=10
+// compare=previous|yoy|custom (compare_from, compare_to) → perbandingan per item dengan periode lain, lihat getComparison
 func (c *RawMaterialReportController) GetReport(ctx *gin.Context) {
-	from, to, err := apiRequest.GetRange(ctx)
+	period, err := apiRequest.GetPeriod(ctx)
 	if err != nil {
 		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
 			"from": ctx.Query("from"),
@@ -36,10 +42,32 @@ func (c *RawMaterialReportController) GetReport(ctx *gin.Context) {
 		})
 		return
 	}
+	from, to := period.From, period.To
+	if ctx.Query("compare") != "" {
+		c.getComparison(ctx, period)
+		return
+	}
 
 	// Get optional filter parameters
 	itemCode := ctx.Query("item_code")
 	itemName := ctx.Query("item_name")
+	groupBy := ctx.Query("groupBy")
+	location := ctx.Query("location")
+	if groupBy != "" && groupBy != rawMaterialReportRepository.GroupByLocation {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_GROUP_BY", "groupBy must be empty or location", nil, gin.H{
+			"groupBy": groupBy,
+		})
+		return
+	}
+
+	// Get sort and filter options
+	opts, err := apiRequest.GetReportOptions(ctx, rawMaterialReportRepository.ReportColumns)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REPORT_OPTIONS", "invalid sort or filter parameter", err, gin.H{
+			"sort": ctx.Query("sort"),
+		})
+		return
+	}
 
 	// Get pagination parameters
 	page := apiRequest.ParseInt(ctx, "page", 0)
@@ -50,16 +78,21 @@ func (c *RawMaterialReportController) GetReport(ctx *gin.Context) {
 		To:       to,
 		ItemCode: itemCode,
 		ItemName: itemName,
+		Options:  opts,
+		GroupBy:  groupBy,
+		Location: location,
 		Page:     page,
 		Limit:    limit,
 	}
-	res, totalCount, err := c.RawMaterialReportService.GetReport(filter)
+	res, totalCount, err := c.RawMaterialReportService.GetReport(ctx.Request.Context(), filter)
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get raw material report", err, gin.H{
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail to get raw material report", err, gin.H{
 			"from":      from.Format("2006-01-02"),
 			"to":        to.Format("2006-01-02"),
 			"item_code": itemCode,
 			"item_name": itemName,
+			"groupBy":   groupBy,
+			"location":  location,
 			"page":      page,
 			"limit":     limit,
 		})
@@ -85,8 +118,12 @@ func (c *RawMaterialReportController) GetReport(ctx *gin.Context) {
 	apiresponse.OK(ctx, res, "ok", gin.H{
 		"from":      from.Format("2006-01-02"),
 		"to":        to.Format("2006-01-02"),
+		"period":    period.Meta(),
 		"item_code": itemCode,
 		"item_name": itemName,
+		"options":   opts.Meta(),
+		"groupBy":   groupBy,
+		"location":  location,
 		"pagination": gin.H{
 			"page":       page,
 			"limit":      limit,
@@ -99,28 +136,50 @@ func (c *RawMaterialReportController) GetReport(ctx *gin.Context) {
 	})
 }
 
-// GET /report/raw-material/export?from=YYYY-MM-DD&to=YYYY-MM-DD&item_code=...&item_name=...
+// GET /report/raw-material/export?from=YYYY-MM-DD&to=YYYY-MM-DD&item_code=...&item_name=...&sort=...&groupBy=location&location=...&format=xlsx|csv|pdf
 func (c *RawMaterialReportController) ExportExcel(ctx *gin.Context) {
-	fromStr := ctx.Query("from")
-	from, err := time.Parse("2006-01-02", fromStr)
+	format, err := reportExport.ParseFormat(ctx.Query("format"))
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE", "invalid from date", err, gin.H{
-			"from": fromStr,
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
 		})
 		return
 	}
-	toStr := ctx.Query("to")
-	to, err := time.Parse("2006-01-02", toStr)
+
+	period, err := apiRequest.GetPeriod(ctx)
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE", "invalid to date", err, gin.H{
-			"to": toStr,
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
+			"from": ctx.Query("from"),
+			"to":   ctx.Query("to"),
 		})
 		return
 	}
+	from, to := period.From, period.To
+	if ctx.Query("compare") != "" {
+		c.exportComparison(ctx, format, period)
+		return
+	}
 
 	// Get optional filter parameters
 	itemCode := ctx.Query("item_code")
 	itemName := ctx.Query("item_name")
+	groupBy := ctx.Query("groupBy")
+	location := ctx.Query("location")
+	if groupBy != "" && groupBy != rawMaterialReportRepository.GroupByLocation {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_GROUP_BY", "groupBy must be empty or location", nil, gin.H{
+			"groupBy": groupBy,
+		})
+		return
+	}
+
+	// Get sort and filter options
+	opts, err := apiRequest.GetReportOptions(ctx, rawMaterialReportRepository.ReportColumns)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REPORT_OPTIONS", "invalid sort or filter parameter", err, gin.H{
+			"sort": ctx.Query("sort"),
+		})
+		return
+	}
 
 	// For export, we don't use pagination - get all data
 	filter := rawMaterialReportRepository.GetReportFilter{
@@ -128,225 +187,211 @@ func (c *RawMaterialReportController) ExportExcel(ctx *gin.Context) {
 		To:       to,
 		ItemCode: itemCode,
 		ItemName: itemName,
+		Options:  opts,
+		GroupBy:  groupBy,
+		Location: location,
 		Page:     0, // No pagination
 		Limit:    0, // No limit
 	}
 
-	res, _, err := c.RawMaterialReportService.GetReport(filter)
+	res, _, err := c.RawMaterialReportService.GetReport(ctx.Request.Context(), filter)
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get raw material report for export", err, gin.H{
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail to get raw material report for export", err, gin.H{
 			"from":      from,
 			"to":        to,
 			"item_code": itemCode,
 			"item_name": itemName,
+			"groupBy":   groupBy,
+			"location":  location,
 		})
 		return
 	}
 
-	// Generate Excel file
-	excelFile, err := c.generateExcelFile(res, from, to)
+	doc := c.exportDocument(from, to, filter.GroupBy != "" || filter.Location != "")
+	doc.Rows = res
+	reportExport.Send(ctx, format, doc)
+}
+
+// BundleSheet adalah laporan mutasi bahan baku periode from..to tanpa filter tambahan (bundle LPJ).
+func (c *RawMaterialReportController) BundleSheet(ctx context.Context, from, to time.Time) (reportExport.Sheet, error) {
+	res, _, err := c.RawMaterialReportService.GetReport(ctx, rawMaterialReportRepository.GetReportFilter{From: from, To: to})
 	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "EXCEL_GENERATION_FAILED", "failed to generate Excel file", err, gin.H{
-			"from": from,
-			"to":   to,
-		})
-		return
+		return nil, err
 	}
-	defer excelFile.Close()
 
-	// Set headers for Excel file download
-	filename := fmt.Sprintf("laporan_mutasi_bahan_baku_%s_%s.xlsx", from.Format("2006-01-02"), to.Format("2006-01-02"))
-	
-	ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	ctx.Header("Cache-Control", "no-cache")
-	
-	// Write Excel file to response
-	buffer, err := excelFile.WriteToBuffer()
-	if err != nil {
-		apiresponse.Error(ctx, http.StatusInternalServerError, "EXCEL_WRITE_FAILED", "failed to write Excel file", err, gin.H{})
-		return
+	doc := c.exportDocument(from, to, false)
+	doc.Rows = res
+	return doc, nil
+}
+
+// exportDocument adalah kop dan kolom export laporan mutasi bahan baku; baris diisi pemanggil.
+// byLocation menambah kolom LOKASI untuk export per lokasi.
+func (c *RawMaterialReportController) exportDocument(from, to time.Time, byLocation bool) reportExport.Document[model.RawMaterialReportResponse] {
+	return reportExport.Document[model.RawMaterialReportResponse]{
+		FileName:  fmt.Sprintf("laporan_mutasi_bahan_baku_%s_%s", from.Format("2006-01-02"), to.Format("2006-01-02")),
+		SheetName: "Laporan Mutasi Bahan Baku",
+		Title:     "LAPORAN PERTANGGUNGJAWABAN MUTASI BAHAN BAKU DAN BAHAN PENOLONG",
+		Period:    reportExport.PeriodRange(from, to),
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   exportColumns(to, byLocation),
 	}
-	
-	ctx.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buffer.Bytes())
 }
 
-// generateExcelFile creates a real XLSX file using excelize for Raw Material Report
-func (c *RawMaterialReportController) generateExcelFile(data []model.RawMaterialReportResponse, from, to time.Time) (*excelize.File, error) {
-	// Create a new Excel file
-	f := excelize.NewFile()
-	sheetName := "Laporan Mutasi Bahan Baku"
-	index, err := f.NewSheet(sheetName)
+// exportColumns adalah definisi kolom export (xlsx/csv/pdf) laporan bahan baku.
+// SALDO AKHIR diisi nilai stok opname seperti laporan LPJ sebelumnya.
+// byLocation menambah kolom LOKASI untuk export per lokasi.
+func exportColumns(to time.Time, byLocation bool) []reportExport.Column[model.RawMaterialReportResponse] {
+	date := to.Format("02-01-2006")
+	columns := []reportExport.Column[model.RawMaterialReportResponse]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.RawMaterialReportResponse) any { return i + 1 }},
+		{Key: "item_code", Title: "KODE BARANG", Width: 15, Value: func(_ int, r model.RawMaterialReportResponse) any { return r.ItemCode }},
+		{Key: "item_name", Title: "NAMA BARANG", Width: 30, Value: func(_ int, r model.RawMaterialReportResponse) any { return r.ItemName }},
+		{Key: "unit_code", Title: "SAT", Width: 8, Value: func(_ int, r model.RawMaterialReportResponse) any { return r.UnitCode }},
+		{Key: "awal", Title: "SALDO AWAL", Width: 12, Number: true, Decimals: 2, Value: func(_ int, r model.RawMaterialReportResponse) any { return reportExport.Decimal(r.Awal) }},
+		{Key: "masuk", Title: "PEMASUKAN", Width: 12, Number: true, Decimals: 2, Value: func(_ int, r model.RawMaterialReportResponse) any { return reportExport.Decimal(r.Masuk) }},
+		{Key: "keluar", Title: "PENGELUARAN", Width: 12, Number: true, Decimals: 2, Value: func(_ int, r model.RawMaterialReportResponse) any { return reportExport.Decimal(r.Keluar) }},
+		{Key: "peny", Title: "PENYESUAIAN", Width: 12, Number: true, Decimals: 2, Value: func(_ int, r model.RawMaterialReportResponse) any { return reportExport.Decimal(r.Peny) }},
+		{Key: "akhir", Group: "SALDO AKHIR", Title: date, Width: 12, Number: true, Decimals: 2, Value: func(_ int, r model.RawMaterialReportResponse) any { return reportExport.Decimal(r.Opname) }},
+		{Key: "opname", Group: "STOK OPNAME", Title: date, Width: 12, Number: true, Decimals: 2, Value: func(_ int, r model.RawMaterialReportResponse) any { return reportExport.Decimal(r.Opname) }},
+		{Key: "selisih", Title: "SELISIH", Width: 10, Number: true, Decimals: 2, Value: func(_ int, r model.RawMaterialReportResponse) any { return reportExport.Decimal(r.Selisih) }},
+		{Key: "keterangan", Title: "KETERANGAN", Width: 15, Value: func(_ int, r model.RawMaterialReportResponse) any { return "" }},
+	}
+	if !byLocation {
+		return columns
+	}
+
+	// LOKASI ditambahkan setelah KETERANGAN; KETERANGAN tetap kosong seperti format LPJ.
+	return append(columns, reportExport.Column[model.RawMaterialReportResponse]{Key: "location_code", Title: "LOKASI", Width: 12, Value: func(_ int, r model.RawMaterialReportResponse) any { return r.LocationCode }})
+}
+
+// ==========================
+// Period comparison (compare=previous|yoy|custom)
+// ==========================
+
+// comparisonFilter membaca periode pembanding dan filter laporan perbandingan. Perbandingan selalu
+// per item (tanpa groupBy/location); false berarti respons error sudah ditulis.
+func (c *RawMaterialReportController) comparisonFilter(ctx *gin.Context, period apiRequest.Period) (rawMaterialReportRepository.GetReportFilter, string, apiRequest.Period, bool) {
+	var filter rawMaterialReportRepository.GetReportFilter
+
+	mode, base, err := apiRequest.GetComparePeriod(ctx, period)
 	if err != nil {
-		return nil, err
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_COMPARE", "invalid compare period", err, gin.H{
+			"compare":      ctx.Query("compare"),
+			"compare_from": ctx.Query("compare_from"),
+			"compare_to":   ctx.Query("compare_to"),
+		})
+		return filter, mode, base, false
+	}
+	if ctx.Query("groupBy") != "" || ctx.Query("location") != "" {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_GROUP_BY", "groupBy and location are not supported together with compare", nil, gin.H{
+			"groupBy":  ctx.Query("groupBy"),
+			"location": ctx.Query("location"),
+		})
+		return filter, mode, base, false
 	}
-	f.SetActiveSheet(index)
-
-	// Company and report header (no border table)
-	f.SetCellValue(sheetName, "A1", "PT FUKUSUKE KOGYO INDONESIA")
-	f.SetCellValue(sheetName, "A2", "LAPORAN PERTANGGUNGJAWABAN MUTASI BAHAN BAKU DAN BAHAN PENOLONG")
-	f.SetCellValue(sheetName, "A3", "") // Empty row
-	f.SetCellValue(sheetName, "A4", "Nama Kawasan Berikat")
-	f.SetCellValue(sheetName, "C4", ": PT FUKUSUKE KOGYO INDONESIA")
-	f.SetCellValue(sheetName, "A5", "NPWP")
-	f.SetCellValue(sheetName, "C5", ": 01.071.250.3-052.000")
-	f.SetCellValue(sheetName, "A6", "Alamat")
-	f.SetCellValue(sheetName, "C6", ": Blok M-3-2, Kawasan MM2100, Cikarang Barat, Bekasi, 17520")
-	f.SetCellValue(sheetName, "A7", "Periode Laporan")
-	f.SetCellValue(sheetName, "C7", fmt.Sprintf(": %s s.d %s", from.Format("02-01-2006"), to.Format("02-01-2006")))
-
-	// Merge cells for company info values (extend to column L for 12 columns)
-	f.MergeCell(sheetName, "A1", "L1") // Company name
-	f.MergeCell(sheetName, "A2", "L2") // Report title
-	f.MergeCell(sheetName, "C4", "L4") // Kawasan Berikat
-	f.MergeCell(sheetName, "C5", "L5") // NPWP
-	f.MergeCell(sheetName, "C6", "L6") // Alamat
-	f.MergeCell(sheetName, "C7", "L7") // Periode
-
-	// Set header info style (center for titles, left for info)
-	titleStyle, _ := f.NewStyle(&excelize.Style{
-		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
-		Font:      &excelize.Font{Bold: true, Size: 12},
-	})
-	f.SetCellStyle(sheetName, "A1", "A2", titleStyle)
 
-	headerInfoStyle, _ := f.NewStyle(&excelize.Style{
-		Alignment: &excelize.Alignment{Horizontal: "left", Vertical: "center"},
-		Font:      &excelize.Font{Bold: true, Size: 10},
-	})
-	f.SetCellStyle(sheetName, "A4", "A7", headerInfoStyle)
+	opts, err := apiRequest.GetCompareOptions(ctx)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REPORT_OPTIONS", "invalid sort or filter parameter", err, gin.H{
+			"sort": ctx.Query("sort"),
+		})
+		return filter, mode, base, false
+	}
+
+	filter = rawMaterialReportRepository.GetReportFilter{
+		From:     period.From,
+		To:       period.To,
+		ItemCode: ctx.Query("item_code"),
+		ItemName: ctx.Query("item_name"),
+		Options:  opts,
+	}
+	return filter, mode, base, true
+}
 
-	// Set table headers starting from row 9
-	headers := [][]string{
-		{"No.", "KODE BARANG", "NAMA BARANG", "SAT", "SALDO AWAL", "PEMASUKAN", "PENGELUARAN", "PENYESUAIAN", "SALDO AKHIR", "STOK OPNAME", "SELISIH", "KETERANGAN"},
-		{"", "", "", "", "", "", "", "", to.Format("2006-01-02"), to.Format("2006-01-02"), "", ""},
+// getComparison menjawab GET dengan compare=...: masuk, keluar dan akhir per item untuk periode
+// berjalan dan pembanding berdampingan, dengan selisih absolut dan persentase.
+// Default terurut perubahan saldo akhir terbesar (sort=-abs_delta_akhir).
+func (c *RawMaterialReportController) getComparison(ctx *gin.Context, period apiRequest.Period) {
+	filter, mode, base, ok := c.comparisonFilter(ctx, period)
+	if !ok {
+		return
 	}
 
-	// Set first header row (row 9)
-	for col, header := range headers[0] {
-		cell, _ := excelize.CoordinatesToCellName(col+1, 9)
-		f.SetCellValue(sheetName, cell, header)
+	rows, err := c.RawMaterialReportService.Compare(ctx.Request.Context(), filter, base.From, base.To)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail to compare raw material report", err, gin.H{
+			"from":         period.From.Format("2006-01-02"),
+			"to":           period.To.Format("2006-01-02"),
+			"compare":      mode,
+			"compare_from": base.From.Format("2006-01-02"),
+			"compare_to":   base.To.Format("2006-01-02"),
+		})
+		return
 	}
 
-	// Set second header row (row 10)
-	for col, header := range headers[1] {
-		if header != "" {
-			cell, _ := excelize.CoordinatesToCellName(col+1, 10)
-			f.SetCellValue(sheetName, cell, header)
-		}
+	page := apiRequest.ParseInt(ctx, "page", 0)
+	limit := apiRequest.ParseInt(ctx, "limit", 0)
+	totalCount := len(rows)
+	totalPages := 1
+	if limit > 0 {
+		totalPages = (totalCount + limit - 1) / limit
 	}
+	res := reportCache.Page(rows, page, limit)
 
-	// Merge header cells for most columns that don't have sub-headers
-	f.MergeCell(sheetName, "A9", "A10")   // No.
-	f.MergeCell(sheetName, "B9", "B10")   // KODE BARANG
-	f.MergeCell(sheetName, "C9", "C10")   // NAMA BARANG
-	f.MergeCell(sheetName, "D9", "D10")   // SAT
-	f.MergeCell(sheetName, "E9", "E10")   // SALDO AWAL
-	f.MergeCell(sheetName, "F9", "F10")   // PEMASUKAN
-	f.MergeCell(sheetName, "G9", "G10")   // PENGELUARAN
-	f.MergeCell(sheetName, "H9", "H10")   // PENYESUAIAN
-	// I9 = "SALDO AKHIR", I10 = date
-	// J9 = "STOK OPNAME", J10 = date
-	f.MergeCell(sheetName, "K9", "K10")   // SELISIH
-	f.MergeCell(sheetName, "L9", "L10")   // KETERANGAN
-
-	// Set header style
-	headerStyle, _ := f.NewStyle(&excelize.Style{
-		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
-		Font:      &excelize.Font{Bold: true},
-		Border: []excelize.Border{
-			{Type: "left", Color: "000000", Style: 1},
-			{Type: "top", Color: "000000", Style: 1},
-			{Type: "bottom", Color: "000000", Style: 1},
-			{Type: "right", Color: "000000", Style: 1},
+	apiresponse.OK(ctx, res, "ok", gin.H{
+		"from":      period.From.Format("2006-01-02"),
+		"to":        period.To.Format("2006-01-02"),
+		"period":    period.Meta(),
+		"compare":   gin.H{"mode": mode, "period": base.Meta()},
+		"item_code": filter.ItemCode,
+		"item_name": filter.ItemName,
+		"options":   filter.Options.Meta(),
+		"totals":    reportCompare.Totals(rows),
+		"pagination": gin.H{
+			"page":       page,
+			"limit":      limit,
+			"totalCount": totalCount,
+			"totalPages": totalPages,
+			"count":      len(res),
+			"hasNext":    limit > 0 && page < totalPages,
+			"hasPrev":    limit > 0 && page > 1,
 		},
-		Fill: excelize.Fill{Type: "pattern", Color: []string{"F0F0F0"}, Pattern: 1},
 	})
-	f.SetCellStyle(sheetName, "A9", "L10", headerStyle)
-
-	// Add data rows starting from row 11
-	for i, rawMaterial := range data {
-		row := i + 11
-		
-		// Set values for each column
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), i+1)                           // No
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), rawMaterial.ItemCode)          // Item Code
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), rawMaterial.ItemName)          // Item Name
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), rawMaterial.UnitCode)          // Unit Code
-		
-		// Convert decimal.Decimal to float64 for proper number formatting in Excel
-		awalFloat, _ := rawMaterial.Awal.Float64()
-		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), awalFloat)                     // Saldo Awal
-		
-		masukFloat, _ := rawMaterial.Masuk.Float64()
-		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), masukFloat)                    // Pemasukan
-		
-		keluarFloat, _ := rawMaterial.Keluar.Float64()
-		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), keluarFloat)                   // Pengeluaran
-		
-		penyFloat, _ := rawMaterial.Peny.Float64()
-		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), penyFloat)                     // Penyesuaian
-		
-		// akhirFloat, _ := rawMaterial.Akhir.Float64()
-		opnameFloat, _ := rawMaterial.Opname.Float64()
-		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), opnameFloat)                    // Saldo Akhir
-		
-		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), opnameFloat)                   // Stok Opname
-		
-		selisihFloat, _ := rawMaterial.Selisih.Float64()
-		f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), selisihFloat)                  // Selisih
-		
-		f.SetCellValue(sheetName, fmt.Sprintf("L%d", row), "")                            // Keterangan (empty for now)
+}
+
+// exportComparison adalah export laporan perbandingan (format=xlsx|csv|pdf).
+func (c *RawMaterialReportController) exportComparison(ctx *gin.Context, format reportExport.Format, period apiRequest.Period) {
+	filter, mode, base, ok := c.comparisonFilter(ctx, period)
+	if !ok {
+		return
 	}
 
-	// Set data style with borders
-	if len(data) > 0 {
-		dataStyle, _ := f.NewStyle(&excelize.Style{
-			Border: []excelize.Border{
-				{Type: "left", Color: "000000", Style: 1},
-				{Type: "top", Color: "000000", Style: 1},
-				{Type: "bottom", Color: "000000", Style: 1},
-				{Type: "right", Color: "000000", Style: 1},
-			},
-		})
-		lastRow := len(data) + 10
-		f.SetCellStyle(sheetName, "A11", fmt.Sprintf("L%d", lastRow), dataStyle)
-		
-		// Number format with thousand separator WITHOUT decimal places
-		numStyle, _ := f.NewStyle(&excelize.Style{
-			NumFmt: 3, // "#,##0" - format ribuan tanpa desimal
-			Border: []excelize.Border{
-				{Type: "left", Color: "000000", Style: 1},
-				{Type: "top", Color: "000000", Style: 1},
-				{Type: "bottom", Color: "000000", Style: 1},
-				{Type: "right", Color: "000000", Style: 1},
-			},
+	rows, err := c.RawMaterialReportService.Compare(ctx.Request.Context(), filter, base.From, base.To)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail to compare raw material report for export", err, gin.H{
+			"from":    period.From.Format("2006-01-02"),
+			"to":      period.To.Format("2006-01-02"),
+			"compare": mode,
 		})
-		f.SetCellStyle(sheetName, "E11", fmt.Sprintf("E%d", lastRow), numStyle) // Saldo Awal
-		f.SetCellStyle(sheetName, "F11", fmt.Sprintf("F%d", lastRow), numStyle) // Pemasukan
-		f.SetCellStyle(sheetName, "G11", fmt.Sprintf("G%d", lastRow), numStyle) // Pengeluaran
-		f.SetCellStyle(sheetName, "H11", fmt.Sprintf("H%d", lastRow), numStyle) // Penyesuaian
-		f.SetCellStyle(sheetName, "I11", fmt.Sprintf("I%d", lastRow), numStyle) // Saldo Akhir
-		f.SetCellStyle(sheetName, "J11", fmt.Sprintf("J%d", lastRow), numStyle) // Stok Opname
-		f.SetCellStyle(sheetName, "K11", fmt.Sprintf("K%d", lastRow), numStyle) // Selisih
+		return
 	}
 
-	// Set column widths
-	f.SetColWidth(sheetName, "A", "A", 5)   // No
-	f.SetColWidth(sheetName, "B", "B", 15)  // Item Code
-	f.SetColWidth(sheetName, "C", "C", 30)  // Item Name
-	f.SetColWidth(sheetName, "D", "D", 8)   // Unit Code
-	f.SetColWidth(sheetName, "E", "E", 12)  // Saldo Awal
-	f.SetColWidth(sheetName, "F", "F", 12)  // Pemasukan
-	f.SetColWidth(sheetName, "G", "G", 12)  // Pengeluaran
-	f.SetColWidth(sheetName, "H", "H", 12)  // Penyesuaian
-	f.SetColWidth(sheetName, "I", "I", 12)  // Saldo Akhir
-	f.SetColWidth(sheetName, "J", "J", 12)  // Stok Opname
-	f.SetColWidth(sheetName, "K", "K", 10)  // Selisih
-	f.SetColWidth(sheetName, "L", "L", 15)  // Keterangan
-
-	// Delete default sheet if it exists
-	f.DeleteSheet("Sheet1")
-
-	return f, nil
+	reportExport.Send(ctx, format, reportExport.Document[model.ReportComparison]{
+		FileName:  fmt.Sprintf("laporan_perbandingan_bahan_baku_%s_%s_vs_%s_%s", period.From.Format("2006-01-02"), period.To.Format("2006-01-02"), base.From.Format("2006-01-02"), base.To.Format("2006-01-02")),
+		SheetName: "Perbandingan Bahan Baku",
+		Title:     "PERBANDINGAN MUTASI BAHAN BAKU",
+		Period:    reportExport.PeriodRange(period.From, period.To) + " vs " + reportExport.PeriodRange(base.From, base.To),
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   reportCompare.ExportColumns(comparisonLabel(period), comparisonLabel(base)),
+		Rows:      rows,
+	})
+}
+
+// comparisonLabel adalah judul kolom periode di export perbandingan: label preset (mis. 09-2026,
+// 2026Q3) atau rentang tanggal.
+func comparisonLabel(p apiRequest.Period) string {
+	if p.Label != "" {
+		return p.Label
+	}
+	return p.From.Format("02-01-2006") + " s.d " + p.To.Format("02-01-2006")
 }
@@ -0,0 +1,294 @@
+package reconciliationController
+
+import (
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/reconciliationRepository"
+	"Bea-Cukai/service/companyProfileService"
+	"Bea-Cukai/service/reconciliationService"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// defaultDateToleranceDays adalah batas hari penerimaan setelah tgl_pabean bila ?dateToleranceDays kosong.
+const defaultDateToleranceDays = 7
+
+type ReconciliationController struct {
+	ReconciliationService *reconciliationService.ReconciliationService
+	CompanyProfileService *companyProfileService.CompanyProfileService
+}
+
+func NewReconciliationController(svc *reconciliationService.ReconciliationService, companyProfileSvc *companyProfileService.CompanyProfileService) *ReconciliationController {
+	return &ReconciliationController{ReconciliationService: svc, CompanyProfileService: companyProfileSvc}
+}
+
+// ==========================
+// Report endpoints
+// ==========================
+
+// GET /report/reconciliation/inbound?from=YYYY-MM-DD&to=YYYY-MM-DD&pabeanType=...&transNo=...&productCode=...&productName=...&productGroup=A,B&status=NOT_RECEIVED,QTY_MISMATCH&qtyTolerance=0&dateToleranceDays=7&page=1&limit=10
+// Setiap baris pemasukan (trans_no + item) dicocokkan dengan penerimaan gudang tr_ap_inv.
+// meta.summary menghitung semua baris per status; data hanya berisi status yang diminta.
+func (c *ReconciliationController) GetInbound(ctx *gin.Context) {
+	filter, opts, meta, ok := reconciliationRequest(ctx, model.InboundReconciliationStatuses)
+	if !ok {
+		return
+	}
+
+	lines, summary, err := c.ReconciliationService.Inbound(ctx.Request.Context(), filter, opts)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get inbound reconciliation", err, meta)
+		return
+	}
+
+	lines, meta["pagination"] = paginate(ctx, lines)
+	meta["summary"] = summary
+	apiresponse.OK(ctx, lines, "ok", meta)
+}
+
+// GET /report/reconciliation/inbound/export?...&format=xlsx|csv|pdf (filter sama dengan GetInbound, tanpa pagination)
+func (c *ReconciliationController) ExportInbound(ctx *gin.Context) {
+	format, err := reportExport.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
+		})
+		return
+	}
+
+	filter, opts, meta, ok := reconciliationRequest(ctx, model.InboundReconciliationStatuses)
+	if !ok {
+		return
+	}
+
+	lines, summary, err := c.ReconciliationService.Inbound(ctx.Request.Context(), filter, opts)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get inbound reconciliation", err, meta)
+		return
+	}
+
+	reportExport.Send(ctx, format, reportExport.Document[model.InboundReconciliationLine]{
+		FileName:  fmt.Sprintf("rekonsiliasi_pemasukan_%s_%s", filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02")),
+		SheetName: "Rekonsiliasi Pemasukan",
+		Title:     "REKONSILIASI DOKUMEN PABEAN PEMASUKAN DENGAN PENERIMAAN GUDANG",
+		Period:    reportExport.PeriodRange(filter.From, filter.To),
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   inboundColumns(),
+		Rows:      lines,
+		Totals:    summaryTotals(summary, model.InboundReconciliationStatuses),
+	})
+}
+
+// GET /report/reconciliation/outbound?from=YYYY-MM-DD&to=YYYY-MM-DD&pabeanType=...&productCode=...&productName=...&productGroup=A,B&status=NOT_SHIPPED,NO_DOCUMENT&qtyTolerance=0&page=1&limit=10
+// Per item (kode dinormalisasi): kuantitas dokumen pengeluaran vs ekspor (tr_export) + pengiriman
+// (tr_inv_moveout) dalam periode yang sama. meta.summary menghitung semua item per status.
+func (c *ReconciliationController) GetOutbound(ctx *gin.Context) {
+	filter, opts, meta, ok := reconciliationRequest(ctx, model.OutboundReconciliationStatuses)
+	if !ok {
+		return
+	}
+
+	lines, summary, err := c.ReconciliationService.Outbound(ctx.Request.Context(), filter, opts)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get outbound reconciliation", err, meta)
+		return
+	}
+
+	lines, meta["pagination"] = paginate(ctx, lines)
+	meta["summary"] = summary
+	apiresponse.OK(ctx, lines, "ok", meta)
+}
+
+// GET /report/reconciliation/outbound/export?...&format=xlsx|csv|pdf (filter sama dengan GetOutbound, tanpa pagination)
+func (c *ReconciliationController) ExportOutbound(ctx *gin.Context) {
+	format, err := reportExport.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
+		})
+		return
+	}
+
+	filter, opts, meta, ok := reconciliationRequest(ctx, model.OutboundReconciliationStatuses)
+	if !ok {
+		return
+	}
+
+	lines, summary, err := c.ReconciliationService.Outbound(ctx.Request.Context(), filter, opts)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get outbound reconciliation", err, meta)
+		return
+	}
+
+	reportExport.Send(ctx, format, reportExport.Document[model.OutboundReconciliationLine]{
+		FileName:  fmt.Sprintf("rekonsiliasi_pengeluaran_%s_%s", filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02")),
+		SheetName: "Rekonsiliasi Pengeluaran",
+		Title:     "REKONSILIASI DOKUMEN PABEAN PENGELUARAN DENGAN EKSPOR DAN PENGIRIMAN",
+		Period:    reportExport.PeriodRange(filter.From, filter.To),
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   outboundColumns(),
+		Rows:      lines,
+		Totals:    summaryTotals(summary, model.OutboundReconciliationStatuses),
+	})
+}
+
+// paginate memotong hasil per halaman di memori (status baru diketahui setelah pencocokan);
+// tanpa page/limit semua baris dikembalikan.
+func paginate[T any](ctx *gin.Context, lines []T) ([]T, gin.H) {
+	page := apiRequest.ParseInt(ctx, "page", 0)
+	limit := apiRequest.ParseInt(ctx, "limit", 0)
+
+	totalCount := len(lines)
+	totalPages, hasNext, hasPrev := 1, false, false
+	if limit > 0 && page > 0 {
+		totalPages = (totalCount + limit - 1) / limit // ceil division
+		hasNext = page < totalPages
+		hasPrev = page > 1
+		start := min((page-1)*limit, totalCount)
+		lines = lines[start:min(start+limit, totalCount)]
+	}
+
+	return lines, gin.H{
+		"page":       page,
+		"limit":      limit,
+		"totalCount": totalCount,
+		"totalPages": totalPages,
+		"count":      len(lines),
+		"hasNext":    hasNext,
+		"hasPrev":    hasPrev,
+	}
+}
+
+// reconciliationRequest membaca filter dan opsi rekonsiliasi; statuses adalah nilai ?status= yang
+// valid. ok = false bila respons error sudah dikirim.
+func reconciliationRequest(ctx *gin.Context, statuses []string) (reconciliationRepository.Filter, model.ReconciliationOptions, gin.H, bool) {
+	period, err := apiRequest.GetPeriod(ctx)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "invalid date range", err, gin.H{
+			"from": ctx.Query("from"),
+			"to":   ctx.Query("to"),
+		})
+		return reconciliationRepository.Filter{}, model.ReconciliationOptions{}, nil, false
+	}
+
+	filter := reconciliationRepository.Filter{
+		From:         period.From,
+		To:           period.To,
+		PabeanType:   ctx.Query("pabeanType"),
+		TransNo:      strings.TrimSpace(ctx.Query("transNo")),
+		ProductCode:  ctx.Query("productCode"),
+		ProductName:  ctx.Query("productName"),
+		ProductGroup: apiRequest.ParseList(ctx, "productGroup"),
+	}
+
+	opts := model.ReconciliationOptions{
+		DateToleranceDays: apiRequest.ParseInt(ctx, "dateToleranceDays", defaultDateToleranceDays),
+	}
+	if v := strings.TrimSpace(ctx.Query("qtyTolerance")); v != "" {
+		tolerance, err := decimal.NewFromString(v)
+		if err != nil || tolerance.IsNegative() {
+			apiresponse.Error(ctx, http.StatusBadRequest, "BAD_TOLERANCE", "qtyTolerance must be a number >= 0", err, gin.H{
+				"qtyTolerance": v,
+			})
+			return filter, opts, nil, false
+		}
+		opts.QtyTolerance = tolerance
+	}
+	if opts.DateToleranceDays < 0 {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_TOLERANCE", "dateToleranceDays must be >= 0", nil, gin.H{
+			"dateToleranceDays": ctx.Query("dateToleranceDays"),
+		})
+		return filter, opts, nil, false
+	}
+	for _, status := range apiRequest.ParseList(ctx, "status") {
+		status = strings.ToUpper(status)
+		if !slices.Contains(statuses, status) {
+			apiresponse.Error(ctx, http.StatusBadRequest, "BAD_STATUS", "status must be one of: "+strings.Join(statuses, ", "), nil, gin.H{
+				"status": ctx.Query("status"),
+			})
+			return filter, opts, nil, false
+		}
+		opts.Status = append(opts.Status, status)
+	}
+
+	meta := gin.H{
+		"period":       period.Meta(),
+		"pabeanType":   filter.PabeanType,
+		"transNo":      filter.TransNo,
+		"productCode":  filter.ProductCode,
+		"productName":  filter.ProductName,
+		"productGroup": filter.ProductGroup,
+		"status":       opts.Status,
+		"qtyTolerance": opts.QtyTolerance,
+	}
+	if slices.Contains(statuses, model.ReconciliationDateMismatch) {
+		meta["dateToleranceDays"] = opts.DateToleranceDays
+	}
+	return filter, opts, meta, true
+}
+
+// summaryTotals adalah ringkasan jumlah baris per status di bawah tabel export.
+func summaryTotals(summary model.ReconciliationSummary, statuses []string) []reportExport.TotalRow {
+	rows := make([]reportExport.TotalRow, 0, len(summary.ByStatus)+1)
+	rows = append(rows, reportExport.TotalRow{Label: fmt.Sprintf("JUMLAH BARIS: %d", summary.LineCount)})
+	for _, status := range statuses {
+		if n, ok := summary.ByStatus[status]; ok {
+			rows = append(rows, reportExport.TotalRow{Label: fmt.Sprintf("%s: %d", status, n)})
+		}
+	}
+	return rows
+}
+
+// inboundColumns adalah definisi kolom export (xlsx/csv/pdf) rekonsiliasi pemasukan.
+func inboundColumns() []reportExport.Column[model.InboundReconciliationLine] {
+	return []reportExport.Column[model.InboundReconciliationLine]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.InboundReconciliationLine) any { return i + 1 }},
+		{Key: "status", Title: "STATUS", Width: 15, Value: func(_ int, r model.InboundReconciliationLine) any { return r.Status }},
+		{Key: "trans_no", Title: "NO. TRANSAKSI", Width: 16, Value: func(_ int, r model.InboundReconciliationLine) any { return r.TransNo }},
+		{Key: "jenis_pabean", Group: "DOKUMEN PABEAN", Title: "JENIS", Width: 10, Value: func(_ int, r model.InboundReconciliationLine) any { return r.JenisPabean }},
+		{Key: "no_pabean", Group: "DOKUMEN PABEAN", Title: "NOMOR", Width: 14, Value: func(_ int, r model.InboundReconciliationLine) any { return r.NoPabean }},
+		{Key: "tgl_pabean", Group: "DOKUMEN PABEAN", Title: "TANGGAL", Width: 12, Value: func(_ int, r model.InboundReconciliationLine) any { return reportExport.DatePtr(r.TglPabean) }},
+		{Key: "vendor_name", Title: "PENGIRIM BARANG", Width: 22, Value: func(_ int, r model.InboundReconciliationLine) any { return r.VendorName }},
+		{Key: "item_code", Title: "KODE BARANG", Width: 15, Value: func(_ int, r model.InboundReconciliationLine) any { return r.ItemCode }},
+		{Key: "item_name", Title: "NAMA BARANG", Width: 28, Value: func(_ int, r model.InboundReconciliationLine) any { return r.ItemName }},
+		{Key: "unit", Title: "SATUAN", Width: 8, Value: func(_ int, r model.InboundReconciliationLine) any { return r.Unit }},
+		{Key: "doc_qty", Title: "JUMLAH DOKUMEN", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.InboundReconciliationLine) any { return reportExport.Decimal(r.DocQty) }},
+		{Key: "in_date", Group: "PENERIMAAN GUDANG", Title: "TANGGAL", Width: 12, Value: func(_ int, r model.InboundReconciliationLine) any { return reportExport.DatePtr(r.InDate) }},
+		{Key: "received_qty", Group: "PENERIMAAN GUDANG", Title: "JUMLAH", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.InboundReconciliationLine) any { return reportExport.Decimal(r.ReceivedQty) }},
+		{Key: "not_received_qty", Title: "BELUM DITERIMA", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.InboundReconciliationLine) any { return reportExport.Decimal(r.NotReceivedQty) }},
+		{Key: "undocumented_qty", Title: "TANPA DOKUMEN", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.InboundReconciliationLine) any { return reportExport.Decimal(r.UndocumentedQty) }},
+		{Key: "date_diff_days", Title: "SELISIH HARI", Width: 10, Number: true, Value: func(_ int, r model.InboundReconciliationLine) any {
+			if r.DateDiffDays == nil {
+				return ""
+			}
+			return *r.DateDiffDays
+		}},
+	}
+}
+
+// outboundColumns adalah definisi kolom export (xlsx/csv/pdf) rekonsiliasi pengeluaran.
+func outboundColumns() []reportExport.Column[model.OutboundReconciliationLine] {
+	return []reportExport.Column[model.OutboundReconciliationLine]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.OutboundReconciliationLine) any { return i + 1 }},
+		{Key: "status", Title: "STATUS", Width: 15, Value: func(_ int, r model.OutboundReconciliationLine) any { return r.Status }},
+		{Key: "item_code", Title: "KODE BARANG", Width: 15, Value: func(_ int, r model.OutboundReconciliationLine) any { return r.ItemCode }},
+		{Key: "item_name", Title: "NAMA BARANG", Width: 28, Value: func(_ int, r model.OutboundReconciliationLine) any { return r.ItemName }},
+		{Key: "item_group", Title: "GRUP", Width: 12, Value: func(_ int, r model.OutboundReconciliationLine) any { return r.ItemGroup }},
+		{Key: "unit", Title: "SATUAN", Width: 8, Value: func(_ int, r model.OutboundReconciliationLine) any { return r.Unit }},
+		{Key: "jenis_pabean", Group: "DOKUMEN PABEAN", Title: "JENIS", Width: 14, Value: func(_ int, r model.OutboundReconciliationLine) any { return r.JenisPabean }},
+		{Key: "doc_count", Group: "DOKUMEN PABEAN", Title: "DOKUMEN", Width: 9, Number: true, Value: func(_ int, r model.OutboundReconciliationLine) any { return r.DocCount }},
+		{Key: "doc_qty", Group: "DOKUMEN PABEAN", Title: "JUMLAH", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.OutboundReconciliationLine) any { return reportExport.Decimal(r.DocQty) }},
+		{Key: "exported_qty", Group: "BARANG KELUAR", Title: "EKSPOR", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.OutboundReconciliationLine) any { return reportExport.Decimal(r.ExportedQty) }},
+		{Key: "delivered_qty", Group: "BARANG KELUAR", Title: "PENGIRIMAN", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.OutboundReconciliationLine) any { return reportExport.Decimal(r.DeliveredQty) }},
+		{Key: "shipped_qty", Group: "BARANG KELUAR", Title: "TOTAL", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.OutboundReconciliationLine) any { return reportExport.Decimal(r.ShippedQty) }},
+		{Key: "not_shipped_qty", Title: "BELUM KELUAR", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.OutboundReconciliationLine) any { return reportExport.Decimal(r.NotShippedQty) }},
+		{Key: "undocumented_qty", Title: "TANPA DOKUMEN", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.OutboundReconciliationLine) any { return reportExport.Decimal(r.UndocumentedQty) }},
+	}
+}
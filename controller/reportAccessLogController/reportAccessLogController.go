@@ -0,0 +1,149 @@
+package reportAccessLogController
+
+import (
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/model"
+	"Bea-Cukai/service/companyProfileService"
+	"Bea-Cukai/service/reportAccessLogService"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportAccessLogController struct {
+	ReportAccessLogService *reportAccessLogService.ReportAccessLogService
+	CompanyProfileService  *companyProfileService.CompanyProfileService
+}
+
+func NewReportAccessLogController(svc *reportAccessLogService.ReportAccessLogService, companyProfileSvc *companyProfileService.CompanyProfileService) *ReportAccessLogController {
+	return &ReportAccessLogController{
+		ReportAccessLogService: svc,
+		CompanyProfileService:  companyProfileSvc,
+	}
+}
+
+// listRequest membaca filter list/export dari query parameter:
+// - user_id: filter by user ID
+// - username: filter by username (partial match)
+// - action: filter by action (view, export)
+// - report_type: filter by report type (raw-material, entry-products, ...)
+// - start_date: filter by start date (format: 2006-01-02)
+// - end_date: filter by end date (format: 2006-01-02)
+// - page: page number (default: 1)
+// - limit: items per page (default: 20)
+func listRequest(ctx *gin.Context) model.ReportAccessLogListRequest {
+	return model.ReportAccessLogListRequest{
+		UserId:     ctx.Query("user_id"),
+		Username:   ctx.Query("username"),
+		Action:     ctx.Query("action"),
+		ReportType: ctx.Query("report_type"),
+		StartDate:  ctx.Query("start_date"),
+		EndDate:    ctx.Query("end_date"),
+		Page:       apiRequest.ParseInt(ctx, "page", 1),
+		Limit:      apiRequest.ParseInt(ctx, "limit", 20),
+	}
+}
+
+// GET /admin/report-access-logs?user_id=&username=&action=&report_type=&start_date=&end_date=&page=&limit=
+func (c *ReportAccessLogController) GetAll(ctx *gin.Context) {
+	req := listRequest(ctx)
+
+	logs, _, meta, err := c.ReportAccessLogService.GetAll(req)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get report access logs", err, gin.H{
+			"action":      req.Action,
+			"report_type": req.ReportType,
+		})
+		return
+	}
+
+	meta["action"] = req.Action
+	meta["report_type"] = req.ReportType
+	meta["start_date"] = req.StartDate
+	meta["end_date"] = req.EndDate
+	apiresponse.OK(ctx, logs, "ok", meta)
+}
+
+// GET /admin/report-access-logs/export?...&format=xlsx|csv|pdf (filter sama dengan list, tanpa pagination)
+func (c *ReportAccessLogController) ExportExcel(ctx *gin.Context) {
+	format, err := reportExport.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
+		})
+		return
+	}
+
+	req := listRequest(ctx)
+	req.Page, req.Limit = 0, 0
+
+	period := "Semua"
+	if req.StartDate != "" || req.EndDate != "" {
+		period = fmt.Sprintf("%s s.d %s", displayDate(req.StartDate), displayDate(req.EndDate))
+	}
+
+	reportExport.Send(ctx, format, reportExport.Document[model.ReportAccessLog]{
+		FileName:  "log_akses_laporan_" + time.Now().Format("20060102_150405"),
+		SheetName: "Log Akses Laporan",
+		Title:     "LOG AKSES DAN EXPORT LAPORAN",
+		Period:    period,
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   exportColumns(),
+		Stream: func(fn func(model.ReportAccessLog) error) error {
+			return c.ReportAccessLogService.Stream(req, fn)
+		},
+	})
+}
+
+// displayDate memformat YYYY-MM-DD sebagai dd-mm-yyyy; kosong / tidak valid = "-".
+func displayDate(v string) string {
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return "-"
+	}
+	return t.Format("02-01-2006")
+}
+
+// exportColumns adalah definisi kolom export log akses laporan.
+func exportColumns() []reportExport.Column[model.ReportAccessLog] {
+	return []reportExport.Column[model.ReportAccessLog]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.ReportAccessLog) any { return i + 1 }},
+		{Key: "created_at", Title: "WAKTU", Width: 19, Value: func(_ int, r model.ReportAccessLog) any { return r.CreatedAt.Format("2006-01-02 15:04:05") }},
+		{Key: "username", Title: "USER", Width: 15, Value: func(_ int, r model.ReportAccessLog) any { return r.Username }},
+		{Key: "action", Title: "AKSI", Width: 8, Value: func(_ int, r model.ReportAccessLog) any { return r.Action }},
+		{Key: "report_type", Title: "LAPORAN", Width: 22, Value: func(_ int, r model.ReportAccessLog) any { return r.ReportType }},
+		{Key: "filters", Title: "FILTER", Width: 40, Value: func(_ int, r model.ReportAccessLog) any { return filterText(r.Filters) }},
+		{Key: "format", Title: "FORMAT", Width: 8, Value: func(_ int, r model.ReportAccessLog) any { return r.Format }},
+		{Key: "row_count", Title: "JUMLAH BARIS", Width: 12, Number: true, Value: func(_ int, r model.ReportAccessLog) any {
+			if r.RowCount == nil {
+				return nil
+			}
+			return *r.RowCount
+		}},
+		{Key: "status_code", Title: "STATUS", Width: 8, Value: func(_ int, r model.ReportAccessLog) any { return r.StatusCode }},
+		{Key: "duration_ms", Title: "DURASI (ms)", Width: 12, Number: true, Value: func(_ int, r model.ReportAccessLog) any { return int(r.DurationMs) }},
+		{Key: "ip_address", Title: "IP", Width: 15, Value: func(_ int, r model.ReportAccessLog) any { return r.IpAddress }},
+		{Key: "document_id", Title: "DOCUMENT ID", Width: 26, Value: func(_ int, r model.ReportAccessLog) any { return r.DocumentId }},
+	}
+}
+
+// filterText menulis filter sebagai "key=value; ..." terurut per key.
+func filterText(filters map[string]string) string {
+	keys := make([]string, 0, len(filters))
+	for k := range filters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+filters[k])
+	}
+	return strings.Join(parts, "; ")
+}
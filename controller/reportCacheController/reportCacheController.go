@@ -0,0 +1,37 @@
+package reportCacheController
+
+import (
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/reportCache"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportCacheController struct {
+	ReportCache *reportCache.Cache
+}
+
+func NewReportCacheController(cache *reportCache.Cache) *ReportCacheController {
+	return &ReportCacheController{ReportCache: cache}
+}
+
+// GET /admin/report-cache
+func (c *ReportCacheController) GetStats(ctx *gin.Context) {
+	apiresponse.OK(ctx, c.ReportCache.Stats(), "ok", nil)
+}
+
+// DELETE /admin/report-cache?bump_version=true
+// Menghapus seluruh entri cache. bump_version=true juga menaikkan data version
+// (berguna bila data diubah langsung di database tanpa lewat sync).
+func (c *ReportCacheController) Purge(ctx *gin.Context) {
+	if err := c.ReportCache.Purge(); err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "CACHE_PURGE_FAILED", "fail to purge report cache", err, nil)
+		return
+	}
+	if ctx.Query("bump_version") == "true" {
+		c.ReportCache.BumpVersion()
+	}
+
+	apiresponse.OK(ctx, c.ReportCache.Stats(), "ok", gin.H{"purged": true})
+}
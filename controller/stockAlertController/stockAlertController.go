@@ -0,0 +1,225 @@
+package stockAlertController
+
+import (
+	"Bea-Cukai/helper"
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/model"
+	"Bea-Cukai/service/stockAlertService"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+type StockAlertController struct {
+	StockAlertService *stockAlertService.StockAlertService
+}
+
+func NewStockAlertController(svc *stockAlertService.StockAlertService) *StockAlertController {
+	return &StockAlertController{StockAlertService: svc}
+}
+
+// ==========================
+// Alert endpoints
+// ==========================
+
+// GET /alerts?status=open|acknowledged|resolved&item_group=...&rule_type=...&item_code=...&from=YYYY-MM-DD&to=YYYY-MM-DD&page=1&limit=20
+func (c *StockAlertController) GetAll(ctx *gin.Context) {
+	req := model.StockAlertListRequest{
+		Status:    ctx.Query("status"),
+		ItemGroup: ctx.Query("item_group"),
+		RuleType:  ctx.Query("rule_type"),
+		ItemCode:  ctx.Query("item_code"),
+		From:      ctx.Query("from"),
+		To:        ctx.Query("to"),
+		Page:      apiRequest.ParseInt(ctx, "page", 1),
+		Limit:     apiRequest.ParseInt(ctx, "limit", 20),
+	}
+
+	alerts, _, meta, err := c.StockAlertService.GetAll(req)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get stock alerts", err, gin.H{
+			"status":     req.Status,
+			"item_group": req.ItemGroup,
+		})
+		return
+	}
+
+	meta["status"] = req.Status
+	meta["item_group"] = req.ItemGroup
+	meta["rule_type"] = req.RuleType
+	meta["item_code"] = req.ItemCode
+	apiresponse.OK(ctx, alerts, "ok", meta)
+}
+
+// PUT /alerts/:id/acknowledge  body: {"note": "..."}
+func (c *StockAlertController) Acknowledge(ctx *gin.Context) {
+	c.changeStatus(ctx, model.AlertStatusAcknowledged)
+}
+
+// PUT /alerts/:id/resolve  body: {"note": "..."}
+func (c *StockAlertController) Resolve(ctx *gin.Context) {
+	c.changeStatus(ctx, model.AlertStatusResolved)
+}
+
+func (c *StockAlertController) changeStatus(ctx *gin.Context, status string) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_ID", "invalid alert id", err, gin.H{"id": ctx.Param("id")})
+		return
+	}
+
+	// body opsional
+	var req model.StockAlertActionRequest
+	_ = ctx.ShouldBindJSON(&req)
+
+	userData := ctx.MustGet("userData").(jwt.MapClaims)
+	username, _ := userData["username"].(string)
+
+	var alert model.StockAlert
+	if status == model.AlertStatusResolved {
+		alert, err = c.StockAlertService.Resolve(id, username, req.Note)
+	} else {
+		alert, err = c.StockAlertService.Acknowledge(id, username, req.Note)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apiresponse.Error(ctx, http.StatusNotFound, "ALERT_NOT_FOUND", "stock alert not found", err, gin.H{"id": id})
+		return
+	}
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "ALERT_UPDATE_FAILED", "fail to update stock alert", err, gin.H{
+			"id":     id,
+			"status": status,
+		})
+		return
+	}
+
+	apiresponse.OK(ctx, alert, "ok", gin.H{"id": id, "status": status})
+}
+
+// POST /alerts/evaluate?from=YYYY-MM-DD&to=YYYY-MM-DD  (default: bulan berjalan)
+func (c *StockAlertController) Evaluate(ctx *gin.Context) {
+	if ctx.Query("from") == "" && ctx.Query("to") == "" {
+		res, err := c.StockAlertService.EvaluateCurrentPeriod(ctx.Request.Context())
+		if err != nil {
+			apiresponse.Error(ctx, http.StatusInternalServerError, "ALERT_EVALUATION_FAILED", "fail to evaluate stock alert rules", err, nil)
+			return
+		}
+		apiresponse.OK(ctx, res, "ok", gin.H{"from": res.PeriodFrom, "to": res.PeriodTo})
+		return
+	}
+
+	from, errFrom := time.Parse("2006-01-02", ctx.Query("from"))
+	to, errTo := time.Parse("2006-01-02", ctx.Query("to"))
+	if err := errors.Join(errFrom, errTo); err != nil || from.After(to) {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE_RANGE", "from and to must be YYYY-MM-DD with from <= to", err, gin.H{
+			"from": ctx.Query("from"),
+			"to":   ctx.Query("to"),
+		})
+		return
+	}
+
+	res, err := c.StockAlertService.Evaluate(ctx.Request.Context(), from, to)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "ALERT_EVALUATION_FAILED", "fail to evaluate stock alert rules", err, gin.H{
+			"from": from.Format("2006-01-02"),
+			"to":   to.Format("2006-01-02"),
+		})
+		return
+	}
+	apiresponse.OK(ctx, res, "ok", gin.H{"from": res.PeriodFrom, "to": res.PeriodTo})
+}
+
+// ==========================
+// Rule endpoints
+// ==========================
+
+// GET /alerts/rules
+func (c *StockAlertController) GetRules(ctx *gin.Context) {
+	rules, err := c.StockAlertService.GetRules()
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "DATA_FETCH_FAILED", "fail to get stock alert rules", err, nil)
+		return
+	}
+	apiresponse.OK(ctx, rules, "ok", gin.H{"total": len(rules)})
+}
+
+// POST /alerts/rules  body: {"item_group": "MATERIAL", "rule_type": "selisih_percent", "threshold_percent": 5}
+func (c *StockAlertController) CreateRule(ctx *gin.Context) {
+	req, ok := bindRuleRequest(ctx)
+	if !ok {
+		return
+	}
+
+	rule, err := c.StockAlertService.CreateRule(req)
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "RULE_CREATE_FAILED", "fail to create stock alert rule", err, gin.H{
+			"item_group": req.ItemGroup,
+			"rule_type":  req.RuleType,
+		})
+		return
+	}
+	apiresponse.Created(ctx, rule, "ok", nil)
+}
+
+// PUT /alerts/rules/:id
+func (c *StockAlertController) UpdateRule(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_ID", "invalid rule id", err, gin.H{"id": ctx.Param("id")})
+		return
+	}
+	req, ok := bindRuleRequest(ctx)
+	if !ok {
+		return
+	}
+
+	rule, err := c.StockAlertService.UpdateRule(id, req)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apiresponse.Error(ctx, http.StatusNotFound, "RULE_NOT_FOUND", "stock alert rule not found", err, gin.H{"id": id})
+		return
+	}
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "RULE_UPDATE_FAILED", "fail to update stock alert rule", err, gin.H{"id": id})
+		return
+	}
+	apiresponse.OK(ctx, rule, "ok", gin.H{"id": id})
+}
+
+// DELETE /alerts/rules/:id
+func (c *StockAlertController) DeleteRule(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_ID", "invalid rule id", err, gin.H{"id": ctx.Param("id")})
+		return
+	}
+
+	err = c.StockAlertService.DeleteRule(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apiresponse.Error(ctx, http.StatusNotFound, "RULE_NOT_FOUND", "stock alert rule not found", err, gin.H{"id": id})
+		return
+	}
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "RULE_DELETE_FAILED", "fail to delete stock alert rule", err, gin.H{"id": id})
+		return
+	}
+	apiresponse.OK(ctx, gin.H{"id": id}, "ok", nil)
+}
+
+func bindRuleRequest(ctx *gin.Context) (model.StockAlertRuleRequest, bool) {
+	var req model.StockAlertRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "fail bind data", err, nil)
+		return req, false
+	}
+	if err := helper.NewValidator().Validate(req); err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_REQUEST", "Invalid request format", err, nil)
+		return req, false
+	}
+	return req, true
+}
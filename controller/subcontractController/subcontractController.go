@@ -0,0 +1,222 @@
+package subcontractController
+
+import (
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/subcontractRepository"
+	"Bea-Cukai/service/companyProfileService"
+	"Bea-Cukai/service/subcontractService"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultAllowedDays adalah batas hari barang subkontrak harus kembali bila ?allowedDays kosong.
+const defaultAllowedDays = 365
+
+type SubcontractController struct {
+	SubcontractService    *subcontractService.SubcontractService
+	CompanyProfileService *companyProfileService.CompanyProfileService
+}
+
+func NewSubcontractController(svc *subcontractService.SubcontractService, companyProfileSvc *companyProfileService.CompanyProfileService) *SubcontractController {
+	return &SubcontractController{SubcontractService: svc, CompanyProfileService: companyProfileSvc}
+}
+
+// ==========================
+// Report endpoints
+// ==========================
+
+// GET /report/subcontract?asOf=YYYY-MM-DD&subcontractorCode=...&itemCode=...&itemGroup=A,B&allowedDays=365&status=OPEN,OVERDUE&page=1&limit=10
+// Setiap baris BC 2.6.1 sampai asOf (default hari ini) dengan pengembalian BC 2.6.2 yang
+// dipasangkan, sisa yang belum kembali dan lama terbuka. meta.summary menghitung semua pengiriman.
+func (c *SubcontractController) GetOutstanding(ctx *gin.Context) {
+	filter, opts, meta, ok := subcontractRequest(ctx)
+	if !ok {
+		return
+	}
+
+	shipments, summary, err := c.SubcontractService.Outstanding(ctx.Request.Context(), filter, opts)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get subcontract outstanding", err, meta)
+		return
+	}
+
+	shipments, meta["pagination"] = paginate(ctx, shipments)
+	meta["summary"] = summary
+	apiresponse.OK(ctx, shipments, "ok", meta)
+}
+
+// GET /report/subcontract/export?...&format=xlsx|csv|pdf (filter sama dengan GetOutstanding, tanpa pagination)
+func (c *SubcontractController) ExportOutstanding(ctx *gin.Context) {
+	format, err := reportExport.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_FORMAT", "invalid export format", err, gin.H{
+			"format": ctx.Query("format"),
+		})
+		return
+	}
+
+	filter, opts, meta, ok := subcontractRequest(ctx)
+	if !ok {
+		return
+	}
+
+	shipments, summary, err := c.SubcontractService.Outstanding(ctx.Request.Context(), filter, opts)
+	if err != nil {
+		apiresponse.QueryError(ctx, "DATA_FETCH_FAILED", "fail get subcontract outstanding", err, meta)
+		return
+	}
+
+	reportExport.Send(ctx, format, reportExport.Document[model.SubcontractShipment]{
+		FileName:  fmt.Sprintf("subkontrak_%s", opts.AsOf.Format("2006-01-02")),
+		SheetName: "Subkontrak",
+		Title:     "LAPORAN SALDO BARANG SUBKONTRAK (BC 2.6.1 / BC 2.6.2)",
+		Period:    fmt.Sprintf("Posisi %s, batas kembali %d hari", opts.AsOf.Format("02-01-2006"), opts.AllowedDays),
+		Profile:   c.CompanyProfileService.GetForExport(),
+		Columns:   shipmentColumns(),
+		Rows:      shipments,
+		Totals:    summaryTotals(summary),
+	})
+}
+
+// paginate memotong hasil per halaman di memori (status baru diketahui setelah pemasangan);
+// tanpa page/limit semua baris dikembalikan.
+func paginate[T any](ctx *gin.Context, lines []T) ([]T, gin.H) {
+	page := apiRequest.ParseInt(ctx, "page", 0)
+	limit := apiRequest.ParseInt(ctx, "limit", 0)
+
+	totalCount := len(lines)
+	totalPages, hasNext, hasPrev := 1, false, false
+	if limit > 0 && page > 0 {
+		totalPages = (totalCount + limit - 1) / limit // ceil division
+		hasNext = page < totalPages
+		hasPrev = page > 1
+		start := min((page-1)*limit, totalCount)
+		lines = lines[start:min(start+limit, totalCount)]
+	}
+
+	return lines, gin.H{
+		"page":       page,
+		"limit":      limit,
+		"totalCount": totalCount,
+		"totalPages": totalPages,
+		"count":      len(lines),
+		"hasNext":    hasNext,
+		"hasPrev":    hasPrev,
+	}
+}
+
+// subcontractRequest membaca filter dan opsi laporan subkontrak; ok = false bila respons error
+// sudah dikirim.
+func subcontractRequest(ctx *gin.Context) (subcontractRepository.Filter, model.SubcontractOptions, gin.H, bool) {
+	asOf, ok := parseDate(ctx, "asOf")
+	if !ok {
+		return subcontractRepository.Filter{}, model.SubcontractOptions{}, nil, false
+	}
+
+	filter := subcontractRepository.Filter{
+		AsOf:              asOf,
+		SubcontractorCode: strings.TrimSpace(ctx.Query("subcontractorCode")),
+		ItemCode:          strings.TrimSpace(ctx.Query("itemCode")),
+		ItemGroup:         apiRequest.ParseList(ctx, "itemGroup"),
+	}
+
+	opts := model.SubcontractOptions{
+		AsOf:        asOf,
+		AllowedDays: apiRequest.ParseInt(ctx, "allowedDays", defaultAllowedDays),
+	}
+	if opts.AllowedDays < 0 {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_ALLOWED_DAYS", "allowedDays must be >= 0", nil, gin.H{
+			"allowedDays": ctx.Query("allowedDays"),
+		})
+		return filter, opts, nil, false
+	}
+	for _, status := range apiRequest.ParseList(ctx, "status") {
+		status = strings.ToUpper(status)
+		if !slices.Contains(model.SubcontractStatuses, status) {
+			apiresponse.Error(ctx, http.StatusBadRequest, "BAD_STATUS", "status must be one of: "+strings.Join(model.SubcontractStatuses, ", "), nil, gin.H{
+				"status": ctx.Query("status"),
+			})
+			return filter, opts, nil, false
+		}
+		opts.Status = append(opts.Status, status)
+	}
+
+	return filter, opts, gin.H{
+		"asOf":              asOf.Format("2006-01-02"),
+		"subcontractorCode": filter.SubcontractorCode,
+		"itemCode":          filter.ItemCode,
+		"itemGroup":         filter.ItemGroup,
+		"allowedDays":       opts.AllowedDays,
+		"status":            opts.Status,
+	}, true
+}
+
+// parseDate membaca tanggal YYYY-MM-DD dari query key (kosong = hari ini); ok = false bila
+// respons error sudah dikirim.
+func parseDate(ctx *gin.Context, key string) (time.Time, bool) {
+	v := strings.TrimSpace(ctx.Query(key))
+	if v == "" {
+		now := time.Now().In(apiRequest.Location())
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), true
+	}
+	date, err := time.ParseInLocation("2006-01-02", v, apiRequest.Location())
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusBadRequest, "BAD_DATE", key+" must be in YYYY-MM-DD format", errors.New("invalid date"), gin.H{
+			key: v,
+		})
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// summaryTotals adalah ringkasan jumlah pengiriman per status di bawah tabel export.
+func summaryTotals(summary model.SubcontractSummary) []reportExport.TotalRow {
+	rows := []reportExport.TotalRow{{Label: fmt.Sprintf("JUMLAH PENGIRIMAN: %d", summary.ShipmentCount)}}
+	for _, status := range model.SubcontractStatuses {
+		rows = append(rows, reportExport.TotalRow{Label: fmt.Sprintf("%s: %d", status, summary.ByStatus[status])})
+	}
+	rows = append(rows, reportExport.TotalRow{Label: fmt.Sprintf("MELEWATI BATAS HARI: %d", summary.LateCount)})
+	if n := len(summary.UnmatchedReturns); n > 0 {
+		rows = append(rows, reportExport.TotalRow{Label: fmt.Sprintf("BC 2.6.2 TANPA PASANGAN BC 2.6.1: %d baris", n)})
+	}
+	return rows
+}
+
+// shipmentColumns adalah definisi kolom export (xlsx/csv/pdf) saldo subkontrak per pengiriman.
+func shipmentColumns() []reportExport.Column[model.SubcontractShipment] {
+	return []reportExport.Column[model.SubcontractShipment]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.SubcontractShipment) any { return i + 1 }},
+		{Key: "status", Title: "STATUS", Width: 11, Value: func(_ int, r model.SubcontractShipment) any { return r.Status }},
+		{Key: "jenis_pabean", Group: "DOKUMEN BC 2.6.1", Title: "JENIS", Width: 10, Value: func(_ int, r model.SubcontractShipment) any { return r.JenisPabean }},
+		{Key: "no_pabean", Group: "DOKUMEN BC 2.6.1", Title: "NOMOR", Width: 14, Value: func(_ int, r model.SubcontractShipment) any { return r.NoPabean }},
+		{Key: "tgl_pabean", Group: "DOKUMEN BC 2.6.1", Title: "TANGGAL", Width: 12, Value: func(_ int, r model.SubcontractShipment) any { return reportExport.Date(r.TglPabean) }},
+		{Key: "trans_no", Title: "NO. TRANSAKSI", Width: 16, Value: func(_ int, r model.SubcontractShipment) any { return r.TransNo }},
+		{Key: "subcontractor_name", Title: "SUBKONTRAKTOR", Width: 24, Value: func(_ int, r model.SubcontractShipment) any { return r.SubcontractorName }},
+		{Key: "item_code", Title: "KODE BARANG", Width: 15, Value: func(_ int, r model.SubcontractShipment) any { return r.ItemCode }},
+		{Key: "item_name", Title: "NAMA BARANG", Width: 28, Value: func(_ int, r model.SubcontractShipment) any { return r.ItemName }},
+		{Key: "unit", Title: "SATUAN", Width: 8, Value: func(_ int, r model.SubcontractShipment) any { return r.Unit }},
+		{Key: "qty", Title: "DIKIRIM", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.SubcontractShipment) any { return reportExport.Decimal(r.Qty) }},
+		{Key: "returned_qty", Title: "KEMBALI", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.SubcontractShipment) any { return reportExport.Decimal(r.ReturnedQty) }},
+		{Key: "outstanding_qty", Title: "SISA", Width: 14, Number: true, Decimals: 2, Value: func(_ int, r model.SubcontractShipment) any { return reportExport.Decimal(r.OutstandingQty) }},
+		{Key: "return_docs", Title: "DOKUMEN BC 2.6.2", Width: 24, Value: func(_ int, r model.SubcontractShipment) any {
+			docs := make([]string, 0, len(r.Returns))
+			for _, ret := range r.Returns {
+				if !slices.Contains(docs, ret.NoPabean) {
+					docs = append(docs, ret.NoPabean)
+				}
+			}
+			return strings.Join(docs, ", ")
+		}},
+		{Key: "due_date", Title: "BATAS KEMBALI", Width: 12, Value: func(_ int, r model.SubcontractShipment) any { return reportExport.Date(r.DueDate) }},
+		{Key: "days_open", Title: "LAMA (HARI)", Width: 10, Number: true, Value: func(_ int, r model.SubcontractShipment) any { return r.DaysOpen }},
+	}
+}
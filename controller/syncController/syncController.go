@@ -2,16 +2,23 @@ package syncController
 
 import (
 	"Bea-Cukai/helper"
+	"Bea-Cukai/helper/reportCache"
+	"Bea-Cukai/service/stockAlertService"
+	"context"
+	"log"
 	"net/http"
 	"os/exec"
 
 	"github.com/gin-gonic/gin"
 )
 
-type SyncController struct{}
+type SyncController struct {
+	StockAlertService *stockAlertService.StockAlertService
+	ReportCache       *reportCache.Cache
+}
 
-func NewSyncController() *SyncController {
-	return &SyncController{}
+func NewSyncController(stockAlertService *stockAlertService.StockAlertService, reportCache *reportCache.Cache) *SyncController {
+	return &SyncController{StockAlertService: stockAlertService, ReportCache: reportCache}
 }
 
 // RunSync executes the database sync script
@@ -52,11 +59,28 @@ func (sc *SyncController) RunSync(c *gin.Context) {
 		return
 	}
 
+	// Data berubah → naikkan data version agar hasil laporan yang ter-cache tidak dipakai lagi.
+	version := sc.ReportCache.BumpVersion()
+
+	// Evaluasi rule alert stok untuk bulan berjalan setelah data baru masuk.
+	// Dijalankan di background agar response sync tidak menunggu query laporan.
+	if sc.StockAlertService != nil {
+		go func() {
+			res, err := sc.StockAlertService.EvaluateCurrentPeriod(context.Background())
+			if err != nil {
+				log.Printf("stock alert evaluation after sync failed: %v", err)
+				return
+			}
+			log.Printf("stock alert evaluation after sync: %d violations (%d new, %d reopened)", res.Violations, res.Created, res.Reopened)
+		}()
+	}
+
 	// Success
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
-		"message": "Sinkronisasi database berhasil.",
-		"output":  string(output),
+		"message":      "Sinkronisasi database berhasil.",
+		"output":       string(output),
+		"data_version": version,
 	})
 }
 
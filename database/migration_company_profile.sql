@@ -0,0 +1,19 @@
+-- Migration script untuk profil perusahaan (kop & tanda tangan export Excel)
+
+CREATE TABLE IF NOT EXISTS `company_profile` (
+  `id` INT NOT NULL,
+  `company_name` VARCHAR(255) NOT NULL,
+  `npwp` VARCHAR(30) NOT NULL,
+  `kb_license_no` VARCHAR(100) NULL COMMENT 'Nomor izin Kawasan Berikat',
+  `address` VARCHAR(500) NOT NULL,
+  `city` VARCHAR(100) NULL COMMENT 'Kota pada blok tanda tangan',
+  `signatory_name` VARCHAR(150) NULL COMMENT 'Nama Penanggung Jawab',
+  `signatory_title` VARCHAR(150) NULL COMMENT 'Jabatan Penanggung Jawab',
+  `updated_by` VARCHAR(100) NULL,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Single-row company profile for report letterheads';
+
+-- Nilai awal = kop yang sebelumnya hard-coded
+INSERT IGNORE INTO `company_profile` (`id`, `company_name`, `npwp`, `address`, `city`) VALUES
+  (1, 'PT FUKUSUKE KOGYO INDONESIA', '01.071.250.3-052.000', 'Blok M-3-2, Kawasan MM2100, Cikarang Barat, Bekasi, 17520', 'Bekasi');
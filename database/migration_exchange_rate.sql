@@ -0,0 +1,23 @@
+-- Migration script untuk kurs mata uang asing (konversi nilai laporan ke IDR)
+
+CREATE TABLE IF NOT EXISTS `exchange_rate` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `curr_code` VARCHAR(3) NOT NULL COMMENT 'Kode mata uang ISO 4217, mis. USD, JPY (IDR tidak perlu kurs)',
+  `rate` DECIMAL(20,6) NOT NULL COMMENT 'Nilai IDR untuk 1 unit mata uang',
+  `valid_from` DATE NOT NULL,
+  `valid_to` DATE NULL COMMENT 'NULL = berlaku sampai ada kurs yang lebih baru',
+  `source` VARCHAR(30) NULL COMMENT 'mis. NDPBM (kurs pajak mingguan), BI',
+  `notes` VARCHAR(255) NULL,
+  `created_by` VARCHAR(100) NULL,
+  `updated_by` VARCHAR(100) NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq_curr_valid_from` (`curr_code`, `valid_from`),
+  INDEX `idx_valid_range` (`curr_code`, `valid_from`, `valid_to`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Kurs mata uang asing ke IDR per periode berlaku';
+
+-- Contoh kurs NDPBM mingguan (opsional)
+-- INSERT INTO `exchange_rate` (`curr_code`, `rate`, `valid_from`, `valid_to`, `source`) VALUES
+--   ('USD', 16250.000000, '2026-10-14', '2026-10-20', 'NDPBM'),
+--   ('JPY', 108.450000, '2026-10-14', '2026-10-20', 'NDPBM');
@@ -0,0 +1,23 @@
+-- Migration script untuk register file export bertanda tangan (Document ID + SHA-256)
+-- dan verifikasi file (POST /exports/verify)
+
+CREATE TABLE IF NOT EXISTS `export_document` (
+  `id` VARCHAR(32) NOT NULL COMMENT 'Document ID yang ditanam di file (EXP-YYYYMMDD-XXXXXXXXXXXX)',
+  `sha256` CHAR(64) NOT NULL COMMENT 'SHA-256 isi file yang dikirim ke user',
+  `file_name` VARCHAR(255) NULL,
+  `file_size` BIGINT NOT NULL DEFAULT 0,
+  `format` VARCHAR(10) NOT NULL COMMENT 'xlsx, csv, pdf, zip',
+  `report_path` VARCHAR(255) NOT NULL COMMENT 'Endpoint export, mis. /report/raw-material/export',
+  `filters` TEXT NULL COMMENT 'Query param laporan (JSON)',
+  `export_job_id` CHAR(32) NULL COMMENT 'Diisi bila dihasilkan oleh background export job',
+  `requested_by_id` VARCHAR(50) NULL,
+  `requested_by` VARCHAR(100) NULL,
+  `ip_address` VARCHAR(45) NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  INDEX `idx_sha256` (`sha256`),
+  INDEX `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Register file export untuk verifikasi keaslian';
+
+ALTER TABLE `export_job`
+  ADD COLUMN `document_id` VARCHAR(32) NULL COMMENT 'Document ID file hasil job (export_document.id)' AFTER `file_size`;
@@ -0,0 +1,27 @@
+-- Migration script untuk background export job (POST /exports)
+
+CREATE TABLE IF NOT EXISTS `export_job` (
+  `id` CHAR(32) NOT NULL,
+  `report_type` VARCHAR(50) NOT NULL COMMENT 'entry-products, raw-material, lpj-bundle, ... (lihat exportJobService.ReportPaths)',
+  `format` VARCHAR(10) NOT NULL COMMENT 'xlsx, csv, pdf (lpj-bundle: xlsx, zip)',
+  `filters` TEXT NULL COMMENT 'Query param laporan (JSON)',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'queued' COMMENT 'queued, running, done, failed, expired',
+  `file_name` VARCHAR(255) NULL,
+  `file_path` VARCHAR(500) NULL,
+  `file_size` BIGINT NOT NULL DEFAULT 0,
+  `error` TEXT NULL,
+  `requested_by_id` VARCHAR(50) NOT NULL,
+  `requested_by` VARCHAR(100) NULL,
+  `ip_address` VARCHAR(45) NULL,
+  `download_count` INT NOT NULL DEFAULT 0,
+  `last_downloaded_at` DATETIME NULL,
+  `started_at` DATETIME NULL,
+  `finished_at` DATETIME NULL,
+  `expires_at` DATETIME NULL COMMENT 'File dihapus setelah waktu ini (EXPORT_RETENTION_HOURS)',
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  INDEX `idx_requested_by_id` (`requested_by_id`),
+  INDEX `idx_status_expires` (`status`, `expires_at`),
+  INDEX `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Background report export jobs';
@@ -0,0 +1,7 @@
+-- Migration script untuk penanda penangguhan bea masuk per jenis dokumen pabean (laporan aging lot impor)
+
+ALTER TABLE `ms_pabean`
+  ADD COLUMN `duty_suspended` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '1 = barang mendapat penangguhan bea masuk / pajak impor' AFTER `notes`;
+
+-- Nilai awal untuk kode dokumen di pabean.txt: BC2.3, BC2.6.1, BC2.6.2 dan BC2.7
+UPDATE `ms_pabean` SET `duty_suspended` = 1 WHERE `pabean_code` IN ('23', '200', '262', '300');
@@ -0,0 +1,17 @@
+-- Migration script untuk aturan jenis dokumen pabean (validasi laporan pemasukan / pengeluaran)
+-- Butuh migration_ms_pabean_duty_suspended.sql (kolom duty_suspended).
+
+ALTER TABLE `ms_pabean`
+  ADD COLUMN `direction` VARCHAR(4) NULL COMMENT 'IN, OUT atau BOTH; NULL = belum diatur (tidak divalidasi arahnya)' AFTER `notes`,
+  ADD COLUMN `lpj_columns` VARCHAR(255) NULL COMMENT 'Kolom LPJ yang dipengaruhi, dipisah koma, mis. BAHAN_BAKU_MASUK,MESIN_MASUK' AFTER `direction`;
+
+-- Nilai awal untuk kode dokumen di pabean.txt
+UPDATE `ms_pabean` SET `direction` = 'IN', `lpj_columns` = 'BAHAN_BAKU_MASUK,BAHAN_PENOLONG_MASUK,MESIN_MASUK' WHERE `pabean_code` = '20'; -- BC2.0
+UPDATE `ms_pabean` SET `direction` = 'IN', `lpj_columns` = 'BAHAN_BAKU_MASUK,BAHAN_PENOLONG_MASUK,MESIN_MASUK' WHERE `pabean_code` = '23'; -- BC2.3
+UPDATE `ms_pabean` SET `direction` = 'OUT', `lpj_columns` = 'BAHAN_BAKU_KELUAR,BARANG_JADI_KELUAR,MESIN_KELUAR,SCRAP_KELUAR' WHERE `pabean_code` = '100'; -- BC2.5
+UPDATE `ms_pabean` SET `direction` = 'OUT', `lpj_columns` = 'BAHAN_BAKU_KELUAR,BARANG_JADI_KELUAR,MESIN_KELUAR' WHERE `pabean_code` = '200'; -- BC2.6.1
+UPDATE `ms_pabean` SET `direction` = 'IN', `lpj_columns` = 'BAHAN_BAKU_MASUK,BARANG_JADI_MASUK,MESIN_MASUK' WHERE `pabean_code` = '262'; -- BC2.6.2
+UPDATE `ms_pabean` SET `direction` = 'BOTH', `lpj_columns` = 'BAHAN_BAKU_MASUK,BAHAN_BAKU_KELUAR,BARANG_JADI_KELUAR,MESIN_MASUK,MESIN_KELUAR' WHERE `pabean_code` = '300'; -- BC2.7
+UPDATE `ms_pabean` SET `direction` = 'OUT', `lpj_columns` = 'BARANG_JADI_KELUAR,SCRAP_KELUAR' WHERE `pabean_code` = '400'; -- BC3.0
+UPDATE `ms_pabean` SET `direction` = 'IN', `lpj_columns` = 'BAHAN_BAKU_MASUK,BAHAN_PENOLONG_MASUK,MESIN_MASUK' WHERE `pabean_code` = '40'; -- BC4.0
+UPDATE `ms_pabean` SET `direction` = 'OUT', `lpj_columns` = 'BAHAN_BAKU_KELUAR,BAHAN_PENOLONG_KELUAR,MESIN_KELUAR' WHERE `pabean_code` = '500'; -- BC4.1
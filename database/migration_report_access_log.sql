@@ -0,0 +1,25 @@
+-- Migration script untuk log akses laporan (view & export), diisi middleware ReportAudit
+
+CREATE TABLE IF NOT EXISTS `report_access_log` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `user_id` VARCHAR(50) NULL COMMENT 'Kosong bila laporan dibuka tanpa login',
+  `username` VARCHAR(100) NULL,
+  `action` VARCHAR(10) NOT NULL COMMENT 'view, export',
+  `report_type` VARCHAR(100) NOT NULL COMMENT 'raw-material, entry-products, lpj-bundle, ...',
+  `path` VARCHAR(255) NOT NULL,
+  `filters` TEXT NULL COMMENT 'Query param laporan (JSON), tanpa format',
+  `format` VARCHAR(10) NULL COMMENT 'xlsx, csv, pdf, zip (export)',
+  `row_count` INT NULL COMMENT 'Jumlah baris data yang dikirim',
+  `status_code` INT NOT NULL DEFAULT 0,
+  `duration_ms` BIGINT NOT NULL DEFAULT 0,
+  `document_id` VARCHAR(32) NULL COMMENT 'export_document.id untuk export bertanda tangan',
+  `export_job_id` CHAR(32) NULL COMMENT 'Diisi bila dijalankan oleh background export job',
+  `ip_address` VARCHAR(50) NULL,
+  `user_agent` TEXT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  INDEX `idx_user_id` (`user_id`),
+  INDEX `idx_report_type` (`report_type`),
+  INDEX `idx_action` (`action`),
+  INDEX `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Audit log akses dan export laporan';
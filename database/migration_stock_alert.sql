@@ -0,0 +1,62 @@
+-- Migration script untuk fitur alert stok negatif / selisih opname
+
+-- 1. Rule alert per item group
+CREATE TABLE IF NOT EXISTS `stock_alert_rule` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `item_group` VARCHAR(50) NOT NULL COMMENT 'ms_item.item_group: MATERIAL, PRODUCT, MESIN, SCRAP',
+  `rule_type` VARCHAR(30) NOT NULL COMMENT 'negative_akhir, selisih_percent, missing_opname',
+  `threshold_percent` DECIMAL(9,2) NULL COMMENT 'Batas |selisih| dalam persen dari |akhir| (selisih_percent)',
+  `is_active` TINYINT(1) NOT NULL DEFAULT 1,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  INDEX `idx_item_group` (`item_group`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Stock alert rules per item group';
+
+-- 2. Pelanggaran rule (satu baris per rule + item + bulan; period_to = tanggal evaluasi terakhir)
+CREATE TABLE IF NOT EXISTS `stock_alert` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `rule_id` INT NOT NULL,
+  `rule_type` VARCHAR(30) NOT NULL,
+  `item_group` VARCHAR(50) NOT NULL,
+  `item_code` VARCHAR(50) NOT NULL,
+  `item_name` VARCHAR(255) NULL,
+  `unit_code` VARCHAR(20) NULL,
+  `period_from` DATE NOT NULL,
+  `period_to` DATE NOT NULL,
+  `awal` DECIMAL(18,4) NOT NULL DEFAULT 0,
+  `akhir` DECIMAL(18,4) NOT NULL DEFAULT 0,
+  `opname` DECIMAL(18,4) NOT NULL DEFAULT 0,
+  `selisih` DECIMAL(18,4) NOT NULL DEFAULT 0,
+  `message` VARCHAR(255) NULL,
+  `status` VARCHAR(20) NOT NULL DEFAULT 'open' COMMENT 'open, acknowledged, resolved',
+  `acknowledged_by` VARCHAR(100) NULL,
+  `acknowledged_at` DATETIME NULL,
+  `resolved_by` VARCHAR(100) NULL,
+  `resolved_at` DATETIME NULL,
+  `note` TEXT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq_rule_item_period` (`rule_id`, `item_code`, `period_from`),
+  INDEX `idx_status` (`status`),
+  INDEX `idx_item_group` (`item_group`),
+  INDEX `idx_item_code` (`item_code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Stock alerts raised by stock_alert_rule';
+
+-- 3. Upgrade tabel yang dibuat dengan kunci lama (period_from + period_to):
+--    sisakan alert terbaru per rule + item + period_from, lalu ganti unique key.
+-- DELETE a FROM `stock_alert` a
+--   INNER JOIN `stock_alert` b
+--     ON a.rule_id = b.rule_id AND a.item_code = b.item_code AND a.period_from = b.period_from
+--    AND (a.period_to < b.period_to OR (a.period_to = b.period_to AND a.id < b.id));
+-- ALTER TABLE `stock_alert`
+--   DROP INDEX `uq_rule_item_period`,
+--   ADD UNIQUE KEY `uq_rule_item_period` (`rule_id`, `item_code`, `period_from`);
+
+-- 4. Rule awal (opsional)
+-- INSERT INTO `stock_alert_rule` (`item_group`, `rule_type`, `threshold_percent`) VALUES
+--   ('MATERIAL', 'negative_akhir', NULL),
+--   ('MATERIAL', 'selisih_percent', 5.00),
+--   ('PRODUCT', 'negative_akhir', NULL),
+--   ('PRODUCT', 'missing_opname', NULL);
@@ -0,0 +1,18 @@
+-- Migration script untuk pemetaan subkontraktor (laporan subkontrak BC 2.6.1 / BC 2.6.2)
+-- BC 2.6.1 mencatat subkontraktor sebagai customer (tr_pengeluaran_barang.cust_code), BC 2.6.2
+-- sebagai vendor (tr_pemasukan_barang.vendor_code). Tanpa baris di tabel ini kedua kode dianggap
+-- sama; isi tabel ini bila subkontraktor punya kode vendor dan kode customer yang berbeda.
+
+CREATE TABLE IF NOT EXISTS `ms_subcontractor_map` (
+  `vendor_code` VARCHAR(50) NOT NULL COMMENT 'tr_pemasukan_barang.vendor_code pada BC 2.6.2',
+  `cust_code` VARCHAR(50) NOT NULL COMMENT 'tr_pengeluaran_barang.cust_code pada BC 2.6.1',
+  `notes` VARCHAR(255) NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`vendor_code`),
+  INDEX `idx_cust_code` (`cust_code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Kode vendor subkontraktor ke kode customer';
+
+-- Contoh (opsional)
+-- INSERT INTO `ms_subcontractor_map` (`vendor_code`, `cust_code`, `notes`) VALUES
+--   ('V-SUB-01', 'C-SUB-01', 'PT Subkontraktor Satu');
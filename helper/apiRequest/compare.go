@@ -0,0 +1,114 @@
+package apiRequest
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Mode perbandingan periode (compare=...).
+const (
+	CompareNone     = ""
+	ComparePrevious = "previous"
+	CompareYoy      = "yoy"
+	CompareCustom   = "custom"
+)
+
+// GetComparePeriod membaca periode pembanding untuk laporan periode current:
+//
+//	compare=previous                                  periode sebelumnya dengan panjang yang sama
+//	                                                  (bulan/kuartal/tahun sebelumnya untuk preset)
+//	compare=yoy                                       periode yang sama tahun lalu
+//	compare=custom&compare_from=...&compare_to=...    rentang bebas
+//
+// Tanpa compare mengembalikan CompareNone.
+func GetComparePeriod(ctx *gin.Context, current Period) (string, Period, error) {
+	return ResolveComparePeriod(ctx.Request.URL.Query(), current)
+}
+
+// ResolveComparePeriod adalah GetComparePeriod di atas query parameter q.
+func ResolveComparePeriod(q url.Values, current Period) (string, Period, error) {
+	loadCalendar()
+	mode := strings.ToLower(strings.TrimSpace(q.Get("compare")))
+	if mode != CompareCustom && (q.Get("compare_from") != "" || q.Get("compare_to") != "") {
+		return mode, Period{}, fmt.Errorf("compare_from/compare_to require compare=custom")
+	}
+
+	var base Period
+	switch mode {
+	case CompareNone:
+		return mode, Period{}, nil
+	case ComparePrevious:
+		base = previousPeriod(current)
+	case CompareYoy:
+		base = yearAgoPeriod(current)
+	case CompareCustom:
+		fromStr, toStr := strings.TrimSpace(q.Get("compare_from")), strings.TrimSpace(q.Get("compare_to"))
+		if fromStr == "" || toStr == "" {
+			return mode, Period{}, fmt.Errorf("compare=custom requires compare_from and compare_to (YYYY-MM-DD)")
+		}
+		from, errFrom := time.ParseInLocation(dateLayout, fromStr, jakarta)
+		to, errTo := time.ParseInLocation(dateLayout, toStr, jakarta)
+		if errFrom != nil || errTo != nil {
+			return mode, Period{}, fmt.Errorf("invalid compare_from/compare_to (use YYYY-MM-DD)")
+		}
+		base = newPeriod(from, to, PresetCustom, from.Format("02-01-2006")+" s.d "+to.Format("02-01-2006"))
+	default:
+		return mode, Period{}, fmt.Errorf("invalid compare %q (use previous, yoy or custom)", mode)
+	}
+
+	if base.From.After(base.To) {
+		return mode, Period{}, fmt.Errorf("compare_from (%s) must not be after compare_to (%s)", base.From.Format(dateLayout), base.To.Format(dateLayout))
+	}
+	if maxRangeDays > 0 && base.Days() > maxRangeDays {
+		return mode, Period{}, fmt.Errorf("compare range of %d days exceeds the maximum of %d days", base.Days(), maxRangeDays)
+	}
+	return mode, base, nil
+}
+
+// previousPeriod: preset bulan/kuartal/tahun mundur satu satuan kalender (Maret → Februari, bukan
+// 31 hari sebelumnya); rentang lain mundur sepanjang jumlah harinya, berakhir sehari sebelum From.
+func previousPeriod(p Period) Period {
+	switch p.Preset {
+	case PresetMonth, PresetLastClosedMonth:
+		return monthPeriod(p.From.AddDate(0, -1, 0), PresetMonth)
+	case PresetQuarter:
+		start := p.From.AddDate(0, -3, 0)
+		return newPeriod(start, start.AddDate(0, 3, -1), PresetQuarter, quarterLabel(start))
+	case PresetYear:
+		start := p.From.AddDate(-1, 0, 0)
+		return newPeriod(start, start.AddDate(1, 0, -1), PresetYear, fiscalYearLabel(fiscalYearOf(start)))
+	}
+	to := p.From.AddDate(0, 0, -1)
+	from := to.AddDate(0, 0, 1-p.Days())
+	return newPeriod(from, to, PresetCustom, from.Format("02-01-2006")+" s.d "+to.Format("02-01-2006"))
+}
+
+// yearAgoPeriod: rentang yang sama satu tahun sebelumnya; 29 Februari menjadi 28 Februari.
+func yearAgoPeriod(p Period) Period {
+	switch p.Preset {
+	case PresetMonth, PresetLastClosedMonth:
+		return monthPeriod(p.From.AddDate(-1, 0, 0), PresetMonth)
+	case PresetQuarter:
+		start := p.From.AddDate(-1, 0, 0)
+		return newPeriod(start, start.AddDate(0, 3, -1), PresetQuarter, quarterLabel(start))
+	case PresetYear:
+		start := p.From.AddDate(-1, 0, 0)
+		return newPeriod(start, start.AddDate(1, 0, -1), PresetYear, fiscalYearLabel(fiscalYearOf(start)))
+	case PresetYtd:
+		from := p.From.AddDate(-1, 0, 0)
+		return newPeriod(from, yearAgo(p.To), PresetYtd, "YTD "+fiscalYearLabel(fiscalYearOf(from)))
+	}
+	from, to := yearAgo(p.From), yearAgo(p.To)
+	return newPeriod(from, to, PresetCustom, from.Format("02-01-2006")+" s.d "+to.Format("02-01-2006"))
+}
+
+func yearAgo(t time.Time) time.Time {
+	if t.Month() == time.February && t.Day() == 29 {
+		return time.Date(t.Year()-1, time.February, 28, 0, 0, 0, 0, t.Location())
+	}
+	return t.AddDate(-1, 0, 0)
+}
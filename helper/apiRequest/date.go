@@ -1,41 +1,267 @@
 package apiRequest
 
 import (
+	"Bea-Cukai/helper"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func parseDate(value string, loc *time.Location) (time.Time, error) {
-	// accept RFC3339 date or YYYY-MM-DD
-	if value == "" {
-		return time.Time{}, nil
-	}
-	if t, err := time.Parse("2006-01-02", value); err == nil {
-		return t, nil
+// Preset periode laporan (meta.period.preset).
+const (
+	PresetCustom          = "custom"
+	PresetMonth           = "month"
+	PresetQuarter         = "quarter"
+	PresetYear            = "year"
+	PresetYtd             = "ytd"
+	PresetLastClosedMonth = "last-closed-month"
+	PresetLast30Days      = "last-30-days"
+)
+
+const (
+	defaultMaxRangeDays = 366
+	dateLayout          = "2006-01-02"
+)
+
+var (
+	calendarOnce         sync.Once
+	fiscalYearStartMonth = time.January
+	maxRangeDays         = defaultMaxRangeDays
+	jakarta              *time.Location
+)
+
+// loadCalendar membaca kalender laporan dari env (sekali):
+//
+//	FISCAL_YEAR_START_MONTH  bulan awal tahun buku 1..12 (default 1); year=/quarter=/ytd mengikuti tahun buku
+//	REPORT_MAX_RANGE_DAYS    rentang maksimal satu laporan dalam hari (default 366, 0 = tanpa batas)
+func loadCalendar() {
+	calendarOnce.Do(func() {
+		if m, err := strconv.Atoi(helper.GetEnv("FISCAL_YEAR_START_MONTH")); err == nil && m >= 1 && m <= 12 {
+			fiscalYearStartMonth = time.Month(m)
+		}
+		if n, err := strconv.Atoi(helper.GetEnv("REPORT_MAX_RANGE_DAYS")); err == nil && n >= 0 {
+			maxRangeDays = n
+		}
+		loc, err := time.LoadLocation("Asia/Jakarta")
+		if err != nil {
+			// tzdata tidak tersedia di server: WIB tidak mengenal DST, offset tetap cukup
+			loc = time.FixedZone("WIB", 7*60*60)
+		}
+		jakarta = loc
+	})
+}
+
+// Location adalah zona waktu laporan (Asia/Jakarta).
+func Location() *time.Location {
+	loadCalendar()
+	return jakarta
+}
+
+// Period adalah rentang tanggal laporan yang sudah di-resolve dari query parameter.
+// From adalah awal hari pertama dan To akhir hari terakhir (23:59:59), keduanya di Asia/Jakarta.
+type Period struct {
+	From   time.Time
+	To     time.Time
+	Preset string
+	Label  string
+}
+
+// Days adalah jumlah hari kalender di periode (inklusif).
+func (p Period) Days() int {
+	return DaysBetween(p.From, p.To) + 1
+}
+
+// DaysBetween adalah selisih hari kalender to - from (jam dan zona diabaikan; negatif bila to sebelum from).
+func DaysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// Meta adalah periode yang dipakai laporan, untuk meta.period di respons.
+func (p Period) Meta() gin.H {
+	return gin.H{
+		"from":     p.From.Format(dateLayout),
+		"to":       p.To.Format(dateLayout),
+		"preset":   p.Preset,
+		"label":    p.Label,
+		"days":     p.Days(),
+		"timezone": Location().String(),
 	}
-	return time.Parse("2006-01-02", value)
 }
 
+// GetPeriod membaca periode laporan dari query parameter. Pilih salah satu:
+//
+//	from=YYYY-MM-DD&to=YYYY-MM-DD    rentang bebas (salah satu boleh kosong)
+//	period=2026-09                   satu bulan
+//	period=2026Q3 | quarter=2026Q3   satu kuartal tahun buku
+//	period=2026   | year=2026        satu tahun buku
+//	period=ytd                       awal tahun buku s.d hari ini
+//	period=last-closed-month         bulan kalender sebelumnya
+//
+// Tanpa parameter: 30 hari terakhir. from > to dan rentang lebih dari REPORT_MAX_RANGE_DAYS ditolak.
+func GetPeriod(ctx *gin.Context) (Period, error) {
+	return ResolvePeriod(ctx.Request.URL.Query(), time.Now())
+}
+
+// GetRange adalah GetPeriod yang hanya mengembalikan from dan to.
 func GetRange(ctx *gin.Context) (time.Time, time.Time, error) {
-	loc, _ := time.LoadLocation("Asia/Jakarta")
-	fromStr := ctx.Query("from")
-	toStr := ctx.Query("to")
-
-	// defaults: last 30 days
-	now := time.Now()
-	from := now.AddDate(0, 0, -30)
-	to := now
-
-	if t, err := parseDate(fromStr, loc); err == nil && !t.IsZero() {
-		from = t
-	} else if fromStr != "" && err != nil {
-		return time.Time{}, time.Time{}, err
-	}
-	if t, err := parseDate(toStr, loc); err == nil && !t.IsZero() {
-		to = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
-	} else if toStr != "" && err != nil {
-		return time.Time{}, time.Time{}, err
-	}
-	return from, to, nil
-}
\ No newline at end of file
+	p, err := GetPeriod(ctx)
+	return p.From, p.To, err
+}
+
+// ResolvePeriod adalah GetPeriod dengan "hari ini" = now (dikonversi ke Asia/Jakarta).
+func ResolvePeriod(q url.Values, now time.Time) (Period, error) {
+	loadCalendar()
+	today := startOfDay(now.In(jakarta))
+
+	var presets []string
+	for _, key := range []string{"period", "quarter", "year"} {
+		if strings.TrimSpace(q.Get(key)) != "" {
+			presets = append(presets, key)
+		}
+	}
+	fromStr, toStr := strings.TrimSpace(q.Get("from")), strings.TrimSpace(q.Get("to"))
+	if len(presets) > 1 || (len(presets) == 1 && (fromStr != "" || toStr != "")) {
+		if fromStr != "" || toStr != "" {
+			presets = append(presets, "from/to")
+		}
+		return Period{}, fmt.Errorf("use only one of period, quarter, year or from/to (got %s)", strings.Join(presets, ", "))
+	}
+
+	var (
+		p   Period
+		err error
+	)
+	switch {
+	case len(presets) == 1 && presets[0] == "quarter":
+		p, err = quarterPeriod(q.Get("quarter"))
+	case len(presets) == 1 && presets[0] == "year":
+		p, err = yearPeriod(q.Get("year"))
+	case len(presets) == 1:
+		p, err = presetPeriod(q.Get("period"), today)
+	default:
+		p, err = customPeriod(fromStr, toStr, today)
+	}
+	if err != nil {
+		return Period{}, err
+	}
+
+	if p.From.After(p.To) {
+		return Period{}, fmt.Errorf("from (%s) must not be after to (%s)", p.From.Format(dateLayout), p.To.Format(dateLayout))
+	}
+	if maxRangeDays > 0 && p.Days() > maxRangeDays {
+		return Period{}, fmt.Errorf("date range of %d days exceeds the maximum of %d days", p.Days(), maxRangeDays)
+	}
+	return p, nil
+}
+
+func presetPeriod(value string, today time.Time) (Period, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case PresetYtd:
+		start := fiscalYearStart(today)
+		return newPeriod(start, today, PresetYtd, "YTD "+fiscalYearLabel(fiscalYearOf(today))), nil
+	case PresetLastClosedMonth:
+		first := time.Date(today.Year(), today.Month()-1, 1, 0, 0, 0, 0, jakarta)
+		return monthPeriod(first, PresetLastClosedMonth), nil
+	case PresetLast30Days:
+		return newPeriod(today.AddDate(0, 0, -30), today, PresetLast30Days, "30 hari terakhir"), nil
+	}
+	if strings.Contains(value, "q") {
+		return quarterPeriod(value)
+	}
+	if len(value) == 4 {
+		return yearPeriod(value)
+	}
+	t, err := time.ParseInLocation("2006-01", value, jakarta)
+	if err != nil {
+		return Period{}, fmt.Errorf("invalid period %q (use YYYY-MM, YYYYQn, YYYY, ytd or last-closed-month)", value)
+	}
+	return monthPeriod(t, PresetMonth), nil
+}
+
+func customPeriod(fromStr, toStr string, today time.Time) (Period, error) {
+	if fromStr == "" && toStr == "" {
+		return newPeriod(today.AddDate(0, 0, -30), today, PresetLast30Days, "30 hari terakhir"), nil
+	}
+	from, to := today.AddDate(0, 0, -30), today
+	var err error
+	if fromStr != "" {
+		if from, err = time.ParseInLocation(dateLayout, fromStr, jakarta); err != nil {
+			return Period{}, fmt.Errorf("invalid from date %q (use YYYY-MM-DD)", fromStr)
+		}
+	}
+	if toStr != "" {
+		if to, err = time.ParseInLocation(dateLayout, toStr, jakarta); err != nil {
+			return Period{}, fmt.Errorf("invalid to date %q (use YYYY-MM-DD)", toStr)
+		}
+	}
+	return newPeriod(from, to, PresetCustom, from.Format("02-01-2006")+" s.d "+to.Format("02-01-2006")), nil
+}
+
+func monthPeriod(first time.Time, preset string) Period {
+	return newPeriod(first, first.AddDate(0, 1, -1), preset, first.Format("01-2006"))
+}
+
+// quarterPeriod: 2026Q1 = tiga bulan pertama tahun buku 2026.
+func quarterPeriod(value string) (Period, error) {
+	yearStr, qStr, ok := strings.Cut(strings.ToUpper(strings.TrimSpace(value)), "Q")
+	year, errYear := strconv.Atoi(strings.TrimSuffix(yearStr, "-"))
+	q, errQ := strconv.Atoi(qStr)
+	if !ok || errYear != nil || errQ != nil || year < 1900 || q < 1 || q > 4 {
+		return Period{}, fmt.Errorf("invalid quarter %q (use YYYYQn, e.g. 2026Q3)", value)
+	}
+	start := time.Date(year, fiscalYearStartMonth+time.Month(3*(q-1)), 1, 0, 0, 0, 0, jakarta)
+	return newPeriod(start, start.AddDate(0, 3, -1), PresetQuarter, quarterLabel(start)), nil
+}
+
+// yearPeriod: tahun buku penuh; dengan FISCAL_YEAR_START_MONTH=4, 2026 = 01-04-2026 s.d 31-03-2027.
+func yearPeriod(value string) (Period, error) {
+	year, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || year < 1900 || year > 9999 {
+		return Period{}, fmt.Errorf("invalid year %q (use YYYY)", value)
+	}
+	start := time.Date(year, fiscalYearStartMonth, 1, 0, 0, 0, 0, jakarta)
+	return newPeriod(start, start.AddDate(1, 0, -1), PresetYear, fiscalYearLabel(year)), nil
+}
+
+func newPeriod(from, to time.Time, preset, label string) Period {
+	from = startOfDay(from)
+	to = startOfDay(to).Add(24*time.Hour - time.Second)
+	return Period{From: from, To: to, Preset: preset, Label: label}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, jakarta)
+}
+
+// fiscalYearOf adalah tahun buku yang memuat t (dinamai menurut tahun kalender awalnya).
+func fiscalYearOf(t time.Time) int {
+	if t.Month() < fiscalYearStartMonth {
+		return t.Year() - 1
+	}
+	return t.Year()
+}
+
+func fiscalYearStart(t time.Time) time.Time {
+	return time.Date(fiscalYearOf(t), fiscalYearStartMonth, 1, 0, 0, 0, 0, jakarta)
+}
+
+// quarterLabel: label kuartal tahun buku yang dimulai pada start, mis. 2026Q3.
+func quarterLabel(start time.Time) string {
+	q := (int(start.Month()-fiscalYearStartMonth)+12)%12/3 + 1
+	return fmt.Sprintf("%sQ%d", fiscalYearLabel(fiscalYearOf(start)), q)
+}
+
+func fiscalYearLabel(year int) string {
+	if fiscalYearStartMonth == time.January {
+		return strconv.Itoa(year)
+	}
+	return "FY" + strconv.Itoa(year)
+}
@@ -0,0 +1,153 @@
+package apiRequest
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestResolvePeriod(t *testing.T) {
+	// 2026-10-01 01:00 WIB = 2026-09-30 18:00 UTC: "hari ini" harus mengikuti Asia/Jakarta
+	now := time.Date(2026, 9, 30, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		query            string
+		from, to, preset string
+	}{
+		{"", "2026-09-01", "2026-10-01", PresetLast30Days},
+		{"from=2026-09-01&to=2026-09-15", "2026-09-01", "2026-09-15", PresetCustom},
+		{"period=2026-02", "2026-02-01", "2026-02-28", PresetMonth},
+		{"period=2026Q3", "2026-07-01", "2026-09-30", PresetQuarter},
+		{"quarter=2026q4", "2026-10-01", "2026-12-31", PresetQuarter},
+		{"year=2024", "2024-01-01", "2024-12-31", PresetYear},
+		{"period=ytd", "2026-01-01", "2026-10-01", PresetYtd},
+		{"period=last-closed-month", "2026-09-01", "2026-09-30", PresetLastClosedMonth},
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		p, err := ResolvePeriod(q, now)
+		if err != nil {
+			t.Errorf("%q: %v", tt.query, err)
+			continue
+		}
+		if got := p.From.Format(dateLayout); got != tt.from {
+			t.Errorf("%q: from = %s; want %s", tt.query, got, tt.from)
+		}
+		if got := p.To.Format(dateLayout); got != tt.to {
+			t.Errorf("%q: to = %s; want %s", tt.query, got, tt.to)
+		}
+		if p.Preset != tt.preset {
+			t.Errorf("%q: preset = %s; want %s", tt.query, p.Preset, tt.preset)
+		}
+		if p.From.Location() != Location() || p.To.Hour() != 23 {
+			t.Errorf("%q: from/to must be Asia/Jakarta day bounds, got %v..%v", tt.query, p.From, p.To)
+		}
+	}
+}
+
+func TestResolvePeriod_Invalid(t *testing.T) {
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	for _, query := range []string{
+		"from=2026-09-15&to=2026-09-01",
+		"from=2024-01-01&to=2026-01-01",
+		"period=2026-13",
+		"quarter=2026Q5",
+		"year=26",
+		"period=2026-09&from=2026-09-01",
+		"period=2026-09&year=2026",
+		"from=01-09-2026",
+	} {
+		q, _ := url.ParseQuery(query)
+		if _, err := ResolvePeriod(q, now); err == nil {
+			t.Errorf("%q: expected error", query)
+		}
+	}
+}
+
+func TestResolvePeriod_FiscalYear(t *testing.T) {
+	loadCalendar()
+	defer func(m time.Month) { fiscalYearStartMonth = m }(fiscalYearStartMonth)
+	fiscalYearStartMonth = time.April
+
+	now := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	for query, want := range map[string][2]string{
+		"year=2026":      {"2026-04-01", "2027-03-31"},
+		"quarter=2026Q1": {"2026-04-01", "2026-06-30"},
+		"quarter=2026Q4": {"2027-01-01", "2027-03-31"},
+		"period=ytd":     {"2025-04-01", "2026-02-10"},
+	} {
+		q, _ := url.ParseQuery(query)
+		p, err := ResolvePeriod(q, now)
+		if err != nil {
+			t.Errorf("%q: %v", query, err)
+			continue
+		}
+		if got := [2]string{p.From.Format(dateLayout), p.To.Format(dateLayout)}; got != want {
+			t.Errorf("%q: got %v; want %v", query, got, want)
+		}
+	}
+}
+
+func TestResolveComparePeriod(t *testing.T) {
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		query    string
+		from, to string
+	}{
+		{"period=2026-03&compare=previous", "2026-02-01", "2026-02-28"},
+		{"period=2026-09&compare=yoy", "2025-09-01", "2025-09-30"},
+		{"quarter=2026Q1&compare=previous", "2025-10-01", "2025-12-31"},
+		{"year=2026&compare=yoy", "2025-01-01", "2025-12-31"},
+		{"from=2026-09-11&to=2026-09-20&compare=previous", "2026-09-01", "2026-09-10"},
+		{"from=2024-02-01&to=2024-02-29&compare=yoy", "2023-02-01", "2023-02-28"},
+		{"period=ytd&compare=yoy", "2025-01-01", "2025-10-19"},
+		{"period=2026-09&compare=custom&compare_from=2026-06-01&compare_to=2026-06-30", "2026-06-01", "2026-06-30"},
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		current, err := ResolvePeriod(q, now)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.query, err)
+		}
+		_, base, err := ResolveComparePeriod(q, current)
+		if err != nil {
+			t.Errorf("%q: %v", tt.query, err)
+			continue
+		}
+		if got := [2]string{base.From.Format(dateLayout), base.To.Format(dateLayout)}; got != [2]string{tt.from, tt.to} {
+			t.Errorf("%q: base = %v; want %s..%s", tt.query, got, tt.from, tt.to)
+		}
+	}
+
+	for _, query := range []string{
+		"period=2026-09&compare=lastweek",
+		"period=2026-09&compare=custom&compare_from=2026-06-01",
+		"period=2026-09&compare=previous&compare_from=2026-06-01",
+		"period=2026-09&compare=custom&compare_from=2026-06-30&compare_to=2026-06-01",
+	} {
+		q, _ := url.ParseQuery(query)
+		current, _ := ResolvePeriod(q, now)
+		if _, _, err := ResolveComparePeriod(q, current); err == nil {
+			t.Errorf("%q: expected error", query)
+		}
+	}
+}
+
+func TestDaysBetween(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	tests := []struct {
+		from, to time.Time
+		want     int
+	}{
+		{time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 9, 1, 23, 59, 0, 0, time.UTC), 0},
+		{time.Date(2026, 9, 1, 23, 0, 0, 0, wib), time.Date(2026, 9, 2, 1, 0, 0, 0, time.UTC), 1},
+		{time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC), time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), -9},
+	}
+	for _, tt := range tests {
+		if got := DaysBetween(tt.from, tt.to); got != tt.want {
+			t.Errorf("DaysBetween(%s, %s) = %d; want %d", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
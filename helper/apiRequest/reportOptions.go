@@ -0,0 +1,107 @@
+package apiRequest
+
+import (
+	"Bea-Cukai/helper/reportCompare"
+	"Bea-Cukai/helper/reportQuery"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// GetReportOptions membaca parameter sort/filter laporan LPJ dan memvalidasinya terhadap whitelist kolom:
+//
+//	sort=-selisih,item_code    item_type_code=...    unit_code=...
+//	only_selisih=1             negative_akhir=1      min_<kolom>=...&max_<kolom>=...
+func GetReportOptions(ctx *gin.Context, cols reportQuery.Columns) (reportQuery.Options, error) {
+	opts := reportQuery.Options{
+		ItemTypeCode: strings.TrimSpace(ctx.Query("item_type_code")),
+		UnitCode:     strings.TrimSpace(ctx.Query("unit_code")),
+	}
+
+	sortFields, err := reportQuery.ParseSort(ctx.Query("sort"), cols)
+	if err != nil {
+		return opts, err
+	}
+	opts.Sort = sortFields
+
+	if opts.NonZeroOnly, err = parseBoolQuery(ctx, "only_selisih"); err != nil {
+		return opts, err
+	}
+	if opts.NegativeAkhir, err = parseBoolQuery(ctx, "negative_akhir"); err != nil {
+		return opts, err
+	}
+
+	for _, name := range cols.NumericNames() {
+		t := reportQuery.Threshold{Column: name}
+		if t.Min, err = parseDecimalQuery(ctx, "min_"+name); err != nil {
+			return opts, err
+		}
+		if t.Max, err = parseDecimalQuery(ctx, "max_"+name); err != nil {
+			return opts, err
+		}
+		if t.Min != nil || t.Max != nil {
+			opts.Thresholds = append(opts.Thresholds, t)
+		}
+	}
+
+	// min_/max_ untuk kolom yang tidak ada di laporan ini → tolak, jangan diam-diam diabaikan
+	for key := range ctx.Request.URL.Query() {
+		if col, ok := strings.CutPrefix(key, "min_"); ok && !cols[col].Numeric {
+			return opts, fmt.Errorf("unknown numeric column %q (allowed: %s)", col, strings.Join(cols.NumericNames(), ", "))
+		}
+		if col, ok := strings.CutPrefix(key, "max_"); ok && !cols[col].Numeric {
+			return opts, fmt.Errorf("unknown numeric column %q (allowed: %s)", col, strings.Join(cols.NumericNames(), ", "))
+		}
+	}
+
+	return opts, opts.Validate(cols)
+}
+
+// GetCompareOptions membaca parameter laporan perbandingan periode (compare=...):
+// sort terhadap kolom perbandingan (mis. sort=-abs_delta_akhir), item_type_code dan unit_code.
+// only_selisih, negative_akhir dan min_/max_ ditolak karena akan menyaring tiap periode secara terpisah.
+func GetCompareOptions(ctx *gin.Context) (reportQuery.Options, error) {
+	opts := reportQuery.Options{
+		ItemTypeCode: strings.TrimSpace(ctx.Query("item_type_code")),
+		UnitCode:     strings.TrimSpace(ctx.Query("unit_code")),
+	}
+	for key := range ctx.Request.URL.Query() {
+		if key == "only_selisih" || key == "negative_akhir" || strings.HasPrefix(key, "min_") || strings.HasPrefix(key, "max_") {
+			return opts, fmt.Errorf("%s is not supported together with compare", key)
+		}
+	}
+
+	sortFields, err := reportQuery.ParseSort(ctx.Query("sort"), reportCompare.Columns)
+	if err != nil {
+		return opts, err
+	}
+	opts.Sort = sortFields
+	return opts, nil
+}
+
+func parseBoolQuery(ctx *gin.Context, key string) (bool, error) {
+	v := strings.TrimSpace(ctx.Query(key))
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean", key)
+	}
+	return b, nil
+}
+
+func parseDecimalQuery(ctx *gin.Context, key string) (*decimal.Decimal, error) {
+	v := strings.TrimSpace(ctx.Query(key))
+	if v == "" {
+		return nil, nil
+	}
+	d, err := decimal.NewFromString(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", key)
+	}
+	return &d, nil
+}
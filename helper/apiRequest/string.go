@@ -13,3 +13,21 @@ func ParseString(c *gin.Context, key, def string) string {
 	}
 	return v
 }
+
+// ParseList membaca parameter multi-nilai: key=A,B dan/atau key=A&key=B.
+// Nilai kosong dibuang dan duplikat dihapus dengan urutan tetap.
+func ParseList(c *gin.Context, key string) []string {
+	list := []string{}
+	seen := map[string]bool{}
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			v = strings.TrimSpace(v)
+			if v == "" || seen[v] {
+				continue
+			}
+			seen[v] = true
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,26 @@
+package apiresponse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusClientClosedRequest (nginx 499): client menutup koneksi sebelum respons dikirim.
+const StatusClientClosedRequest = 499
+
+// QueryError menulis error dari query laporan. Query yang melewati batas waktu laporan
+// dijawab 504 QUERY_TIMEOUT, query yang dibatalkan karena client memutus koneksi 499
+// CLIENT_CLOSED_REQUEST; error lain 500 dengan code yang diberikan.
+func QueryError(c *gin.Context, code, message string, err error, meta any) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		Error(c, http.StatusGatewayTimeout, "QUERY_TIMEOUT", "report query exceeded the time limit, narrow the period or filters", err, meta)
+	case errors.Is(err, context.Canceled):
+		Error(c, StatusClientClosedRequest, "CLIENT_CLOSED_REQUEST", "request cancelled by client", err, meta)
+	default:
+		Error(c, http.StatusInternalServerError, code, message, err, meta)
+	}
+}
@@ -67,6 +67,7 @@ func OK[T any](c *gin.Context, data T, message string, meta any) {
 		Meta:    meta,
 		TraceID: getTraceID(c),
 	}
+	setRowCountOf(c, data)
 	c.JSON(http.StatusOK, resp)
 }
 
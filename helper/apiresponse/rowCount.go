@@ -0,0 +1,33 @@
+package apiresponse
+
+import (
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rowCountKey menyimpan jumlah baris yang dikirim handler laporan, dibaca oleh
+// middleware.ReportAudit untuk log akses laporan.
+const rowCountKey = "apiresponse.rowCount"
+
+// SetRowCount mencatat jumlah baris respons (data list atau baris file export).
+func SetRowCount(c *gin.Context, n int) {
+	c.Set(rowCountKey, n)
+}
+
+// RowCount mengembalikan jumlah baris yang dicatat SetRowCount / OK.
+func RowCount(c *gin.Context) (int, bool) {
+	n, ok := c.Get(rowCountKey)
+	if !ok {
+		return 0, false
+	}
+	v, ok := n.(int)
+	return v, ok
+}
+
+// setRowCountOf mencatat panjang data bila berupa slice.
+func setRowCountOf(c *gin.Context, data any) {
+	if v := reflect.ValueOf(data); v.Kind() == reflect.Slice {
+		SetRowCount(c, v.Len())
+	}
+}
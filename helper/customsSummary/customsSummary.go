@@ -0,0 +1,180 @@
+package customsSummary
+
+import (
+	"Bea-Cukai/model"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Ringkasan dokumen pabean dihitung dari tiga query GROUP BY di atas query laporan yang sudah
+// difilter (alias p): jumlah dokumen & baris per kelompok, kuantitas per satuan dan nilai per
+// mata uang. Kuantitas dan nilai tidak dijumlahkan lintas satuan / mata uang.
+
+// Pengelompokan yang tersedia (groupBy=...).
+const (
+	GroupByJenisPabean = "jenis_pabean"
+	GroupByMonth       = "month"
+	GroupByVendor      = "vendor"
+	GroupByCustomer    = "customer"
+	GroupByItem        = "item"
+	GroupByItemGroup   = "item_group"
+	GroupByCurrency    = "currency"
+)
+
+// Group adalah ekspresi SQL satu pengelompokan: Key untuk GROUP BY, Label teks tampilannya.
+type Group struct {
+	Key   string
+	Label string
+}
+
+// Spec adalah definisi ringkasan satu tabel transaksi pabean.
+type Spec struct {
+	Groups         map[string]Group
+	QuantityColumn string // mis. p.rcv_qty
+	UnitColumn     string // mis. p.pch_unit
+}
+
+// GroupNames mengembalikan nama pengelompokan yang didukung, terurut (untuk pesan error).
+func (s Spec) GroupNames() []string {
+	names := make([]string, 0, len(s.Groups))
+	for name := range s.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate memastikan groupBy didukung tabel ini.
+func (s Spec) Validate(groupBy string) error {
+	if _, ok := s.Groups[groupBy]; !ok {
+		return fmt.Errorf("unknown groupBy %q (allowed: %s)", groupBy, strings.Join(s.GroupNames(), ", "))
+	}
+	return nil
+}
+
+// Pengelompokan yang sama untuk pemasukan dan pengeluaran; month bernilai YYYY-MM dengan label MM-YYYY.
+var (
+	JenisPabeanGroup = Group{Key: "p.jenis_pabean", Label: "p.jenis_pabean"}
+	MonthGroup       = Group{Key: "DATE_FORMAT(p.tgl_pabean, '%Y-%m')", Label: "DATE_FORMAT(p.tgl_pabean, '%m-%Y')"}
+	ItemGroupGroup   = Group{Key: "COALESCE(i.item_group, '')", Label: "COALESCE(i.item_group, '')"}
+	CurrencyGroup    = Group{Key: "p.curr_code", Label: "p.curr_code"}
+)
+
+// totalGroup mengelompokkan semua baris menjadi satu (ringkasan keseluruhan).
+var totalGroup = Group{Key: "'TOTAL'", Label: "'TOTAL'"}
+
+// Run menghitung ringkasan per kelompok groupBy beserta ringkasan keseluruhan (jumlah dokumen
+// dihitung ulang, bukan dijumlahkan, karena satu dokumen bisa muncul di beberapa kelompok).
+// base harus mengembalikan query baru yang sudah difilter setiap kali dipanggil (tanpa
+// select/order/pagination). Urutan hasil: jenis_pabean, month dan currency menurut key; lainnya
+// menurut jumlah dokumen terbanyak. limit > 0 membatasi ke N kelompok teratas.
+func Run(base func() *gorm.DB, spec Spec, groupBy string, limit int) ([]model.CustomsSummary, model.CustomsSummary, error) {
+	total := model.CustomsSummary{Key: "TOTAL", Label: "TOTAL", Quantities: []model.QuantityTotal{}, Amounts: []model.AmountTotal{}}
+	if err := spec.Validate(groupBy); err != nil {
+		return nil, total, err
+	}
+
+	rows, err := aggregate(base, spec, spec.Groups[groupBy])
+	if err != nil {
+		return nil, total, err
+	}
+	totals, err := aggregate(base, spec, totalGroup)
+	if err != nil {
+		return nil, total, err
+	}
+	if len(totals) == 1 {
+		total = totals[0]
+	}
+
+	switch groupBy {
+	case GroupByJenisPabean, GroupByMonth, GroupByCurrency:
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].Key < rows[j].Key })
+	default:
+		sort.SliceStable(rows, func(i, j int) bool {
+			if rows[i].DocumentCount != rows[j].DocumentCount {
+				return rows[i].DocumentCount > rows[j].DocumentCount
+			}
+			return rows[i].Key < rows[j].Key
+		})
+	}
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows, total, nil
+}
+
+func aggregate(base func() *gorm.DB, spec Spec, group Group) ([]model.CustomsSummary, error) {
+	var counts []struct {
+		GroupKey      string
+		GroupLabel    string
+		DocumentCount int64
+		LineCount     int64
+	}
+	err := base().
+		Select(fmt.Sprintf(`COALESCE(%s, '') AS group_key, COALESCE(MAX(%s), '') AS group_label,
+			COUNT(DISTINCT p.jenis_pabean, p.no_pabean) AS document_count, COUNT(*) AS line_count`, group.Key, group.Label)).
+		Group("group_key").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// alias qty_unit / amount_curr sengaja beda dari nama kolom: GROUP BY MySQL mencari nama
+	// kolom tabel lebih dulu sebelum alias select.
+	var quantities []struct {
+		GroupKey string
+		QtyUnit  string
+		Quantity decimal.Decimal
+	}
+	err = base().
+		Select(fmt.Sprintf("COALESCE(%s, '') AS group_key, COALESCE(%s, '') AS qty_unit, SUM(%s) AS quantity", group.Key, spec.UnitColumn, spec.QuantityColumn)).
+		Group("group_key").Group("qty_unit").
+		Order("qty_unit").
+		Scan(&quantities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var amounts []struct {
+		GroupKey   string
+		AmountCurr string
+		Amount     decimal.Decimal
+	}
+	err = base().
+		Select(fmt.Sprintf("COALESCE(%s, '') AS group_key, COALESCE(p.curr_code, '') AS amount_curr, SUM(p.net_amount) AS amount", group.Key)).
+		Group("group_key").Group("amount_curr").
+		Order("amount_curr").
+		Scan(&amounts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(counts))
+	rows := make([]model.CustomsSummary, len(counts))
+	for i, c := range counts {
+		index[c.GroupKey] = i
+		rows[i] = model.CustomsSummary{
+			Key:           c.GroupKey,
+			Label:         c.GroupLabel,
+			DocumentCount: c.DocumentCount,
+			LineCount:     c.LineCount,
+			Quantities:    []model.QuantityTotal{},
+			Amounts:       []model.AmountTotal{},
+		}
+	}
+	for _, q := range quantities {
+		if i, ok := index[q.GroupKey]; ok {
+			rows[i].Quantities = append(rows[i].Quantities, model.QuantityTotal{Unit: q.QtyUnit, Quantity: q.Quantity})
+		}
+	}
+	for _, a := range amounts {
+		if i, ok := index[a.GroupKey]; ok {
+			rows[i].Amounts = append(rows[i].Amounts, model.AmountTotal{CurrCode: a.AmountCurr, Amount: a.Amount})
+		}
+	}
+	return rows, nil
+}
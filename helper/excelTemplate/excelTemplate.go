@@ -0,0 +1,135 @@
+package excelTemplate
+
+import (
+	"Bea-Cukai/model"
+	"fmt"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Kop dan blok tanda tangan ditulis lewat excelize.StreamWriter agar export besar tidak
+// menahan seluruh sheet di memori. StreamWriter mewajibkan baris ditulis berurutan, jadi
+// pemanggil menulis kop lebih dulu, lalu tabel, lalu tanda tangan.
+
+// LetterheadRows adalah jumlah baris yang dipakai kop; tabel laporan dimulai di baris berikutnya.
+const LetterheadRows = 8
+
+// WriteLetterhead menulis kop laporan LPJ dari profil perusahaan:
+//
+//	1 nama perusahaan, 2 judul laporan, 4 Nama Kawasan Berikat, 5 NPWP,
+//	6 No. Izin KB, 7 Alamat, 8 Periode Laporan
+//
+// Tabel laporan dimulai di baris 9.
+func WriteLetterhead(f *excelize.File, sw *excelize.StreamWriter, p model.CompanyProfile, title, period, lastCol string) error {
+	titleStyle, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		Font:      &excelize.Font{Bold: true, Size: 12},
+	})
+	if err != nil {
+		return err
+	}
+	labelStyle, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "left", Vertical: "center"},
+		Font:      &excelize.Font{Bold: true, Size: 10},
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, text := range []string{p.CompanyName, title} {
+		row := i + 1
+		if err := sw.SetRow(fmt.Sprintf("A%d", row), []interface{}{excelize.Cell{StyleID: titleStyle, Value: text}}); err != nil {
+			return err
+		}
+		if err := sw.MergeCell(fmt.Sprintf("A%d", row), fmt.Sprintf("%s%d", lastCol, row)); err != nil {
+			return err
+		}
+	}
+
+	rows := [][2]string{
+		{"Nama Kawasan Berikat", p.CompanyName},
+		{"NPWP", p.Npwp},
+		{"No. Izin KB", p.KbLicenseNo},
+		{"Alamat", p.Address},
+		{"Periode Laporan", period},
+	}
+	for i, r := range rows {
+		row := i + 4
+		cells := []interface{}{excelize.Cell{StyleID: labelStyle, Value: r[0]}, nil, ": " + r[1]}
+		if err := sw.SetRow(fmt.Sprintf("A%d", row), cells); err != nil {
+			return err
+		}
+		if err := sw.MergeCell(fmt.Sprintf("C%d", row), fmt.Sprintf("%s%d", lastCol, row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSignature menulis blok tanda tangan "Penanggung Jawab" mulai baris row,
+// rata tengah di kolom fromCol..toCol. Mengembalikan baris terakhir yang terpakai.
+func WriteSignature(f *excelize.File, sw *excelize.StreamWriter, p model.CompanyProfile, row int, fromCol, toCol string, signDate time.Time) (int, error) {
+	dateLine := signDate.Format("02-01-2006")
+	if p.City != "" {
+		dateLine = p.City + ", " + dateLine
+	}
+
+	lines := []string{
+		dateLine,
+		"Penanggung Jawab",
+		"", "", "", // ruang tanda tangan
+		p.SignatoryName,
+		p.SignatoryTitle,
+	}
+
+	centerStyle, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	})
+	if err != nil {
+		return 0, err
+	}
+	nameStyle, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		Font:      &excelize.Font{Bold: true, Underline: "single"},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	fromNum, err := excelize.ColumnNameToNumber(fromCol)
+	if err != nil {
+		return 0, err
+	}
+	toNum, err := excelize.ColumnNameToNumber(toCol)
+	if err != nil {
+		return 0, err
+	}
+
+	nameRow := row + len(lines) - 2
+	for i, line := range lines {
+		r := row + i
+		style := centerStyle
+		if r == nameRow {
+			style = nameStyle
+		}
+
+		cells := make([]interface{}, toNum-fromNum+1)
+		for c := range cells {
+			cells[c] = excelize.Cell{StyleID: style}
+		}
+		cells[0] = excelize.Cell{StyleID: style, Value: line}
+
+		from := fmt.Sprintf("%s%d", fromCol, r)
+		if err := sw.SetRow(from, cells); err != nil {
+			return 0, err
+		}
+		if toNum > fromNum {
+			if err := sw.MergeCell(from, fmt.Sprintf("%s%d", toCol, r)); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return row + len(lines) - 1, nil
+}
@@ -0,0 +1,182 @@
+package exchangeRate
+
+import (
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/model"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Konversi nilai transaksi ke IDR dilakukan di SQL: setiap baris di-join ke kurs (tabel
+// exchange_rate, alias er) yang berlaku pada tanggal dokumennya. Bila beberapa kurs berlaku,
+// dipakai yang valid_from-nya paling akhir. Baris IDR memakai kurs 1; baris tanpa kurs
+// bernilai NULL dan ditandai rate_missing.
+
+// Join adalah LEFT JOIN kurs yang berlaku untuk mata uang currCol pada tanggal dateCol.
+func Join(currCol, dateCol string) string {
+	return fmt.Sprintf(`LEFT JOIN %[1]s er ON er.id = (
+		SELECT r.id FROM %[1]s r
+		WHERE r.curr_code = %[2]s AND r.valid_from <= %[3]s AND (r.valid_to IS NULL OR r.valid_to >= %[3]s)
+		ORDER BY r.valid_from DESC, r.id DESC LIMIT 1)`, model.ExchangeRate{}.TableName(), currCol, dateCol)
+}
+
+// RateExpr adalah kurs IDR baris (NULL bila tidak ada); butuh Join.
+func RateExpr(currCol string) string {
+	return fmt.Sprintf("(CASE WHEN %s = '%s' THEN 1 ELSE er.rate END)", currCol, model.BaseCurrency)
+}
+
+// Select adalah kolom idr_rate, net_amount_idr dan rate_missing untuk baris laporan; butuh Join.
+func Select(currCol, amountCol string) string {
+	rate := RateExpr(currCol)
+	return fmt.Sprintf("%[1]s AS idr_rate, %[2]s * %[1]s AS net_amount_idr, (%[1]s IS NULL) AS rate_missing", rate, amountCol)
+}
+
+// TotalsSelect adalah kolom model.CurrencyTotal untuk GROUP BY currCol; butuh Join.
+func TotalsSelect(currCol, amountCol string) string {
+	rate := RateExpr(currCol)
+	return fmt.Sprintf(`COALESCE(%[1]s, '') AS curr_code, COUNT(*) AS line_count, COALESCE(SUM(%[2]s), 0) AS amount,
+		COALESCE(SUM(%[2]s * %[3]s), 0) AS amount_idr, SUM(%[3]s IS NULL) AS missing_rate_count`, currCol, amountCol, rate)
+}
+
+// SumIdr menjumlahkan total per mata uang menjadi satu total IDR.
+func SumIdr(totals []model.CurrencyTotal) model.IdrTotal {
+	var sum model.IdrTotal
+	for _, t := range totals {
+		sum.Amount = sum.Amount.Add(t.AmountIdr)
+		sum.MissingRateCount += t.MissingRateCount
+	}
+	sum.Complete = sum.MissingRateCount == 0
+	return sum
+}
+
+// ExportTotals adalah baris total export laporan: satu baris per mata uang (nilai asli dan
+// nilainya dalam IDR) lalu total IDR. amountKey / idrKey adalah Column.Key kolom nilai dan
+// kolom nilai IDR.
+func ExportTotals(totals []model.CurrencyTotal, amountKey, idrKey string) []reportExport.TotalRow {
+	rows := make([]reportExport.TotalRow, 0, len(totals)+1)
+	for _, t := range totals {
+		label := "TOTAL " + t.CurrCode
+		if t.MissingRateCount > 0 {
+			label += fmt.Sprintf(" (%d baris tanpa kurs)", t.MissingRateCount)
+		}
+		rows = append(rows, reportExport.TotalRow{Label: label, Values: map[string]any{
+			amountKey: reportExport.Decimal(t.Amount),
+			idrKey:    reportExport.Decimal(t.AmountIdr),
+		}})
+	}
+
+	sum := SumIdr(totals)
+	label := "TOTAL DALAM IDR"
+	if !sum.Complete {
+		label += fmt.Sprintf(" (tidak termasuk %d baris tanpa kurs)", sum.MissingRateCount)
+	}
+	return append(rows, reportExport.TotalRow{Label: label, Values: map[string]any{
+		idrKey: reportExport.Decimal(sum.Amount),
+	}})
+}
+
+// CSVRow adalah satu baris CSV kurs beserta nomor barisnya di file (header = baris 1).
+type CSVRow struct {
+	Line    int
+	Request model.ExchangeRateRequest
+}
+
+// csvColumns adalah kolom CSV import; curr_code, rate dan valid_from wajib.
+var csvColumns = []string{"curr_code", "rate", "valid_from", "valid_to", "source", "notes"}
+
+// ParseCSV membaca CSV kurs dengan baris header (urutan kolom bebas, lihat csvColumns).
+// Pemisah ',' atau ';' dideteksi dari header. Kurs memakai titik desimal; koma diterima
+// sebagai desimal bila tidak ada titik (mis. 16250,5). Baris yang tidak terbaca dikembalikan
+// sebagai error per baris; validasi isi (tanggal, kurs > 0) dilakukan service.
+func ParseCSV(r io.Reader) ([]CSVRow, []model.ExchangeRateImportError, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	text := strings.TrimPrefix(string(data), "\ufeff") // BOM dari Excel
+
+	firstLine, _, _ := strings.Cut(text, "\n")
+	cr := csv.NewReader(strings.NewReader(text))
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, errors.New("csv is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	index := map[string]int{}
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range csvColumns[:3] {
+		if _, ok := index[required]; !ok {
+			return nil, nil, fmt.Errorf("csv header must contain %s (got %s)", strings.Join(csvColumns[:3], ", "), strings.Join(header, ", "))
+		}
+	}
+
+	var (
+		rows    []CSVRow
+		rowErrs []model.ExchangeRateImportError
+	)
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			rowErrs = append(rowErrs, model.ExchangeRateImportError{Line: parseErr.Line, Error: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		rate, err := parseRate(field("rate"))
+		if err != nil {
+			rowErrs = append(rowErrs, model.ExchangeRateImportError{Line: line, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, CSVRow{Line: line, Request: model.ExchangeRateRequest{
+			CurrCode:  field("curr_code"),
+			Rate:      rate,
+			ValidFrom: field("valid_from"),
+			ValidTo:   field("valid_to"),
+			Source:    field("source"),
+			Notes:     field("notes"),
+		}})
+	}
+	return rows, rowErrs, nil
+}
+
+func parseRate(s string) (decimal.Decimal, error) {
+	if !strings.Contains(s, ".") {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	rate, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid rate %q", s)
+	}
+	return rate, nil
+}
@@ -0,0 +1,69 @@
+package exchangeRate
+
+import (
+	"Bea-Cukai/model"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// CSV dari Excel (BOM, pemisah ';', koma desimal) terbaca; baris dengan kurs tidak valid
+// dikembalikan sebagai error dengan nomor barisnya.
+func TestParseCSV_SemicolonWithBOM(t *testing.T) {
+	input := "\ufeffvalid_from;curr_code;rate;source\n" +
+		"2026-10-14;usd;16250,5;NDPBM\n" +
+		"\n" +
+		"2026-10-14;EUR;abc;NDPBM\n" +
+		"2026-10-14;JPY;108.25;\n"
+
+	rows, rowErrs, err := ParseCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("rows = %d; want 2", len(rows))
+	}
+	if rows[0].Line != 2 || rows[0].Request.CurrCode != "usd" || !rows[0].Request.Rate.Equal(decimal.RequireFromString("16250.5")) {
+		t.Errorf("row 0 = %+v", rows[0])
+	}
+	if rows[1].Line != 5 || rows[1].Request.Source != "" {
+		t.Errorf("row 1 = %+v", rows[1])
+	}
+	if len(rowErrs) != 1 || rowErrs[0].Line != 4 {
+		t.Errorf("row errors = %+v; want one error on line 4", rowErrs)
+	}
+}
+
+func TestParseCSV_MissingRequiredColumn(t *testing.T) {
+	if _, _, err := ParseCSV(strings.NewReader("curr_code,rate\nUSD,16000\n")); err == nil {
+		t.Fatal("expected error for missing valid_from column")
+	}
+}
+
+// Total IDR hanya menjumlahkan baris yang punya kurs dan labelnya menyebut baris tanpa kurs.
+func TestExportTotals_FlagsMissingRates(t *testing.T) {
+	totals := []model.CurrencyTotal{
+		{CurrCode: "IDR", LineCount: 2, Amount: decimal.NewFromInt(1000), AmountIdr: decimal.NewFromInt(1000)},
+		{CurrCode: "USD", LineCount: 3, Amount: decimal.NewFromInt(30), AmountIdr: decimal.NewFromInt(320000), MissingRateCount: 1},
+	}
+
+	sum := SumIdr(totals)
+	if !sum.Amount.Equal(decimal.NewFromInt(321000)) || sum.MissingRateCount != 1 || sum.Complete {
+		t.Errorf("SumIdr = %+v", sum)
+	}
+
+	rows := ExportTotals(totals, "net_amount", "net_amount_idr")
+	if len(rows) != 3 {
+		t.Fatalf("rows = %d; want 3", len(rows))
+	}
+	if rows[1].Label != "TOTAL USD (1 baris tanpa kurs)" {
+		t.Errorf("USD label = %q", rows[1].Label)
+	}
+	if rows[2].Label != "TOTAL DALAM IDR (tidak termasuk 1 baris tanpa kurs)" {
+		t.Errorf("IDR label = %q", rows[2].Label)
+	}
+	if _, ok := rows[2].Values["net_amount"]; ok {
+		t.Error("IDR total row must not carry an original-currency amount")
+	}
+}
@@ -0,0 +1,66 @@
+package pabeanType
+
+import (
+	"Bea-Cukai/model"
+	"fmt"
+	"strings"
+)
+
+// jenis_pabean di tr_pemasukan_barang / tr_pengeluaran_barang adalah teks bebas ("BC 2.3",
+// "BC2.3", "2.3", atau kode ms_pabean "23"). Baris dicocokkan ke master ms_pabean (alias mp)
+// lewat pabean_code atau pabean_name yang sudah dinormalisasi (lihat Normalize).
+
+// Normalize membuang awalan BC, spasi dan titik: "BC 2.6.1" dan "2.6.1" menjadi "261".
+func Normalize(jenisPabean string) string {
+	s := strings.ToUpper(jenisPabean)
+	for _, old := range []string{"BC", " ", "."} {
+		s = strings.ReplaceAll(s, old, "")
+	}
+	return s
+}
+
+// NormalizedSQL adalah Normalize dalam SQL untuk kolom col.
+func NormalizedSQL(col string) string {
+	return fmt.Sprintf("REPLACE(REPLACE(REPLACE(UPPER(%s), 'BC', ''), ' ', ''), '.', '')", col)
+}
+
+// Join adalah LEFT JOIN master ms_pabean (alias mp) untuk jenis_pabean pada kolom jenisCol.
+// Master di-resolve sekali per query menjadi tabel turunan dengan satu baris per kunci
+// ternormalisasi (pabean_code dan pabean_name); bila beberapa master punya kunci yang sama,
+// id terkecil yang dipakai. Setiap baris laporan cukup di-join ke mp.pabean_key.
+func Join(jenisCol string) string {
+	return fmt.Sprintf(`LEFT JOIN (
+		SELECT k.pabean_key, m.* FROM (
+			SELECT pabean_key, MIN(id) AS id FROM (
+				SELECT id, %[2]s AS pabean_key FROM %[1]s
+				UNION ALL
+				SELECT id, %[3]s AS pabean_key FROM %[1]s
+			) pk GROUP BY pabean_key
+		) k INNER JOIN %[1]s m ON m.id = k.id
+	) mp ON mp.pabean_key = %[4]s`, model.MsPabean{}.TableName(), NormalizedSQL("pabean_code"), NormalizedSQL("pabean_name"), NormalizedSQL(jenisCol))
+}
+
+// Directions adalah nilai ms_pabean.direction yang berlaku untuk dokumen arah direction
+// (model.PabeanDirectionIn / Out): arah itu sendiri dan BOTH. Dipakai bersama Join, mis.
+// "mp.direction IN ?".
+func Directions(direction string) []string {
+	return []string{direction, model.PabeanDirectionBoth}
+}
+
+// StatusExpr adalah hasil validasi jenis dokumen terhadap master untuk laporan arah direction
+// (model.PabeanDirectionIn / Out); butuh Join. Master tanpa arah tidak dianggap salah arah, dan
+// direction kosong atau BOTH hanya memeriksa apakah jenis dokumen dikenal.
+func StatusExpr(direction string) string {
+	if direction == "" || direction == model.PabeanDirectionBoth {
+		return fmt.Sprintf(`(CASE WHEN mp.id IS NULL THEN '%s' ELSE '%s' END)`, model.PabeanStatusUnknown, model.PabeanStatusOk)
+	}
+	return fmt.Sprintf(`(CASE WHEN mp.id IS NULL THEN '%s'
+		WHEN COALESCE(mp.direction, '') NOT IN ('', '%s', '%s') THEN '%s'
+		ELSE '%s' END)`,
+		model.PabeanStatusUnknown, direction, model.PabeanDirectionBoth, model.PabeanStatusWrongDirection, model.PabeanStatusOk)
+}
+
+// Select adalah kolom pabean_status dan duty_suspended untuk baris laporan; butuh Join.
+func Select(direction string) string {
+	return StatusExpr(direction) + " AS pabean_status, COALESCE(mp.duty_suspended, 0) AS duty_suspended"
+}
@@ -0,0 +1,72 @@
+package pabeanType
+
+import (
+	"Bea-Cukai/model"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"BC 2.6.1": "261",
+		"BC2.6.1":  "261",
+		"bc 2.3":   "23",
+		"2.7":      "27",
+		"262":      "262",
+	}
+	for in, want := range cases {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
+
+func TestStatusExpr(t *testing.T) {
+	wrongDirection := "'" + model.PabeanStatusWrongDirection + "'"
+	unknown := "WHEN mp.id IS NULL THEN '" + model.PabeanStatusUnknown + "'"
+
+	tests := []struct {
+		name      string
+		direction string
+		contains  []string
+		excludes  []string
+	}{
+		{"arah kosong hanya cek master", "", []string{unknown}, []string{wrongDirection, "mp.direction"}},
+		{"BOTH hanya cek master", model.PabeanDirectionBoth, []string{unknown}, []string{wrongDirection, "mp.direction"}},
+		{"pemasukan menerima IN, BOTH dan master tanpa arah", model.PabeanDirectionIn,
+			[]string{unknown, "NOT IN ('', 'IN', 'BOTH') THEN " + wrongDirection}, nil},
+		{"pengeluaran menerima OUT, BOTH dan master tanpa arah", model.PabeanDirectionOut,
+			[]string{unknown, "NOT IN ('', 'OUT', 'BOTH') THEN " + wrongDirection}, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expr := StatusExpr(tc.direction)
+			for _, want := range tc.contains {
+				if !strings.Contains(expr, want) {
+					t.Errorf("StatusExpr(%q) = %s; want it to contain %s", tc.direction, expr, want)
+				}
+			}
+			for _, unwanted := range tc.excludes {
+				if strings.Contains(expr, unwanted) {
+					t.Errorf("StatusExpr(%q) = %s; must not contain %s", tc.direction, expr, unwanted)
+				}
+			}
+		})
+	}
+}
+
+// Join memakai tabel turunan yang di-join lewat kunci ternormalisasi, bukan subquery per baris.
+func TestJoin_DerivedTableKeyedOnNormalizedCode(t *testing.T) {
+	join := Join("p.jenis_pabean")
+	if !strings.HasSuffix(join, "mp ON mp.pabean_key = "+NormalizedSQL("p.jenis_pabean")) {
+		t.Errorf("join tidak memakai kunci ternormalisasi: %s", join)
+	}
+	for _, key := range []string{NormalizedSQL("pabean_code"), NormalizedSQL("pabean_name")} {
+		if !strings.Contains(join, key+" AS pabean_key") {
+			t.Errorf("join tidak membuat kunci %s", key)
+		}
+	}
+	if strings.Contains(join, "LIMIT 1") || strings.Count(join, "p.jenis_pabean") != 1 {
+		t.Errorf("join masih berkorelasi dengan baris laporan: %s", join)
+	}
+}
@@ -0,0 +1,69 @@
+package queryGuard
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Membatalkan context hanya memutus koneksi di sisi Go; MySQL tetap menjalankan query berat
+// (CTE laporan) sampai selesai. queryGuard menjalankan query di koneksi yang dipin dan, bila
+// context berakhir lebih dulu (client menutup browser / timeout laporan), menghentikannya di
+// server dengan KILL QUERY <connection_id> dari koneksi lain.
+
+// Scan menjalankan raw query dan memindai hasilnya ke dest, seperti
+// db.WithContext(ctx).Raw(query, args...).Scan(dest).Error.
+// Error karena context berakhir dibungkus dengan ctx.Err() (cek errors.Is(err, context.DeadlineExceeded)).
+func Scan(ctx context.Context, db *gorm.DB, dest interface{}, query string, args ...interface{}) error {
+	return Run(ctx, db, func(tx *gorm.DB) error {
+		return tx.Raw(query, args...).Scan(dest).Error
+	})
+}
+
+// Run menjalankan fn dengan tx yang terikat ke satu koneksi; semua query di fn dihentikan
+// bersama bila ctx berakhir. Context tanpa Done (mis. context.Background) langsung memakai db.
+func Run(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if ctx.Done() == nil {
+		return fn(db.WithContext(ctx))
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Koneksi dipin tanpa cancel agar driver tidak menutupnya saat ctx berakhir;
+	// query dihentikan lewat KILL QUERY sehingga koneksi tetap bisa dipakai ulang.
+	return db.WithContext(context.WithoutCancel(ctx)).Connection(func(tx *gorm.DB) error {
+		var connId int64
+		if err := tx.Raw("SELECT CONNECTION_ID()").Scan(&connId).Error; err != nil {
+			return err
+		}
+
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-ctx.Done():
+				if err := db.Exec("KILL QUERY ?", connId).Error; err != nil {
+					log.Printf("queryGuard: fail to kill query on connection %d: %v", connId, err)
+				}
+			case <-done:
+			}
+		}()
+
+		err := fn(tx)
+		// Tunggu goroutine selesai sebelum koneksi kembali ke pool agar KILL tidak mengenai
+		// query berikutnya di koneksi yang sama.
+		close(done)
+		wg.Wait()
+
+		if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+			return fmt.Errorf("%w: %v", ctxErr, err)
+		}
+		return err
+	})
+}
@@ -0,0 +1,79 @@
+package queryGuard
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open mock: %v", err)
+	}
+	return gormDB, mock
+}
+
+func TestScan_KillsQueryOnTimeout(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectQuery("SELECT CONNECTION_ID()").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	// sqlmock tidak bisa dihentikan KILL; simulasikan error MySQL saat query di-kill
+	mock.ExpectQuery("WITH heavy AS").
+		WillDelayFor(200 * time.Millisecond).
+		WillReturnError(errors.New("Error 1317 (70100): Query execution was interrupted"))
+	mock.ExpectExec("KILL QUERY").WithArgs(42).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var n int
+	err := Scan(ctx, db, &n, "WITH heavy AS (SELECT 1) SELECT * FROM heavy")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v; want context.DeadlineExceeded", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestScan_NoKillWhenFinished(t *testing.T) {
+	db, mock := newMockDB(t)
+
+	mock.ExpectQuery("SELECT CONNECTION_ID()").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectQuery("SELECT n FROM t").
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(3))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var n int
+	if err := Scan(ctx, db, &n, "SELECT n FROM t"); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("n = %d; want 3", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package reportCache
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fileEntryExt    = ".json"
+	fileVersionName = "data_version"
+)
+
+// FileStore menyimpan setiap entri sebagai file di direktori lokal sehingga cache
+// (dan data version) bertahan setelah restart. Eviction berdasarkan waktu simpan (mtime), terlama lebih dulu.
+type FileStore struct {
+	mu         sync.Mutex
+	dir        string
+	maxEntries int
+	maxBytes   int64
+	evictions  int64
+}
+
+// NewFileStore membuat direktori bila belum ada; batas 0 = tanpa batas.
+func NewFileStore(dir string, maxEntries int, maxBytes int64) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir, maxEntries: maxEntries, maxBytes: maxBytes}, nil
+}
+
+func (s *FileStore) path(key string) string {
+	// key dibangun oleh Cache.Key (report type + hex) sehingga aman sebagai nama file
+	return filepath.Join(s.dir, key+fileEntryExt)
+}
+
+func (s *FileStore) Get(key string) ([]byte, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.path(key)
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	raw, err := os.ReadFile(p)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	return raw, info.ModTime(), true
+}
+
+func (s *FileStore) Set(key string, value []byte) error {
+	if s.maxBytes > 0 && int64(len(value)) > s.maxBytes {
+		return ErrEntryTooLarge
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// tulis ke file sementara lalu rename agar pembaca tidak melihat file setengah jadi
+	p := s.path(key)
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, value, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return s.evict()
+}
+
+type fileEntry struct {
+	path     string
+	size     int64
+	storedAt time.Time
+}
+
+func (s *FileStore) entries() ([]fileEntry, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []fileEntry
+	for _, de := range dirEntries {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), fileEntryExt) {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, fileEntry{filepath.Join(s.dir, de.Name()), info.Size(), info.ModTime()})
+	}
+	return out, nil
+}
+
+// evict menghapus entri tertua sampai di bawah batas.
+func (s *FileStore) evict() error {
+	files, err := s.entries()
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].storedAt.Before(files[j].storedAt) })
+
+	for len(files) > 0 &&
+		((s.maxEntries > 0 && len(files) > s.maxEntries) || (s.maxBytes > 0 && total > s.maxBytes)) {
+		if err := os.Remove(files[0].path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= files[0].size
+		files = files[1:]
+		s.evictions++
+	}
+	return nil
+}
+
+func (s *FileStore) Purge() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := s.entries()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) Stats() StoreStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := StoreStats{Kind: "file", MaxEntries: s.maxEntries, MaxBytes: s.maxBytes, Evictions: s.evictions}
+	files, _ := s.entries()
+	stats.Entries = len(files)
+	for _, f := range files {
+		stats.Bytes += f.size
+	}
+	return stats
+}
+
+func (s *FileStore) LoadVersion() int64 {
+	raw, err := os.ReadFile(filepath.Join(s.dir, fileVersionName))
+	if err != nil {
+		return 0
+	}
+	v, _ := strconv.ParseInt(strings.TrimSpace(string(raw)), 10, 64)
+	return v
+}
+
+func (s *FileStore) SaveVersion(v int64) error {
+	return os.WriteFile(filepath.Join(s.dir, fileVersionName), []byte(strconv.FormatInt(v, 10)), 0o644)
+}
@@ -0,0 +1,126 @@
+package reportCache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrEntryTooLarge dikembalikan bila satu entri melebihi batas ukuran store; hasil tetap dikembalikan ke pemanggil tanpa disimpan.
+var ErrEntryTooLarge = errors.New("report cache: entry exceeds max bytes")
+
+type memoryEntry struct {
+	key      string
+	value    []byte
+	storedAt time.Time
+}
+
+// MemoryStore adalah store LRU in-memory dengan batas jumlah entri dan total byte.
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	evictions  int64
+	version    int64
+	order      *list.List // depan = paling baru dipakai
+	items      map[string]*list.Element
+}
+
+// NewMemoryStore membuat MemoryStore; batas 0 = tanpa batas.
+func NewMemoryStore(maxEntries int, maxBytes int64) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		items:      map[string]*list.Element{},
+	}
+}
+
+func (s *MemoryStore) Get(key string) ([]byte, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	s.order.MoveToFront(el)
+	entry := el.Value.(*memoryEntry)
+	return entry.value, entry.storedAt, true
+}
+
+func (s *MemoryStore) Set(key string, value []byte) error {
+	if s.maxBytes > 0 && int64(len(value)) > s.maxBytes {
+		return ErrEntryTooLarge
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		s.removeElement(el)
+	}
+	s.items[key] = s.order.PushFront(&memoryEntry{key: key, value: value, storedAt: time.Now()})
+	s.bytes += int64(len(value))
+
+	for s.overLimit() {
+		oldest := s.order.Back()
+		if oldest == nil {
+			break
+		}
+		s.removeElement(oldest)
+		s.evictions++
+	}
+	return nil
+}
+
+func (s *MemoryStore) overLimit() bool {
+	return (s.maxEntries > 0 && s.order.Len() > s.maxEntries) ||
+		(s.maxBytes > 0 && s.bytes > s.maxBytes)
+}
+
+func (s *MemoryStore) removeElement(el *list.Element) {
+	entry := s.order.Remove(el).(*memoryEntry)
+	delete(s.items, entry.key)
+	s.bytes -= int64(len(entry.value))
+}
+
+func (s *MemoryStore) Purge() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.order.Init()
+	s.items = map[string]*list.Element{}
+	s.bytes = 0
+	return nil
+}
+
+func (s *MemoryStore) Stats() StoreStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return StoreStats{
+		Kind:       "memory",
+		Entries:    s.order.Len(),
+		Bytes:      s.bytes,
+		MaxEntries: s.maxEntries,
+		MaxBytes:   s.maxBytes,
+		Evictions:  s.evictions,
+	}
+}
+
+// Version in-memory tidak bertahan setelah restart; tidak masalah karena entri juga ikut hilang.
+func (s *MemoryStore) LoadVersion() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+func (s *MemoryStore) SaveVersion(v int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version = v
+	return nil
+}
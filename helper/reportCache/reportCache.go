@@ -0,0 +1,271 @@
+package reportCache
+
+import (
+	"Bea-Cukai/helper"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache menyimpan hasil lengkap (tanpa pagination) laporan LPJ yang berat.
+//
+// Key = report type + data version + filter yang dinormalisasi. Data version dinaikkan
+// setiap sync berhasil sehingga entri lama otomatis tidak terpakai lagi (dan tergusur oleh batas ukuran).
+type Cache struct {
+	store   Store
+	ttl     time.Duration
+	version atomic.Int64
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	sets   atomic.Int64
+	errors atomic.Int64
+
+	// loading mencegah beberapa request dengan key sama menjalankan query yang sama bersamaan
+	mu      sync.Mutex
+	loading map[string]*sync.WaitGroup
+}
+
+// Store adalah tempat penyimpanan entri cache (memory atau file lokal).
+type Store interface {
+	Get(key string) ([]byte, time.Time, bool)
+	Set(key string, value []byte) error
+	Purge() error
+	Stats() StoreStats
+	// LoadVersion / SaveVersion menyimpan data version agar bertahan setelah restart (file store).
+	LoadVersion() int64
+	SaveVersion(v int64) error
+}
+
+type StoreStats struct {
+	Kind       string `json:"kind"`
+	Entries    int    `json:"entries"`
+	Bytes      int64  `json:"bytes"`
+	MaxEntries int    `json:"max_entries"`
+	MaxBytes   int64  `json:"max_bytes"`
+	Evictions  int64  `json:"evictions"`
+}
+
+type Stats struct {
+	Enabled     bool       `json:"enabled"`
+	DataVersion int64      `json:"data_version"`
+	TTLSeconds  int64      `json:"ttl_seconds"`
+	Hits        int64      `json:"hits"`
+	Misses      int64      `json:"misses"`
+	Sets        int64      `json:"sets"`
+	Errors      int64      `json:"errors"`
+	HitRatio    float64    `json:"hit_ratio"`
+	Store       StoreStats `json:"store"`
+}
+
+// New membuat cache di atas store; ttl 0 = tanpa kedaluwarsa (hanya invalidasi via version).
+func New(store Store, ttl time.Duration) *Cache {
+	c := &Cache{store: store, ttl: ttl, loading: map[string]*sync.WaitGroup{}}
+	c.version.Store(store.LoadVersion())
+	return c
+}
+
+// NewFromEnv membuat cache dari environment:
+//
+//	REPORT_CACHE_STORE        memory (default) | file | off
+//	REPORT_CACHE_DIR          direktori file store (default tmp/report-cache)
+//	REPORT_CACHE_MAX_ENTRIES  default 200
+//	REPORT_CACHE_MAX_MB       default 256
+//	REPORT_CACHE_TTL_MINUTES  default 0 (tanpa TTL)
+//
+// Mengembalikan nil bila cache dimatikan; semua method aman dipanggil pada nil.
+func NewFromEnv() *Cache {
+	kind := helper.GetEnv("REPORT_CACHE_STORE")
+	maxEntries := envInt("REPORT_CACHE_MAX_ENTRIES", 200)
+	maxBytes := int64(envInt("REPORT_CACHE_MAX_MB", 256)) << 20
+	ttl := time.Duration(envInt("REPORT_CACHE_TTL_MINUTES", 0)) * time.Minute
+
+	switch kind {
+	case "off":
+		return nil
+	case "file":
+		dir := helper.GetEnv("REPORT_CACHE_DIR")
+		if dir == "" {
+			dir = "tmp/report-cache"
+		}
+		store, err := NewFileStore(dir, maxEntries, maxBytes)
+		if err != nil {
+			// fallback ke memory agar aplikasi tetap jalan
+			return New(NewMemoryStore(maxEntries, maxBytes), ttl)
+		}
+		return New(store, ttl)
+	default:
+		return New(NewMemoryStore(maxEntries, maxBytes), ttl)
+	}
+}
+
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(helper.GetEnv(key)); err == nil && v >= 0 {
+		return v
+	}
+	return def
+}
+
+// Key membangun key dari report type, data version dan filter (sudah dinormalisasi oleh pemanggil:
+// tanpa page/limit, tanggal dalam format tetap).
+func (c *Cache) Key(reportType string, normalizedFilter any) string {
+	raw, _ := json.Marshal(normalizedFilter)
+	sum := sha256.Sum256(append([]byte(reportType+"|v"+strconv.FormatInt(c.Version(), 10)+"|"), raw...))
+	return reportType + "-" + hex.EncodeToString(sum[:16])
+}
+
+// Version mengembalikan data version saat ini.
+func (c *Cache) Version() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.version.Load()
+}
+
+// BumpVersion dipanggil setelah sync berhasil; entri versi lama tidak akan pernah terbaca lagi.
+func (c *Cache) BumpVersion() int64 {
+	if c == nil {
+		return 0
+	}
+	v := c.version.Add(1)
+	if err := c.store.SaveVersion(v); err != nil {
+		c.errors.Add(1)
+	}
+	return v
+}
+
+// Purge menghapus seluruh entri (data version tidak berubah).
+func (c *Cache) Purge() error {
+	if c == nil {
+		return nil
+	}
+	return c.store.Purge()
+}
+
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{Enabled: false}
+	}
+	hits, misses := c.hits.Load(), c.misses.Load()
+	ratio := 0.0
+	if hits+misses > 0 {
+		ratio = float64(hits) / float64(hits+misses)
+	}
+	return Stats{
+		Enabled:     true,
+		DataVersion: c.Version(),
+		TTLSeconds:  int64(c.ttl / time.Second),
+		Hits:        hits,
+		Misses:      misses,
+		Sets:        c.sets.Load(),
+		Errors:      c.errors.Load(),
+		HitRatio:    ratio,
+		Store:       c.store.Stats(),
+	}
+}
+
+func (c *Cache) get(key string, dest any) bool {
+	raw, storedAt, ok := c.store.Get(key)
+	if !ok || (c.ttl > 0 && time.Since(storedAt) > c.ttl) {
+		return false
+	}
+	if err := json.Unmarshal(raw, dest); err != nil {
+		c.errors.Add(1)
+		return false
+	}
+	return true
+}
+
+// GetOrLoad mengembalikan hasil dari cache atau menjalankan load lalu menyimpannya.
+// hit = true bila data berasal dari cache. Cache nil → selalu load.
+func GetOrLoad[T any](c *Cache, key string, load func() (T, error)) (T, bool, error) {
+	var result T
+	if c == nil {
+		result, err := load()
+		return result, false, err
+	}
+
+	for {
+		if c.get(key, &result) {
+			c.hits.Add(1)
+			return result, true, nil
+		}
+
+		c.mu.Lock()
+		wg, busy := c.loading[key]
+		if !busy {
+			wg = &sync.WaitGroup{}
+			wg.Add(1)
+			c.loading[key] = wg
+			c.mu.Unlock()
+			break
+		}
+		c.mu.Unlock()
+
+		// request lain sedang memuat key yang sama → tunggu lalu coba baca lagi
+		wg.Wait()
+		if c.get(key, &result) {
+			c.hits.Add(1)
+			return result, true, nil
+		}
+		// loader lain gagal / terlalu besar untuk disimpan → muat sendiri
+		c.mu.Lock()
+		if _, still := c.loading[key]; !still {
+			wg = &sync.WaitGroup{}
+			wg.Add(1)
+			c.loading[key] = wg
+			c.mu.Unlock()
+			break
+		}
+		c.mu.Unlock()
+	}
+
+	defer func() {
+		c.mu.Lock()
+		wg := c.loading[key]
+		delete(c.loading, key)
+		c.mu.Unlock()
+		wg.Done()
+	}()
+
+	c.misses.Add(1)
+	result, err := load()
+	if err != nil {
+		return result, false, err
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		c.errors.Add(1)
+		return result, false, nil
+	}
+	if err := c.store.Set(key, raw); err != nil {
+		c.errors.Add(1)
+		return result, false, nil
+	}
+	c.sets.Add(1)
+	return result, false, nil
+}
+
+// Page memotong satu halaman dari hasil lengkap; limit <= 0 = seluruh data.
+func Page[T any](rows []T, page, limit int) []T {
+	if limit <= 0 {
+		return rows
+	}
+	if page < 1 {
+		page = 1
+	}
+	start := (page - 1) * limit
+	if start >= len(rows) {
+		return []T{}
+	}
+	end := start + limit
+	if end > len(rows) {
+		end = len(rows)
+	}
+	return rows[start:end]
+}
@@ -0,0 +1,89 @@
+package reportCache
+
+import (
+	"errors"
+	"testing"
+)
+
+type testFilter struct {
+	ItemCode string
+	Page     int
+	Limit    int
+}
+
+func TestGetOrLoad_HitAfterMissAndVersionBump(t *testing.T) {
+	c := New(NewMemoryStore(10, 0), 0)
+	calls := 0
+	load := func() ([]int, error) {
+		calls++
+		return []int{1, 2, 3}, nil
+	}
+
+	key := c.Key("raw_material", testFilter{ItemCode: "A"})
+	if _, hit, _ := GetOrLoad(c, key, load); hit {
+		t.Fatal("first call should be a miss")
+	}
+	rows, hit, err := GetOrLoad(c, key, load)
+	if err != nil || !hit || len(rows) != 3 || calls != 1 {
+		t.Fatalf("expected cache hit without reload, got hit=%v calls=%d err=%v", hit, calls, err)
+	}
+
+	// setelah sync, key yang sama tidak boleh membaca entri lama
+	c.BumpVersion()
+	newKey := c.Key("raw_material", testFilter{ItemCode: "A"})
+	if newKey == key {
+		t.Fatal("key must change after version bump")
+	}
+	if _, hit, _ := GetOrLoad(c, newKey, load); hit || calls != 2 {
+		t.Fatalf("expected reload after version bump, hit=%v calls=%d", hit, calls)
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.DataVersion != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestGetOrLoad_ErrorNotCached(t *testing.T) {
+	c := New(NewMemoryStore(10, 0), 0)
+	if _, _, err := GetOrLoad(c, "k", func() ([]int, error) { return nil, errors.New("db down") }); err == nil {
+		t.Fatal("expected error")
+	}
+	if c.Stats().Store.Entries != 0 {
+		t.Fatal("failed load must not be stored")
+	}
+}
+
+func TestMemoryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	s := NewMemoryStore(2, 0)
+	_ = s.Set("a", []byte("1"))
+	_ = s.Set("b", []byte("2"))
+	s.Get("a") // a jadi paling baru dipakai
+	_ = s.Set("c", []byte("3"))
+
+	if _, _, ok := s.Get("b"); ok {
+		t.Fatal("b should be evicted")
+	}
+	if _, _, ok := s.Get("a"); !ok {
+		t.Fatal("a should still be cached")
+	}
+	if err := NewMemoryStore(0, 2).Set("big", []byte("123")); !errors.Is(err, ErrEntryTooLarge) {
+		t.Fatalf("expected ErrEntryTooLarge, got %v", err)
+	}
+}
+
+func TestPage(t *testing.T) {
+	rows := []int{1, 2, 3, 4, 5}
+	if got := Page(rows, 2, 2); len(got) != 2 || got[0] != 3 {
+		t.Fatalf("page 2: %v", got)
+	}
+	if got := Page(rows, 3, 2); len(got) != 1 || got[0] != 5 {
+		t.Fatalf("page 3: %v", got)
+	}
+	if got := Page(rows, 9, 2); len(got) != 0 {
+		t.Fatalf("page out of range: %v", got)
+	}
+	if got := Page(rows, 1, 0); len(got) != 5 {
+		t.Fatalf("limit 0: %v", got)
+	}
+}
@@ -0,0 +1,217 @@
+package reportCompare
+
+import (
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/helper/reportQuery"
+	"Bea-Cukai/model"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Item adalah nilai satu item laporan mutasi dalam satu periode, masukan untuk Compare.
+type Item struct {
+	ItemCode     string
+	ItemName     string
+	UnitCode     string
+	ItemTypeCode string
+	ItemGroup    string
+	Masuk        decimal.Decimal
+	Keluar       decimal.Decimal
+	Akhir        decimal.Decimal
+}
+
+// DefaultSort mengurutkan perubahan saldo akhir terbesar (absolut) lebih dulu.
+const DefaultSort = "-abs_delta_akhir"
+
+var metrics = []string{"masuk", "keluar", "akhir"}
+
+// Columns adalah whitelist sort laporan perbandingan (dipakai dengan reportQuery.ParseSort):
+// item_code, item_name, <nilai>, previous_<nilai>, delta_<nilai>, abs_delta_<nilai> dan
+// delta_pct_<nilai> untuk nilai masuk, keluar dan akhir.
+var Columns = func() reportQuery.Columns {
+	cols := reportQuery.Columns{
+		"item_code": {},
+		"item_name": {},
+	}
+	for _, m := range metrics {
+		for _, prefix := range []string{"", "previous_", "delta_", "abs_delta_", "delta_pct_"} {
+			cols[prefix+m] = reportQuery.Column{Numeric: true}
+		}
+	}
+	return cols
+}()
+
+// Compare menggabungkan baris periode berjalan dan pembanding per item_code. Item yang hanya
+// ada di salah satu periode tetap muncul dengan nilai 0 di periode lainnya.
+func Compare(current, previous []Item) []model.ReportComparison {
+	index := map[string]int{}
+	rows := []model.ReportComparison{}
+	values := [][2]Item{}
+
+	add := func(it Item, slot int) {
+		i, ok := index[it.ItemCode]
+		if !ok {
+			i = len(rows)
+			index[it.ItemCode] = i
+			rows = append(rows, model.ReportComparison{
+				ItemCode:     it.ItemCode,
+				ItemName:     it.ItemName,
+				UnitCode:     it.UnitCode,
+				ItemTypeCode: it.ItemTypeCode,
+				ItemGroup:    it.ItemGroup,
+			})
+			values = append(values, [2]Item{})
+		}
+		v := &values[i][slot]
+		v.Masuk = v.Masuk.Add(it.Masuk)
+		v.Keluar = v.Keluar.Add(it.Keluar)
+		v.Akhir = v.Akhir.Add(it.Akhir)
+	}
+	for _, it := range current {
+		add(it, 0)
+	}
+	for _, it := range previous {
+		add(it, 1)
+	}
+
+	for i := range rows {
+		cur, prev := values[i][0], values[i][1]
+		rows[i].Masuk = newValue(cur.Masuk, prev.Masuk)
+		rows[i].Keluar = newValue(cur.Keluar, prev.Keluar)
+		rows[i].Akhir = newValue(cur.Akhir, prev.Akhir)
+	}
+	return rows
+}
+
+func newValue(current, previous decimal.Decimal) model.ComparisonValue {
+	v := model.ComparisonValue{
+		Current:  current,
+		Previous: previous,
+		Delta:    current.Sub(previous),
+	}
+	if !previous.IsZero() {
+		pct := v.Delta.Div(previous.Abs()).Mul(decimal.NewFromInt(100)).Round(2)
+		v.DeltaPct = &pct
+	}
+	return v
+}
+
+// Sort mengurutkan rows sesuai fields (hasil reportQuery.ParseSort dengan Columns);
+// tanpa fields memakai DefaultSort. item_code selalu menjadi urutan terakhir agar stabil.
+// delta_pct tanpa nilai (previous = 0) diletakkan di akhir.
+func Sort(rows []model.ReportComparison, fields []reportQuery.SortField) {
+	if len(fields) == 0 {
+		fields, _ = reportQuery.ParseSort(DefaultSort, Columns)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, f := range fields {
+			if metric, ok := strings.CutPrefix(f.Column, "delta_pct_"); ok {
+				pa, pb := metricValue(rows[i], metric).DeltaPct, metricValue(rows[j], metric).DeltaPct
+				if (pa == nil) != (pb == nil) {
+					return pb == nil
+				}
+			}
+			c := compareField(rows[i], rows[j], f.Column)
+			if c == 0 {
+				continue
+			}
+			if f.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return rows[i].ItemCode < rows[j].ItemCode
+	})
+}
+
+func compareField(a, b model.ReportComparison, column string) int {
+	switch column {
+	case "item_code":
+		return strings.Compare(a.ItemCode, b.ItemCode)
+	case "item_name":
+		return strings.Compare(a.ItemName, b.ItemName)
+	}
+
+	prefix, metric := "", column
+	for _, p := range []string{"previous_", "abs_delta_", "delta_pct_", "delta_"} {
+		if m, ok := strings.CutPrefix(column, p); ok {
+			prefix, metric = p, m
+			break
+		}
+	}
+	va, vb := metricValue(a, metric), metricValue(b, metric)
+
+	switch prefix {
+	case "previous_":
+		return va.Previous.Cmp(vb.Previous)
+	case "delta_":
+		return va.Delta.Cmp(vb.Delta)
+	case "abs_delta_":
+		return va.Delta.Abs().Cmp(vb.Delta.Abs())
+	case "delta_pct_":
+		if va.DeltaPct == nil || vb.DeltaPct == nil {
+			return 0
+		}
+		return va.DeltaPct.Cmp(*vb.DeltaPct)
+	}
+	return va.Current.Cmp(vb.Current)
+}
+
+func metricValue(r model.ReportComparison, metric string) model.ComparisonValue {
+	switch metric {
+	case "masuk":
+		return r.Masuk
+	case "keluar":
+		return r.Keluar
+	}
+	return r.Akhir
+}
+
+// Totals menjumlahkan nilai semua rows per nilai (untuk meta ringkasan).
+func Totals(rows []model.ReportComparison) map[string]model.ComparisonValue {
+	var cur, prev Item
+	for _, r := range rows {
+		cur.Masuk, prev.Masuk = cur.Masuk.Add(r.Masuk.Current), prev.Masuk.Add(r.Masuk.Previous)
+		cur.Keluar, prev.Keluar = cur.Keluar.Add(r.Keluar.Current), prev.Keluar.Add(r.Keluar.Previous)
+		cur.Akhir, prev.Akhir = cur.Akhir.Add(r.Akhir.Current), prev.Akhir.Add(r.Akhir.Previous)
+	}
+	return map[string]model.ComparisonValue{
+		"masuk":  newValue(cur.Masuk, prev.Masuk),
+		"keluar": newValue(cur.Keluar, prev.Keluar),
+		"akhir":  newValue(cur.Akhir, prev.Akhir),
+	}
+}
+
+// ExportColumns adalah kolom export (xlsx/csv/pdf) laporan perbandingan: untuk setiap nilai
+// satu grup berisi periode berjalan, periode pembanding, selisih dan persentasenya.
+func ExportColumns(currentLabel, previousLabel string) []reportExport.Column[model.ReportComparison] {
+	cols := []reportExport.Column[model.ReportComparison]{
+		{Key: "no", Title: "No.", Width: 5, Value: func(i int, _ model.ReportComparison) any { return i + 1 }},
+		{Key: "item_code", Title: "KODE BARANG", Width: 15, Value: func(_ int, r model.ReportComparison) any { return r.ItemCode }},
+		{Key: "item_name", Title: "NAMA BARANG", Width: 30, Value: func(_ int, r model.ReportComparison) any { return r.ItemName }},
+		{Key: "unit_code", Title: "SAT", Width: 8, Value: func(_ int, r model.ReportComparison) any { return r.UnitCode }},
+	}
+	titles := map[string]string{"masuk": "PEMASUKAN", "keluar": "PENGELUARAN", "akhir": "SALDO AKHIR"}
+	for _, m := range metrics {
+		cols = append(cols,
+			reportExport.Column[model.ReportComparison]{Key: m, Group: titles[m], Title: currentLabel, Width: 12, Number: true, Value: func(_ int, r model.ReportComparison) any {
+				return reportExport.Decimal(metricValue(r, m).Current)
+			}},
+			reportExport.Column[model.ReportComparison]{Key: "previous_" + m, Group: titles[m], Title: previousLabel, Width: 12, Number: true, Value: func(_ int, r model.ReportComparison) any {
+				return reportExport.Decimal(metricValue(r, m).Previous)
+			}},
+			reportExport.Column[model.ReportComparison]{Key: "delta_" + m, Group: titles[m], Title: "SELISIH", Width: 12, Number: true, Value: func(_ int, r model.ReportComparison) any {
+				return reportExport.Decimal(metricValue(r, m).Delta)
+			}},
+			reportExport.Column[model.ReportComparison]{Key: "delta_pct_" + m, Group: titles[m], Title: "%", Width: 8, Number: true, Decimals: 2, Value: func(_ int, r model.ReportComparison) any {
+				if pct := metricValue(r, m).DeltaPct; pct != nil {
+					return reportExport.Decimal(*pct)
+				}
+				return nil
+			}},
+		)
+	}
+	return cols
+}
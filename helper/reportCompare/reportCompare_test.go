@@ -0,0 +1,90 @@
+package reportCompare
+
+import (
+	"Bea-Cukai/helper/reportQuery"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func d(v int64) decimal.Decimal { return decimal.NewFromInt(v) }
+
+func TestCompare_MergesByItemCode(t *testing.T) {
+	current := []Item{
+		{ItemCode: "A", Masuk: d(10), Keluar: d(5), Akhir: d(150)},
+		{ItemCode: "B", Masuk: d(0), Keluar: d(0), Akhir: d(20)},
+	}
+	previous := []Item{
+		{ItemCode: "A", Masuk: d(20), Keluar: d(5), Akhir: d(100)},
+		{ItemCode: "C", Masuk: d(7), Keluar: d(0), Akhir: d(-7)},
+	}
+
+	rows := Compare(current, previous)
+	if len(rows) != 3 {
+		t.Fatalf("len(rows) = %d; want 3", len(rows))
+	}
+	byCode := map[string]int{}
+	for i, r := range rows {
+		byCode[r.ItemCode] = i
+	}
+
+	a := rows[byCode["A"]]
+	if !a.Akhir.Delta.Equal(d(50)) || a.Akhir.DeltaPct == nil || !a.Akhir.DeltaPct.Equal(d(50)) {
+		t.Errorf("A akhir = %+v; want delta 50 (50%%)", a.Akhir)
+	}
+	if !a.Masuk.Delta.Equal(d(-10)) || !a.Masuk.DeltaPct.Equal(d(-50)) {
+		t.Errorf("A masuk = %+v; want delta -10 (-50%%)", a.Masuk)
+	}
+
+	b := rows[byCode["B"]]
+	if !b.Akhir.Previous.IsZero() || b.Akhir.DeltaPct != nil {
+		t.Errorf("B (only in current) akhir = %+v; want previous 0 and no delta_pct", b.Akhir)
+	}
+
+	// previous negatif: persentase dihitung terhadap |previous| agar arah tanda tetap benar
+	c := rows[byCode["C"]]
+	if !c.Akhir.Current.IsZero() || !c.Akhir.DeltaPct.Equal(d(100)) {
+		t.Errorf("C (only in previous) akhir = %+v; want current 0 and delta_pct 100", c.Akhir)
+	}
+}
+
+func TestSort(t *testing.T) {
+	rows := Compare([]Item{
+		{ItemCode: "A", Akhir: d(110)},
+		{ItemCode: "B", Akhir: d(0)},
+		{ItemCode: "C", Akhir: d(30)},
+		{ItemCode: "D", Akhir: d(5)},
+	}, []Item{
+		{ItemCode: "A", Akhir: d(100)},
+		{ItemCode: "B", Akhir: d(40)},
+		{ItemCode: "C", Akhir: d(10)},
+	})
+
+	order := func() string {
+		s := ""
+		for _, r := range rows {
+			s += r.ItemCode
+		}
+		return s
+	}
+
+	// default: |delta akhir| terbesar → B(-40), C(+20), A(+10), D(+5)
+	Sort(rows, nil)
+	if got := order(); got != "BCAD" {
+		t.Errorf("default order = %s; want BCAD", got)
+	}
+
+	fields, err := reportQuery.ParseSort("-delta_pct_akhir", Columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// C +200%, A +10%, B -100%, D tanpa persentase selalu di akhir
+	Sort(rows, fields)
+	if got := order(); got != "CABD" {
+		t.Errorf("-delta_pct_akhir order = %s; want CABD", got)
+	}
+
+	if _, err := reportQuery.ParseSort("-selisih", Columns); err == nil {
+		t.Error("expected unknown sort column error for selisih")
+	}
+}
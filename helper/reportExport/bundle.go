@@ -0,0 +1,323 @@
+package reportExport
+
+import (
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/excelTemplate"
+	"Bea-Cukai/model"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// Bundle menggabungkan beberapa laporan (Document dengan tipe baris berbeda) dalam satu
+// workbook dengan cover sheet, atau satu ZIP berisi CSV per laporan.
+
+// FormatZIP adalah format bundle: ZIP berisi satu CSV per laporan.
+const FormatZIP Format = "zip"
+
+var ErrUnsupportedBundleFormat = errors.New("format must be one of: xlsx, zip")
+
+// ParseBundleFormat membaca query param format untuk bundle; kosong = xlsx.
+func ParseBundleFormat(v string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(v))) {
+	case "", FormatXLSX:
+		return FormatXLSX, nil
+	case FormatZIP:
+		return FormatZIP, nil
+	}
+	return "", ErrUnsupportedBundleFormat
+}
+
+// Sheet adalah Document yang tipe barisnya disembunyikan agar laporan berbeda bisa
+// digabung dalam satu Bundle. Document[T] memenuhi interface ini.
+type Sheet interface {
+	Info() SheetInfo
+	AddTo(f *excelize.File, sheetName, documentId string) (rows int, err error)
+	WriteCSVTo(w io.Writer) (rows int, err error)
+}
+
+// SheetInfo adalah identitas laporan di cover sheet / index ZIP.
+type SheetInfo struct {
+	FileName  string
+	SheetName string
+	Title     string
+	Period    string
+}
+
+func (d Document[T]) Info() SheetInfo {
+	return SheetInfo{FileName: d.FileName, SheetName: d.SheetName, Title: d.Title, Period: d.Period}
+}
+
+// AddTo menambahkan dokumen sebagai sheet di workbook f (lihat AddSheet);
+// sheetName kosong = SheetName dokumen. documentId adalah Document ID bundle untuk footer.
+func (d Document[T]) AddTo(f *excelize.File, sheetName, documentId string) (int, error) {
+	if sheetName != "" {
+		d.SheetName = sheetName
+	}
+	d.DocumentId = documentId
+	if d.SignDate.IsZero() {
+		d.SignDate = time.Now()
+	}
+	return addSheet(f, d)
+}
+
+// WriteCSVTo menulis dokumen sebagai CSV (lihat WriteCSV).
+func (d Document[T]) WriteCSVTo(w io.Writer) (int, error) {
+	return writeCSV(w, d)
+}
+
+type Bundle struct {
+	FileName string // tanpa ekstensi
+	Title    string
+	Period   string
+	Profile  model.CompanyProfile
+	SignDate time.Time
+	Sheets   []Sheet
+
+	// DocumentId ditanam di workbook / komentar ZIP; diisi oleh SendBundle bila Registrar terpasang.
+	DocumentId string
+}
+
+// bundleEntry adalah satu baris daftar isi cover / index.csv.
+type bundleEntry struct {
+	Name  string // nama sheet atau nama file CSV
+	Title string
+	Rows  int
+}
+
+// WriteBundleXLSX menulis workbook: cover sheet (kop, daftar isi dengan link ke tiap sheet,
+// tanda tangan) diikuti satu sheet per laporan dengan layout yang sama seperti export tunggal.
+func WriteBundleXLSX(w io.Writer, b Bundle) error {
+	_, err := writeBundleXLSX(w, b)
+	return err
+}
+
+// writeBundleXLSX adalah WriteBundleXLSX yang juga mengembalikan total baris data semua sheet.
+func writeBundleXLSX(w io.Writer, b Bundle) (int, error) {
+	if b.SignDate.IsZero() {
+		b.SignDate = time.Now()
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	// Cover dibuat lebih dulu agar menjadi sheet pertama; isinya ditulis setelah jumlah baris
+	// setiap laporan diketahui.
+	const cover = "Cover"
+	if _, err := f.NewSheet(cover); err != nil {
+		return 0, err
+	}
+	f.DeleteSheet("Sheet1")
+	if b.DocumentId != "" {
+		if err := f.SetHeaderFooter(cover, documentFooter(b.DocumentId)); err != nil {
+			return 0, err
+		}
+		if err := setDocumentProps(f, b.DocumentId, b.Title, b.Profile.CompanyName); err != nil {
+			return 0, err
+		}
+	}
+
+	used := map[string]bool{strings.ToLower(cover): true}
+	total := 0
+	entries := make([]bundleEntry, 0, len(b.Sheets))
+	for _, sheet := range b.Sheets {
+		info := sheet.Info()
+		name := uniqueSheetName(info.SheetName, used)
+		rows, err := sheet.AddTo(f, name, b.DocumentId)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", info.Title, err)
+		}
+		entries = append(entries, bundleEntry{Name: name, Title: info.Title, Rows: rows})
+		total += rows
+	}
+
+	if err := writeCover(f, cover, b, entries); err != nil {
+		return 0, err
+	}
+	index, _ := f.GetSheetIndex(cover)
+	f.SetActiveSheet(index)
+
+	return total, f.Write(w)
+}
+
+// WriteBundleZIP menulis ZIP berisi satu CSV per laporan dan index.csv (daftar isi).
+func WriteBundleZIP(w io.Writer, b Bundle) error {
+	_, err := writeBundleZIP(w, b)
+	return err
+}
+
+// writeBundleZIP adalah WriteBundleZIP yang juga mengembalikan total baris data semua CSV.
+func writeBundleZIP(w io.Writer, b Bundle) (int, error) {
+	zw := zip.NewWriter(w)
+	if b.DocumentId != "" {
+		if err := zw.SetComment(zipCommentPrefix + b.DocumentId); err != nil {
+			return 0, err
+		}
+	}
+
+	used := map[string]bool{}
+	total := 0
+	entries := make([]bundleEntry, 0, len(b.Sheets))
+	for i, sheet := range b.Sheets {
+		info := sheet.Info()
+		name := fmt.Sprintf("%02d_%s.csv", i+1, info.FileName)
+		if used[name] {
+			name = fmt.Sprintf("%02d_%s_%d.csv", i+1, info.FileName, i+1)
+		}
+		used[name] = true
+
+		fw, err := zw.Create(name)
+		if err != nil {
+			return 0, err
+		}
+		rows, err := sheet.WriteCSVTo(fw)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", info.Title, err)
+		}
+		entries = append(entries, bundleEntry{Name: name, Title: info.Title, Rows: rows})
+		total += rows
+	}
+
+	fw, err := zw.Create("index.csv")
+	if err != nil {
+		return 0, err
+	}
+	index := Document[bundleEntry]{
+		Columns: []Column[bundleEntry]{
+			{Key: "file", Value: func(_ int, e bundleEntry) any { return e.Name }},
+			{Key: "title", Value: func(_ int, e bundleEntry) any { return e.Title }},
+			{Key: "period", Value: func(_ int, _ bundleEntry) any { return b.Period }},
+			{Key: "rows", Value: func(_ int, e bundleEntry) any { return e.Rows }},
+		},
+		Rows: entries,
+	}
+	if _, err := writeCSV(fw, index); err != nil {
+		return 0, err
+	}
+
+	return total, zw.Close()
+}
+
+// SendBundle merender bundle (xlsx atau zip) dan mengirimkannya sebagai attachment.
+func SendBundle(ctx *gin.Context, format Format, b Bundle) {
+	sendFile(ctx, format, b.FileName, func(w io.Writer, documentId string) error {
+		b.DocumentId = documentId
+		write := writeBundleXLSX
+		if format == FormatZIP {
+			write = writeBundleZIP
+		}
+		rows, err := write(w, b)
+		if err != nil {
+			return err
+		}
+		apiresponse.SetRowCount(ctx, rows)
+		return nil
+	})
+}
+
+// uniqueSheetName memotong nama ke batas 31 karakter Excel, membuang karakter terlarang
+// dan menambahkan nomor bila nama sudah dipakai.
+func uniqueSheetName(name string, used map[string]bool) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		name = "Laporan"
+	}
+
+	candidate := truncateRunes(name, excelize.MaxSheetNameLength)
+	for n := 2; used[strings.ToLower(candidate)]; n++ {
+		suffix := " (" + strconv.Itoa(n) + ")"
+		candidate = truncateRunes(name, excelize.MaxSheetNameLength-len(suffix)) + suffix
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) > n {
+		return strings.TrimSpace(string(r[:n]))
+	}
+	return s
+}
+
+// writeCover menulis cover sheet bundle.
+func writeCover(f *excelize.File, sheet string, b Bundle, entries []bundleEntry) error {
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	for col, width := range []float64{6, 34, 70, 14} {
+		if err := sw.SetColWidth(col+1, col+1, width); err != nil {
+			return err
+		}
+	}
+
+	if err := excelTemplate.WriteLetterhead(f, sw, b.Profile, b.Title, b.Period, "D"); err != nil {
+		return err
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		Font:      &excelize.Font{Bold: true},
+		Border:    xlsxBorder,
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"F0F0F0"}, Pattern: 1},
+	})
+	if err != nil {
+		return err
+	}
+	textStyle, err := f.NewStyle(&excelize.Style{Border: xlsxBorder})
+	if err != nil {
+		return err
+	}
+	linkStyle, err := f.NewStyle(&excelize.Style{Border: xlsxBorder, Font: &excelize.Font{Color: "0563C1", Underline: "single"}})
+	if err != nil {
+		return err
+	}
+	numFmt := "#,##0"
+	numStyle, err := f.NewStyle(&excelize.Style{Border: xlsxBorder, CustomNumFmt: &numFmt})
+	if err != nil {
+		return err
+	}
+
+	row := xlsxHeaderRow
+	header := []interface{}{
+		excelize.Cell{StyleID: headerStyle, Value: "No."},
+		excelize.Cell{StyleID: headerStyle, Value: "SHEET"},
+		excelize.Cell{StyleID: headerStyle, Value: "LAPORAN"},
+		excelize.Cell{StyleID: headerStyle, Value: "JUMLAH BARIS"},
+	}
+	if err := sw.SetRow(fmt.Sprintf("A%d", row), header); err != nil {
+		return err
+	}
+
+	for i, e := range entries {
+		row++
+		link := fmt.Sprintf(`HYPERLINK("#'%s'!A1","%s")`, strings.ReplaceAll(e.Name, "'", "''"), strings.ReplaceAll(e.Name, `"`, `""`))
+		cells := []interface{}{
+			excelize.Cell{StyleID: textStyle, Value: i + 1},
+			excelize.Cell{StyleID: linkStyle, Formula: link, Value: e.Name},
+			excelize.Cell{StyleID: textStyle, Value: e.Title},
+			excelize.Cell{StyleID: numStyle, Value: e.Rows},
+		}
+		if err := sw.SetRow(fmt.Sprintf("A%d", row), cells); err != nil {
+			return err
+		}
+	}
+
+	if _, err := excelTemplate.WriteSignature(f, sw, b.Profile, row+3, "C", "D", b.SignDate); err != nil {
+		return err
+	}
+	return sw.Flush()
+}
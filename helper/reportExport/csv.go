@@ -0,0 +1,58 @@
+package reportExport
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// WriteCSV menulis data mentah untuk tool lain: satu baris header (Column.Key), tanpa kop,
+// angka tanpa pemisah ribuan dengan titik desimal.
+func WriteCSV[T any](w io.Writer, doc Document[T]) error {
+	_, err := writeCSV(w, doc)
+	return err
+}
+
+// writeCSV adalah WriteCSV yang juga mengembalikan jumlah baris data yang ditulis.
+func writeCSV[T any](w io.Writer, doc Document[T]) (int, error) {
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(doc.Columns))
+	for c, col := range doc.Columns {
+		header[c] = col.Key
+	}
+	if err := cw.Write(header); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	record := make([]string, len(doc.Columns))
+	err := doc.each(func(i int, row T) error {
+		for c, col := range doc.Columns {
+			record[c] = csvValue(col.Value(i, row))
+		}
+		rows++
+		return cw.Write(record)
+	})
+	if err != nil {
+		return rows, err
+	}
+
+	cw.Flush()
+	return rows, cw.Error()
+}
+
+func csvValue(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case int:
+		return strconv.Itoa(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return cellText(Column[struct{}]{}, v)
+	}
+}
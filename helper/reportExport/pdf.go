@@ -0,0 +1,228 @@
+package reportExport
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Layout PDF: A4 landscape, margin 10 mm.
+const (
+	pdfMargin     = 10.0
+	pdfRowHeight  = 5.0
+	pdfFontSize   = 7.0
+	pdfHeaderSize = 7.5
+)
+
+// WritePDF merender dokumen sebagai PDF (pure Go) dengan kop, header tabel yang diulang
+// di setiap halaman, blok tanda tangan dan nomor halaman.
+// Catatan: fpdf menyusun seluruh halaman di memori sebelum Output; untuk data sangat besar
+// gunakan xlsx atau csv yang ditulis secara streaming.
+func WritePDF[T any](w io.Writer, doc Document[T]) error {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	pdf.AliasNbPages("")
+	pdf.SetTitle(doc.Title, true)
+	pdf.SetAuthor(doc.Profile.CompanyName, true)
+	if doc.DocumentId != "" {
+		pdf.SetKeywords("Document ID: "+doc.DocumentId, true)
+	}
+
+	// font inti PDF memakai cp1252; translator agar karakter non-ASCII tidak rusak
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pageW, pageH := pdf.GetPageSize()
+	widths := pdfColumnWidths(doc.Columns, pageW-2*pdfMargin)
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin + 2)
+		pdf.SetFont("Helvetica", "I", 7)
+		if doc.DocumentId != "" {
+			pdf.CellFormat(0, 4, "Document ID: "+doc.DocumentId, "", 0, "L", false, 0, "")
+			pdf.SetX(pdfMargin)
+		}
+		pdf.CellFormat(0, 4, tr(fmt.Sprintf("%s - Halaman %d/{nb}", doc.Title, pdf.PageNo())), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+	writePDFLetterhead(pdf, tr, doc)
+	writePDFHeader(pdf, tr, doc.Columns, widths)
+
+	pdf.SetFont("Helvetica", "", pdfFontSize)
+	bottom := pageH - pdfMargin - 4
+	err := doc.each(func(i int, row T) error {
+		if pdf.GetY()+pdfRowHeight > bottom {
+			pdf.AddPage()
+			writePDFHeader(pdf, tr, doc.Columns, widths)
+			pdf.SetFont("Helvetica", "", pdfFontSize)
+		}
+		for c, col := range doc.Columns {
+			align := "L"
+			if col.Number {
+				align = "R"
+			}
+			text := fitText(pdf, tr(cellText(col, col.Value(i, row))), widths[c]-1)
+			pdf.CellFormat(widths[c], pdfRowHeight, text, "1", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+		return pdf.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writePDFTotals(pdf, tr, doc.Columns, doc.Totals, widths, bottom)
+
+	// Signature block: 7 baris di sisi kanan; pindah halaman bila tidak muat
+	sigW := 70.0
+	if pdf.GetY()+8+7*pdfRowHeight > bottom {
+		pdf.AddPage()
+	}
+	pdf.Ln(8)
+	writePDFSignature(pdf, tr, doc, pageW-pdfMargin-sigW, sigW)
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+// writePDFTotals menulis baris total (tebal) di bawah tabel, Label selebar kolom-kolom awalnya.
+func writePDFTotals[T any](pdf *fpdf.Fpdf, tr func(string) string, cols []Column[T], totals []TotalRow, widths []float64, bottom float64) {
+	keys := make([]string, len(cols))
+	for c, col := range cols {
+		keys[c] = col.Key
+	}
+
+	pdf.SetFont("Helvetica", "B", pdfFontSize)
+	for _, t := range totals {
+		if pdf.GetY()+pdfRowHeight > bottom {
+			pdf.AddPage()
+			writePDFHeader(pdf, tr, cols, widths)
+			pdf.SetFont("Helvetica", "B", pdfFontSize)
+		}
+		span := t.labelSpan(keys)
+		labelW := 0.0
+		for c := 0; c < span; c++ {
+			labelW += widths[c]
+		}
+		pdf.CellFormat(labelW, pdfRowHeight, fitText(pdf, tr(t.Label), labelW-1), "1", 0, "L", false, 0, "")
+		for c := span; c < len(cols); c++ {
+			text := ""
+			align := "L"
+			if v, ok := t.Values[cols[c].Key]; ok {
+				text = fitText(pdf, tr(cellText(cols[c], v)), widths[c]-1)
+			}
+			if cols[c].Number {
+				align = "R"
+			}
+			pdf.CellFormat(widths[c], pdfRowHeight, text, "1", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+func writePDFLetterhead[T any](pdf *fpdf.Fpdf, tr func(string) string, doc Document[T]) {
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 6, tr(doc.Profile.CompanyName), "", 1, "C", false, 0, "")
+	pdf.CellFormat(0, 6, tr(doc.Title), "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+
+	rows := [][2]string{
+		{"Nama Kawasan Berikat", doc.Profile.CompanyName},
+		{"NPWP", doc.Profile.Npwp},
+		{"No. Izin KB", doc.Profile.KbLicenseNo},
+		{"Alamat", doc.Profile.Address},
+		{"Periode Laporan", doc.Period},
+	}
+	for _, r := range rows {
+		pdf.SetFont("Helvetica", "B", 8)
+		pdf.CellFormat(40, 4.5, tr(r[0]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(0, 4.5, tr(": "+r[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(3)
+}
+
+// writePDFHeader menulis header dua baris dengan aturan yang sama seperti Excel.
+func writePDFHeader[T any](pdf *fpdf.Fpdf, tr func(string) string, cols []Column[T], widths []float64) {
+	pdf.SetFont("Helvetica", "B", pdfHeaderSize)
+	pdf.SetFillColor(240, 240, 240)
+
+	x0, y0 := pdf.GetX(), pdf.GetY()
+	h := pdfRowHeight
+
+	x := x0
+	for _, span := range headerSpans(cols) {
+		w := 0.0
+		for c := span.Start; c <= span.End; c++ {
+			w += widths[c]
+		}
+		pdf.SetXY(x, y0)
+		if !span.Grouped {
+			pdf.CellFormat(w, 2*h, fitText(pdf, tr(span.Label), w-1), "1", 0, "C", true, 0, "")
+		} else {
+			pdf.CellFormat(w, h, fitText(pdf, tr(span.Label), w-1), "1", 0, "C", true, 0, "")
+			sx := x
+			for c := span.Start; c <= span.End; c++ {
+				pdf.SetXY(sx, y0+h)
+				pdf.CellFormat(widths[c], h, fitText(pdf, tr(cols[c].Title), widths[c]-1), "1", 0, "C", true, 0, "")
+				sx += widths[c]
+			}
+		}
+		x += w
+	}
+	pdf.SetXY(x0, y0+2*h)
+}
+
+func writePDFSignature[T any](pdf *fpdf.Fpdf, tr func(string) string, doc Document[T], x, w float64) {
+	dateLine := doc.SignDate.Format("02-01-2006")
+	if doc.Profile.City != "" {
+		dateLine = doc.Profile.City + ", " + dateLine
+	}
+
+	line := func(text, style string) {
+		pdf.SetX(x)
+		pdf.SetFont("Helvetica", style, 8)
+		pdf.CellFormat(w, pdfRowHeight, tr(text), "", 1, "C", false, 0, "")
+	}
+	line(dateLine, "")
+	line("Penanggung Jawab", "")
+	pdf.Ln(3 * pdfRowHeight)
+	line(doc.Profile.SignatoryName, "BU")
+	line(doc.Profile.SignatoryTitle, "")
+}
+
+// pdfColumnWidths membagi lebar halaman sesuai proporsi Column.Width.
+func pdfColumnWidths[T any](cols []Column[T], total float64) []float64 {
+	sum := 0.0
+	for _, col := range cols {
+		sum += pdfWeight(col.Width)
+	}
+	widths := make([]float64, len(cols))
+	for c, col := range cols {
+		widths[c] = total * pdfWeight(col.Width) / sum
+	}
+	return widths
+}
+
+func pdfWeight(w float64) float64 {
+	if w <= 0 {
+		return 10
+	}
+	return w
+}
+
+// fitText memotong teks agar muat di lebar sel.
+func fitText(pdf *fpdf.Fpdf, s string, width float64) string {
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	r := []byte(s) // sudah cp1252 setelah translator: 1 byte per karakter
+	for len(r) > 0 && pdf.GetStringWidth(string(r)+"..") > width {
+		r = r[:len(r)-1]
+	}
+	return string(r) + ".."
+}
@@ -0,0 +1,347 @@
+package reportExport
+
+import (
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/model"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// reportExport adalah lapisan export bersama untuk semua endpoint /export.
+// Setiap laporan cukup mendefinisikan kolomnya sekali ([]Column[T]); format xlsx, csv dan pdf
+// dirender dari definisi yang sama dengan kop (company profile), judul dan periode yang sama.
+
+type Format string
+
+const (
+	FormatXLSX Format = "xlsx"
+	FormatCSV  Format = "csv"
+	FormatPDF  Format = "pdf"
+)
+
+var ErrUnsupportedFormat = errors.New("format must be one of: xlsx, csv, pdf")
+
+// ParseFormat membaca query param format; kosong = xlsx.
+func ParseFormat(v string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(v))) {
+	case "", FormatXLSX:
+		return FormatXLSX, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatPDF:
+		return FormatPDF, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	case FormatZIP:
+		return "application/zip"
+	default:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+}
+
+// Column mendefinisikan satu kolom laporan.
+type Column[T any] struct {
+	Key   string // header CSV (snake_case, stabil untuk tool lain)
+	Group string // header baris pertama yang menaungi kolom berurutan dengan Group sama; kosong = header dua baris
+	Title string
+	Width float64 // lebar kolom Excel (karakter); PDF memakai proporsi yang sama
+
+	// Number: rata kanan dengan pemisah ribuan dan Decimals angka di belakang koma.
+	Number   bool
+	Decimals int
+
+	// Value mengembalikan string, int atau float64. i = index baris (0-based).
+	Value func(i int, row T) any
+}
+
+// Document adalah satu laporan yang siap di-export.
+type Document[T any] struct {
+	FileName  string // tanpa ekstensi
+	SheetName string
+	Title     string
+	Period    string
+	Profile   model.CompanyProfile
+	SignDate  time.Time
+	Columns   []Column[T]
+	Rows      []T
+
+	// DocumentId ditanam di file (lihat signature.go); diisi oleh Send bila Registrar terpasang.
+	DocumentId string
+
+	// Stream, bila diisi, menggantikan Rows: baris dibaca bertahap dari repository (cursor)
+	// dan diteruskan ke fn satu per satu tanpa ditampung dalam slice.
+	Stream func(fn func(row T) error) error
+
+	// Totals ditulis tebal di bawah tabel (xlsx dan pdf); csv tetap data mentah tanpa baris total.
+	Totals []TotalRow
+}
+
+// TotalRow adalah satu baris total: Label di kolom-kolom awal sampai sebelum kolom pertama yang
+// punya nilai, Values berisi nilai per Column.Key (string, int atau float64).
+type TotalRow struct {
+	Label  string
+	Values map[string]any
+}
+
+// labelSpan adalah jumlah kolom awal yang dipakai Label (minimal 1).
+func (t TotalRow) labelSpan(keys []string) int {
+	for c, key := range keys {
+		if _, ok := t.Values[key]; ok {
+			return max(c, 1)
+		}
+	}
+	return max(len(keys), 1)
+}
+
+// each memanggil fn untuk setiap baris dokumen, dari Stream atau Rows.
+func (d Document[T]) each(fn func(i int, row T) error) error {
+	if d.Stream == nil {
+		for i, row := range d.Rows {
+			if err := fn(i, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	i := 0
+	return d.Stream(func(row T) error {
+		err := fn(i, row)
+		i++
+		return err
+	})
+}
+
+// Write merender dokumen ke w dalam format yang diminta.
+func Write[T any](w io.Writer, format Format, doc Document[T]) error {
+	if doc.SignDate.IsZero() {
+		doc.SignDate = time.Now()
+	}
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, doc)
+	case FormatPDF:
+		return WritePDF(w, doc)
+	default:
+		return WriteXLSX(w, doc)
+	}
+}
+
+// Send merender dokumen dan mengirimkannya sebagai attachment.
+// Jumlah baris yang ditulis dicatat lewat apiresponse.SetRowCount (log akses laporan).
+func Send[T any](ctx *gin.Context, format Format, doc Document[T]) {
+	rows := len(doc.Rows)
+	if stream := doc.Stream; stream != nil {
+		rows = 0
+		doc.Stream = func(fn func(row T) error) error {
+			return stream(func(row T) error {
+				rows++
+				return fn(row)
+			})
+		}
+	}
+
+	sendFile(ctx, format, doc.FileName, func(w io.Writer, documentId string) error {
+		doc.DocumentId = documentId
+		if err := Write(w, format, doc); err != nil {
+			return err
+		}
+		apiresponse.SetRowCount(ctx, rows)
+		return nil
+	})
+}
+
+// sendFile menulis hasil render ke file sementara (dihapus setelah terkirim) lalu mengirimkannya,
+// supaya error di tengah pembacaan data masih bisa dijawab dengan JSON 500 tanpa menahan
+// seluruh file di memori. Bila Registrar terpasang, file diberi Document ID dan SHA-256-nya
+// dicatat sebelum dikirim.
+func sendFile(ctx *gin.Context, format Format, fileName string, render func(w io.Writer, documentId string) error) {
+	var documentId string
+	if registrar != nil {
+		var err error
+		if documentId, err = NewDocumentId(time.Now()); err != nil {
+			apiresponse.Error(ctx, http.StatusInternalServerError, "EXPORT_GENERATION_FAILED", "failed to create document id", err, gin.H{
+				"format": format,
+			})
+			return
+		}
+	}
+
+	tmp, err := os.CreateTemp("", "export-*."+string(format))
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "EXPORT_GENERATION_FAILED", "failed to create temporary export file", err, gin.H{
+			"format": format,
+		})
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := render(tmp, documentId); err != nil {
+		// Data diambil saat render (Stream); query yang timeout dijawab 504.
+		apiresponse.QueryError(ctx, "EXPORT_GENERATION_FAILED", "failed to generate export file", err, gin.H{
+			"format": format,
+		})
+		return
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		apiresponse.Error(ctx, http.StatusInternalServerError, "EXPORT_GENERATION_FAILED", "failed to read export file", err, gin.H{
+			"format": format,
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("%s.%s", fileName, format)
+	if documentId != "" {
+		sig, err := sign(ctx, tmp, Signature{DocumentId: documentId, Format: format, FileName: filename})
+		if err != nil {
+			apiresponse.Error(ctx, http.StatusInternalServerError, "EXPORT_REGISTER_FAILED", "failed to register export document", err, gin.H{
+				"format":      format,
+				"document_id": documentId,
+			})
+			return
+		}
+		ctx.Header("X-Document-Id", sig.DocumentId)
+		ctx.Header("X-Document-Sha256", sig.Sha256)
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.DataFromReader(http.StatusOK, size, format.ContentType(), tmp, nil)
+}
+
+// ==========================
+// Value helpers
+// ==========================
+
+// Decimal mengonversi decimal.Decimal ke float64 untuk sel numerik.
+func Decimal(d decimal.Decimal) float64 {
+	f, _ := d.Float64()
+	return f
+}
+
+// DecimalPtr adalah Decimal untuk nilai opsional; nil = sel kosong.
+func DecimalPtr(d *decimal.Decimal) any {
+	if d == nil {
+		return nil
+	}
+	return Decimal(*d)
+}
+
+// ParseNumber mengonversi nilai angka berbentuk string (laporan mesin/scrap/aux/WIP); tidak valid = 0.
+func ParseNumber(s string) float64 {
+	f, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f
+}
+
+// Date memformat tanggal; zero time = kosong.
+func Date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// DatePtr adalah Date untuk tanggal opsional; nil = kosong.
+func DatePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return Date(*t)
+}
+
+// PeriodRange adalah teks periode standar "dd-mm-yyyy s.d dd-mm-yyyy".
+func PeriodRange(from, to time.Time) string {
+	return fmt.Sprintf("%s s.d %s", from.Format("02-01-2006"), to.Format("02-01-2006"))
+}
+
+// formatNumber memformat angka dengan pemisah ribuan "," dan desimal "." (sama dengan format Excel).
+func formatNumber(v float64, decimals int) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i:]
+	}
+
+	var b strings.Builder
+	for i, ch := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(ch)
+	}
+
+	out := b.String() + frac
+	if neg {
+		out = "-" + out
+	}
+	return out
+}
+
+// cellText adalah representasi teks nilai sel untuk PDF.
+func cellText[T any](col Column[T], v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case int:
+		return strconv.Itoa(x)
+	case float64:
+		if col.Number {
+			return formatNumber(x, col.Decimals)
+		}
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// headerSpan adalah satu sel header baris pertama: Group yang menaungi kolom start..end,
+// atau kolom tanpa Group (start == end, Group kosong).
+type headerSpan struct {
+	Label      string
+	Start, End int
+	Grouped    bool
+}
+
+func headerSpans[T any](cols []Column[T]) []headerSpan {
+	var spans []headerSpan
+	for i := 0; i < len(cols); i++ {
+		if cols[i].Group == "" {
+			spans = append(spans, headerSpan{Label: cols[i].Title, Start: i, End: i})
+			continue
+		}
+		j := i
+		for j+1 < len(cols) && cols[j+1].Group == cols[i].Group {
+			j++
+		}
+		spans = append(spans, headerSpan{Label: cols[i].Group, Start: i, End: j, Grouped: true})
+		i = j
+	}
+	return spans
+}
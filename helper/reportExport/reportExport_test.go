@@ -0,0 +1,362 @@
+package reportExport
+
+import (
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/model"
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+type testRow struct {
+	Code string
+	Qty  float64
+}
+
+func testDocument() Document[testRow] {
+	return Document[testRow]{
+		FileName: "laporan_test",
+		Title:    "LAPORAN TEST",
+		Period:   "01-01-2025 s.d 31-01-2025",
+		Profile:  model.DefaultCompanyProfile(),
+		SignDate: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		Columns: []Column[testRow]{
+			{Key: "no", Title: "NO", Value: func(i int, _ testRow) any { return i + 1 }},
+			{Key: "kode", Group: "BARANG", Title: "KODE", Value: func(_ int, r testRow) any { return r.Code }},
+			{Key: "jumlah", Group: "BARANG", Title: "JUMLAH", Number: true, Decimals: 2, Value: func(_ int, r testRow) any { return r.Qty }},
+		},
+		Rows: []testRow{{Code: "A-1", Qty: 1234.5}, {Code: "B-2", Qty: -7}},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	cases := map[string]Format{"": FormatXLSX, "XLSX": FormatXLSX, " csv ": FormatCSV, "pdf": FormatPDF}
+	for in, want := range cases {
+		got, err := ParseFormat(in)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("docx"); err != ErrUnsupportedFormat {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	cases := []struct {
+		v        float64
+		decimals int
+		want     string
+	}{
+		{0, 0, "0"},
+		{999, 0, "999"},
+		{1234567.891, 2, "1,234,567.89"},
+		{-1000, 0, "-1,000"},
+	}
+	for _, c := range cases {
+		if got := formatNumber(c.v, c.decimals); got != c.want {
+			t.Errorf("formatNumber(%v, %d) = %q; want %q", c.v, c.decimals, got, c.want)
+		}
+	}
+}
+
+func TestHeaderSpans(t *testing.T) {
+	spans := headerSpans(testDocument().Columns)
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Grouped || spans[0].Label != "NO" {
+		t.Errorf("unexpected first span: %+v", spans[0])
+	}
+	if !spans[1].Grouped || spans[1].Label != "BARANG" || spans[1].Start != 1 || spans[1].End != 2 {
+		t.Errorf("unexpected group span: %+v", spans[1])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, testDocument()); err != nil {
+		t.Fatal(err)
+	}
+	want := "no,kode,jumlah\n1,A-1,1234.5\n2,B-2,-7\n"
+	if buf.String() != want {
+		t.Errorf("unexpected csv:\n%s", buf.String())
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatXLSX, testDocument()); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sheet := f.GetSheetName(f.GetActiveSheetIndex())
+	checks := map[string]string{"B9": "BARANG", "C10": "JUMLAH", "B11": "A-1", "B12": "B-2"}
+	for cell, want := range checks {
+		if got, _ := f.GetCellValue(sheet, cell); got != want {
+			t.Errorf("%s = %q; want %q", cell, got, want)
+		}
+	}
+}
+
+// Baris total ditulis di bawah data dengan Label di-merge sampai kolom nilai pertama;
+// CSV tidak memuat baris total.
+func TestWriteXLSX_Totals(t *testing.T) {
+	doc := testDocument()
+	doc.Totals = []TotalRow{
+		{Label: "TOTAL", Values: map[string]any{"jumlah": 1227.5}},
+		{Label: "TANPA NILAI"},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatXLSX, doc); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sheet := f.GetSheetName(f.GetActiveSheetIndex())
+	checks := map[string]string{"A13": "TOTAL", "C13": "1,227.50", "A14": "TANPA NILAI"}
+	for cell, want := range checks {
+		if got, _ := f.GetCellValue(sheet, cell); got != want {
+			t.Errorf("%s = %q; want %q", cell, got, want)
+		}
+	}
+	merges, _ := f.GetMergeCells(sheet)
+	found := false
+	for _, m := range merges {
+		if m.GetStartAxis() == "A13" && m.GetEndAxis() == "B13" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected total label merged over A13:B13")
+	}
+
+	buf.Reset()
+	if err := Write(&buf, FormatCSV, doc); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "TOTAL") {
+		t.Errorf("csv must not contain total rows:\n%s", buf.String())
+	}
+	if err := Write(io.Discard, FormatPDF, doc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatPDF, testDocument()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "%PDF-") {
+		t.Error("output is not a PDF document")
+	}
+}
+
+func TestWriteXLSX_Stream(t *testing.T) {
+	doc := testDocument()
+	rows := doc.Rows
+	doc.Rows = nil
+	doc.Stream = func(fn func(testRow) error) error {
+		for _, r := range rows {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatXLSX, doc); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sheet := f.GetSheetName(f.GetActiveSheetIndex())
+	checks := map[string]string{"A11": "1", "A12": "2", "B12": "B-2", "A15": "Bekasi, 01-02-2025"}
+	for cell, want := range checks {
+		if got, _ := f.GetCellValue(sheet, cell); got != want {
+			t.Errorf("%s = %q; want %q", cell, got, want)
+		}
+	}
+
+	merged, err := f.GetMergeCells(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, m := range merged {
+		if m.GetStartAxis() == "B9" && m.GetEndAxis() == "C9" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected group header B9:C9 to be merged")
+	}
+}
+
+func testBundle() Bundle {
+	doc := testDocument()
+	doc.SheetName = "Laporan Test Dengan Nama Yang Sangat Panjang"
+	return Bundle{
+		FileName: "bundel_test",
+		Title:    "BUNDEL TEST",
+		Period:   doc.Period,
+		Profile:  doc.Profile,
+		SignDate: doc.SignDate,
+		Sheets:   []Sheet{doc, doc},
+	}
+}
+
+func TestWriteBundleXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBundleXLSX(&buf, testBundle()); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	want := []string{"Cover", "Laporan Test Dengan Nama Yang S", "Laporan Test Dengan Nama Ya (2)"}
+	got := f.GetSheetList()
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("sheets = %q; want %q", got, want)
+	}
+	if f.GetSheetName(f.GetActiveSheetIndex()) != "Cover" {
+		t.Error("cover should be the active sheet")
+	}
+	if v, _ := f.GetCellValue("Cover", "D10"); v != "2" {
+		t.Errorf("cover row count = %q; want 2", v)
+	}
+	if v, _ := f.GetCellValue(want[2], "B11"); v != "A-1" {
+		t.Errorf("second sheet B11 = %q; want A-1", v)
+	}
+}
+
+func TestWriteBundleZIP(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBundleZIP(&buf, testBundle()); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, file := range zr.File {
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, "|") != "01_laporan_test.csv|02_laporan_test.csv|index.csv" {
+		t.Fatalf("unexpected zip entries: %v", names)
+	}
+
+	rc, err := zr.File[2].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	index, _ := io.ReadAll(rc)
+	if !strings.Contains(string(index), "01_laporan_test.csv,LAPORAN TEST,01-01-2025 s.d 31-01-2025,2") {
+		t.Errorf("unexpected index.csv:\n%s", index)
+	}
+}
+
+func TestDocumentId_Embedded(t *testing.T) {
+	const id = "EXP-20250131-ABCDEF012345"
+
+	doc := testDocument()
+	doc.DocumentId = id
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	if got := DocumentIdOf(bytes.NewReader(buf.Bytes()), int64(buf.Len())); got != id {
+		t.Errorf("xlsx DocumentIdOf = %q; want %q", got, id)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	hf, err := f.GetHeaderFooter(f.GetSheetName(0))
+	if err != nil || hf == nil || !strings.Contains(hf.OddFooter, id) {
+		t.Errorf("footer = %+v (%v); want Document ID %s", hf, err, id)
+	}
+
+	b := testBundle()
+	b.DocumentId = id
+	buf.Reset()
+	if err := WriteBundleZIP(&buf, b); err != nil {
+		t.Fatal(err)
+	}
+	if got := DocumentIdOf(bytes.NewReader(buf.Bytes()), int64(buf.Len())); got != id {
+		t.Errorf("zip DocumentIdOf = %q; want %q", got, id)
+	}
+
+	if got := DocumentIdOf(strings.NewReader("a,b\n1,2\n"), 8); got != "" {
+		t.Errorf("csv DocumentIdOf = %q; want empty", got)
+	}
+}
+
+type testRegistrar struct{ sigs []Signature }
+
+func (r *testRegistrar) Register(_ *gin.Context, sig Signature) error {
+	r.sigs = append(r.sigs, sig)
+	return nil
+}
+
+func TestSend_Signed(t *testing.T) {
+	reg := &testRegistrar{}
+	SetRegistrar(reg)
+	defer SetRegistrar(nil)
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/report/test/export?format=xlsx", nil)
+
+	Send(ctx, FormatXLSX, testDocument())
+
+	if w.Code != http.StatusOK || len(reg.sigs) != 1 {
+		t.Fatalf("status = %d, registered = %d", w.Code, len(reg.sigs))
+	}
+	sig := reg.sigs[0]
+	hash, size, _ := HashFile(bytes.NewReader(w.Body.Bytes()))
+	if sig.Sha256 != hash || sig.Size != size || w.Header().Get("X-Document-Sha256") != hash {
+		t.Errorf("registered hash %s (%d bytes); body hash %s (%d bytes)", sig.Sha256, sig.Size, hash, size)
+	}
+	if w.Header().Get("X-Document-Id") != sig.DocumentId || !strings.HasPrefix(sig.DocumentId, "EXP-") {
+		t.Errorf("unexpected document id %q / header %q", sig.DocumentId, w.Header().Get("X-Document-Id"))
+	}
+	if got := DocumentIdOf(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len())); got != sig.DocumentId {
+		t.Errorf("embedded document id = %q; want %q", got, sig.DocumentId)
+	}
+	if n, ok := apiresponse.RowCount(ctx); !ok || n != 2 {
+		t.Errorf("row count = %d (%v); want 2", n, ok)
+	}
+}
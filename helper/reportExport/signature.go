@@ -0,0 +1,167 @@
+package reportExport
+
+import (
+	"Bea-Cukai/helper"
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/xuri/excelize/v2"
+)
+
+// Setiap file export diberi Document ID yang ditanam di file (properti workbook + footer xlsx,
+// metadata + footer PDF, komentar ZIP) dan dicatat bersama SHA-256 file oleh Registrar,
+// sehingga file yang diserahkan ke auditor bisa dibuktikan belum diubah (POST /exports/verify).
+// CSV tidak punya tempat untuk metadata; keasliannya dibuktikan lewat hash saja.
+
+// Registrar mencatat file export yang sudah dirender (lihat exportDocumentService).
+type Registrar interface {
+	Register(ctx *gin.Context, sig Signature) error
+}
+
+// Signature adalah identitas satu file export.
+type Signature struct {
+	DocumentId string
+	Sha256     string
+	Size       int64
+	Format     Format
+	FileName   string
+}
+
+// registrar nil = export tidak ditandatangani (mis. di test).
+var registrar Registrar
+
+// SetRegistrar memasang Registrar untuk semua export; dipanggil sekali saat startup.
+func SetRegistrar(r Registrar) {
+	registrar = r
+}
+
+// NewDocumentId membuat Document ID "EXP-YYYYMMDD-XXXXXXXXXXXX".
+func NewDocumentId(now time.Time) (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("EXP-%s-%s", now.Format("20060102"), strings.ToUpper(hex.EncodeToString(b))), nil
+}
+
+// HashFile menghitung SHA-256 (hex) isi r.
+func HashFile(r io.Reader) (string, int64, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+const zipCommentPrefix = "Document ID: "
+
+// DocumentIdOf membaca Document ID yang ditanam di file export xlsx atau zip; kosong bila
+// file bukan export bertanda tangan (atau CSV/PDF).
+func DocumentIdOf(r io.ReaderAt, size int64) string {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return ""
+	}
+	if id, ok := strings.CutPrefix(zr.Comment, zipCommentPrefix); ok {
+		return strings.TrimSpace(id)
+	}
+
+	f, err := excelize.OpenReader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	props, err := f.GetDocProps()
+	if err != nil {
+		return ""
+	}
+	return props.Identifier
+}
+
+// documentFooter adalah footer halaman cetak xlsx: Document ID di kiri, nomor halaman di kanan.
+func documentFooter(documentId string) *excelize.HeaderFooterOptions {
+	return &excelize.HeaderFooterOptions{
+		OddFooter: fmt.Sprintf("&L&8Document ID: %s&R&8Halaman &P dari &N", documentId),
+	}
+}
+
+// setDocumentProps menanam Document ID di properti workbook.
+func setDocumentProps(f *excelize.File, documentId, title, creator string) error {
+	return f.SetDocProps(&excelize.DocProperties{
+		Identifier:  documentId,
+		Title:       title,
+		Creator:     creator,
+		Description: fmt.Sprintf("Document ID %s. Keaslian file dapat diverifikasi melalui POST /exports/verify.", documentId),
+		Created:     time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// ==========================
+// Requester
+// ==========================
+
+// Requester adalah user yang meminta export, untuk dicatat oleh Registrar.
+// Worker export job (tanpa JWT) menitipkannya lewat context request.
+type Requester struct {
+	Id          string
+	Username    string
+	ExportJobId string
+	IpAddress   string // IP client saat export job dibuat (hanya worker export job)
+}
+
+type requesterKey struct{}
+
+func WithRequester(ctx context.Context, r Requester) context.Context {
+	return context.WithValue(ctx, requesterKey{}, r)
+}
+
+func RequesterFrom(ctx context.Context) (Requester, bool) {
+	r, ok := ctx.Value(requesterKey{}).(Requester)
+	return r, ok
+}
+
+// RequesterOf menentukan user peminta laporan: titipan worker export job, userData dari
+// middleware Authentication, atau JWT bila dikirim (endpoint laporan tidak mewajibkan login).
+// Kosong bila anonim.
+func RequesterOf(ctx *gin.Context) Requester {
+	if r, ok := RequesterFrom(ctx.Request.Context()); ok {
+		return r
+	}
+
+	var claims any
+	if v, ok := ctx.Get("userData"); ok {
+		claims = v
+	} else if v, err := helper.VerifyToken(ctx); err == nil {
+		claims = v
+	}
+
+	var r Requester
+	if userData, ok := claims.(jwt.MapClaims); ok {
+		r.Id, _ = userData["id"].(string)
+		r.Username, _ = userData["username"].(string)
+	}
+	return r
+}
+
+// sign menghitung hash file export dan mendaftarkannya; file harus sudah di posisi awal.
+func sign(ctx *gin.Context, file io.ReadSeeker, sig Signature) (Signature, error) {
+	hash, size, err := HashFile(file)
+	if err != nil {
+		return sig, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return sig, err
+	}
+	sig.Sha256, sig.Size = hash, size
+	return sig, registrar.Register(ctx, sig)
+}
@@ -0,0 +1,255 @@
+package reportExport
+
+import (
+	"Bea-Cukai/helper/excelTemplate"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Layout Excel: kop baris 1-8, header tabel baris 9-10, data mulai baris 11.
+const (
+	xlsxHeaderRow = excelTemplate.LetterheadRows + 1
+	xlsxDataRow   = xlsxHeaderRow + 2
+)
+
+// WriteXLSX merender dokumen sebagai workbook satu sheet.
+func WriteXLSX[T any](w io.Writer, doc Document[T]) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := AddSheet(f, doc); err != nil {
+		return err
+	}
+	f.DeleteSheet("Sheet1")
+	if doc.DocumentId != "" {
+		if err := setDocumentProps(f, doc.DocumentId, doc.Title, doc.Profile.CompanyName); err != nil {
+			return err
+		}
+	}
+
+	return f.Write(w)
+}
+
+// AddSheet menambahkan dokumen sebagai sheet baru di workbook f dan menjadikannya aktif.
+// Sheet ditulis dengan excelize.StreamWriter: baris dikirim satu per satu (lihat Document.Stream)
+// dan excelize menampung sisanya di file sementara, sehingga pemakaian memori tetap datar
+// berapa pun jumlah barisnya.
+func AddSheet[T any](f *excelize.File, doc Document[T]) error {
+	_, err := addSheet(f, doc)
+	return err
+}
+
+// addSheet adalah AddSheet yang juga mengembalikan jumlah baris data yang ditulis.
+func addSheet[T any](f *excelize.File, doc Document[T]) (int, error) {
+	sheet := doc.SheetName
+	if sheet == "" {
+		sheet = "Laporan"
+	}
+	index, err := f.NewSheet(sheet)
+	if err != nil {
+		return 0, err
+	}
+	f.SetActiveSheet(index)
+
+	// Footer cetak wajib di-set sebelum StreamWriter dibuat; setelahnya diabaikan excelize
+	if doc.DocumentId != "" {
+		if err := f.SetHeaderFooter(sheet, documentFooter(doc.DocumentId)); err != nil {
+			return 0, err
+		}
+	}
+
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return 0, err
+	}
+
+	// Lebar kolom wajib di-set sebelum baris pertama ditulis
+	for c, col := range doc.Columns {
+		if col.Width > 0 {
+			if err := sw.SetColWidth(c+1, c+1, col.Width); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	lastCol, _ := excelize.ColumnNumberToName(len(doc.Columns))
+	if err := excelTemplate.WriteLetterhead(f, sw, doc.Profile, doc.Title, doc.Period, lastCol); err != nil {
+		return 0, err
+	}
+	if err := writeXLSXHeader(f, sw, doc.Columns); err != nil {
+		return 0, err
+	}
+
+	styles, err := xlsxColumnStyles(f, doc.Columns, false)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	record := make([]interface{}, len(doc.Columns))
+	err = doc.each(func(i int, row T) error {
+		for c, col := range doc.Columns {
+			record[c] = excelize.Cell{StyleID: styles[c], Value: col.Value(i, row)}
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, xlsxDataRow+i)
+		rows++
+		return sw.SetRow(cell, record)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := writeXLSXTotals(f, sw, doc.Columns, doc.Totals, xlsxDataRow+rows); err != nil {
+		return 0, err
+	}
+
+	// Signature block (Penanggung Jawab) di 3 kolom terakhir, 2 baris di bawah tabel (dan total)
+	sigFrom := lastCol
+	if len(doc.Columns) >= 3 {
+		sigFrom, _ = excelize.ColumnNumberToName(len(doc.Columns) - 2)
+	}
+	if _, err := excelTemplate.WriteSignature(f, sw, doc.Profile, xlsxDataRow+rows+len(doc.Totals)+2, sigFrom, lastCol, doc.SignDate); err != nil {
+		return 0, err
+	}
+
+	return rows, sw.Flush()
+}
+
+// writeXLSXTotals menulis baris total mulai baris row; Label di-merge sampai sebelum kolom
+// pertama yang punya nilai.
+func writeXLSXTotals[T any](f *excelize.File, sw *excelize.StreamWriter, cols []Column[T], totals []TotalRow, row int) error {
+	if len(totals) == 0 {
+		return nil
+	}
+	styles, err := xlsxColumnStyles(f, cols, true)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, len(cols))
+	for c, col := range cols {
+		keys[c] = col.Key
+	}
+	for i, t := range totals {
+		record := make([]interface{}, len(cols))
+		for c, col := range cols {
+			cell := excelize.Cell{StyleID: styles[c]}
+			if v, ok := t.Values[col.Key]; ok {
+				cell.Value = v
+			}
+			record[c] = cell
+		}
+		span := t.labelSpan(keys)
+		record[0] = excelize.Cell{StyleID: styles[0], Value: t.Label}
+
+		start, _ := excelize.CoordinatesToCellName(1, row+i)
+		if err := sw.SetRow(start, record); err != nil {
+			return err
+		}
+		if span > 1 {
+			end, _ := excelize.CoordinatesToCellName(span, row+i)
+			if err := sw.MergeCell(start, end); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// xlsxColumnStyles membuat style border per kolom; kolom angka memakai format "#,##0[.00]".
+// bold = style baris total.
+func xlsxColumnStyles[T any](f *excelize.File, cols []Column[T], bold bool) ([]int, error) {
+	font := &excelize.Font{Bold: bold}
+	textStyle, err := f.NewStyle(&excelize.Style{Border: xlsxBorder, Font: font})
+	if err != nil {
+		return nil, err
+	}
+
+	numStyles := map[int]int{}
+	styles := make([]int, len(cols))
+	for c, col := range cols {
+		if !col.Number {
+			styles[c] = textStyle
+			continue
+		}
+		if _, ok := numStyles[col.Decimals]; !ok {
+			numFmt := "#,##0"
+			if col.Decimals > 0 {
+				numFmt += "." + strings.Repeat("0", col.Decimals)
+			}
+			if numStyles[col.Decimals], err = f.NewStyle(&excelize.Style{Border: xlsxBorder, Font: font, CustomNumFmt: &numFmt}); err != nil {
+				return nil, err
+			}
+		}
+		styles[c] = numStyles[col.Decimals]
+	}
+	return styles, nil
+}
+
+var xlsxBorder = []excelize.Border{
+	{Type: "left", Color: "000000", Style: 1},
+	{Type: "top", Color: "000000", Style: 1},
+	{Type: "bottom", Color: "000000", Style: 1},
+	{Type: "right", Color: "000000", Style: 1},
+}
+
+// writeXLSXHeader menulis header dua baris (9-10): kolom tanpa Group di-merge vertikal,
+// kolom ber-Group mendapat judul Group di baris 9 (merge horizontal) dan Title di baris 10.
+func writeXLSXHeader[T any](f *excelize.File, sw *excelize.StreamWriter, cols []Column[T]) error {
+	top, bottom := xlsxHeaderRow, xlsxHeaderRow+1
+
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center", WrapText: true},
+		Font:      &excelize.Font{Bold: true},
+		Border:    xlsxBorder,
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"F0F0F0"}, Pattern: 1},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Sel yang tertutup merge tetap diberi style agar border-nya utuh
+	topCells := make([]interface{}, len(cols))
+	bottomCells := make([]interface{}, len(cols))
+	for c := range cols {
+		topCells[c] = excelize.Cell{StyleID: headerStyle}
+		bottomCells[c] = excelize.Cell{StyleID: headerStyle}
+	}
+
+	var merges [][2]string
+	for _, span := range headerSpans(cols) {
+		topCells[span.Start] = excelize.Cell{StyleID: headerStyle, Value: span.Label}
+		start, _ := excelize.CoordinatesToCellName(span.Start+1, top)
+
+		if !span.Grouped {
+			end, _ := excelize.CoordinatesToCellName(span.Start+1, bottom)
+			merges = append(merges, [2]string{start, end})
+			continue
+		}
+
+		if span.End > span.Start {
+			end, _ := excelize.CoordinatesToCellName(span.End+1, top)
+			merges = append(merges, [2]string{start, end})
+		}
+		for c := span.Start; c <= span.End; c++ {
+			bottomCells[c] = excelize.Cell{StyleID: headerStyle, Value: cols[c].Title}
+		}
+	}
+
+	topCell, _ := excelize.CoordinatesToCellName(1, top)
+	if err := sw.SetRow(topCell, topCells); err != nil {
+		return err
+	}
+	bottomCell, _ := excelize.CoordinatesToCellName(1, bottom)
+	if err := sw.SetRow(bottomCell, bottomCells); err != nil {
+		return err
+	}
+	for _, m := range merges {
+		if err := sw.MergeCell(m[0], m[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
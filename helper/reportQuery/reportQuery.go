@@ -0,0 +1,206 @@
+package reportQuery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Alias adalah alias derived table yang dipakai Apply dan OrderBy.
+// Ekspresi di Columns harus ditulis dengan alias ini (mis. "rq.selisih").
+const Alias = "rq"
+
+// DefaultOrder dipakai bila tidak ada parameter sort agar urutan (dan pagination) stabil.
+const DefaultOrder = "rq.item_code, rq.location_code"
+
+// Column adalah satu kolom laporan yang boleh dipakai untuk sort/filter.
+type Column struct {
+	Expr    string // ekspresi SQL di atas alias rq
+	Numeric bool   // true → boleh dipakai untuk threshold min_/max_
+}
+
+// Columns adalah whitelist kolom per laporan: nama publik (sesuai json) → ekspresi SQL.
+type Columns map[string]Column
+
+// Names mengembalikan nama kolom terurut, untuk pesan error.
+func (c Columns) Names() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NumericNames mengembalikan nama kolom numerik terurut.
+func (c Columns) NumericNames() []string {
+	names := []string{}
+	for _, name := range c.Names() {
+		if c[name].Numeric {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// Threshold membatasi nilai kolom numerik secara inklusif (Min <= kolom <= Max).
+type Threshold struct {
+	Column string
+	Min    *decimal.Decimal
+	Max    *decimal.Decimal
+}
+
+// Options adalah sort dan filter tambahan yang berlaku di atas hasil query laporan.
+type Options struct {
+	Sort          []SortField
+	ItemTypeCode  string
+	UnitCode      string
+	NonZeroOnly   bool // hanya baris dengan selisih <> 0
+	NegativeAkhir bool // hanya baris dengan akhir < 0
+	Thresholds    []Threshold
+}
+
+// ParseSort mengurai "-selisih,item_code" menjadi SortField; prefix "-" = descending.
+func ParseSort(value string, cols Columns) ([]SortField, error) {
+	fields := []SortField{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field := SortField{Column: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if _, ok := cols[field.Column]; !ok {
+			return nil, fmt.Errorf("unknown sort column %q (allowed: %s)", field.Column, strings.Join(cols.Names(), ", "))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Validate memastikan semua kolom yang dipakai Options ada di whitelist laporan.
+func (o Options) Validate(cols Columns) error {
+	for _, s := range o.Sort {
+		if _, ok := cols[s.Column]; !ok {
+			return fmt.Errorf("unknown sort column %q", s.Column)
+		}
+	}
+	if o.NonZeroOnly {
+		if _, ok := cols["selisih"]; !ok {
+			return fmt.Errorf("this report has no selisih column")
+		}
+	}
+	if o.NegativeAkhir {
+		if _, ok := cols["akhir"]; !ok {
+			return fmt.Errorf("this report has no akhir column")
+		}
+	}
+	for _, t := range o.Thresholds {
+		col, ok := cols[t.Column]
+		if !ok || !col.Numeric {
+			return fmt.Errorf("unknown numeric column %q (allowed: %s)", t.Column, strings.Join(cols.NumericNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// Apply membungkus baseQuery sebagai derived table rq dan menambahkan filter Options.
+// Argumen filter ditambahkan setelah args milik baseQuery.
+// Kolom yang tidak ada di whitelist diabaikan; panggil Validate lebih dulu di controller.
+func (o Options) Apply(baseQuery string, args []interface{}, cols Columns) (string, []interface{}) {
+	conditions := ""
+	out := append([]interface{}{}, args...)
+
+	if o.ItemTypeCode != "" {
+		conditions += " AND rq.item_type_code = ?"
+		out = append(out, o.ItemTypeCode)
+	}
+	if o.UnitCode != "" {
+		conditions += " AND rq.unit_code = ?"
+		out = append(out, o.UnitCode)
+	}
+	if col, ok := cols["selisih"]; ok && o.NonZeroOnly {
+		conditions += fmt.Sprintf(" AND %s <> 0", col.Expr)
+	}
+	if col, ok := cols["akhir"]; ok && o.NegativeAkhir {
+		conditions += fmt.Sprintf(" AND %s < 0", col.Expr)
+	}
+	for _, t := range o.Thresholds {
+		col, ok := cols[t.Column]
+		if !ok || !col.Numeric {
+			continue
+		}
+		if t.Min != nil {
+			conditions += fmt.Sprintf(" AND %s >= ?", col.Expr)
+			out = append(out, t.Min.String())
+		}
+		if t.Max != nil {
+			conditions += fmt.Sprintf(" AND %s <= ?", col.Expr)
+			out = append(out, t.Max.String())
+		}
+	}
+
+	if conditions == "" {
+		return fmt.Sprintf("SELECT rq.* FROM (%s) AS rq", baseQuery), out
+	}
+	return fmt.Sprintf("SELECT rq.* FROM (%s) AS rq WHERE 1 = 1%s", baseQuery, conditions), out
+}
+
+// OrderBy mengembalikan klausa " ORDER BY ..." untuk query luar yang memakai alias rq.
+// Bila tidak ada sort, defaultOrder dipakai (boleh kosong).
+func (o Options) OrderBy(cols Columns, defaultOrder string) string {
+	parts := []string{}
+	for _, s := range o.Sort {
+		col, ok := cols[s.Column]
+		if !ok {
+			continue
+		}
+		if s.Desc {
+			parts = append(parts, col.Expr+" DESC")
+		} else {
+			parts = append(parts, col.Expr+" ASC")
+		}
+	}
+	if len(parts) == 0 {
+		if defaultOrder == "" {
+			return ""
+		}
+		return " ORDER BY " + defaultOrder
+	}
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// Meta mengembalikan representasi Options untuk di-echo di meta response.
+func (o Options) Meta() map[string]interface{} {
+	sortParts := []string{}
+	for _, s := range o.Sort {
+		if s.Desc {
+			sortParts = append(sortParts, "-"+s.Column)
+		} else {
+			sortParts = append(sortParts, s.Column)
+		}
+	}
+	thresholds := map[string]interface{}{}
+	for _, t := range o.Thresholds {
+		if t.Min != nil {
+			thresholds["min_"+t.Column] = t.Min.String()
+		}
+		if t.Max != nil {
+			thresholds["max_"+t.Column] = t.Max.String()
+		}
+	}
+	return map[string]interface{}{
+		"sort":           strings.Join(sortParts, ","),
+		"item_type_code": o.ItemTypeCode,
+		"unit_code":      o.UnitCode,
+		"only_selisih":   o.NonZeroOnly,
+		"negative_akhir": o.NegativeAkhir,
+		"thresholds":     thresholds,
+	}
+}
@@ -0,0 +1,60 @@
+package reportQuery
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+var testColumns = Columns{
+	"item_code": {Expr: "rq.item_code"},
+	"akhir":     {Expr: "rq.akhir", Numeric: true},
+	"selisih":   {Expr: "rq.selisih", Numeric: true},
+}
+
+func TestParseSort_RejectsUnknownColumn(t *testing.T) {
+	if _, err := ParseSort("-selisih,item_name", testColumns); err == nil {
+		t.Fatal("expected error untuk kolom di luar whitelist")
+	}
+
+	fields, err := ParseSort("-selisih, item_code", testColumns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fields) != 2 || !fields[0].Desc || fields[0].Column != "selisih" || fields[1].Desc {
+		t.Errorf("unexpected sort fields: %+v", fields)
+	}
+}
+
+func TestApply_ArgsAppendedAfterBaseArgs(t *testing.T) {
+	minAkhir := decimal.NewFromInt(10)
+	opts := Options{
+		UnitCode:      "KG",
+		NonZeroOnly:   true,
+		NegativeAkhir: true,
+		Thresholds:    []Threshold{{Column: "akhir", Min: &minAkhir}},
+	}
+
+	query, args := opts.Apply("SELECT 1", []interface{}{"base"}, testColumns)
+
+	for _, want := range []string{"rq.unit_code = ?", "rq.selisih <> 0", "rq.akhir < 0", "rq.akhir >= ?"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query tidak mengandung %q: %s", want, query)
+		}
+	}
+	if len(args) != 3 || args[0] != "base" || args[1] != "KG" || args[2] != "10" {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestOrderBy_DefaultWhenNoSort(t *testing.T) {
+	if got := (Options{}).OrderBy(testColumns, DefaultOrder); got != " ORDER BY "+DefaultOrder {
+		t.Errorf("default order: got %q", got)
+	}
+
+	opts := Options{Sort: []SortField{{Column: "selisih", Desc: true}, {Column: "item_code"}}}
+	if got := opts.OrderBy(testColumns, DefaultOrder); got != " ORDER BY rq.selisih DESC, rq.item_code ASC" {
+		t.Errorf("order: got %q", got)
+	}
+}
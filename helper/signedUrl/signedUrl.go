@@ -0,0 +1,44 @@
+package signedUrl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// signedUrl membuat dan memverifikasi tanda tangan HMAC-SHA256 untuk URL download
+// yang bisa dibuka tanpa JWT (misalnya dari <a href> atau tab baru) selama belum kedaluwarsa.
+// Yang ditandatangani: id resource dan waktu kedaluwarsa (unix detik).
+
+var (
+	ErrInvalidSignature = errors.New("invalid download signature")
+	ErrExpired          = errors.New("download link has expired")
+)
+
+// Sign mengembalikan signature hex untuk id yang berlaku sampai expires.
+func Sign(key []byte, id string, expires time.Time) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id + "|" + strconv.FormatInt(expires.Unix(), 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify memeriksa query param expires (unix detik) dan signature terhadap id.
+func Verify(key []byte, id, expires, signature string, now time.Time) error {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	exp := time.Unix(unix, 0)
+
+	want := Sign(key, id, exp)
+	if !hmac.Equal([]byte(want), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	if now.After(exp) {
+		return ErrExpired
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package signedUrl
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	key := []byte("secret")
+	now := time.Unix(1_700_000_000, 0)
+	exp := now.Add(time.Hour)
+	expStr := strconv.FormatInt(exp.Unix(), 10)
+	sig := Sign(key, "job-1", exp)
+
+	if err := Verify(key, "job-1", expStr, sig, now); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if err := Verify(key, "job-2", expStr, sig, now); err != ErrInvalidSignature {
+		t.Errorf("signature untuk id lain harus ditolak, got %v", err)
+	}
+	if err := Verify([]byte("other"), "job-1", expStr, sig, now); err != ErrInvalidSignature {
+		t.Errorf("signature dengan key lain harus ditolak, got %v", err)
+	}
+	if err := Verify(key, "job-1", strconv.FormatInt(exp.Unix()+60, 10), sig, now); err != ErrInvalidSignature {
+		t.Errorf("expires yang diubah harus ditolak, got %v", err)
+	}
+	if err := Verify(key, "job-1", expStr, sig, exp.Add(time.Second)); err != ErrExpired {
+		t.Errorf("link kedaluwarsa harus ditolak, got %v", err)
+	}
+	if err := Verify(key, "job-1", "abc", sig, now); err != ErrInvalidSignature {
+		t.Errorf("expires tidak valid harus ditolak, got %v", err)
+	}
+}
@@ -1 +1,50 @@
 package middleware
+
+import (
+	"Bea-Cukai/repo/userRepository"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AdminLevel adalah nilai user.level untuk administrator.
+const AdminLevel = "admin"
+
+// Authorization membatasi endpoint untuk user dengan level tertentu.
+// Harus dipasang setelah Authentication(); level dibaca dari tabel user (bukan dari token)
+// agar perubahan level langsung berlaku.
+func Authorization(userRepo *userRepository.UserRepository, levels ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userData, ok := c.MustGet("userData").(jwt.MapClaims)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"message": "Invalid token",
+			})
+			return
+		}
+		id, _ := userData["id"].(string)
+
+		user, err := userRepo.GetUserById(id)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": "Access denied",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		for _, level := range levels {
+			if strings.EqualFold(user.Level, level) {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"message": "Access denied",
+			"error":   "user level " + user.Level + " is not allowed",
+		})
+	}
+}
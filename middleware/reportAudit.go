@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"Bea-Cukai/helper/apiresponse"
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/model"
+	"Bea-Cukai/service/reportAccessLogService"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditLogExportPath adalah export log akses itu sendiri; tidak dicatat ke log akses.
+const auditLogExportPath = "/admin/report-access-logs/export"
+
+// exportJobUserAgent menandai entry dari worker export job.
+const exportJobUserAgent = "export-job"
+
+// ReportAudit mencatat setiap GET laporan (/report/*, /auxiliary-material, /pabean-documents) dan setiap
+// GET .../export: user, jenis laporan, filter, jumlah baris, durasi dan IP.
+// Dipasang global (app.Use) sebelum route didaftarkan; request lain diteruskan tanpa dicatat.
+//
+// Export di background dicatat sekali, dari replay worker export job (POST /exports dan download
+// tidak dicatat). Replay dikenali dari Requester di context request, bukan dari header X-Request-ID
+// yang bisa dikirim client, dan diberi export_job_id, IP client saat job dibuat dan user agent
+// exportJobUserAgent.
+func ReportAudit(svc *reportAccessLogService.ReportAccessLogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if c.Request.Method != http.MethodGet || !isAuditedPath(path) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		entry := model.ReportAccessLog{
+			Action:     model.ReportActionView,
+			ReportType: reportType(path),
+			Path:       c.Request.URL.Path,
+			Filters:    map[string]string{},
+			StatusCode: c.Writer.Status(),
+			DurationMs: time.Since(start).Milliseconds(),
+			DocumentId: c.Writer.Header().Get("X-Document-Id"),
+			IpAddress:  c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+		}
+		if strings.HasSuffix(path, "/export") {
+			entry.Action = model.ReportActionExport
+		}
+		for k, v := range c.Request.URL.Query() {
+			if k == "format" {
+				entry.Format = strings.Join(v, ",")
+				continue
+			}
+			entry.Filters[k] = strings.Join(v, ",")
+		}
+		if entry.Action == model.ReportActionExport && entry.Format == "" {
+			entry.Format = string(reportExport.FormatXLSX)
+		}
+		if n, ok := apiresponse.RowCount(c); ok {
+			entry.RowCount = &n
+		}
+
+		r := reportExport.RequesterOf(c)
+		entry.UserId, entry.Username, entry.ExportJobId = r.Id, r.Username, r.ExportJobId
+		if r.ExportJobId != "" {
+			entry.IpAddress, entry.UserAgent = r.IpAddress, exportJobUserAgent
+		}
+
+		svc.Record(entry)
+	}
+}
+
+func isAuditedPath(path string) bool {
+	if path == auditLogExportPath {
+		return false
+	}
+	return strings.HasPrefix(path, "/report/") ||
+		strings.HasPrefix(path, "/auxiliary-material") ||
+		strings.HasPrefix(path, "/pabean-documents") ||
+		strings.HasSuffix(path, "/export")
+}
+
+// reportType menurunkan jenis laporan dari route: /report/raw-material/export -> raw-material,
+// /pabean-documents/:jenis/:no -> pabean-documents.
+func reportType(path string) string {
+	if i := strings.Index(path, "/:"); i >= 0 {
+		path = path[:i]
+	}
+	path = strings.TrimSuffix(path, "/export")
+	path = strings.TrimPrefix(path, "/report/")
+	return strings.Trim(path, "/")
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"Bea-Cukai/helper"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Default batas waktu laporan bila env tidak diisi.
+const (
+	defaultReportTimeout       = 60 * time.Second
+	defaultReportExportTimeout = 5 * time.Minute
+)
+
+// ReportTimeout memasang deadline pada context request laporan. Context ini diteruskan
+// controller → service → repository, sehingga query dibatalkan (dan di-KILL di MySQL, lihat
+// helper/queryGuard) bila client memutus koneksi atau batas waktu terlewati; controller lalu
+// menjawab 504 QUERY_TIMEOUT.
+//
+//	REPORT_TIMEOUT_SECONDS                  default semua laporan (default 60)
+//	REPORT_TIMEOUT_<REPORT>_SECONDS         per laporan, mis. REPORT_TIMEOUT_RAW_MATERIAL_SECONDS
+//	REPORT_EXPORT_TIMEOUT_SECONDS           minimal untuk GET .../export (default 300)
+//
+// 0 = tanpa batas waktu (context tetap dibatalkan saat client memutus koneksi).
+// Request yang context-nya sudah punya deadline (export job di background, lihat
+// exportJobService) memakai deadline tersebut.
+func ReportTimeout(report string) gin.HandlerFunc {
+	key := "REPORT_TIMEOUT_" + strings.ToUpper(strings.NewReplacer("-", "_", "/", "_").Replace(report)) + "_SECONDS"
+	view := envSeconds(key, envSeconds("REPORT_TIMEOUT_SECONDS", defaultReportTimeout))
+	export := envSeconds("REPORT_EXPORT_TIMEOUT_SECONDS", defaultReportExportTimeout)
+	if view == 0 {
+		export = 0
+	} else if export != 0 {
+		export = max(export, view)
+	}
+
+	return func(c *gin.Context) {
+		timeout := view
+		if strings.HasSuffix(c.FullPath(), "/export") {
+			timeout = export
+		}
+		if _, ok := c.Request.Context().Deadline(); ok || timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func envSeconds(key string, def time.Duration) time.Duration {
+	if v, err := strconv.Atoi(helper.GetEnv(key)); err == nil && v >= 0 {
+		return time.Duration(v) * time.Second
+	}
+	return def
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// CompanyProfileId - tabel company_profile hanya berisi satu baris.
+const CompanyProfileId = 1
+
+// CompanyProfile adalah identitas Kawasan Berikat yang dicetak di kop dan blok tanda tangan export Excel.
+type CompanyProfile struct {
+	Id             int       `json:"id" gorm:"primaryKey;column:id"`
+	CompanyName    string    `json:"company_name" gorm:"column:company_name;not null"`
+	Npwp           string    `json:"npwp" gorm:"column:npwp;not null"`
+	KbLicenseNo    string    `json:"kb_license_no" gorm:"column:kb_license_no"`
+	Address        string    `json:"address" gorm:"column:address;not null"`
+	City           string    `json:"city" gorm:"column:city"`
+	SignatoryName  string    `json:"signatory_name" gorm:"column:signatory_name"`
+	SignatoryTitle string    `json:"signatory_title" gorm:"column:signatory_title"`
+	UpdatedBy      string    `json:"updated_by" gorm:"column:updated_by"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (CompanyProfile) TableName() string {
+	return "company_profile"
+}
+
+// DefaultCompanyProfile dipakai bila tabel company_profile belum diisi (nilai kop lama).
+func DefaultCompanyProfile() CompanyProfile {
+	return CompanyProfile{
+		Id:          CompanyProfileId,
+		CompanyName: "PT FUKUSUKE KOGYO INDONESIA",
+		Npwp:        "01.071.250.3-052.000",
+		Address:     "Blok M-3-2, Kawasan MM2100, Cikarang Barat, Bekasi, 17520",
+		City:        "Bekasi",
+	}
+}
+
+type CompanyProfileRequest struct {
+	CompanyName    string `json:"company_name" validate:"required"`
+	Npwp           string `json:"npwp" validate:"required"`
+	KbLicenseNo    string `json:"kb_license_no"`
+	Address        string `json:"address" validate:"required"`
+	City           string `json:"city"`
+	SignatoryName  string `json:"signatory_name" validate:"required"`
+	SignatoryTitle string `json:"signatory_title" validate:"required"`
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Report types yang mendukung pengecekan kesinambungan saldo.
+// Keduanya menurunkan saldo awal dari opname terakhir + mutasi setelah opname.
+const (
+	ContinuityReportRawMaterial     = "raw-material"
+	ContinuityReportFinishedProduct = "finished-product"
+)
+
+// PeriodBalance adalah saldo awal/akhir satu item pada satu periode.
+type PeriodBalance struct {
+	ItemCode string
+	ItemName string
+	UnitCode string
+	Awal     decimal.Decimal
+	Akhir    decimal.Decimal
+}
+
+type ContinuityCheckRequest struct {
+	ReportType string    `json:"type" validate:"required"`
+	FromMonth  time.Time `json:"from" validate:"required"` // hari pertama bulan awal
+	ToMonth    time.Time `json:"to" validate:"required"`   // hari pertama bulan akhir
+	ItemCode   string    `json:"item_code"`
+	ItemName   string    `json:"item_name"`
+}
+
+// ContinuityBreak mencatat satu item dimana akhir(N) ≠ awal(N+1).
+type ContinuityBreak struct {
+	ItemCode       string          `json:"item_code"`
+	ItemName       string          `json:"item_name"`
+	UnitCode       string          `json:"unit_code"`
+	Period         string          `json:"period"`      // YYYY-MM (periode N)
+	PeriodFrom     string          `json:"period_from"` // YYYY-MM-DD
+	PeriodTo       string          `json:"period_to"`   // YYYY-MM-DD
+	OpnameDate     string          `json:"opname_date"` // opname dasar saldo periode N
+	Akhir          decimal.Decimal `json:"akhir"`
+	NextPeriod     string          `json:"next_period"`
+	NextPeriodFrom string          `json:"next_period_from"`
+	NextPeriodTo   string          `json:"next_period_to"`
+	NextOpnameDate string          `json:"next_opname_date"` // opname dasar saldo periode N+1
+	NextAwal       decimal.Decimal `json:"next_awal"`
+	Selisih        decimal.Decimal `json:"selisih"` // next_awal - akhir
+}
+
+type ContinuityCheckResponse struct {
+	ReportType     string            `json:"type"`
+	From           string            `json:"from"`
+	To             string            `json:"to"`
+	PeriodsChecked int               `json:"periods_checked"`
+	BreakCount     int               `json:"break_count"`
+	Breaks         []ContinuityBreak `json:"breaks"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+// CustomsSummary adalah satu kelompok ringkasan dokumen pabean (pemasukan / pengeluaran barang).
+// Key adalah nilai pengelompokan (mis. BC 2.3, 2026-09, kode vendor); Label teks tampilannya.
+type CustomsSummary struct {
+	Key           string          `json:"key"`
+	Label         string          `json:"label"`
+	DocumentCount int64           `json:"document_count"`
+	LineCount     int64           `json:"line_count"`
+	Quantities    []QuantityTotal `json:"quantities"`
+	Amounts       []AmountTotal   `json:"amounts"`
+}
+
+// QuantityTotal adalah total kuantitas dalam satu satuan; kuantitas beda satuan tidak dijumlahkan.
+type QuantityTotal struct {
+	Unit     string          `json:"unit"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
+
+// AmountTotal adalah total nilai (NetAmount) dalam satu mata uang.
+type AmountTotal struct {
+	CurrCode string          `json:"curr_code"`
+	Amount   decimal.Decimal `json:"amount"`
+}
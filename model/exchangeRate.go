@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BaseCurrency adalah mata uang pelaporan; nilai dalam IDR dikonversi dengan kurs 1.
+const BaseCurrency = "IDR"
+
+// ExchangeRate adalah kurs satu mata uang ke IDR (mis. NDPBM mingguan) yang berlaku
+// valid_from s.d valid_to; valid_to kosong = berlaku sampai ada kurs yang lebih baru.
+type ExchangeRate struct {
+	Id        int             `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	CurrCode  string          `json:"curr_code" gorm:"column:curr_code;not null"`
+	Rate      decimal.Decimal `json:"rate" gorm:"column:rate;type:decimal(20,6);not null"` // IDR per 1 unit mata uang
+	ValidFrom time.Time       `json:"valid_from" gorm:"column:valid_from;type:date;not null"`
+	ValidTo   *time.Time      `json:"valid_to" gorm:"column:valid_to;type:date"`
+	Source    string          `json:"source" gorm:"column:source"`
+	Notes     string          `json:"notes" gorm:"column:notes"`
+	CreatedBy string          `json:"created_by" gorm:"column:created_by"`
+	UpdatedBy string          `json:"updated_by" gorm:"column:updated_by"`
+	CreatedAt time.Time       `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time       `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (ExchangeRate) TableName() string {
+	return "exchange_rate"
+}
+
+type ExchangeRateRequest struct {
+	CurrCode  string          `json:"curr_code" validate:"required,len=3"`
+	Rate      decimal.Decimal `json:"rate"`
+	ValidFrom string          `json:"valid_from" validate:"required"` // YYYY-MM-DD
+	ValidTo   string          `json:"valid_to"`                       // YYYY-MM-DD, opsional
+	Source    string          `json:"source"`
+	Notes     string          `json:"notes"`
+}
+
+type ExchangeRateListRequest struct {
+	CurrCode string `json:"curr_code" form:"curr_code"`
+	Date     string `json:"date" form:"date"` // kurs yang berlaku pada tanggal ini (YYYY-MM-DD)
+	Source   string `json:"source" form:"source"`
+	Page     int    `json:"page" form:"page"`
+	Limit    int    `json:"limit" form:"limit"`
+}
+
+// ExchangeRateImportError adalah satu baris CSV yang ditolak (Line = nomor baris file, header = 1).
+type ExchangeRateImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ExchangeRateImportResult struct {
+	Created int                       `json:"created"`
+	Updated int                       `json:"updated"`
+	Errors  []ExchangeRateImportError `json:"errors"`
+}
+
+// CurrencyTotal adalah total nilai laporan dalam satu mata uang beserta konversinya ke IDR.
+// AmountIdr hanya menjumlahkan baris yang punya kurs; MissingRateCount = baris tanpa kurs.
+type CurrencyTotal struct {
+	CurrCode         string          `json:"curr_code"`
+	LineCount        int64           `json:"line_count"`
+	Amount           decimal.Decimal `json:"amount"`
+	AmountIdr        decimal.Decimal `json:"amount_idr"`
+	MissingRateCount int64           `json:"missing_rate_count"`
+}
+
+// IdrTotal adalah total seluruh mata uang dalam IDR; Complete = false bila ada baris tanpa kurs
+// (nilainya tidak ikut dijumlahkan).
+type IdrTotal struct {
+	Amount           decimal.Decimal `json:"amount"`
+	MissingRateCount int64           `json:"missing_rate_count"`
+	Complete         bool            `json:"complete"`
+}
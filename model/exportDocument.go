@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// ExportDocument adalah register file export bertanda tangan: Document ID yang ditanam di file
+// dan SHA-256 isi file, untuk membuktikan file yang diserahkan ke auditor belum diubah.
+type ExportDocument struct {
+	Id            string            `json:"id" gorm:"primaryKey;column:id"`
+	Sha256        string            `json:"sha256" gorm:"column:sha256;not null"`
+	FileName      string            `json:"file_name" gorm:"column:file_name"`
+	FileSize      int64             `json:"file_size" gorm:"column:file_size"`
+	Format        string            `json:"format" gorm:"column:format;not null"`
+	ReportPath    string            `json:"report_path" gorm:"column:report_path;not null"`
+	Filters       map[string]string `json:"filters" gorm:"column:filters;serializer:json"`
+	ExportJobId   string            `json:"export_job_id,omitempty" gorm:"column:export_job_id"`
+	RequestedById string            `json:"requested_by_id" gorm:"column:requested_by_id"`
+	RequestedBy   string            `json:"requested_by" gorm:"column:requested_by"`
+	IpAddress     string            `json:"ip_address" gorm:"column:ip_address"`
+	CreatedAt     time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (ExportDocument) TableName() string {
+	return "export_document"
+}
+
+// Hasil verifikasi POST /exports/verify.
+const (
+	ExportVerifyMatch    = "match"    // hash terdaftar: file identik dengan yang dihasilkan
+	ExportVerifyModified = "modified" // Document ID terdaftar tapi hash berbeda: file sudah diubah
+	ExportVerifyUnknown  = "unknown"  // hash maupun Document ID tidak terdaftar
+)
+
+// ExportVerifyResponse adalah hasil verifikasi file yang diunggah.
+type ExportVerifyResponse struct {
+	Valid      bool            `json:"valid"`
+	Result     string          `json:"result"`
+	Sha256     string          `json:"sha256"`
+	FileSize   int64           `json:"file_size"`
+	DocumentId string          `json:"document_id,omitempty"` // Document ID yang terbaca di file / dikirim user
+	Document   *ExportDocument `json:"document,omitempty"`    // export terdaftar yang cocok
+}
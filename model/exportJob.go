@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// Status export job.
+const (
+	ExportStatusQueued  = "queued"
+	ExportStatusRunning = "running"
+	ExportStatusDone    = "done"
+	ExportStatusFailed  = "failed"
+	ExportStatusExpired = "expired" // file sudah dihapus oleh retention cleanup
+)
+
+// ExportJob adalah satu permintaan export laporan yang dikerjakan di background.
+// Record tetap disimpan setelah file kedaluwarsa sebagai jejak siapa meminta export apa.
+type ExportJob struct {
+	Id               string            `json:"id" gorm:"primaryKey;column:id"`
+	ReportType       string            `json:"report_type" gorm:"column:report_type;not null"`
+	Format           string            `json:"format" gorm:"column:format;not null"`
+	Filters          map[string]string `json:"filters" gorm:"column:filters;serializer:json"`
+	Status           string            `json:"status" gorm:"column:status;not null"`
+	FileName         string            `json:"file_name" gorm:"column:file_name"`
+	FilePath         string            `json:"-" gorm:"column:file_path"`
+	FileSize         int64             `json:"file_size" gorm:"column:file_size"`
+	DocumentId       string            `json:"document_id,omitempty" gorm:"column:document_id"` // lihat ExportDocument
+	Error            string            `json:"error,omitempty" gorm:"column:error"`
+	RequestedById    string            `json:"requested_by_id" gorm:"column:requested_by_id;not null"`
+	RequestedBy      string            `json:"requested_by" gorm:"column:requested_by"`
+	IpAddress        string            `json:"ip_address" gorm:"column:ip_address"`
+	DownloadCount    int               `json:"download_count" gorm:"column:download_count"`
+	LastDownloadedAt *time.Time        `json:"last_downloaded_at" gorm:"column:last_downloaded_at"`
+	StartedAt        *time.Time        `json:"started_at" gorm:"column:started_at"`
+	FinishedAt       *time.Time        `json:"finished_at" gorm:"column:finished_at"`
+	ExpiresAt        *time.Time        `json:"expires_at" gorm:"column:expires_at"`
+	CreatedAt        time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time         `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (ExportJob) TableName() string {
+	return "export_job"
+}
+
+// ExportJobRequest adalah body POST /exports. Filters berisi query param yang sama dengan
+// endpoint export laporan (from, to, item_code, ...), tanpa format.
+type ExportJobRequest struct {
+	ReportType string            `json:"report_type" validate:"required"`
+	Format     string            `json:"format"`
+	Filters    map[string]string `json:"filters"`
+}
+
+type ExportJobListRequest struct {
+	ReportType string `json:"report_type" form:"report_type"`
+	Status     string `json:"status" form:"status"`
+	Page       int    `json:"page" form:"page"`
+	Limit      int    `json:"limit" form:"limit"`
+}
+
+// ExportJobResponse menambahkan signed download URL untuk job yang sudah selesai.
+type ExportJobResponse struct {
+	ExportJob
+	DownloadUrl          string     `json:"download_url,omitempty"`
+	DownloadUrlExpiresAt *time.Time `json:"download_url_expires_at,omitempty"`
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Lot adalah satu baris penerimaan bahan baku (tr_ap_inv_det, kunci data_no) beserta dokumen
+// pabean pemasukannya (tr_pemasukan_barang, lewat trans_no + item_code) dan pemakaiannya
+// (tr_inv_rm_det.data_no) sampai tanggal AsOf.
+type Lot struct {
+	DataNo     string    `json:"data_no" gorm:"column:data_no"`
+	TransNo    string    `json:"trans_no" gorm:"column:trans_no"`
+	InDate     time.Time `json:"in_date" gorm:"column:in_date"`
+	ItemCode   string    `json:"item_code" gorm:"column:item_code"`
+	ItemName   string    `json:"item_name" gorm:"column:item_name"`
+	ItemGroup  string    `json:"item_group" gorm:"column:item_group"`
+	Unit       string    `json:"unit" gorm:"column:unit"`
+	VendorName string    `json:"vendor_name" gorm:"column:vendor_name"`
+
+	// Dokumen pabean pemasukan; kosong bila penerimaan tidak punya dokumen.
+	JenisPabean string     `json:"jenis_pabean" gorm:"column:jenis_pabean"`
+	NoPabean    string     `json:"no_pabean" gorm:"column:no_pabean"`
+	TglPabean   *time.Time `json:"tgl_pabean" gorm:"column:tgl_pabean"`
+
+	ReceivedQty  decimal.Decimal `json:"received_qty" gorm:"column:received_qty"`
+	ConsumedQty  decimal.Decimal `json:"consumed_qty" gorm:"column:consumed_qty"`
+	RemainingQty decimal.Decimal `json:"remaining_qty" gorm:"column:remaining_qty"`
+
+	Consumptions []LotConsumption `json:"consumptions,omitempty" gorm:"-"`
+}
+
+// LotConsumption adalah satu pemakaian bahan baku (tr_inv_rm_head/det) dari sebuah lot.
+type LotConsumption struct {
+	DataNo    string          `json:"-" gorm:"column:data_no"`
+	TransNo   string          `json:"trans_no" gorm:"column:trans_no"`
+	TransDate time.Time       `json:"trans_date" gorm:"column:trans_date"`
+	Qty       decimal.Decimal `json:"qty" gorm:"column:qty"`
+}
+
+// LotOrigin adalah asal stok satu item pada suatu tanggal: lot-lot yang masih bersisa, urut
+// penerimaan (FIFO), dan total sisanya.
+type LotOrigin struct {
+	ItemCode     string          `json:"item_code"`
+	Date         string          `json:"date"`
+	RemainingQty decimal.Decimal `json:"remaining_qty"`
+	Lots         []Lot           `json:"lots"`
+}
+
+// Kelompok umur lot (hari sejak tanggal penerimaan sampai tanggal posisi).
+const (
+	AgingBucket0To90    = "0-90"
+	AgingBucket91To180  = "91-180"
+	AgingBucket181To365 = "181-365"
+	AgingBucketOver365  = ">365"
+)
+
+// AgingBuckets adalah urutan kelompok umur untuk ringkasan dan export.
+var AgingBuckets = []string{AgingBucket0To90, AgingBucket91To180, AgingBucket181To365, AgingBucketOver365}
+
+// LotAging adalah lot bahan baku impor yang masih bersisa pada tanggal posisi beserta umur dan
+// nilainya dalam IDR. Harga satuan = net_amount / rcv_qty dokumen pemasukan; nilai IDR memakai
+// kurs pada tgl_pabean (null bila harga atau kurs tidak ada).
+type LotAging struct {
+	Lot
+
+	AgeDays     int    `json:"age_days" gorm:"-"`
+	AgingBucket string `json:"aging_bucket" gorm:"-"`
+
+	CurrCode          string           `json:"curr_code" gorm:"column:curr_code"`
+	UnitPrice         *decimal.Decimal `json:"unit_price" gorm:"column:unit_price"`
+	IdrRate           *decimal.Decimal `json:"idr_rate" gorm:"column:idr_rate"`
+	RemainingValueIdr *decimal.Decimal `json:"remaining_value_idr" gorm:"column:remaining_value_idr"`
+	RateMissing       bool             `json:"rate_missing" gorm:"column:rate_missing"`
+}
+
+// AgingAmounts adalah satu nilai (qty atau IDR) yang dipecah per kelompok umur.
+type AgingAmounts struct {
+	Days0To90    decimal.Decimal `json:"0_90"`
+	Days91To180  decimal.Decimal `json:"91_180"`
+	Days181To365 decimal.Decimal `json:"181_365"`
+	Over365      decimal.Decimal `json:"over_365"`
+	Total        decimal.Decimal `json:"total"`
+}
+
+// AgingDocument adalah umur sisa bahan baku per dokumen pabean pemasukan. Qty dijumlahkan lintas
+// item (bisa berbeda satuan); nilai IDR tidak termasuk lot tanpa harga / kurs (MissingValueCount).
+type AgingDocument struct {
+	JenisPabean       string       `json:"jenis_pabean"`
+	NoPabean          string       `json:"no_pabean"`
+	TglPabean         *time.Time   `json:"tgl_pabean"`
+	VendorName        string       `json:"vendor_name"`
+	LotCount          int          `json:"lot_count"`
+	OldestInDate      time.Time    `json:"oldest_in_date"`
+	MaxAgeDays        int          `json:"max_age_days"`
+	RemainingQty      AgingAmounts `json:"remaining_qty"`
+	RemainingValueIdr AgingAmounts `json:"remaining_value_idr"`
+	MissingValueCount int          `json:"missing_value_count"`
+}
+
+// AgingBucketSummary adalah jumlah lot dan nilai sisa IDR dalam satu kelompok umur.
+type AgingBucketSummary struct {
+	Bucket            string          `json:"bucket"`
+	LotCount          int             `json:"lot_count"`
+	RemainingValueIdr decimal.Decimal `json:"remaining_value_idr"`
+	MissingValueCount int             `json:"missing_value_count"`
+}
+
+// AgingSummary adalah ringkasan laporan umur per kelompok; Complete = false bila ada lot tanpa
+// nilai IDR.
+type AgingSummary struct {
+	LotCount          int                  `json:"lot_count"`
+	RemainingValueIdr decimal.Decimal      `json:"remaining_value_idr"`
+	MissingValueCount int                  `json:"missing_value_count"`
+	Complete          bool                 `json:"complete"`
+	Buckets           []AgingBucketSummary `json:"buckets"`
+}
@@ -9,6 +9,12 @@ type MsPabean struct {
 	PabeanCode  string    `json:"pabean_code" gorm:"column:pabean_code;type:varchar(255);not null"`
 	PabeanName  string    `json:"pabean_name" gorm:"column:pabean_name;type:varchar(255);not null"`
 	Notes       string    `json:"notes" gorm:"type:text"`
+
+	// Aturan dokumen untuk validasi laporan pemasukan / pengeluaran.
+	Direction     string `json:"direction" gorm:"column:direction;type:varchar(4)"`                  // IN, OUT atau BOTH; kosong = belum diatur
+	DutySuspended bool   `json:"duty_suspended" gorm:"column:duty_suspended;not null;default:false"` // barang mendapat penangguhan bea masuk / pajak impor
+	LpjColumns    string `json:"lpj_columns" gorm:"column:lpj_columns;type:varchar(255)"`            // kolom LPJ yang dipengaruhi, dipisah koma (lihat PabeanLpjColumns)
+
 	CreatedBy   string    `json:"created_by" gorm:"type:varchar(255)"`
 	CreatedDate time.Time `json:"created_date" gorm:"type:datetime"`
 	UpdatedBy   string    `json:"updated_by" gorm:"type:varchar(255)"`
@@ -19,19 +25,57 @@ type MsPabean struct {
 func (MsPabean) TableName() string { return "ms_pabean" }
 
 type MsPabeanRequest struct {
-	PabeanCode string `json:"pabean_code" validate:"required"`
-	PabeanName string `json:"pabean_name" validate:"required"`
-	Notes      string `json:"notes"`
-	CreatedBy  string `json:"created_by"`
-	UpdatedBy  string `json:"updated_by"`
+	PabeanCode    string   `json:"pabean_code" validate:"required"`
+	PabeanName    string   `json:"pabean_name" validate:"required"`
+	Notes         string   `json:"notes"`
+	Direction     string   `json:"direction"`
+	DutySuspended bool     `json:"duty_suspended"`
+	LpjColumns    []string `json:"lpj_columns"`
+	CreatedBy     string   `json:"created_by"`
+	UpdatedBy     string   `json:"updated_by"`
 }
 
 type MsPabeanResponse struct {
-	PabeanCode  string    `json:"pabean_code"`
-	PabeanName  string    `json:"pabean_name"`
-	Notes       string    `json:"notes"`
+	PabeanCode    string    `json:"pabean_code"`
+	PabeanName    string    `json:"pabean_name"`
+	Notes         string    `json:"notes"`
+	Direction     string    `json:"direction"`
+	DutySuspended bool      `json:"duty_suspended"`
+	LpjColumns    string    `json:"lpj_columns"`
 	CreatedBy   string    `json:"created_by"`
 	CreatedDate time.Time `json:"created_date"`
 	UpdatedBy   string    `json:"updated_by"`
 	UpdatedDate time.Time `json:"updated_date"`
 }
+
+// Arah dokumen pabean terhadap kawasan berikat.
+const (
+	PabeanDirectionIn   = "IN"
+	PabeanDirectionOut  = "OUT"
+	PabeanDirectionBoth = "BOTH" // mis. BC 2.7 (antar TPB) dipakai untuk pemasukan dan pengeluaran
+)
+
+var PabeanDirections = []string{PabeanDirectionIn, PabeanDirectionOut, PabeanDirectionBoth}
+
+// PabeanLpjColumns adalah kolom mutasi LPJ (laporan pertanggungjawaban) yang bisa dipengaruhi dokumen.
+var PabeanLpjColumns = []string{
+	"BAHAN_BAKU_MASUK", "BAHAN_BAKU_KELUAR",
+	"BAHAN_PENOLONG_MASUK", "BAHAN_PENOLONG_KELUAR",
+	"BARANG_JADI_MASUK", "BARANG_JADI_KELUAR",
+	"MESIN_MASUK", "MESIN_KELUAR",
+	"SCRAP_MASUK", "SCRAP_KELUAR",
+}
+
+// Hasil validasi jenis_pabean baris laporan terhadap ms_pabean (kolom pabean_status).
+const (
+	PabeanStatusOk             = "OK"
+	PabeanStatusUnknown        = "UNKNOWN"         // jenis_pabean tidak ada di master
+	PabeanStatusWrongDirection = "WRONG_DIRECTION" // dokumen keluar di laporan pemasukan atau sebaliknya
+)
+
+// PabeanValidationCount adalah jumlah baris laporan per jenis_pabean yang gagal validasi.
+type PabeanValidationCount struct {
+	JenisPabean string `json:"jenis_pabean" gorm:"column:jenis_pabean"`
+	Status      string `json:"status" gorm:"column:status"`
+	LineCount   int64  `json:"line_count" gorm:"column:line_count"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Arah dokumen pabean: pemasukan (tr_pemasukan_barang) atau pengeluaran (tr_pengeluaran_barang).
+const (
+	PabeanDirectionInbound  = "inbound"
+	PabeanDirectionOutbound = "outbound"
+)
+
+// PabeanDocumentKey mengidentifikasi satu dokumen pabean (baris-baris dengan jenis dan nomor yang sama).
+type PabeanDocumentKey struct {
+	Direction   string    `json:"direction"`
+	JenisPabean string    `json:"jenis_pabean"`
+	NoPabean    string    `json:"no_pabean"`
+	TglPabean   time.Time `json:"tgl_pabean"`
+}
+
+// PabeanDocument adalah header satu dokumen pabean beserta total barisnya.
+// Lines hanya diisi di tampilan detail dan export.
+type PabeanDocument struct {
+	Direction       string               `json:"direction"`
+	JenisPabean     string               `json:"jenis_pabean"`
+	NoPabean        string               `json:"no_pabean"`
+	TglPabean       time.Time            `json:"tgl_pabean"`
+	CounterpartCode string               `json:"counterpart_code"` // vendor (inbound) / customer (outbound)
+	CounterpartName string               `json:"counterpart_name"`
+	TransNos        []string             `json:"trans_nos"`    // nomor bukti penerimaan / pengeluaran barang
+	DeliveryNos     []string             `json:"delivery_nos"` // surat jalan pengirim (inbound)
+	TransDate       *time.Time           `json:"trans_date"`   // tanggal bukti paling awal
+	LineCount       int                  `json:"line_count"`
+	Quantities      []QuantityTotal      `json:"quantities"`
+	Amounts         []AmountTotal        `json:"amounts"`
+	Lines           []PabeanDocumentLine `json:"lines,omitempty"`
+}
+
+// PabeanDocumentLine adalah satu baris barang dokumen pabean, kolomnya diseragamkan untuk
+// pemasukan dan pengeluaran (Qty = rcv_qty / dlv_qty, Unit = pch_unit / sales_unit).
+type PabeanDocumentLine struct {
+	JenisPabean     string          `json:"-"`
+	NoPabean        string          `json:"-"`
+	TglPabean       time.Time       `json:"-"`
+	CounterpartCode string          `json:"-"`
+	CounterpartName string          `json:"-"`
+	TransNo         string          `json:"trans_no"`
+	DeliveryNo      string          `json:"delivery_no"`
+	TransDate       time.Time       `json:"trans_date"`
+	ItemCode        string          `json:"item_code"`
+	ItemName        string          `json:"item_name"`
+	ItemGroup       string          `json:"item_group"`
+	Qty             decimal.Decimal `json:"qty"`
+	Unit            string          `json:"unit"`
+	CurrCode        string          `json:"curr_code"`
+	NetPrice        decimal.Decimal `json:"net_price"`
+	NetAmount       decimal.Decimal `json:"net_amount"`
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Status rekonsiliasi satu baris dokumen pabean terhadap mutasi gudang. Baris inbound yang
+// selisih kuantitas dan tanggalnya sekaligus berstatus QTY_MISMATCH (DateMismatch tetap true).
+const (
+	ReconciliationMatched      = "MATCHED"
+	ReconciliationNotReceived  = "NOT_RECEIVED" // ada di dokumen pemasukan, tidak ada penerimaan gudang
+	ReconciliationNotShipped   = "NOT_SHIPPED"  // ada di dokumen pengeluaran, tidak ada ekspor / pengiriman
+	ReconciliationNoDocument   = "NO_DOCUMENT"  // ada mutasi gudang, tidak ada dokumen pabean
+	ReconciliationQtyMismatch  = "QTY_MISMATCH"
+	ReconciliationDateMismatch = "DATE_MISMATCH"
+)
+
+// InboundReconciliationStatuses adalah status inbound yang valid untuk filter ?status=.
+var InboundReconciliationStatuses = []string{
+	ReconciliationMatched,
+	ReconciliationNotReceived,
+	ReconciliationNoDocument,
+	ReconciliationQtyMismatch,
+	ReconciliationDateMismatch,
+}
+
+// OutboundReconciliationStatuses adalah status outbound yang valid untuk filter ?status=
+// (outbound dicocokkan per item per periode, jadi tidak ada DATE_MISMATCH).
+var OutboundReconciliationStatuses = []string{
+	ReconciliationMatched,
+	ReconciliationNotShipped,
+	ReconciliationNoDocument,
+	ReconciliationQtyMismatch,
+}
+
+// ReconciliationOptions adalah toleransi pencocokan dan filter status hasil.
+type ReconciliationOptions struct {
+	QtyTolerance      decimal.Decimal // selisih kuantitas absolut yang masih dianggap cocok
+	DateToleranceDays int             // inbound: penerimaan paling lambat N hari setelah tgl_pabean
+	Status            []string        // kosong = semua status
+}
+
+// InboundReconciliationLine adalah satu trans_no + item: kuantitas di dokumen pabean pemasukan
+// (tr_pemasukan_barang) dibanding kuantitas yang diterima gudang (tr_ap_inv_head/det).
+type InboundReconciliationLine struct {
+	TransNo    string `json:"trans_no" gorm:"column:trans_no"`
+	ItemCode   string `json:"item_code" gorm:"column:item_code"`
+	ItemName   string `json:"item_name" gorm:"column:item_name"`
+	ItemGroup  string `json:"item_group" gorm:"column:item_group"`
+	Unit       string `json:"unit" gorm:"column:unit"`
+	VendorCode string `json:"vendor_code" gorm:"column:vendor_code"`
+	VendorName string `json:"vendor_name" gorm:"column:vendor_name"`
+
+	// Sisi dokumen pabean; HasDocument = false bila barang diterima tanpa dokumen.
+	HasDocument bool            `json:"has_document" gorm:"column:has_document"`
+	JenisPabean string          `json:"jenis_pabean" gorm:"column:jenis_pabean"`
+	NoPabean    string          `json:"no_pabean" gorm:"column:no_pabean"`
+	TglPabean   *time.Time      `json:"tgl_pabean" gorm:"column:tgl_pabean"`
+	DocQty      decimal.Decimal `json:"doc_qty" gorm:"column:doc_qty"`
+
+	// Sisi penerimaan gudang; HasReceipt = false bila belum diterima.
+	HasReceipt  bool            `json:"has_receipt" gorm:"column:has_receipt"`
+	InDate      *time.Time      `json:"in_date" gorm:"column:in_date"`
+	ReceivedQty decimal.Decimal `json:"received_qty" gorm:"column:received_qty"`
+
+	// Hasil pencocokan (diisi service).
+	Status          string          `json:"status" gorm:"-"`
+	QtyDiff         decimal.Decimal `json:"qty_diff" gorm:"-"`         // received_qty - doc_qty
+	NotReceivedQty  decimal.Decimal `json:"not_received_qty" gorm:"-"` // didokumenkan tapi tidak diterima
+	UndocumentedQty decimal.Decimal `json:"undocumented_qty" gorm:"-"` // diterima tanpa dokumen
+	DateDiffDays    *int            `json:"date_diff_days" gorm:"-"`   // in_date - tgl_pabean
+	QtyMismatch     bool            `json:"qty_mismatch" gorm:"-"`
+	DateMismatch    bool            `json:"date_mismatch" gorm:"-"`
+}
+
+// OutboundReconciliationLine adalah satu item (kode dinormalisasi, prefix "1" dibuang) dalam satu
+// periode: kuantitas di dokumen pabean pengeluaran (tr_pengeluaran_barang, tgl_pabean) dibanding
+// kuantitas ekspor (tr_export_head/det, tgl_ekspor) ditambah pengiriman lain (tr_inv_moveout_head/det).
+type OutboundReconciliationLine struct {
+	ItemCode    string `json:"item_code" gorm:"column:item_code"`
+	ItemName    string `json:"item_name" gorm:"column:item_name"`
+	ItemGroup   string `json:"item_group" gorm:"column:item_group"`
+	Unit        string `json:"unit" gorm:"column:unit"`
+	JenisPabean string `json:"jenis_pabean" gorm:"column:jenis_pabean"` // jenis dokumen, dipisah koma
+
+	DocCount     int             `json:"doc_count" gorm:"column:doc_count"`
+	DocQty       decimal.Decimal `json:"doc_qty" gorm:"column:doc_qty"`
+	ExportedQty  decimal.Decimal `json:"exported_qty" gorm:"column:exported_qty"`
+	DeliveredQty decimal.Decimal `json:"delivered_qty" gorm:"column:delivered_qty"`
+
+	// Hasil pencocokan (diisi service).
+	Status          string          `json:"status" gorm:"-"`
+	ShippedQty      decimal.Decimal `json:"shipped_qty" gorm:"-"`      // exported_qty + delivered_qty
+	QtyDiff         decimal.Decimal `json:"qty_diff" gorm:"-"`         // shipped_qty - doc_qty
+	NotShippedQty   decimal.Decimal `json:"not_shipped_qty" gorm:"-"`  // didokumenkan tapi tidak keluar
+	UndocumentedQty decimal.Decimal `json:"undocumented_qty" gorm:"-"` // keluar tanpa dokumen
+}
+
+// ReconciliationSummary adalah jumlah baris per status (sebelum filter status dan pagination).
+type ReconciliationSummary struct {
+	LineCount int            `json:"line_count"`
+	ByStatus  map[string]int `json:"by_status"`
+	Complete  bool           `json:"complete"` // true bila semua baris MATCHED
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// Action log akses laporan.
+const (
+	ReportActionView   = "view"
+	ReportActionExport = "export"
+)
+
+// ReportAccessLog mencatat siapa melihat atau meng-export laporan apa untuk periode/filter apa.
+// Diisi otomatis oleh middleware.ReportAudit untuk setiap GET /report/* dan .../export.
+type ReportAccessLog struct {
+	Id          int               `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	UserId      string            `json:"user_id" gorm:"column:user_id"`
+	Username    string            `json:"username" gorm:"column:username"`
+	Action      string            `json:"action" gorm:"column:action;not null"` // view, export
+	ReportType  string            `json:"report_type" gorm:"column:report_type;not null"`
+	Path        string            `json:"path" gorm:"column:path;not null"`
+	Filters     map[string]string `json:"filters" gorm:"column:filters;serializer:json"`
+	Format      string            `json:"format,omitempty" gorm:"column:format"`
+	RowCount    *int              `json:"row_count" gorm:"column:row_count"` // nil bila respons bukan list (mis. error)
+	StatusCode  int               `json:"status_code" gorm:"column:status_code"`
+	DurationMs  int64             `json:"duration_ms" gorm:"column:duration_ms"`
+	DocumentId  string            `json:"document_id,omitempty" gorm:"column:document_id"` // lihat ExportDocument
+	ExportJobId string            `json:"export_job_id,omitempty" gorm:"column:export_job_id"`
+	IpAddress   string            `json:"ip_address" gorm:"column:ip_address"`
+	UserAgent   string            `json:"user_agent" gorm:"column:user_agent"`
+	CreatedAt   time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (ReportAccessLog) TableName() string {
+	return "report_access_log"
+}
+
+type ReportAccessLogListRequest struct {
+	UserId     string `json:"user_id" form:"user_id"`
+	Username   string `json:"username" form:"username"`
+	Action     string `json:"action" form:"action"`
+	ReportType string `json:"report_type" form:"report_type"`
+	StartDate  string `json:"start_date" form:"start_date"` // format: 2006-01-02
+	EndDate    string `json:"end_date" form:"end_date"`     // format: 2006-01-02
+	Page       int    `json:"page" form:"page"`
+	Limit      int    `json:"limit" form:"limit"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "github.com/shopspring/decimal"
+
+// ComparisonValue adalah satu nilai laporan di periode berjalan dan periode pembanding.
+// DeltaPct = Delta / |Previous| * 100, nil bila Previous = 0.
+type ComparisonValue struct {
+	Current  decimal.Decimal  `json:"current"`
+	Previous decimal.Decimal  `json:"previous"`
+	Delta    decimal.Decimal  `json:"delta"`
+	DeltaPct *decimal.Decimal `json:"delta_pct"`
+}
+
+// ReportComparison adalah satu item laporan mutasi (bahan baku / barang jadi) yang
+// dibandingkan antar dua periode (compare=previous|yoy|custom).
+type ReportComparison struct {
+	ItemCode     string          `json:"item_code"`
+	ItemName     string          `json:"item_name"`
+	UnitCode     string          `json:"unit_code"`
+	ItemTypeCode string          `json:"item_type_code"`
+	ItemGroup    string          `json:"item_group"`
+	Masuk        ComparisonValue `json:"masuk"`
+	Keluar       ComparisonValue `json:"keluar"`
+	Akhir        ComparisonValue `json:"akhir"`
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Tipe rule alert stok.
+const (
+	AlertRuleNegativeAkhir  = "negative_akhir"  // akhir < 0
+	AlertRuleSelisihPercent = "selisih_percent" // |selisih| > threshold% dari |akhir|
+	AlertRuleMissingOpname  = "missing_opname"  // opname = 0 sementara akhir > 0
+)
+
+// Status alert stok.
+const (
+	AlertStatusOpen         = "open"
+	AlertStatusAcknowledged = "acknowledged"
+	AlertStatusResolved     = "resolved"
+)
+
+type StockAlertRule struct {
+	Id               int              `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	ItemGroup        string           `json:"item_group" gorm:"column:item_group;not null"`
+	RuleType         string           `json:"rule_type" gorm:"column:rule_type;not null"`
+	ThresholdPercent *decimal.Decimal `json:"threshold_percent" gorm:"column:threshold_percent"` // hanya untuk selisih_percent
+	IsActive         bool             `json:"is_active" gorm:"column:is_active;default:true"`
+	CreatedAt        time.Time        `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time        `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (StockAlertRule) TableName() string {
+	return "stock_alert_rule"
+}
+
+type StockAlertRuleRequest struct {
+	ItemGroup        string           `json:"item_group" validate:"required"`
+	RuleType         string           `json:"rule_type" validate:"required,oneof=negative_akhir selisih_percent missing_opname"`
+	ThresholdPercent *decimal.Decimal `json:"threshold_percent"`
+	IsActive         *bool            `json:"is_active"`
+}
+
+type StockAlert struct {
+	Id             int             `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	RuleId         int             `json:"rule_id" gorm:"column:rule_id;not null"`
+	RuleType       string          `json:"rule_type" gorm:"column:rule_type;not null"`
+	ItemGroup      string          `json:"item_group" gorm:"column:item_group;not null"`
+	ItemCode       string          `json:"item_code" gorm:"column:item_code;not null"`
+	ItemName       string          `json:"item_name" gorm:"column:item_name"`
+	UnitCode       string          `json:"unit_code" gorm:"column:unit_code"`
+	PeriodFrom     time.Time       `json:"period_from" gorm:"column:period_from;type:date"`
+	PeriodTo       time.Time       `json:"period_to" gorm:"column:period_to;type:date"`
+	Awal           decimal.Decimal `json:"awal" gorm:"column:awal"`
+	Akhir          decimal.Decimal `json:"akhir" gorm:"column:akhir"`
+	Opname         decimal.Decimal `json:"opname" gorm:"column:opname"`
+	Selisih        decimal.Decimal `json:"selisih" gorm:"column:selisih"`
+	Message        string          `json:"message" gorm:"column:message"`
+	Status         string          `json:"status" gorm:"column:status;not null"`
+	AcknowledgedBy string          `json:"acknowledged_by" gorm:"column:acknowledged_by"`
+	AcknowledgedAt *time.Time      `json:"acknowledged_at" gorm:"column:acknowledged_at"`
+	ResolvedBy     string          `json:"resolved_by" gorm:"column:resolved_by"`
+	ResolvedAt     *time.Time      `json:"resolved_at" gorm:"column:resolved_at"`
+	Note           string          `json:"note" gorm:"column:note"`
+	CreatedAt      time.Time       `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time       `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (StockAlert) TableName() string {
+	return "stock_alert"
+}
+
+type StockAlertListRequest struct {
+	Status    string `json:"status" form:"status"`
+	ItemGroup string `json:"item_group" form:"item_group"`
+	RuleType  string `json:"rule_type" form:"rule_type"`
+	ItemCode  string `json:"item_code" form:"item_code"`
+	From      string `json:"from" form:"from"` // period_to >= from (YYYY-MM-DD)
+	To        string `json:"to" form:"to"`     // period_from <= to (YYYY-MM-DD)
+	Page      int    `json:"page" form:"page"`
+	Limit     int    `json:"limit" form:"limit"`
+}
+
+type StockAlertActionRequest struct {
+	Note string `json:"note"`
+}
+
+// StockAlertEvaluation adalah ringkasan satu kali evaluasi rule.
+type StockAlertEvaluation struct {
+	PeriodFrom     string   `json:"period_from"`
+	PeriodTo       string   `json:"period_to"`
+	RulesEvaluated int      `json:"rules_evaluated"`
+	Violations     int      `json:"violations"`
+	Created        int      `json:"created"`
+	Updated        int      `json:"updated"`
+	Reopened       int      `json:"reopened"`
+	Unchanged      int      `json:"unchanged"`      // alert sudah ada dengan angka yang sama
+	SkippedGroups  []string `json:"skipped_groups"` // item group tanpa laporan saldo
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Status pengiriman subkontrak (BC 2.6.1) terhadap pemasukan kembaliannya (BC 2.6.2).
+const (
+	SubcontractReturned = "RETURNED" // seluruh kuantitas sudah kembali
+	SubcontractOpen     = "OPEN"     // masih ada sisa, belum lewat batas hari
+	SubcontractOverdue  = "OVERDUE"  // masih ada sisa dan sudah lewat batas hari
+)
+
+// SubcontractStatuses adalah nilai ?status= yang valid untuk laporan subkontrak.
+var SubcontractStatuses = []string{SubcontractOpen, SubcontractOverdue, SubcontractReturned}
+
+// SubcontractOptions adalah parameter pencocokan pengiriman dengan pengembalian subkontrak.
+type SubcontractOptions struct {
+	AsOf        time.Time // posisi: dokumen sampai tanggal ini yang dihitung
+	AllowedDays int       // batas hari barang harus kembali sejak tgl_pabean BC 2.6.1
+	Status      []string  // kosong = semua status
+}
+
+// SubcontractorMap memetakan kode vendor subkontraktor (BC 2.6.2) ke kode customer-nya (BC 2.6.1).
+// Vendor yang tidak terdaftar dianggap memakai kode yang sama dengan kode customer.
+type SubcontractorMap struct {
+	VendorCode string    `json:"vendor_code" gorm:"column:vendor_code;primaryKey"`
+	CustCode   string    `json:"cust_code" gorm:"column:cust_code;not null"`
+	Notes      string    `json:"notes" gorm:"column:notes"`
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (SubcontractorMap) TableName() string {
+	return "ms_subcontractor_map"
+}
+
+// SubcontractMovement adalah satu baris dokumen subkontrak: BC 2.6.1 dari tr_pengeluaran_barang
+// (penerima = cust_code) atau BC 2.6.2 dari tr_pemasukan_barang (pengirim = vendor_code, dipetakan
+// ke cust_code lewat SubcontractorMap). SubcontractorCode selalu kode customer.
+type SubcontractMovement struct {
+	JenisPabean       string          `json:"jenis_pabean" gorm:"column:jenis_pabean"`
+	NoPabean          string          `json:"no_pabean" gorm:"column:no_pabean"`
+	TglPabean         time.Time       `json:"tgl_pabean" gorm:"column:tgl_pabean"`
+	TransNo           string          `json:"trans_no" gorm:"column:trans_no"`
+	SubcontractorCode string          `json:"subcontractor_code" gorm:"column:subcontractor_code"`
+	SubcontractorName string          `json:"subcontractor_name" gorm:"column:subcontractor_name"`
+	ItemCode          string          `json:"item_code" gorm:"column:item_code"`
+	ItemName          string          `json:"item_name" gorm:"column:item_name"`
+	Unit              string          `json:"unit" gorm:"column:unit"`
+	Qty               decimal.Decimal `json:"qty" gorm:"column:qty"`
+}
+
+// SubcontractReturn adalah bagian satu dokumen BC 2.6.2 yang dialokasikan ke sebuah pengiriman.
+type SubcontractReturn struct {
+	NoPabean  string          `json:"no_pabean"`
+	TglPabean time.Time       `json:"tgl_pabean"`
+	TransNo   string          `json:"trans_no"`
+	Qty       decimal.Decimal `json:"qty"`
+}
+
+// SubcontractShipment adalah satu baris BC 2.6.1 beserta pengembalian BC 2.6.2 yang dipasangkan
+// (FIFO per subkontraktor + item) dan sisanya pada tanggal posisi.
+type SubcontractShipment struct {
+	SubcontractMovement
+
+	ReturnedQty    decimal.Decimal     `json:"returned_qty"`
+	OutstandingQty decimal.Decimal     `json:"outstanding_qty"`
+	DueDate        time.Time           `json:"due_date"`
+	DaysOpen       int                 `json:"days_open"` // sampai posisi, atau sampai pengembalian terakhir bila sudah kembali semua
+	Late           bool                `json:"late"`      // DaysOpen melewati batas hari
+	Status         string              `json:"status"`
+	Returns        []SubcontractReturn `json:"returns"`
+}
+
+// SubcontractSummary adalah ringkasan semua pengiriman per status; UnmatchedReturns adalah
+// kuantitas BC 2.6.2 yang tidak punya pengiriman BC 2.6.1 sebelumnya (atau melebihinya).
+// UnknownSubcontractors adalah kode subkontraktor BC 2.6.2 yang tidak pernah muncul di BC 2.6.1,
+// biasanya karena kode vendornya belum dipetakan di ms_subcontractor_map.
+type SubcontractSummary struct {
+	ShipmentCount         int                   `json:"shipment_count"`
+	ByStatus              map[string]int        `json:"by_status"`
+	LateCount             int                   `json:"late_count"`
+	UnmatchedReturns      []SubcontractMovement `json:"unmatched_returns"`
+	UnknownSubcontractors []string              `json:"unknown_subcontractors"`
+}
@@ -7,22 +7,28 @@ import (
 )
 
 type EntryProduct struct {
-	Idx         int             `json:"idx" gorm:"not null;index:idx_idx"`
-	JenisPabean string          `json:"jenis_pabean" gorm:"type:varchar(255)"`
-	NoPabean    string          `json:"no_pabean" gorm:"type:varchar(255)"`
-	TglPabean   time.Time       `json:"tgl_pabean" gorm:"type:date"`
-	TransNo     string          `json:"trans_no" gorm:"type:varchar(255)"`
-	VendDlvNo   string          `json:"vend_dlv_no" gorm:"type:varchar(255)"`
-	TransDate   time.Time       `json:"trans_date" gorm:"type:date"`
-	VendorCode  string          `json:"vendor_code" gorm:"type:varchar(255)"`
-	VendorName  string          `json:"vendor_name" gorm:"type:varchar(255)"`
-	ItemCode    string          `json:"item_code" gorm:"type:varchar(255)"`
-	ItemName    string          `json:"item_name" gorm:"type:varchar(255)"`
-	RcvQty      decimal.Decimal `json:"rcv_qty" gorm:"type:decimal(20,2);not null;default:0"`
-	PchUnit     string          `json:"pch_unit" gorm:"type:varchar(255)"`
-	CurrCode    string          `json:"curr_code" gorm:"type:varchar(255)"`
-	NetPrice    decimal.Decimal `json:"net_price" gorm:"type:decimal(20,2);not null;default:0"`
-	NetAmount   decimal.Decimal `json:"net_amount" gorm:"type:decimal(20,2);not null;default:0"`
+	Idx           int              `json:"idx" gorm:"not null;index:idx_idx"`
+	JenisPabean   string           `json:"jenis_pabean" gorm:"type:varchar(255)"`
+	NoPabean      string           `json:"no_pabean" gorm:"type:varchar(255)"`
+	TglPabean     time.Time        `json:"tgl_pabean" gorm:"type:date"`
+	TransNo       string           `json:"trans_no" gorm:"type:varchar(255)"`
+	VendDlvNo     string           `json:"vend_dlv_no" gorm:"type:varchar(255)"`
+	TransDate     time.Time        `json:"trans_date" gorm:"type:date"`
+	VendorCode    string           `json:"vendor_code" gorm:"type:varchar(255)"`
+	VendorName    string           `json:"vendor_name" gorm:"type:varchar(255)"`
+	ItemCode      string           `json:"item_code" gorm:"type:varchar(255)"`
+	ItemName      string           `json:"item_name" gorm:"type:varchar(255)"`
+	ItemGroup     string           `json:"item_group" gorm:"->;-:migration;column:item_group"` // ms_item.item_group (join, read-only)
+	RcvQty        decimal.Decimal  `json:"rcv_qty" gorm:"type:decimal(20,2);not null;default:0"`
+	PchUnit       string           `json:"pch_unit" gorm:"type:varchar(255)"`
+	CurrCode      string           `json:"curr_code" gorm:"type:varchar(255)"`
+	NetPrice      decimal.Decimal  `json:"net_price" gorm:"type:decimal(20,2);not null;default:0"`
+	NetAmount     decimal.Decimal  `json:"net_amount" gorm:"type:decimal(20,2);not null;default:0"`
+	IdrRate       *decimal.Decimal `json:"idr_rate" gorm:"->;-:migration;column:idr_rate"`             // kurs pada tgl_pabean (exchange_rate, read-only)
+	NetAmountIdr  *decimal.Decimal `json:"net_amount_idr" gorm:"->;-:migration;column:net_amount_idr"` // net_amount * idr_rate; null bila kurs tidak ada
+	RateMissing   bool             `json:"rate_missing" gorm:"->;-:migration;column:rate_missing"`
+	PabeanStatus  string           `json:"pabean_status" gorm:"->;-:migration;column:pabean_status"`   // validasi jenis_pabean terhadap ms_pabean (OK, UNKNOWN, WRONG_DIRECTION)
+	DutySuspended bool             `json:"duty_suspended" gorm:"->;-:migration;column:duty_suspended"` // ms_pabean.duty_suspended
 }
 
 // TableName overrides the default table name.
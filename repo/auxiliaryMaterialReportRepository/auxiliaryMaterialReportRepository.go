@@ -1,6 +1,8 @@
 package auxiliaryMaterialReportRepository
 
 import (
+	"Bea-Cukai/helper/queryGuard"
+	"Bea-Cukai/helper/reportQuery"
 	"Bea-Cukai/model"
 	"context"
 	"fmt"
@@ -27,10 +29,26 @@ type GetReportFilter struct {
 	ItemCode string
 	ItemName string
 	Lap      string // Dynamic item group parameter
+	Options  reportQuery.Options
 	Page     int
 	Limit    int
 }
 
+// ReportColumns adalah whitelist kolom untuk parameter sort/filter laporan ini.
+// akhir dan selisih selalu 0 di laporan ini sehingga tidak bisa dipakai untuk sort/filter.
+var ReportColumns = reportQuery.Columns{
+	"item_code":      {Expr: "rq.item_code"},
+	"item_name":      {Expr: "rq.item_name"},
+	"unit_code":      {Expr: "rq.unit_code"},
+	"item_type_code": {Expr: "rq.item_type_code"},
+	"location_code":  {Expr: "rq.location_code"},
+	"awal":           {Expr: "rq.awal", Numeric: true},
+	"masuk":          {Expr: "rq.masuk", Numeric: true},
+	"keluar":         {Expr: "rq.keluar", Numeric: true},
+	"peny":           {Expr: "rq.peny", Numeric: true},
+	"opname":         {Expr: "rq.opname", Numeric: true},
+}
+
 // Helper function to get max opname date for specific item group (lap parameter)
 func (r *AuxiliaryMaterialReportRepository) getMaxOpnameDateByGroup(ctx context.Context, beforeDate time.Time, itemGroup string) (time.Time, error) {
 	var result struct {
@@ -146,16 +164,19 @@ func (r *AuxiliaryMaterialReportRepository) GetReport(ctx context.Context, filte
 	}
 	queryArgs = append(queryArgs, args...)
 
+	// Sort/filter tambahan dari parameter request
+	finalQuery, queryArgs := filter.Options.Apply(baseQuery, queryArgs, ReportColumns)
+
 	// Get total count first
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (%s) as subquery", baseQuery)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (%s) as subquery", finalQuery)
 	var totalCount int64
-	err := r.db.WithContext(ctx).Raw(countQuery, queryArgs...).Scan(&totalCount).Error
+	err := queryGuard.Scan(ctx, r.db, &totalCount, countQuery, queryArgs...)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// Add LIMIT and OFFSET for pagination
-	paginatedQuery := baseQuery
+	paginatedQuery := finalQuery + filter.Options.OrderBy(ReportColumns, reportQuery.DefaultOrder)
 	if filter.Limit > 0 {
 		offset := 0
 		if filter.Page > 1 {
@@ -181,7 +202,7 @@ func (r *AuxiliaryMaterialReportRepository) GetReport(ctx context.Context, filte
 	}
 
 	var rawResults []RawResult
-	err = r.db.WithContext(ctx).Raw(paginatedQuery, queryArgs...).Scan(&rawResults).Error
+	err = queryGuard.Scan(ctx, r.db, &rawResults, paginatedQuery, queryArgs...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -0,0 +1,51 @@
+package companyProfileRepository
+
+import (
+	"Bea-Cukai/model"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type CompanyProfileRepository struct {
+	db *gorm.DB
+}
+
+func NewCompanyProfileRepository(db *gorm.DB) *CompanyProfileRepository {
+	return &CompanyProfileRepository{
+		db: db,
+	}
+}
+
+// Get - ambil profil perusahaan; bila belum ada baris, kembalikan profil default
+func (r *CompanyProfileRepository) Get() (model.CompanyProfile, error) {
+	var profile model.CompanyProfile
+	err := r.db.Where("id = ?", model.CompanyProfileId).First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.DefaultCompanyProfile(), nil
+	}
+	if err != nil {
+		return model.CompanyProfile{}, err
+	}
+	return profile, nil
+}
+
+// Save - insert atau update baris profil tunggal
+func (r *CompanyProfileRepository) Save(req model.CompanyProfileRequest, username string) (model.CompanyProfile, error) {
+	profile := model.CompanyProfile{
+		Id:             model.CompanyProfileId,
+		CompanyName:    req.CompanyName,
+		Npwp:           req.Npwp,
+		KbLicenseNo:    req.KbLicenseNo,
+		Address:        req.Address,
+		City:           req.City,
+		SignatoryName:  req.SignatoryName,
+		SignatoryTitle: req.SignatoryTitle,
+		UpdatedBy:      username,
+	}
+
+	if err := r.db.Save(&profile).Error; err != nil {
+		return model.CompanyProfile{}, err
+	}
+	return profile, nil
+}
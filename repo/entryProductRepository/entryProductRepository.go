@@ -1,6 +1,10 @@
 package entryProductRepository
 
 import (
+	"Bea-Cukai/helper/customsSummary"
+	"Bea-Cukai/helper/exchangeRate"
+	"Bea-Cukai/helper/pabeanType"
+	"Bea-Cukai/helper/queryGuard"
 	"Bea-Cukai/model"
 	"context"
 	"time"
@@ -25,7 +29,7 @@ type GetReportFilter struct {
 	From         time.Time
 	To           time.Time
 	PabeanType   string
-	ProductGroup string
+	ProductGroup []string // ms_item.item_group; kosong = semua grup
 	NoPabean     string
 	ProductCode  string
 	ProductName  string
@@ -34,49 +38,162 @@ type GetReportFilter struct {
 	IsExport     bool
 }
 
-// GetReport retrieves entry products with filters and pagination
-func (c *EntryProductRepository) GetReport(ctx context.Context, filter GetReportFilter) ([]model.EntryProduct, int64, error) {
+// reportSelect adalah kolom laporan: semua kolom transaksi plus grup barang dari ms_item,
+// konversi nilai ke IDR (butuh rateJoin) dan validasi jenis_pabean (butuh pabeanJoin).
+var reportSelect = "p.*, COALESCE(i.item_group, '') AS item_group, " + exchangeRate.Select("p.curr_code", "p.net_amount") +
+	", " + pabeanType.Select(model.PabeanDirectionIn)
+
+// rateJoin menggabungkan kurs exchange_rate yang berlaku pada tgl_pabean (lihat helper/exchangeRate).
+var rateJoin = exchangeRate.Join("p.curr_code", "p.tgl_pabean")
+
+// pabeanJoin menggabungkan master ms_pabean untuk jenis_pabean baris (lihat helper/pabeanType).
+var pabeanJoin = pabeanType.Join("p.jenis_pabean")
+
+// filteredQuery builds the base query with all report filters applied (no ordering/pagination).
+// db adalah koneksi dari queryGuard.Run; setiap pemanggilan memulai statement baru di koneksi itu.
+// tr_pemasukan_barang tidak punya kolom grup, jadi item_group diambil lewat join ke ms_item.
+func (c *EntryProductRepository) filteredQuery(db *gorm.DB, filter GetReportFilter) *gorm.DB {
 	from, to := filter.From, filter.To
 
-	query := c.db.WithContext(ctx).Model(&model.EntryProduct{}).
-		Where("tgl_pabean BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	query := db.Session(&gorm.Session{NewDB: true}).Model(&model.EntryProduct{}).
+		Table(model.EntryProduct{}.TableName()+" AS p").
+		Joins("LEFT JOIN ms_item i ON i.item_code = p.item_code").
+		Where("p.tgl_pabean BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02"))
 
 	// Apply filters if provided
 	if filter.PabeanType != "" {
-		query = query.Where("jenis_pabean = ?", filter.PabeanType)
+		query = query.Where("p.jenis_pabean = ?", filter.PabeanType)
 	}
 	if filter.NoPabean != "" {
-		query = query.Where("no_pabean = ?", filter.NoPabean)
+		query = query.Where("p.no_pabean = ?", filter.NoPabean)
 	}
 	if filter.ProductCode != "" {
-		query = query.Where("item_code = ?", filter.ProductCode)
+		query = query.Where("p.item_code = ?", filter.ProductCode)
 	}
 	if filter.ProductName != "" {
-		query = query.Where("item_name LIKE ?", "%"+filter.ProductName+"%")
+		query = query.Where("p.item_name LIKE ?", "%"+filter.ProductName+"%")
+	}
+	if len(filter.ProductGroup) > 0 {
+		query = query.Where("i.item_group IN ?", filter.ProductGroup)
 	}
-	// Note: ProductGroup filter might need adjustment based on your data structure
-	// since the current model doesn't have a product_group field directly
 
-	// Get total count before applying pagination
+	return query
+}
+
+// GetReport retrieves entry products with filters and pagination
+func (c *EntryProductRepository) GetReport(ctx context.Context, filter GetReportFilter) ([]model.EntryProduct, int64, error) {
+	var results []model.EntryProduct
 	var totalCount int64
-	err := query.Count(&totalCount).Error
+	err := queryGuard.Run(ctx, c.db, func(tx *gorm.DB) error {
+		query := c.filteredQuery(tx, filter)
+
+		// Get total count before applying pagination
+		if err := query.Count(&totalCount).Error; err != nil {
+			return err
+		}
+
+		// Apply pagination only if both page and limit are provided (> 0)
+		if filter.Limit > 0 && filter.Page > 0 {
+			query = query.Limit(filter.Limit)
+			offset := (filter.Page - 1) * filter.Limit
+			query = query.Offset(offset)
+		}
+
+		query = query.Joins(rateJoin).Joins(pabeanJoin).Select(reportSelect)
+		if filter.IsExport {
+			query = query.Order("p.tgl_pabean ASC")
+		} else {
+			query = query.Order("p.tgl_pabean DESC")
+		}
+		return query.Find(&results).Error
+	})
 	if err != nil {
 		return nil, 0, err
 	}
+	return results, totalCount, nil
+}
 
-	// Apply pagination only if both page and limit are provided (> 0)
-	if filter.Limit > 0 && filter.Page > 0 {
-		query = query.Limit(filter.Limit)
-		offset := (filter.Page - 1) * filter.Limit
-		query = query.Offset(offset)
-	}
+// StreamReport reads entry products for export through a DB cursor, calling fn per row
+// (ordered by tgl_pabean ASC) so the full result set is never held in memory.
+func (c *EntryProductRepository) StreamReport(ctx context.Context, filter GetReportFilter, fn func(model.EntryProduct) error) error {
+	return queryGuard.Run(ctx, c.db, func(tx *gorm.DB) error {
+		query := c.filteredQuery(tx, filter).Joins(rateJoin).Joins(pabeanJoin).Select(reportSelect).Order("p.tgl_pabean ASC")
 
-	var results []model.EntryProduct
-	if filter.IsExport {
-		query = query.Order("tgl_pabean ASC")
-	} else {
-		query = query.Order("tgl_pabean DESC")
-	}
-	err = query.Find(&results).Error
-	return results, totalCount, err
+		rows, err := query.Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var row model.EntryProduct
+			if err := query.ScanRows(rows, &row); err != nil {
+				return err
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+// SummarySpec adalah pengelompokan ringkasan dokumen pemasukan (groupBy=...), lihat helper/customsSummary.
+var SummarySpec = customsSummary.Spec{
+	Groups: map[string]customsSummary.Group{
+		customsSummary.GroupByJenisPabean: customsSummary.JenisPabeanGroup,
+		customsSummary.GroupByMonth:       customsSummary.MonthGroup,
+		customsSummary.GroupByVendor:      {Key: "p.vendor_code", Label: "p.vendor_name"},
+		customsSummary.GroupByItem:        {Key: "p.item_code", Label: "p.item_name"},
+		customsSummary.GroupByItemGroup:   customsSummary.ItemGroupGroup,
+		customsSummary.GroupByCurrency:    customsSummary.CurrencyGroup,
+	},
+	QuantityColumn: "p.rcv_qty",
+	UnitColumn:     "p.pch_unit",
+}
+
+// GetSummary mengelompokkan baris laporan (filter sama dengan GetReport, tanpa pagination) per groupBy:
+// jumlah dokumen, jumlah baris, kuantitas per satuan dan nilai per mata uang, plus total keseluruhan.
+func (c *EntryProductRepository) GetSummary(ctx context.Context, filter GetReportFilter, groupBy string, limit int) ([]model.CustomsSummary, model.CustomsSummary, error) {
+	var rows []model.CustomsSummary
+	var total model.CustomsSummary
+	err := queryGuard.Run(ctx, c.db, func(tx *gorm.DB) error {
+		var err error
+		rows, total, err = customsSummary.Run(func() *gorm.DB { return c.filteredQuery(tx, filter) }, SummarySpec, groupBy, limit)
+		return err
+	})
+	return rows, total, err
+}
+
+// GetTotals menjumlahkan net_amount per mata uang beserta konversinya ke IDR untuk semua baris
+// yang cocok dengan filter (tanpa pagination).
+func (c *EntryProductRepository) GetTotals(ctx context.Context, filter GetReportFilter) ([]model.CurrencyTotal, error) {
+	var totals []model.CurrencyTotal
+	err := queryGuard.Run(ctx, c.db, func(tx *gorm.DB) error {
+		return c.filteredQuery(tx, filter).
+			Joins(rateJoin).
+			Select(exchangeRate.TotalsSelect("p.curr_code", "p.net_amount")).
+			Group("p.curr_code").
+			Order("p.curr_code ASC").
+			Scan(&totals).Error
+	})
+	return totals, err
+}
+
+// GetPabeanValidation menghitung baris yang cocok dengan filter (tanpa pagination) yang jenis_pabean-nya
+// tidak ada di ms_pabean atau bukan dokumen pemasukan, per jenis_pabean dan status.
+func (c *EntryProductRepository) GetPabeanValidation(ctx context.Context, filter GetReportFilter) ([]model.PabeanValidationCount, error) {
+	status := pabeanType.StatusExpr(model.PabeanDirectionIn)
+
+	var counts []model.PabeanValidationCount
+	err := queryGuard.Run(ctx, c.db, func(tx *gorm.DB) error {
+		return c.filteredQuery(tx, filter).
+			Joins(pabeanJoin).
+			Select("p.jenis_pabean, "+status+" AS status, COUNT(*) AS line_count").
+			Where(status+" <> ?", model.PabeanStatusOk).
+			Group("p.jenis_pabean, " + status).
+			Order("p.jenis_pabean ASC").
+			Scan(&counts).Error
+	})
+	return counts, err
 }
@@ -0,0 +1,66 @@
+package entryProductRepository
+
+import (
+	"Bea-Cukai/helper/pabeanType"
+	"Bea-Cukai/model"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open mock: %v", err)
+	}
+	return gormDB, mock
+}
+
+// Validasi jenis_pabean laporan pemasukan: dokumen pengeluaran (WRONG_DIRECTION) dan jenis yang
+// tidak ada di ms_pabean dihitung per jenis_pabean dan status, dengan filter laporan yang sama.
+func TestGetPabeanValidation_CountsUnknownAndWrongDirection(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewEntryProductRepository(db)
+
+	status := regexp.QuoteMeta(pabeanType.StatusExpr(model.PabeanDirectionIn))
+	mock.ExpectQuery(`SELECT p\.jenis_pabean, `+status+` AS status, COUNT\(\*\) AS line_count FROM tr_pemasukan_barang AS p `+
+		`LEFT JOIN ms_item i ON i\.item_code = p\.item_code `+regexp.QuoteMeta(pabeanJoin)+
+		` WHERE \(p\.tgl_pabean BETWEEN \? AND \?\) AND p\.no_pabean = \? AND `+status+` <> \? `+
+		`GROUP BY p\.jenis_pabean, `+status+` ORDER BY p\.jenis_pabean ASC`).
+		WithArgs("2026-09-01", "2026-09-30", "000123", model.PabeanStatusOk).
+		WillReturnRows(sqlmock.NewRows([]string{"jenis_pabean", "status", "line_count"}).
+			AddRow("BC 3.0", model.PabeanStatusWrongDirection, 3).
+			AddRow("BC 9.9", model.PabeanStatusUnknown, 1))
+
+	counts, err := repo.GetPabeanValidation(context.Background(), GetReportFilter{
+		From:     time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		NoPabean: "000123",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(counts) != 2 || counts[0].Status != model.PabeanStatusWrongDirection || counts[0].LineCount != 3 ||
+		counts[1].Status != model.PabeanStatusUnknown {
+		t.Errorf("counts = %+v", counts)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
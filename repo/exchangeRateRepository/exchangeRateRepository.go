@@ -0,0 +1,134 @@
+package exchangeRateRepository
+
+import (
+	"Bea-Cukai/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ExchangeRateRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateRepository(db *gorm.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+// GetAll - get kurs dengan filter dan pagination (terbaru lebih dulu)
+func (r *ExchangeRateRepository) GetAll(req model.ExchangeRateListRequest) ([]model.ExchangeRate, int64, error) {
+	var rates []model.ExchangeRate
+	var total int64
+
+	query := r.db.Model(&model.ExchangeRate{})
+	if req.CurrCode != "" {
+		query = query.Where("curr_code = ?", req.CurrCode)
+	}
+	if req.Source != "" {
+		query = query.Where("source = ?", req.Source)
+	}
+	if req.Date != "" {
+		if date, err := time.Parse("2006-01-02", req.Date); err == nil {
+			day := date.Format("2006-01-02")
+			query = query.Where("valid_from <= ? AND (valid_to IS NULL OR valid_to >= ?)", day, day)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if req.Page > 0 && req.Limit > 0 {
+		offset := (req.Page - 1) * req.Limit
+		query = query.Offset(offset).Limit(req.Limit)
+	}
+
+	if err := query.Order("valid_from DESC, curr_code ASC").Find(&rates).Error; err != nil {
+		return nil, 0, err
+	}
+	return rates, total, nil
+}
+
+// GetById - get satu kurs
+func (r *ExchangeRateRepository) GetById(id int) (model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	err := r.db.Where("id = ?", id).First(&rate).Error
+	return rate, err
+}
+
+// Create - simpan kurs baru; curr_code + valid_from unik
+func (r *ExchangeRateRepository) Create(rate model.ExchangeRate) (model.ExchangeRate, error) {
+	if err := r.db.Create(&rate).Error; err != nil {
+		return model.ExchangeRate{}, err
+	}
+	return rate, nil
+}
+
+// Update - ubah kurs by id
+func (r *ExchangeRateRepository) Update(id int, rate model.ExchangeRate) (model.ExchangeRate, error) {
+	existing, err := r.GetById(id)
+	if err != nil {
+		return model.ExchangeRate{}, err
+	}
+
+	rate.Id = existing.Id
+	rate.CreatedBy = existing.CreatedBy
+	rate.CreatedAt = existing.CreatedAt
+	if err := r.db.Save(&rate).Error; err != nil {
+		return model.ExchangeRate{}, err
+	}
+	return rate, nil
+}
+
+// Delete - hapus kurs by id
+func (r *ExchangeRateRepository) Delete(id int) error {
+	result := r.db.Where("id = ?", id).Delete(&model.ExchangeRate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Upsert - simpan kurs hasil import dalam satu transaksi. Kunci: curr_code + valid_from;
+// kurs yang sudah ada diperbarui (rate, valid_to, source, notes, updated_by).
+func (r *ExchangeRateRepository) Upsert(rates []model.ExchangeRate) (created, updated int, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		for _, rate := range rates {
+			var existing model.ExchangeRate
+			err := tx.Where("curr_code = ? AND valid_from = ?", rate.CurrCode, rate.ValidFrom.Format("2006-01-02")).
+				First(&existing).Error
+
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if err := tx.Create(&rate).Error; err != nil {
+					return err
+				}
+				created++
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			err = tx.Model(&existing).Updates(map[string]interface{}{
+				"rate":       rate.Rate,
+				"valid_to":   rate.ValidTo,
+				"source":     rate.Source,
+				"notes":      rate.Notes,
+				"updated_by": rate.UpdatedBy,
+			}).Error
+			if err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return created, updated, nil
+}
@@ -1,8 +1,13 @@
 package expenditureProductRepository
 
 import (
+	"Bea-Cukai/helper/customsSummary"
+	"Bea-Cukai/helper/exchangeRate"
+	"Bea-Cukai/helper/pabeanType"
+	"Bea-Cukai/helper/queryGuard"
 	"Bea-Cukai/model"
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
@@ -24,7 +29,7 @@ type GetReportFilter struct {
 	From         time.Time
 	To           time.Time
 	PabeanType   string
-	ProductGroup string
+	ProductGroup []string // ms_item.item_group; kosong = semua grup
 	NoPabean     string
 	ProductCode  string
 	ProductName  string
@@ -41,56 +46,180 @@ func normalizeItemCode(itemCode string) string {
 	return strings.TrimPrefix(itemCode, "1")
 }
 
-// GetReport retrieves expenditure products with filters and pagination
-func (c *ExpenditureProductRepository) GetReport(ctx context.Context, filter GetReportFilter) ([]model.ExpenditureProduct, int64, error) {
+// NormalizedItemCode adalah normalizeItemCode dalam SQL untuk kolom col. Dipakai juga oleh
+// laporan lain yang mencocokkan kode barang tr_pengeluaran_barang dengan ms_item.
+func NormalizedItemCode(col string) string {
+	return fmt.Sprintf("CASE WHEN %[1]s <> '1' AND %[1]s LIKE '1%%' THEN SUBSTR(%[1]s, 2) ELSE %[1]s END", col)
+}
+
+// normalizedItemCodeExpr adalah NormalizedItemCode untuk p.item_code (untuk join ke ms_item).
+var normalizedItemCodeExpr = NormalizedItemCode("p.item_code")
+
+// reportSelect adalah kolom laporan: semua kolom transaksi plus grup barang dari ms_item,
+// konversi nilai ke IDR (butuh rateJoin) dan validasi jenis_pabean (butuh pabeanJoin).
+var reportSelect = "p.*, COALESCE(i.item_group, '') AS item_group, " + exchangeRate.Select("p.curr_code", "p.net_amount") +
+	", " + pabeanType.Select(model.PabeanDirectionOut)
+
+// rateJoin menggabungkan kurs exchange_rate yang berlaku pada tgl_pabean (lihat helper/exchangeRate).
+var rateJoin = exchangeRate.Join("p.curr_code", "p.tgl_pabean")
+
+// pabeanJoin menggabungkan master ms_pabean untuk jenis_pabean baris (lihat helper/pabeanType).
+var pabeanJoin = pabeanType.Join("p.jenis_pabean")
+
+// filteredQuery builds the base query with all report filters applied (no ordering/pagination).
+// db adalah koneksi dari queryGuard.Run; setiap pemanggilan memulai statement baru di koneksi itu.
+// tr_pengeluaran_barang tidak punya kolom grup, jadi item_group diambil lewat join ke ms_item
+// dengan kode barang yang sudah dinormalisasi (prefix "1" dibuang, sama dengan normalizeItemCode).
+func (c *ExpenditureProductRepository) filteredQuery(db *gorm.DB, filter GetReportFilter) *gorm.DB {
 	from, to := filter.From, filter.To
-	query := c.db.WithContext(ctx).Model(&model.ExpenditureProduct{}).
-		Where("tgl_pabean BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	query := db.Session(&gorm.Session{NewDB: true}).Model(&model.ExpenditureProduct{}).
+		Table(model.ExpenditureProduct{}.TableName()+" AS p").
+		Joins("LEFT JOIN ms_item i ON i.item_code = "+normalizedItemCodeExpr).
+		Where("p.tgl_pabean BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02"))
 
 	// Apply filters if provided
 	if filter.PabeanType != "" {
-		query = query.Where("jenis_pabean = ?", filter.PabeanType)
+		query = query.Where("p.jenis_pabean = ?", filter.PabeanType)
 	}
 	if filter.NoPabean != "" {
-		query = query.Where("no_pabean = ?", filter.NoPabean)
+		query = query.Where("p.no_pabean = ?", filter.NoPabean)
 	}
 	if filter.ProductCode != "" {
 		if filter.ProductCode == "1" {
-			query = query.Where("item_code = ?", filter.ProductCode)
+			query = query.Where("p.item_code = ?", filter.ProductCode)
 		} else {
-			query = query.Where("(item_code = ? OR (item_code LIKE '1%' AND SUBSTR(item_code, 2) = ?))", filter.ProductCode, filter.ProductCode)
+			query = query.Where("(p.item_code = ? OR (p.item_code LIKE '1%' AND SUBSTR(p.item_code, 2) = ?))", filter.ProductCode, filter.ProductCode)
 		}
 	}
 	if filter.ProductName != "" {
-		query = query.Where("item_name LIKE ?", "%"+filter.ProductName+"%")
+		query = query.Where("p.item_name LIKE ?", "%"+filter.ProductName+"%")
+	}
+	if len(filter.ProductGroup) > 0 {
+		query = query.Where("i.item_group IN ?", filter.ProductGroup)
 	}
 
-	// Note: ProductGroup filter might need adjustment based on your data structure
-	// since the current model doesn't have a product_group field directly
+	return query
+}
 
-	// Get total count before applying pagination
+// GetReport retrieves expenditure products with filters and pagination
+func (c *ExpenditureProductRepository) GetReport(ctx context.Context, filter GetReportFilter) ([]model.ExpenditureProduct, int64, error) {
+	var results []model.ExpenditureProduct
 	var totalCount int64
-	err := query.Count(&totalCount).Error
-	if err != nil {
-		return nil, 0, err
-	}
+	err := queryGuard.Run(ctx, c.db, func(tx *gorm.DB) error {
+		query := c.filteredQuery(tx, filter)
 
-	// Apply pagination only if both page and limit are provided (> 0)
-	if filter.Limit > 0 && filter.Page > 0 {
-		query = query.Limit(filter.Limit)
-		offset := (filter.Page - 1) * filter.Limit
-		query = query.Offset(offset)
-	}
+		// Get total count before applying pagination
+		if err := query.Count(&totalCount).Error; err != nil {
+			return err
+		}
 
-	var results []model.ExpenditureProduct
-	if filter.IsExport {
-		query = query.Order("tgl_pabean ASC")
-	} else {
-		query = query.Order("tgl_pabean DESC")
+		// Apply pagination only if both page and limit are provided (> 0)
+		if filter.Limit > 0 && filter.Page > 0 {
+			query = query.Limit(filter.Limit)
+			offset := (filter.Page - 1) * filter.Limit
+			query = query.Offset(offset)
+		}
+
+		query = query.Joins(rateJoin).Joins(pabeanJoin).Select(reportSelect)
+		if filter.IsExport {
+			query = query.Order("p.tgl_pabean ASC")
+		} else {
+			query = query.Order("p.tgl_pabean DESC")
+		}
+		return query.Find(&results).Error
+	})
+	if err != nil {
+		return nil, 0, err
 	}
-	err = query.Find(&results).Error
 	for i := range results {
 		results[i].ItemCode = normalizeItemCode(results[i].ItemCode)
 	}
-	return results, totalCount, err
+	return results, totalCount, nil
+}
+
+// StreamReport reads expenditure products for export through a DB cursor, calling fn per row
+// (ordered by tgl_pabean ASC) so the full result set is never held in memory.
+func (c *ExpenditureProductRepository) StreamReport(ctx context.Context, filter GetReportFilter, fn func(model.ExpenditureProduct) error) error {
+	return queryGuard.Run(ctx, c.db, func(tx *gorm.DB) error {
+		query := c.filteredQuery(tx, filter).Joins(rateJoin).Joins(pabeanJoin).Select(reportSelect).Order("p.tgl_pabean ASC")
+
+		rows, err := query.Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var row model.ExpenditureProduct
+			if err := query.ScanRows(rows, &row); err != nil {
+				return err
+			}
+			row.ItemCode = normalizeItemCode(row.ItemCode)
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+// SummarySpec adalah pengelompokan ringkasan dokumen pengeluaran (groupBy=...), lihat helper/customsSummary.
+// Item dikelompokkan per kode barang yang sudah dinormalisasi.
+var SummarySpec = customsSummary.Spec{
+	Groups: map[string]customsSummary.Group{
+		customsSummary.GroupByJenisPabean: customsSummary.JenisPabeanGroup,
+		customsSummary.GroupByMonth:       customsSummary.MonthGroup,
+		customsSummary.GroupByCustomer:    {Key: "p.cust_code", Label: "p.cust_name"},
+		customsSummary.GroupByItem:        {Key: normalizedItemCodeExpr, Label: "p.item_name"},
+		customsSummary.GroupByItemGroup:   customsSummary.ItemGroupGroup,
+		customsSummary.GroupByCurrency:    customsSummary.CurrencyGroup,
+	},
+	QuantityColumn: "p.dlv_qty",
+	UnitColumn:     "p.sales_unit",
+}
+
+// GetSummary mengelompokkan baris laporan (filter sama dengan GetReport, tanpa pagination) per groupBy:
+// jumlah dokumen, jumlah baris, kuantitas per satuan dan nilai per mata uang, plus total keseluruhan.
+func (c *ExpenditureProductRepository) GetSummary(ctx context.Context, filter GetReportFilter, groupBy string, limit int) ([]model.CustomsSummary, model.CustomsSummary, error) {
+	var rows []model.CustomsSummary
+	var total model.CustomsSummary
+	err := queryGuard.Run(ctx, c.db, func(tx *gorm.DB) error {
+		var err error
+		rows, total, err = customsSummary.Run(func() *gorm.DB { return c.filteredQuery(tx, filter) }, SummarySpec, groupBy, limit)
+		return err
+	})
+	return rows, total, err
+}
+
+// GetTotals menjumlahkan net_amount per mata uang beserta konversinya ke IDR untuk semua baris
+// yang cocok dengan filter (tanpa pagination).
+func (c *ExpenditureProductRepository) GetTotals(ctx context.Context, filter GetReportFilter) ([]model.CurrencyTotal, error) {
+	var totals []model.CurrencyTotal
+	err := queryGuard.Run(ctx, c.db, func(tx *gorm.DB) error {
+		return c.filteredQuery(tx, filter).
+			Joins(rateJoin).
+			Select(exchangeRate.TotalsSelect("p.curr_code", "p.net_amount")).
+			Group("p.curr_code").
+			Order("p.curr_code ASC").
+			Scan(&totals).Error
+	})
+	return totals, err
+}
+
+// GetPabeanValidation menghitung baris yang cocok dengan filter (tanpa pagination) yang jenis_pabean-nya
+// tidak ada di ms_pabean atau bukan dokumen pengeluaran, per jenis_pabean dan status.
+func (c *ExpenditureProductRepository) GetPabeanValidation(ctx context.Context, filter GetReportFilter) ([]model.PabeanValidationCount, error) {
+	status := pabeanType.StatusExpr(model.PabeanDirectionOut)
+
+	var counts []model.PabeanValidationCount
+	err := queryGuard.Run(ctx, c.db, func(tx *gorm.DB) error {
+		return c.filteredQuery(tx, filter).
+			Joins(pabeanJoin).
+			Select("p.jenis_pabean, "+status+" AS status, COUNT(*) AS line_count").
+			Where(status+" <> ?", model.PabeanStatusOk).
+			Group("p.jenis_pabean, " + status).
+			Order("p.jenis_pabean ASC").
+			Scan(&counts).Error
+	})
+	return counts, err
 }
@@ -0,0 +1,233 @@
+package expenditureProductRepository
+
+import (
+	"Bea-Cukai/helper/pabeanType"
+	"Bea-Cukai/model"
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open mock: %v", err)
+	}
+	return gormDB, mock
+}
+
+// Filter productGroup di-join ke ms_item dengan kode barang yang sudah dinormalisasi,
+// dan item_group ikut terbaca di hasil.
+func TestGetReport_ProductGroupJoinsNormalizedItemCode(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewExpenditureProductRepository(db)
+
+	join := regexp.QuoteMeta("LEFT JOIN ms_item i ON i.item_code = " + normalizedItemCodeExpr)
+	mock.ExpectQuery(`SELECT count\(\*\) FROM tr_pengeluaran_barang AS p `+join+`.*i\.item_group IN \(\?,\?\)`).
+		WithArgs("2026-09-01", "2026-09-30", "FG", "WIP").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT "+reportSelect+" FROM tr_pengeluaran_barang AS p ")+join+`.*`+regexp.QuoteMeta(rateJoin)).
+		WithArgs("2026-09-01", "2026-09-30", "FG", "WIP").
+		WillReturnRows(sqlmock.NewRows([]string{"idx", "item_code", "item_name", "item_group"}).
+			AddRow(1, "1FG-001", "Produk A", "FG"))
+
+	results, total, err := repo.GetReport(context.Background(), GetReportFilter{
+		From:         time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		ProductGroup: []string{"FG", "WIP"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1 || len(results) != 1 {
+		t.Fatalf("total = %d, len = %d; want 1, 1", total, len(results))
+	}
+	if results[0].ItemCode != "FG-001" || results[0].ItemGroup != "FG" {
+		t.Errorf("row = %s/%s; want FG-001/FG", results[0].ItemCode, results[0].ItemGroup)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
+
+// Context yang bisa dibatalkan (request HTTP) menjalankan count dan halaman laporan di satu
+// koneksi lewat queryGuard, sehingga keduanya bisa dihentikan dengan KILL QUERY.
+func TestGetReport_RunsThroughQueryGuard(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewExpenditureProductRepository(db)
+
+	mock.ExpectQuery(`SELECT CONNECTION_ID\(\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM tr_pengeluaran_barang AS p `).
+		WithArgs("2026-09-01", "2026-09-30").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`FROM tr_pengeluaran_barang AS p .*ORDER BY p\.tgl_pabean DESC LIMIT \?`).
+		WithArgs("2026-09-01", "2026-09-30", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"idx", "item_code"}).AddRow(1, "FG-001"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, total, err := repo.GetReport(ctx, GetReportFilter{
+		From:  time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:    time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		Page:  1,
+		Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1 || len(results) != 1 {
+		t.Fatalf("total = %d, len = %d; want 1, 1", total, len(results))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
+
+// Total per mata uang dikonversi ke IDR dengan kurs yang berlaku pada tgl_pabean; baris
+// tanpa kurs dihitung terpisah.
+func TestGetTotals_GroupsByCurrencyWithRateJoin(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewExpenditureProductRepository(db)
+
+	mock.ExpectQuery(`SELECT COALESCE\(p\.curr_code, ''\) AS curr_code.*`+regexp.QuoteMeta(rateJoin)+`.*GROUP BY .p.\..curr_code.`).
+		WithArgs("2026-09-01", "2026-09-30").
+		WillReturnRows(sqlmock.NewRows([]string{"curr_code", "line_count", "amount", "amount_idr", "missing_rate_count"}).
+			AddRow("IDR", 2, "1500000", "1500000", 0).
+			AddRow("USD", 3, "300", "3250000", 1))
+
+	totals, err := repo.GetTotals(context.Background(), GetReportFilter{
+		From: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(totals) != 2 {
+		t.Fatalf("len = %d; want 2", len(totals))
+	}
+	if totals[1].CurrCode != "USD" || !totals[1].AmountIdr.Equal(decimal.NewFromInt(3250000)) || totals[1].MissingRateCount != 1 {
+		t.Errorf("USD total = %+v", totals[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
+
+// Validasi jenis_pabean: baris yang tidak ada di ms_pabean atau dokumen pemasukan di laporan
+// pengeluaran dihitung per jenis_pabean dan status.
+func TestGetPabeanValidation_CountsUnknownAndWrongDirection(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewExpenditureProductRepository(db)
+
+	status := regexp.QuoteMeta(pabeanType.StatusExpr(model.PabeanDirectionOut))
+	mock.ExpectQuery(`SELECT p\.jenis_pabean, `+status+` AS status, COUNT\(\*\) AS line_count FROM tr_pengeluaran_barang AS p .*`+
+		regexp.QuoteMeta(pabeanJoin)+` WHERE \(p\.tgl_pabean BETWEEN \? AND \?\) AND `+status+` <> \? GROUP BY p\.jenis_pabean, `+status).
+		WithArgs("2026-09-01", "2026-09-30", model.PabeanStatusOk).
+		WillReturnRows(sqlmock.NewRows([]string{"jenis_pabean", "status", "line_count"}).
+			AddRow("BC 2.3", model.PabeanStatusWrongDirection, 2).
+			AddRow("BC 9.9", model.PabeanStatusUnknown, 1))
+
+	counts, err := repo.GetPabeanValidation(context.Background(), GetReportFilter{
+		From: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(counts) != 2 || counts[0].Status != model.PabeanStatusWrongDirection || counts[0].LineCount != 2 {
+		t.Errorf("counts = %+v", counts)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
+
+// Ringkasan per customer menggabungkan kuantitas per satuan dan nilai per mata uang ke
+// kelompoknya, diurutkan menurut jumlah dokumen, dan total dihitung dari query terpisah.
+func TestGetSummary_GroupsByCustomer(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewExpenditureProductRepository(db)
+	args := []driver.Value{"2026-09-01", "2026-09-30"}
+
+	mock.ExpectQuery(`SELECT COALESCE\(p\.cust_code, ''\) AS group_key.*COUNT\(DISTINCT p\.jenis_pabean, p\.no_pabean\).*GROUP BY .group_key.`).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"group_key", "group_label", "document_count", "line_count"}).
+			AddRow("C01", "Customer A", 1, 2).
+			AddRow("C02", "Customer B", 3, 5))
+	mock.ExpectQuery(`AS qty_unit, SUM\(p\.dlv_qty\) AS quantity .*GROUP BY .group_key.,.qty_unit.`).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"group_key", "qty_unit", "quantity"}).
+			AddRow("C01", "PCS", "10").
+			AddRow("C02", "KG", "2.5").
+			AddRow("C02", "PCS", "4"))
+	mock.ExpectQuery(`AS amount_curr, SUM\(p\.net_amount\) AS amount .*GROUP BY .group_key.,.amount_curr.`).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"group_key", "amount_curr", "amount"}).
+			AddRow("C01", "USD", "100").
+			AddRow("C02", "IDR", "5000"))
+	mock.ExpectQuery(`SELECT COALESCE\('TOTAL', ''\) AS group_key`).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"group_key", "group_label", "document_count", "line_count"}).
+			AddRow("TOTAL", "TOTAL", 4, 7))
+	mock.ExpectQuery(`SELECT COALESCE\('TOTAL', ''\) AS group_key, COALESCE\(p\.sales_unit, ''\) AS qty_unit`).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"group_key", "qty_unit", "quantity"}))
+	mock.ExpectQuery(`SELECT COALESCE\('TOTAL', ''\) AS group_key, COALESCE\(p\.curr_code, ''\) AS amount_curr`).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"group_key", "amount_curr", "amount"}))
+
+	rows, total, err := repo.GetSummary(context.Background(), GetReportFilter{
+		From: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+	}, "customer", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Key != "C02" || rows[1].Key != "C01" {
+		t.Fatalf("rows = %+v; want C02 then C01", rows)
+	}
+	if len(rows[0].Quantities) != 2 || rows[0].Quantities[0].Unit != "KG" || !rows[0].Quantities[0].Quantity.Equal(decimal.RequireFromString("2.5")) {
+		t.Errorf("C02 quantities = %+v", rows[0].Quantities)
+	}
+	if len(rows[1].Amounts) != 1 || rows[1].Amounts[0].CurrCode != "USD" {
+		t.Errorf("C01 amounts = %+v", rows[1].Amounts)
+	}
+	if total.DocumentCount != 4 || total.LineCount != 7 {
+		t.Errorf("total = %+v; want 4 documents, 7 lines", total)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// groupBy yang tidak didukung tabel pengeluaran (vendor) ditolak tanpa query.
+func TestGetSummary_RejectsUnknownGroupBy(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewExpenditureProductRepository(db)
+
+	if _, _, err := repo.GetSummary(context.Background(), GetReportFilter{}, "vendor", 0); err == nil {
+		t.Fatal("expected error for groupBy=vendor")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected queries: %v", err)
+	}
+}
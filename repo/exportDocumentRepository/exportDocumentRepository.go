@@ -0,0 +1,34 @@
+package exportDocumentRepository
+
+import (
+	"Bea-Cukai/model"
+
+	"gorm.io/gorm"
+)
+
+type ExportDocumentRepository struct {
+	db *gorm.DB
+}
+
+func NewExportDocumentRepository(db *gorm.DB) *ExportDocumentRepository {
+	return &ExportDocumentRepository{db: db}
+}
+
+// Create - catat file export baru
+func (r *ExportDocumentRepository) Create(doc *model.ExportDocument) error {
+	return r.db.Create(doc).Error
+}
+
+// GetById - get export document by Document ID
+func (r *ExportDocumentRepository) GetById(id string) (model.ExportDocument, error) {
+	var doc model.ExportDocument
+	err := r.db.Where("id = ?", id).First(&doc).Error
+	return doc, err
+}
+
+// GetBySha256 - export document pertama dengan hash file yang sama
+func (r *ExportDocumentRepository) GetBySha256(sha256 string) (model.ExportDocument, error) {
+	var doc model.ExportDocument
+	err := r.db.Where("sha256 = ?", sha256).Order("created_at ASC").First(&doc).Error
+	return doc, err
+}
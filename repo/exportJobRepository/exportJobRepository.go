@@ -0,0 +1,94 @@
+package exportJobRepository
+
+import (
+	"Bea-Cukai/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ExportJobRepository struct {
+	db *gorm.DB
+}
+
+func NewExportJobRepository(db *gorm.DB) *ExportJobRepository {
+	return &ExportJobRepository{db: db}
+}
+
+// Create - simpan job baru (status queued)
+func (r *ExportJobRepository) Create(job *model.ExportJob) error {
+	return r.db.Create(job).Error
+}
+
+// Save - update seluruh kolom job
+func (r *ExportJobRepository) Save(job *model.ExportJob) error {
+	return r.db.Save(job).Error
+}
+
+// GetById - get job by id
+func (r *ExportJobRepository) GetById(id string) (model.ExportJob, error) {
+	var job model.ExportJob
+	err := r.db.Where("id = ?", id).First(&job).Error
+	return job, err
+}
+
+// GetAll - get jobs with filtering and pagination; requestedById kosong = semua user
+func (r *ExportJobRepository) GetAll(requestedById string, req model.ExportJobListRequest) ([]model.ExportJob, int64, error) {
+	var jobs []model.ExportJob
+	var total int64
+
+	query := r.db.Model(&model.ExportJob{})
+
+	if requestedById != "" {
+		query = query.Where("requested_by_id = ?", requestedById)
+	}
+	if req.ReportType != "" {
+		query = query.Where("report_type = ?", req.ReportType)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if req.Page > 0 && req.Limit > 0 {
+		offset := (req.Page - 1) * req.Limit
+		query = query.Offset(offset).Limit(req.Limit)
+	}
+
+	if err := query.Order("created_at DESC").Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return jobs, total, nil
+}
+
+// RecordDownload - tambah download_count dan catat waktu download terakhir
+func (r *ExportJobRepository) RecordDownload(id string) error {
+	return r.db.Model(&model.ExportJob{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"download_count":     gorm.Expr("download_count + 1"),
+		"last_downloaded_at": time.Now(),
+	}).Error
+}
+
+// GetExpired - job selesai yang file-nya sudah melewati expires_at
+func (r *ExportJobRepository) GetExpired(now time.Time) ([]model.ExportJob, error) {
+	var jobs []model.ExportJob
+	err := r.db.Where("status = ? AND expires_at <= ?", model.ExportStatusDone, now).Find(&jobs).Error
+	return jobs, err
+}
+
+// FailStale - tandai job queued/running yang dibuat sebelum before sebagai failed
+// (misalnya karena server restart saat job berjalan)
+func (r *ExportJobRepository) FailStale(before time.Time, reason string) (int64, error) {
+	res := r.db.Model(&model.ExportJob{}).
+		Where("status IN ? AND created_at < ?", []string{model.ExportStatusQueued, model.ExportStatusRunning}, before).
+		Updates(map[string]interface{}{
+			"status":      model.ExportStatusFailed,
+			"error":       reason,
+			"finished_at": time.Now(),
+		})
+	return res.RowsAffected, res.Error
+}
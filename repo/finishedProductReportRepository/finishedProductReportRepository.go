@@ -1,6 +1,8 @@
 package finishedProductReportRepository
 
 import (
+	"Bea-Cukai/helper/queryGuard"
+	"Bea-Cukai/helper/reportQuery"
 	"Bea-Cukai/model"
 	"context"
 	"fmt"
@@ -26,10 +28,41 @@ type GetReportFilter struct {
 	To       time.Time
 	ItemCode string
 	ItemName string
+	GroupBy  string // "" (gabungan) atau GroupByLocation
+	Location string // filter location_code; otomatis memakai query per lokasi
+	Options  reportQuery.Options
 	Page     int
 	Limit    int
 }
 
+// ReportColumns adalah whitelist kolom untuk parameter sort/filter laporan ini.
+var ReportColumns = reportQuery.Columns{
+	"item_code":      {Expr: "rq.item_code"},
+	"item_name":      {Expr: "rq.item_name"},
+	"unit_code":      {Expr: "rq.unit_code"},
+	"item_type_code": {Expr: "rq.item_type_code"},
+	"location_code":  {Expr: "rq.location_code"},
+	"awal":           {Expr: "rq.awal", Numeric: true},
+	"masuk":          {Expr: "rq.masuk", Numeric: true},
+	"keluar":         {Expr: "rq.keluar", Numeric: true},
+	"peny":           {Expr: "rq.peny", Numeric: true},
+	"akhir":          {Expr: "rq.akhir", Numeric: true},
+	"opname":         {Expr: "rq.opname", Numeric: true},
+	"selisih":        {Expr: "rq.selisih", Numeric: true},
+}
+
+// GroupByLocation memecah laporan menjadi satu baris per item per lokasi.
+const GroupByLocation = "location"
+
+// Lokasi produk jadi, sesuai kolom tr_inv_produk_harian_det.
+// Mutasi (produk masuk, ekspor, penyesuaian) tidak punya kolom lokasi dan dicatat di LocationWH2.
+const (
+	LocationWH1   = "WH1"
+	LocationWH2   = "WH2"
+	LocationMesin = "MESIN"
+	LocationQC    = "QC"
+)
+
 // productOpnameDates menyimpan tanggal opname per tipe head yang dibutuhkan laporan.
 //
 // Struktur data di tr_inv_produk_harian_head:
@@ -109,8 +142,8 @@ func (r *FinishedProductReportRepository) getAllProductOpnameDates(ctx context.C
 	return dates, nil
 }
 
-// CTE c/d/e menangani periode laporan (filter.From → filter.To) untuk kolom masuk/keluar/peny.
-func buildBaseQuery(dates productOpnameDates, filter GetReportFilter) (string, []any) {
+// itemFilterConditions membangun kondisi WHERE tambahan untuk filter item (alias ms_item = a).
+func itemFilterConditions(filter GetReportFilter) (string, []any) {
 	whereConditions := ""
 	extraArgs := []any{}
 
@@ -122,15 +155,48 @@ func buildBaseQuery(dates productOpnameDates, filter GetReportFilter) (string, [
 		whereConditions += " AND a.item_name LIKE ?"
 		extraArgs = append(extraArgs, "%"+filter.ItemName+"%")
 	}
+	return whereConditions, extraArgs
+}
 
-	awalExpr := "(IFNULL(b.awal, 0) + IFNULL(masuk_awal.masuk, 0) - IFNULL(keluar_awal.keluar, 0) + IFNULL(peny_awal.peny, 0))"
+// finishedProductExprs mengembalikan ekspresi awal, akhir dan opname yang dipakai
+// baik oleh query gabungan maupun query per lokasi.
+func finishedProductExprs(dates productOpnameDates, filter GetReportFilter) (awalExpr, akhirExpr, opnameExpr string) {
+	awalExpr = "(IFNULL(b.awal, 0) + IFNULL(masuk_awal.masuk, 0) - IFNULL(keluar_awal.keluar, 0) + IFNULL(peny_awal.peny, 0))"
 
-	akhirExpr := fmt.Sprintf("%s + IFNULL(c.masuk, 0) - IFNULL(d.keluar, 0) + IFNULL(e.peny, 0)", awalExpr)
+	akhirExpr = fmt.Sprintf("%s + IFNULL(c.masuk, 0) - IFNULL(d.keluar, 0) + IFNULL(e.peny, 0)", awalExpr)
 
-	opnameExpr := "IFNULL(f.opname, 0)"
+	opnameExpr = "IFNULL(f.opname, 0)"
 	if dates.TglAkhirGudang2.Format("2006-01-02") != filter.To.Format("2006-01-02") {
 		opnameExpr = akhirExpr
 	}
+	return awalExpr, akhirExpr, opnameExpr
+}
+
+// baseQueryArgs mengembalikan argumen tanggal untuk CTE b..f.
+// Urutan CTE sama antara buildBaseQuery dan buildLocationQuery.
+func baseQueryArgs(dates productOpnameDates, filter GetReportFilter) []any {
+	return []any{
+		dates.TglAwalGudang2.Format("2006-01-02"),  // b:           opname_gudang2=1 AND trans_date = ?
+		dates.TglAwalGudang2.Format("2006-01-02"),  // masuk_awal:  tgl_proses > ?
+		filter.From.Format("2006-01-02"),           // masuk_awal:  tgl_proses <= ?
+		dates.TglAwalGudang2.Format("2006-01-02"),  // keluar_awal: tgl_ekspor > ?
+		filter.From.Format("2006-01-02"),           // keluar_awal: tgl_ekspor <= ?
+		dates.TglAwalGudang2.Format("2006-01-02"),  // peny_awal:   trans_date > ?
+		filter.From.Format("2006-01-02"),           // peny_awal:   trans_date <= ?
+		filter.From.Format("2006-01-02"),           // c:           tgl_proses > ?
+		filter.To.Format("2006-01-02"),             // c:           tgl_proses <= ?
+		filter.From.Format("2006-01-02"),           // d:           tgl_ekspor > ?
+		filter.To.Format("2006-01-02"),             // d:           tgl_ekspor <= ?
+		filter.From.Format("2006-01-02"),           // e:           trans_date > ?
+		filter.To.Format("2006-01-02"),             // e:           trans_date <= ?
+		dates.TglAkhirGudang2.Format("2006-01-02"), // f:           opname_gudang2=1 AND trans_date = ?
+	}
+}
+
+// CTE c/d/e menangani periode laporan (filter.From → filter.To) untuk kolom masuk/keluar/peny.
+func buildBaseQuery(dates productOpnameDates, filter GetReportFilter) (string, []any) {
+	whereConditions, extraArgs := itemFilterConditions(filter)
+	awalExpr, akhirExpr, opnameExpr := finishedProductExprs(dates, filter)
 
 	query := fmt.Sprintf(`
 		WITH b AS (
@@ -222,24 +288,142 @@ func buildBaseQuery(dates productOpnameDates, filter GetReportFilter) (string, [
 		WHERE a.awal <> 0 OR a.opname <> 0 OR a.keluar <> 0 OR a.peny <> 0 OR akhir <> 0
 	`, awalExpr, akhirExpr, opnameExpr, akhirExpr, opnameExpr, whereConditions)
 
-	baseArgs := []any{
-		dates.TglAwalGudang2.Format("2006-01-02"),  // b:           opname_gudang2=1 AND trans_date = ?
-		dates.TglAwalGudang2.Format("2006-01-02"),  // masuk_awal:  tgl_proses > ?
-		filter.From.Format("2006-01-02"),           // masuk_awal:  tgl_proses <= ?
-		dates.TglAwalGudang2.Format("2006-01-02"),  // keluar_awal: tgl_ekspor > ?
-		filter.From.Format("2006-01-02"),           // keluar_awal: tgl_ekspor <= ?
-		dates.TglAwalGudang2.Format("2006-01-02"),  // peny_awal:   trans_date > ?
-		filter.From.Format("2006-01-02"),           // peny_awal:   trans_date <= ?
-		filter.From.Format("2006-01-02"),           // c:           tgl_proses > ?
-		filter.To.Format("2006-01-02"),             // c:           tgl_proses <= ?
-		filter.From.Format("2006-01-02"),           // d:           tgl_ekspor > ?
-		filter.To.Format("2006-01-02"),             // d:           tgl_ekspor <= ?
-		filter.From.Format("2006-01-02"),           // e:           trans_date > ?
-		filter.To.Format("2006-01-02"),             // e:           trans_date <= ?
-		dates.TglAkhirGudang2.Format("2006-01-02"), // f:           opname_gudang2=1 AND trans_date = ?
+	return query, append(baseQueryArgs(dates, filter), extraArgs...)
+}
+
+// buildLocationQuery adalah varian buildBaseQuery yang menghasilkan satu baris per item per lokasi.
+// Opname (b, f) di-unpivot dari kolom wh1/wh2/mesin/qc head opname_gudang2=1; menurut struktur
+// data hanya wh2 yang terisi pada head tersebut sehingga total per item sama dengan buildBaseQuery.
+// Produk masuk, ekspor dan penyesuaian tidak punya kolom lokasi → LocationWH2.
+// Urutan args identik dengan buildBaseQuery, ditambah filter lokasi di akhir.
+func buildLocationQuery(dates productOpnameDates, filter GetReportFilter) (string, []any) {
+	whereConditions, extraArgs := itemFilterConditions(filter)
+	awalExpr, akhirExpr, opnameExpr := finishedProductExprs(dates, filter)
+
+	locationCondition := ""
+	if filter.Location != "" {
+		locationCondition = " AND a.location_code = ?"
+		extraArgs = append(extraArgs, filter.Location)
 	}
 
-	return query, append(baseArgs, extraArgs...)
+	// unpivot kolom lokasi det opname_gudang2=1 pada satu tanggal → (item_code, location_code, qty)
+	opnameByLocation := func(alias string) string {
+		return fmt.Sprintf(`
+			SELECT src.item_code, l.location_code,
+				SUM(CASE l.location_code
+					WHEN '%[2]s' THEN src.wh1
+					WHEN '%[3]s' THEN src.wh2
+					WHEN '%[4]s' THEN src.mesin
+					ELSE src.qc
+				END) AS %[1]s
+			FROM (
+				SELECT det.item_code, det.wh1, det.wh2, det.mesin, det.qc
+				FROM tr_inv_produk_harian_head head
+				INNER JOIN tr_inv_produk_harian_det det ON head.trans_no = det.trans_no
+				WHERE (head.opname_gudang2 = 1 AND head.trans_date = ?)
+			) src
+			CROSS JOIN (
+				SELECT '%[2]s' AS location_code UNION ALL SELECT '%[3]s' UNION ALL SELECT '%[4]s' UNION ALL SELECT '%[5]s'
+			) l
+			GROUP BY src.item_code, l.location_code`,
+			alias, LocationWH1, LocationWH2, LocationMesin, LocationQC)
+	}
+
+	query := fmt.Sprintf(`
+		WITH b AS (%[7]s
+		),
+		masuk_awal AS (
+			SELECT no_produk, '%[9]s' AS location_code, SUM(isi_palet) AS masuk
+			FROM tr_produk_in_head
+			WHERE tgl_proses > ? AND tgl_proses < ?
+			GROUP BY no_produk
+		),
+		keluar_awal AS (
+			SELECT b.no_produk, '%[9]s' AS location_code, SUM(isi_palet) AS keluar
+			FROM tr_export_head a
+			INNER JOIN tr_export_det b ON a.trans_no = b.trans_no
+			WHERE a.tgl_ekspor > ? AND a.tgl_ekspor < ?
+			GROUP BY b.no_produk
+		),
+		peny_awal AS (
+			SELECT b.item_code, '%[9]s' AS location_code, SUM(qty) AS peny
+			FROM tr_inv_adjust_head a
+			INNER JOIN tr_inv_adjust_det b ON a.trans_no = b.trans_no
+			LEFT JOIN ms_item c ON b.item_code = c.item_code
+			WHERE a.trans_date > ? AND a.trans_date < ? AND c.item_group = 'PRODUCT'
+			GROUP BY b.item_code
+		),
+		c AS (
+			SELECT no_produk, '%[9]s' AS location_code, SUM(isi_palet) AS masuk
+			FROM tr_produk_in_head
+			WHERE tgl_proses >= ? AND tgl_proses <= ?
+			GROUP BY no_produk
+		),
+		d AS (
+			SELECT b.no_produk, '%[9]s' AS location_code, SUM(isi_palet) AS keluar
+			FROM tr_export_head a
+			INNER JOIN tr_export_det b ON a.trans_no = b.trans_no
+			WHERE a.tgl_ekspor >= ? AND a.tgl_ekspor <= ?
+			GROUP BY b.no_produk
+		),
+		e AS (
+			SELECT b.item_code, '%[9]s' AS location_code, SUM(qty) AS peny
+			FROM tr_inv_adjust_head a
+			INNER JOIN tr_inv_adjust_det b ON a.trans_no = b.trans_no
+			LEFT JOIN ms_item c ON b.item_code = c.item_code
+			WHERE a.trans_date >= ? AND a.trans_date <= ? AND c.item_group = 'PRODUCT'
+			GROUP BY b.item_code
+		),
+		f AS (%[8]s
+		),
+		loc AS (
+			SELECT item_code, location_code FROM b
+			UNION SELECT no_produk, location_code FROM masuk_awal
+			UNION SELECT no_produk, location_code FROM keluar_awal
+			UNION SELECT item_code, location_code FROM peny_awal
+			UNION SELECT no_produk, location_code FROM c
+			UNION SELECT no_produk, location_code FROM d
+			UNION SELECT item_code, location_code FROM e
+			UNION SELECT item_code, location_code FROM f
+		),
+		a AS (
+			SELECT
+				a.item_code, a.item_name, a.unit_code, a.item_type_code, a.item_group,
+				loc.location_code,
+				%[1]s AS awal,
+				IFNULL(c.masuk, 0) AS masuk,
+				IFNULL(d.keluar, 0) AS keluar,
+				IFNULL(e.peny, 0) AS peny,
+				%[2]s AS akhir,
+				%[3]s AS opname,
+				(%[4]s) - (%[5]s) AS selisih
+			FROM loc
+			INNER JOIN ms_item a ON a.item_code = loc.item_code
+			LEFT JOIN b ON loc.item_code = b.item_code AND loc.location_code = b.location_code
+			LEFT JOIN masuk_awal ON loc.item_code = masuk_awal.no_produk AND loc.location_code = masuk_awal.location_code
+			LEFT JOIN keluar_awal ON loc.item_code = keluar_awal.no_produk AND loc.location_code = keluar_awal.location_code
+			LEFT JOIN peny_awal ON loc.item_code = peny_awal.item_code AND loc.location_code = peny_awal.location_code
+			LEFT JOIN c ON loc.item_code = c.no_produk AND loc.location_code = c.location_code
+			LEFT JOIN d ON loc.item_code = d.no_produk AND loc.location_code = d.location_code
+			LEFT JOIN e ON loc.item_code = e.item_code AND loc.location_code = e.location_code
+			LEFT JOIN f ON loc.item_code = f.item_code AND loc.location_code = f.location_code
+			WHERE a.item_group = 'PRODUCT' %[6]s
+		)
+		SELECT a.*
+		FROM a
+		WHERE (a.awal <> 0 OR a.opname <> 0 OR a.keluar <> 0 OR a.peny <> 0 OR akhir <> 0)%[10]s
+	`, awalExpr, akhirExpr, opnameExpr, akhirExpr, opnameExpr, whereConditions,
+		opnameByLocation("awal"), opnameByLocation("opname"), LocationWH2, locationCondition)
+
+	return query, append(baseQueryArgs(dates, filter), extraArgs...)
+}
+
+// buildReportQuery memilih query gabungan atau per lokasi sesuai filter.
+func buildReportQuery(dates productOpnameDates, filter GetReportFilter) (string, []any) {
+	if filter.GroupBy == GroupByLocation || filter.Location != "" {
+		return buildLocationQuery(dates, filter)
+	}
+	return buildBaseQuery(dates, filter)
 }
 
 // GetReport mengambil laporan produk jadi dengan kalkulasi inventori kompleks.
@@ -252,7 +436,9 @@ func (r *FinishedProductReportRepository) GetReport(ctx context.Context, filter
 		return nil, 0, err
 	}
 
-	baseQuery, queryArgs := buildBaseQuery(dates, filter)
+	baseQuery, queryArgs := buildReportQuery(dates, filter)
+	baseQuery, queryArgs = filter.Options.Apply(baseQuery, queryArgs, ReportColumns)
+	orderBy := filter.Options.OrderBy(ReportColumns, reportQuery.DefaultOrder)
 
 	var (
 		results    []model.FinishedProductReportResponse
@@ -271,13 +457,13 @@ func (r *FinishedProductReportRepository) GetReport(ctx context.Context, filter
 		}
 
 		paginatedQuery := fmt.Sprintf(`
-			SELECT inner_q.*, COUNT(*) OVER() AS _total_count
-			FROM (%s) AS inner_q
+			SELECT rq.*, COUNT(*) OVER() AS _total_count
+			FROM (%s) AS rq%s
 			LIMIT %d OFFSET %d
-		`, baseQuery, filter.Limit, offset)
+		`, baseQuery, orderBy, filter.Limit, offset)
 
 		var rows []rowWithCount
-		if err = r.db.WithContext(ctx).Raw(paginatedQuery, queryArgs...).Scan(&rows).Error; err != nil {
+		if err = queryGuard.Scan(ctx, r.db, &rows, paginatedQuery, queryArgs...); err != nil {
 			return nil, 0, err
 		}
 
@@ -289,7 +475,7 @@ func (r *FinishedProductReportRepository) GetReport(ctx context.Context, filter
 			totalCount = rows[0].TotalCount
 		}
 	} else {
-		if err = r.db.WithContext(ctx).Raw(baseQuery, queryArgs...).Scan(&results).Error; err != nil {
+		if err = queryGuard.Scan(ctx, r.db, &results, baseQuery+orderBy, queryArgs...); err != nil {
 			return nil, 0, err
 		}
 		totalCount = int64(len(results))
@@ -297,3 +483,40 @@ func (r *FinishedProductReportRepository) GetReport(ctx context.Context, filter
 
 	return results, totalCount, nil
 }
+
+// GetPeriodBalances mengambil seluruh baris laporan satu periode (tanpa pagination)
+// beserta tanggal opname gudang2 yang dipakai sebagai dasar saldo awal.
+// Dipakai oleh pengecekan kesinambungan saldo antar periode.
+func (r *FinishedProductReportRepository) GetPeriodBalances(ctx context.Context, filter GetReportFilter) ([]model.FinishedProductReportResponse, time.Time, error) {
+	dates, err := r.getAllProductOpnameDates(ctx, filter.From, filter.To)
+	if err != nil {
+		return nil, dates.TglAwalGudang2, err
+	}
+
+	baseQuery, queryArgs := buildBaseQuery(dates, filter)
+
+	var results []model.FinishedProductReportResponse
+	if err = queryGuard.Scan(ctx, r.db, &results, baseQuery, queryArgs...); err != nil {
+		return nil, dates.TglAwalGudang2, err
+	}
+	return results, dates.TglAwalGudang2, nil
+}
+
+// GetBaseReport menjalankan buildBaseQuery (satu baris per item, tanpa lokasi) untuk satu periode
+// tanpa pagination, dengan filter Options; sort Options diabaikan.
+// Dipakai mode perbandingan periode (compare=previous|yoy|custom).
+func (r *FinishedProductReportRepository) GetBaseReport(ctx context.Context, filter GetReportFilter) ([]model.FinishedProductReportResponse, error) {
+	dates, err := r.getAllProductOpnameDates(ctx, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+
+	baseQuery, queryArgs := buildBaseQuery(dates, filter)
+	baseQuery, queryArgs = filter.Options.Apply(baseQuery, queryArgs, ReportColumns)
+
+	var results []model.FinishedProductReportResponse
+	if err = queryGuard.Scan(ctx, r.db, &results, baseQuery, queryArgs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
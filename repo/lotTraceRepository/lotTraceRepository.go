@@ -0,0 +1,196 @@
+package lotTraceRepository
+
+import (
+	"Bea-Cukai/helper/exchangeRate"
+	"Bea-Cukai/helper/pabeanType"
+	"Bea-Cukai/helper/queryGuard"
+	"Bea-Cukai/model"
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ---- Constructor ----
+
+type LotTraceRepository struct {
+	db *gorm.DB
+}
+
+func NewLotTraceRepository(db *gorm.DB) *LotTraceRepository {
+	return &LotTraceRepository{db: db}
+}
+
+// Filter memilih lot (baris tr_ap_inv_det). AsOf adalah batas tanggal penerimaan dan pemakaian
+// yang dihitung; From/To membatasi tgl_pabean dokumen pemasukan (laporan saldo lot).
+type Filter struct {
+	AsOf          time.Time
+	From          time.Time // zero = tanpa batas periode dokumen
+	To            time.Time
+	PabeanType    string
+	NoPabean      string
+	TransNo       string
+	DataNo        string
+	ItemCode      string
+	ItemGroup     []string // ms_item.item_group; kosong = semua grup
+	OnlyRemaining bool     // hanya lot yang masih bersisa pada AsOf
+	Page          int
+	Limit         int
+}
+
+const lotSelect = `d.data_no, d.trans_no, h.in_date, d.item_code,
+	COALESCE(i.item_name, '') AS item_name, COALESCE(i.item_group, '') AS item_group, COALESCE(i.unit_code, '') AS unit,
+	COALESCE(doc.vendor_name, '') AS vendor_name,
+	COALESCE(doc.jenis_pabean, '') AS jenis_pabean, COALESCE(doc.no_pabean, '') AS no_pabean, doc.tgl_pabean,
+	d.qty AS received_qty, COALESCE(rm.consumed_qty, 0) AS consumed_qty,
+	d.qty - COALESCE(rm.consumed_qty, 0) AS remaining_qty`
+
+// remainingExpr adalah sisa lot pada AsOf; butuh join rm.
+const remainingExpr = "d.qty - COALESCE(rm.consumed_qty, 0)"
+
+// unitPriceExpr adalah harga satuan rata-rata item pada dokumen pemasukan (NULL bila qty dokumen 0).
+const unitPriceExpr = "doc.net_amount / NULLIF(doc.rcv_qty, 0)"
+
+// agingRateJoin menggabungkan kurs yang berlaku pada tgl_pabean dokumen pemasukan lot.
+var agingRateJoin = exchangeRate.Join("doc.curr_code", "doc.tgl_pabean")
+
+// agingPabeanJoin menggabungkan master ms_pabean (alias mp) untuk jenis dokumen pemasukan lot.
+var agingPabeanJoin = pabeanType.Join("doc.jenis_pabean")
+
+// agingSelect adalah lotSelect ditambah harga, kurs dan nilai sisa IDR; butuh agingRateJoin.
+var agingSelect = lotSelect + fmt.Sprintf(`, COALESCE(doc.curr_code, '') AS curr_code, %[1]s AS unit_price,
+	%[2]s AS idr_rate, (%[3]s) * %[1]s * %[2]s AS remaining_value_idr, (%[2]s IS NULL) AS rate_missing`,
+	unitPriceExpr, exchangeRate.RateExpr("doc.curr_code"), remainingExpr)
+
+// lotQuery membangun query lot dengan semua filter (tanpa select / order / pagination).
+// Dokumen pabean dicari lewat trans_no + item_code seperti rekonsiliasi pemasukan; pemakaian
+// dijumlahkan per data_no seperti CTE keluar laporan bahan baku. db adalah koneksi dari
+// queryGuard.Run; setiap pemanggilan memulai statement baru di koneksi itu.
+func (r *LotTraceRepository) lotQuery(db *gorm.DB, filter Filter) *gorm.DB {
+	asOf := filter.AsOf.Format("2006-01-02")
+	db = db.Session(&gorm.Session{NewDB: true})
+
+	doc := db.Table(model.EntryProduct{}.TableName() + " AS p").
+		Select(`p.trans_no, p.item_code, MAX(p.vendor_name) AS vendor_name,
+			MAX(p.jenis_pabean) AS jenis_pabean, MAX(p.no_pabean) AS no_pabean, MIN(p.tgl_pabean) AS tgl_pabean,
+			MAX(p.curr_code) AS curr_code, SUM(p.net_amount) AS net_amount, SUM(p.rcv_qty) AS rcv_qty`).
+		Group("p.trans_no, p.item_code")
+	consumed := db.Table("tr_inv_rm_head AS rh").
+		Select("rd.data_no, SUM(rd.qty) AS consumed_qty").
+		Joins("INNER JOIN tr_inv_rm_det rd ON rh.trans_no = rd.trans_no").
+		Where("rh.trans_date <= ?", asOf).
+		Group("rd.data_no")
+
+	query := db.Table("tr_ap_inv_det AS d").
+		Joins("INNER JOIN tr_ap_inv_head h ON h.trans_no = d.trans_no").
+		Joins("LEFT JOIN (?) AS doc ON doc.trans_no = d.trans_no AND doc.item_code = d.item_code", doc).
+		Joins("LEFT JOIN (?) AS rm ON rm.data_no = d.data_no", consumed).
+		Joins("LEFT JOIN ms_item i ON i.item_code = d.item_code").
+		Where("h.in_date <= ?", asOf)
+
+	if !filter.From.IsZero() && !filter.To.IsZero() {
+		query = query.Where("doc.tgl_pabean BETWEEN ? AND ?", filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02"))
+	}
+	if filter.PabeanType != "" {
+		query = query.Where(pabeanType.NormalizedSQL("doc.jenis_pabean")+" = ?", pabeanType.Normalize(filter.PabeanType))
+	}
+	if filter.NoPabean != "" {
+		query = query.Where("doc.no_pabean = ?", filter.NoPabean)
+	}
+	if filter.TransNo != "" {
+		query = query.Where("d.trans_no = ?", filter.TransNo)
+	}
+	if filter.DataNo != "" {
+		query = query.Where("d.data_no = ?", filter.DataNo)
+	}
+	if filter.ItemCode != "" {
+		query = query.Where("d.item_code = ?", filter.ItemCode)
+	}
+	if len(filter.ItemGroup) > 0 {
+		query = query.Where("i.item_group IN ?", filter.ItemGroup)
+	}
+	if filter.OnlyRemaining {
+		query = query.Where(remainingExpr + " > 0")
+	}
+	return query
+}
+
+// GetLots mengembalikan lot yang cocok dengan filter, urut penerimaan (FIFO), beserta jumlah total
+// sebelum pagination.
+func (r *LotTraceRepository) GetLots(ctx context.Context, filter Filter) ([]model.Lot, int64, error) {
+	var lots []model.Lot
+	var totalCount int64
+	err := queryGuard.Run(ctx, r.db, func(tx *gorm.DB) error {
+		query := r.lotQuery(tx, filter)
+
+		if err := query.Count(&totalCount).Error; err != nil {
+			return err
+		}
+
+		if filter.Limit > 0 && filter.Page > 0 {
+			query = query.Limit(filter.Limit).Offset((filter.Page - 1) * filter.Limit)
+		}
+
+		return query.Select(lotSelect).
+			Order("h.in_date ASC, d.trans_no ASC, d.data_no ASC").
+			Scan(&lots).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return lots, totalCount, nil
+}
+
+// GetLotBalance mengembalikan lot per dokumen pabean (urut jenis, nomor dokumen lalu penerimaan)
+// untuk laporan saldo lot; tanpa pagination.
+func (r *LotTraceRepository) GetLotBalance(ctx context.Context, filter Filter) ([]model.Lot, error) {
+	var lots []model.Lot
+	err := queryGuard.Run(ctx, r.db, func(tx *gorm.DB) error {
+		return r.lotQuery(tx, filter).
+			Where("doc.no_pabean IS NOT NULL").
+			Select(lotSelect).
+			Order("doc.tgl_pabean ASC, doc.jenis_pabean ASC, doc.no_pabean ASC, h.in_date ASC, d.data_no ASC").
+			Scan(&lots).Error
+	})
+	return lots, err
+}
+
+// GetAging mengembalikan lot berdokumen pabean yang masih bersisa pada filter.AsOf beserta nilai
+// sisanya dalam IDR, urut penerimaan terlama; umur dihitung service. Hanya dokumen yang di ms_pabean
+// mendapat penangguhan bea masuk (duty_suspended) yang ikut; jenis dokumen lain / tidak dikenal
+// tidak punya batas waktu penyelesaian.
+func (r *LotTraceRepository) GetAging(ctx context.Context, filter Filter) ([]model.LotAging, error) {
+	filter.OnlyRemaining = true
+
+	var lots []model.LotAging
+	err := queryGuard.Run(ctx, r.db, func(tx *gorm.DB) error {
+		return r.lotQuery(tx, filter).
+			Where("doc.no_pabean IS NOT NULL").
+			Joins(agingPabeanJoin).
+			Where("mp.duty_suspended = ?", true).
+			Joins(agingRateJoin).
+			Select(agingSelect).
+			Order("h.in_date ASC, doc.jenis_pabean ASC, doc.no_pabean ASC, d.data_no ASC").
+			Scan(&lots).Error
+	})
+	return lots, err
+}
+
+// GetConsumptions mengembalikan pemakaian lot-lot dataNos sampai asOf, urut tanggal.
+func (r *LotTraceRepository) GetConsumptions(ctx context.Context, dataNos []string, asOf time.Time) ([]model.LotConsumption, error) {
+	if len(dataNos) == 0 {
+		return nil, nil
+	}
+
+	var consumptions []model.LotConsumption
+	err := queryGuard.Run(ctx, r.db, func(tx *gorm.DB) error {
+		return tx.Table("tr_inv_rm_head AS rh").
+			Select("rd.data_no, rh.trans_no, rh.trans_date, rd.qty").
+			Joins("INNER JOIN tr_inv_rm_det rd ON rh.trans_no = rd.trans_no").
+			Where("rd.data_no IN ? AND rh.trans_date <= ?", dataNos, asOf.Format("2006-01-02")).
+			Order("rh.trans_date ASC, rh.trans_no ASC").
+			Scan(&consumptions).Error
+	})
+	return consumptions, err
+}
@@ -0,0 +1,179 @@
+package lotTraceRepository
+
+import (
+	"Bea-Cukai/helper/pabeanType"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open mock: %v", err)
+	}
+	return gormDB, mock
+}
+
+// Asal stok: lot item yang diterima sampai tanggal dan masih bersisa setelah pemakaian sampai
+// tanggal yang sama (tr_inv_rm_det.data_no = tr_ap_inv_det.data_no), urut penerimaan.
+func TestGetLots_RemainingAsOfDate(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewLotTraceRepository(db)
+
+	from := `FROM tr_ap_inv_det AS d INNER JOIN tr_ap_inv_head h ON h\.trans_no = d\.trans_no ` +
+		`LEFT JOIN \(SELECT p\.trans_no, p\.item_code.*FROM tr_pemasukan_barang AS p GROUP BY p\.trans_no, p\.item_code\) AS doc ON doc\.trans_no = d\.trans_no AND doc\.item_code = d\.item_code ` +
+		`LEFT JOIN \(SELECT rd\.data_no, SUM\(rd\.qty\) AS consumed_qty FROM tr_inv_rm_head AS rh INNER JOIN tr_inv_rm_det rd ON rh\.trans_no = rd\.trans_no WHERE rh\.trans_date <= \? GROUP BY .rd.\..data_no.\) AS rm ON rm\.data_no = d\.data_no ` +
+		`LEFT JOIN ms_item i ON i\.item_code = d\.item_code ` +
+		`WHERE h\.in_date <= \? AND d\.item_code = \? AND ` + regexp.QuoteMeta(remainingExpr+" > 0")
+	mock.ExpectQuery(`SELECT count\(\*\) `+from).
+		WithArgs("2026-09-15", "2026-09-15", "RM-001").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT d\.data_no, d\.trans_no, h\.in_date.* `+from+` ORDER BY h\.in_date ASC`).
+		WithArgs("2026-09-15", "2026-09-15", "RM-001").
+		WillReturnRows(sqlmock.NewRows([]string{"data_no", "trans_no", "in_date", "item_code", "no_pabean", "received_qty", "consumed_qty", "remaining_qty"}).
+			AddRow("77", "AP-001", time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), "RM-001", "000100", "100", "40", "60"))
+
+	lots, total, err := repo.GetLots(context.Background(), Filter{
+		AsOf:          time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC),
+		ItemCode:      "RM-001",
+		OnlyRemaining: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1 || len(lots) != 1 {
+		t.Fatalf("total = %d, len = %d; want 1, 1", total, len(lots))
+	}
+	if lots[0].DataNo != "77" || lots[0].NoPabean != "000100" || !lots[0].RemainingQty.Equal(decimal.NewFromInt(60)) {
+		t.Errorf("lot = %+v", lots[0])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
+
+// Context yang bisa dibatalkan (request HTTP) menjalankan count dan halaman lot di satu koneksi
+// lewat queryGuard, sehingga keduanya bisa dihentikan dengan KILL QUERY.
+func TestGetLots_RunsThroughQueryGuard(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewLotTraceRepository(db)
+
+	mock.ExpectQuery(`SELECT CONNECTION_ID\(\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM tr_ap_inv_det AS d `).
+		WithArgs("2026-09-15", "2026-09-15").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`FROM tr_ap_inv_det AS d .*ORDER BY h\.in_date ASC.* LIMIT \?`).
+		WithArgs("2026-09-15", "2026-09-15", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"data_no"}).AddRow("77"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lots, total, err := repo.GetLots(ctx, Filter{
+		AsOf:  time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC),
+		Page:  1,
+		Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1 || len(lots) != 1 {
+		t.Fatalf("total = %d, len = %d; want 1, 1", total, len(lots))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
+
+// Filter pabeanType dinormalisasi seperti jenis_pabean: "BC 2.3" cocok dengan "BC2.3" dan "23".
+func TestGetLotBalance_NormalizesPabeanType(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewLotTraceRepository(db)
+
+	mock.ExpectQuery(`SELECT d\.data_no.*WHERE h\.in_date <= \? AND `+
+		regexp.QuoteMeta(pabeanType.NormalizedSQL("doc.jenis_pabean"))+` = \? AND doc\.no_pabean IS NOT NULL ORDER BY doc\.tgl_pabean ASC`).
+		WithArgs("2026-09-15", "2026-09-15", "23").
+		WillReturnRows(sqlmock.NewRows([]string{"data_no", "jenis_pabean"}).AddRow("77", "BC2.3"))
+
+	lots, err := repo.GetLotBalance(context.Background(), Filter{
+		AsOf:       time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC),
+		PabeanType: "BC 2.3",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lots) != 1 {
+		t.Fatalf("len = %d; want 1", len(lots))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
+
+// Umur bahan baku impor: hanya lot berdokumen penangguhan bea yang masih bersisa, kurs pada
+// tgl_pabean, urut penerimaan terlama.
+func TestGetAging_RemainingDocumentedLotsWithRate(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewLotTraceRepository(db)
+
+	mock.ExpectQuery(`SELECT d\.data_no.*`+regexp.QuoteMeta("AS remaining_value_idr")+`.*`+
+		regexp.QuoteMeta(agingPabeanJoin)+`.*`+
+		`LEFT JOIN exchange_rate er ON er\.id = .*r\.curr_code = doc\.curr_code AND r\.valid_from <= doc\.tgl_pabean.*`+
+		`WHERE h\.in_date <= \? AND i\.item_group IN \(\?,\?\) AND `+regexp.QuoteMeta(remainingExpr+" > 0")+
+		` AND doc\.no_pabean IS NOT NULL AND mp\.duty_suspended = \? ORDER BY h\.in_date ASC`).
+		WithArgs("2026-09-15", "2026-09-15", "MATERIAL", "PACKING", true).
+		WillReturnRows(sqlmock.NewRows([]string{"data_no", "in_date", "no_pabean", "remaining_qty", "curr_code", "unit_price", "idr_rate", "remaining_value_idr", "rate_missing"}).
+			AddRow("77", time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), "000100", "60", "USD", "2.5", "16000", "2400000", false).
+			AddRow("78", time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC), "000101", "10", "EUR", "4", nil, nil, true))
+
+	lots, err := repo.GetAging(context.Background(), Filter{
+		AsOf:      time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC),
+		ItemGroup: []string{"MATERIAL", "PACKING"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lots) != 2 {
+		t.Fatalf("len = %d; want 2", len(lots))
+	}
+	if lots[0].DataNo != "77" || lots[0].RemainingValueIdr == nil || !lots[0].RemainingValueIdr.Equal(decimal.NewFromInt(2400000)) {
+		t.Errorf("lots[0] = %+v", lots[0])
+	}
+	if lots[1].RemainingValueIdr != nil || !lots[1].RateMissing {
+		t.Errorf("lots[1] = %+v; want no IDR value and rate_missing", lots[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
+
+func TestGetConsumptions_EmptyDataNos(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewLotTraceRepository(db)
+
+	consumptions, err := repo.GetConsumptions(context.Background(), nil, time.Now())
+	if err != nil || consumptions != nil {
+		t.Fatalf("consumptions = %v, err = %v; want nil, nil", consumptions, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unexpected query: %v", err)
+	}
+}
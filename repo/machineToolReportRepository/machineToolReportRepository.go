@@ -1,6 +1,8 @@
 package machineToolReportRepository
 
 import (
+	"Bea-Cukai/helper/queryGuard"
+	"Bea-Cukai/helper/reportQuery"
 	"Bea-Cukai/model"
 	"context"
 	"fmt"
@@ -25,10 +27,26 @@ type GetReportFilter struct {
 	To       time.Time
 	ItemCode string
 	ItemName string
+	Options  reportQuery.Options
 	Page     int
 	Limit    int
 }
 
+// ReportColumns adalah whitelist kolom untuk parameter sort/filter laporan ini.
+var ReportColumns = reportQuery.Columns{
+	"item_code":      {Expr: "rq.item_code"},
+	"item_name":      {Expr: "rq.item_name"},
+	"unit_code":      {Expr: "rq.unit_code"},
+	"item_type_code": {Expr: "rq.item_type_code"},
+	"location_code":  {Expr: "rq.location_code"},
+	"awal":           {Expr: "rq.awal", Numeric: true},
+	"masuk":          {Expr: "rq.masuk", Numeric: true},
+	"keluar":         {Expr: "rq.keluar", Numeric: true},
+	"peny":           {Expr: "rq.peny", Numeric: true},
+	"akhir":          {Expr: "rq.akhir", Numeric: true},
+	"opname":         {Expr: "rq.opname", Numeric: true},
+}
+
 // Helper function to get max opname date for MESIN items
 func (r *MachineToolReportRepository) getMaxOpnameDate(ctx context.Context, beforeDate time.Time) (time.Time, error) {
 	var result struct {
@@ -176,16 +194,19 @@ func (r *MachineToolReportRepository) GetReport(ctx context.Context, filter GetR
 	}
 	queryArgs = append(queryArgs, args...)
 
+	// Sort/filter tambahan dari parameter request
+	finalQuery, queryArgs = filter.Options.Apply(finalQuery, queryArgs, ReportColumns)
+
 	// Get total count first
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (%s) as subquery", finalQuery)
 	var totalCount int64
-	err = r.db.WithContext(ctx).Raw(countQuery, queryArgs...).Scan(&totalCount).Error
+	err = queryGuard.Scan(ctx, r.db, &totalCount, countQuery, queryArgs...)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// Add LIMIT and OFFSET for pagination
-	paginatedQuery := finalQuery
+	paginatedQuery := finalQuery + filter.Options.OrderBy(ReportColumns, reportQuery.DefaultOrder)
 	if filter.Limit > 0 {
 		offset := 0
 		if filter.Page > 1 {
@@ -196,7 +217,7 @@ func (r *MachineToolReportRepository) GetReport(ctx context.Context, filter GetR
 
 	// Execute the final query
 	var results []model.MachineToolReportResponse
-	err = r.db.WithContext(ctx).Raw(paginatedQuery, queryArgs...).Scan(&results).Error
+	err = queryGuard.Scan(ctx, r.db, &results, paginatedQuery, queryArgs...)
 	if err != nil {
 		return nil, 0, err
 	}
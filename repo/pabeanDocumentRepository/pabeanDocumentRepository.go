@@ -0,0 +1,166 @@
+package pabeanDocumentRepository
+
+import (
+	"Bea-Cukai/helper/queryGuard"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/expenditureProductRepository"
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ---- Constructor ----
+
+type PabeanDocumentRepository struct {
+	db *gorm.DB
+}
+
+func NewPabeanDocumentRepository(db *gorm.DB) *PabeanDocumentRepository {
+	return &PabeanDocumentRepository{db: db}
+}
+
+// source adalah pemetaan kolom satu tabel transaksi pabean ke kolom dokumen yang seragam.
+type source struct {
+	table           string
+	itemCode        string // ekspresi kode barang (pengeluaran: prefix "1" dibuang)
+	counterpartCode string
+	counterpartName string
+	deliveryNo      string
+	qty             string
+	unit            string
+}
+
+// Kode barang pengeluaran dinormalisasi dengan expenditureProductRepository.NormalizedItemCode.
+var sources = map[string]source{
+	model.PabeanDirectionInbound: {
+		table:           model.EntryProduct{}.TableName(),
+		itemCode:        "p.item_code",
+		counterpartCode: "p.vendor_code",
+		counterpartName: "p.vendor_name",
+		deliveryNo:      "p.vend_dlv_no",
+		qty:             "p.rcv_qty",
+		unit:            "p.pch_unit",
+	},
+	model.PabeanDirectionOutbound: {
+		table:           model.ExpenditureProduct{}.TableName(),
+		itemCode:        expenditureProductRepository.NormalizedItemCode("p.item_code"),
+		counterpartCode: "p.cust_code",
+		counterpartName: "p.cust_name",
+		deliveryNo:      "''",
+		qty:             "p.dlv_qty",
+		unit:            "p.sales_unit",
+	},
+}
+
+// Directions adalah arah dokumen yang didukung, urutan pencarian dokumen tanpa arah.
+var Directions = []string{model.PabeanDirectionInbound, model.PabeanDirectionOutbound}
+
+// ListFilter adalah filter daftar dokumen. Direction kosong = pemasukan dan pengeluaran.
+type ListFilter struct {
+	Direction   string
+	From        time.Time
+	To          time.Time
+	PabeanType  string
+	NoPabean    string // sebagian nomor dokumen
+	Counterpart string // kode (persis) atau sebagian nama vendor / customer
+	Page        int
+	Limit       int
+}
+
+// keysQuery mengelompokkan baris satu tabel menjadi dokumen (jenis_pabean + no_pabean).
+// db adalah koneksi dari queryGuard.Run; setiap pemanggilan memulai statement baru di koneksi itu.
+func (r *PabeanDocumentRepository) keysQuery(db *gorm.DB, direction string, filter ListFilter) *gorm.DB {
+	src := sources[direction]
+	query := db.Session(&gorm.Session{NewDB: true}).
+		Table(src.table+" AS p").
+		Select("'"+direction+"' AS direction, p.jenis_pabean, p.no_pabean, MIN(p.tgl_pabean) AS tgl_pabean").
+		Where("p.tgl_pabean BETWEEN ? AND ?", filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02"))
+
+	if filter.PabeanType != "" {
+		query = query.Where("p.jenis_pabean = ?", filter.PabeanType)
+	}
+	if filter.NoPabean != "" {
+		query = query.Where("p.no_pabean LIKE ?", "%"+filter.NoPabean+"%")
+	}
+	if filter.Counterpart != "" {
+		query = query.Where("("+src.counterpartCode+" = ? OR "+src.counterpartName+" LIKE ?)", filter.Counterpart, "%"+filter.Counterpart+"%")
+	}
+
+	return query.Group("p.jenis_pabean, p.no_pabean")
+}
+
+// ListKeys mengembalikan dokumen (bukan baris) yang cocok dengan filter, terbaru lebih dulu,
+// beserta jumlah seluruh dokumen. Pagination berlaku bila Page dan Limit > 0.
+func (r *PabeanDocumentRepository) ListKeys(ctx context.Context, filter ListFilter) ([]model.PabeanDocumentKey, int64, error) {
+	directions := Directions
+	if filter.Direction != "" {
+		directions = []string{filter.Direction}
+	}
+
+	var keys []model.PabeanDocumentKey
+	var totalCount int64
+	err := queryGuard.Run(ctx, r.db, func(tx *gorm.DB) error {
+		parts := make([]string, len(directions))
+		args := make([]any, len(directions))
+		for i, direction := range directions {
+			parts[i] = "(?)"
+			args[i] = r.keysQuery(tx, direction, filter)
+		}
+		query := tx.Table("("+strings.Join(parts, " UNION ALL ")+") AS d", args...)
+
+		if err := query.Count(&totalCount).Error; err != nil {
+			return err
+		}
+
+		if filter.Limit > 0 && filter.Page > 0 {
+			query = query.Limit(filter.Limit).Offset((filter.Page - 1) * filter.Limit)
+		}
+
+		return query.Select("d.*").
+			Order("d.tgl_pabean DESC, d.jenis_pabean ASC, d.no_pabean ASC, d.direction ASC").
+			Scan(&keys).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return keys, totalCount, nil
+}
+
+// GetLines mengembalikan semua baris barang dokumen-dokumen keys (satu arah), urut per dokumen.
+func (r *PabeanDocumentRepository) GetLines(ctx context.Context, direction string, keys []model.PabeanDocumentKey) ([]model.PabeanDocumentLine, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	src := sources[direction]
+
+	pairs := make([][]any, len(keys))
+	for i, k := range keys {
+		pairs[i] = []any{k.JenisPabean, k.NoPabean}
+	}
+
+	var lines []model.PabeanDocumentLine
+	err := queryGuard.Run(ctx, r.db, func(tx *gorm.DB) error {
+		return tx.Table(src.table+" AS p").
+			Joins("LEFT JOIN ms_item i ON i.item_code = "+src.itemCode).
+			Select(strings.Join([]string{
+				"p.jenis_pabean", "p.no_pabean", "p.tgl_pabean",
+				src.counterpartCode + " AS counterpart_code",
+				src.counterpartName + " AS counterpart_name",
+				"p.trans_no",
+				src.deliveryNo + " AS delivery_no",
+				"p.trans_date",
+				src.itemCode + " AS item_code",
+				"p.item_name",
+				"COALESCE(i.item_group, '') AS item_group",
+				src.qty + " AS qty",
+				src.unit + " AS unit",
+				"p.curr_code", "p.net_price", "p.net_amount",
+			}, ", ")).
+			Where("(p.jenis_pabean, p.no_pabean) IN ?", pairs).
+			Order("p.jenis_pabean ASC, p.no_pabean ASC, p.trans_no ASC, p.idx ASC").
+			Scan(&lines).Error
+	})
+	return lines, err
+}
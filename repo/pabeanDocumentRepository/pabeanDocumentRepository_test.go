@@ -0,0 +1,139 @@
+package pabeanDocumentRepository
+
+import (
+	"Bea-Cukai/model"
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open mock: %v", err)
+	}
+	return gormDB, mock
+}
+
+// Tanpa direction, dokumen pemasukan dan pengeluaran digabung (UNION ALL) sebelum dihitung
+// dan dipaginasi, sehingga pagination berlaku per dokumen.
+func TestListKeys_UnionsBothDirections(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewPabeanDocumentRepository(db)
+
+	union := `FROM \(\(SELECT 'inbound' AS direction.*FROM tr_pemasukan_barang AS p .*GROUP BY p\.jenis_pabean, p\.no_pabean\) UNION ALL \(SELECT 'outbound' AS direction.*FROM tr_pengeluaran_barang AS p .*GROUP BY p\.jenis_pabean, p\.no_pabean\)\) AS d`
+	args := []any{"2026-09-01", "2026-09-30", "%0001%", "2026-09-01", "2026-09-30", "%0001%"}
+	mock.ExpectQuery(`SELECT count\(\*\) ` + union).
+		WithArgs(toDriverValues(args)...).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT d\.\* ` + union + ` ORDER BY d\.tgl_pabean DESC.* LIMIT \?`).
+		WithArgs(toDriverValues(append(args, 2))...).
+		WillReturnRows(sqlmock.NewRows([]string{"direction", "jenis_pabean", "no_pabean", "tgl_pabean"}).
+			AddRow("outbound", "BC 3.0", "000123", time.Date(2026, 9, 20, 0, 0, 0, 0, time.UTC)).
+			AddRow("inbound", "BC 2.3", "000100", time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)))
+
+	keys, total, err := repo.ListKeys(context.Background(), ListFilter{
+		From:     time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		NoPabean: "0001",
+		Page:     1,
+		Limit:    2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 3 || len(keys) != 2 {
+		t.Fatalf("total = %d, len = %d; want 3, 2", total, len(keys))
+	}
+	if keys[0].Direction != model.PabeanDirectionOutbound || keys[1].NoPabean != "000100" {
+		t.Errorf("keys = %+v", keys)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// Context yang bisa dibatalkan (request HTTP) menjalankan count dan halaman dokumen di satu
+// koneksi lewat queryGuard, sehingga keduanya bisa dihentikan dengan KILL QUERY.
+func TestListKeys_RunsThroughQueryGuard(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewPabeanDocumentRepository(db)
+
+	mock.ExpectQuery(`SELECT CONNECTION_ID\(\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM \(\(SELECT 'inbound' AS direction`).
+		WithArgs("2026-09-01", "2026-09-30").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT d\.\* FROM \(\(SELECT 'inbound' AS direction`).
+		WithArgs("2026-09-01", "2026-09-30").
+		WillReturnRows(sqlmock.NewRows([]string{"direction", "jenis_pabean", "no_pabean"}).
+			AddRow("inbound", "BC 2.3", "000100"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	keys, total, err := repo.ListKeys(ctx, ListFilter{
+		Direction: model.PabeanDirectionInbound,
+		From:      time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1 || len(keys) != 1 {
+		t.Fatalf("total = %d, len = %d; want 1, 1", total, len(keys))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// Baris pengeluaran dibaca dengan kolom yang diseragamkan dan kode barang yang dinormalisasi.
+func TestGetLines_OutboundMapsColumns(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewPabeanDocumentRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("p.cust_code AS counterpart_code")+`.*`+
+		regexp.QuoteMeta("p.dlv_qty AS qty, p.sales_unit AS unit")+`.*FROM tr_pengeluaran_barang AS p LEFT JOIN ms_item i .*`+
+		regexp.QuoteMeta("WHERE (p.jenis_pabean, p.no_pabean) IN ((?,?))")).
+		WithArgs("BC 3.0", "000123").
+		WillReturnRows(sqlmock.NewRows([]string{"jenis_pabean", "no_pabean", "counterpart_name", "trans_no", "item_code", "qty", "unit"}).
+			AddRow("BC 3.0", "000123", "Customer A", "DO-1", "FG-001", "12.5", "PCS"))
+
+	lines, err := repo.GetLines(context.Background(), model.PabeanDirectionOutbound, []model.PabeanDocumentKey{
+		{JenisPabean: "BC 3.0", NoPabean: "000123"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lines) != 1 || lines[0].CounterpartName != "Customer A" || lines[0].Qty.String() != "12.5" || lines[0].Unit != "PCS" {
+		t.Errorf("lines = %+v", lines)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func toDriverValues(args []any) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a
+	}
+	return values
+}
@@ -26,3 +26,34 @@ func (r *PabeanRepository) GetAll(ctx context.Context) ([]model.MsPabean, error)
 		Find(&results).Error
 	return results, err
 }
+
+// GetById - get satu jenis dokumen
+func (r *PabeanRepository) GetById(ctx context.Context, id int) (model.MsPabean, error) {
+	var result model.MsPabean
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&result).Error
+	return result, err
+}
+
+// Create - simpan jenis dokumen baru
+func (r *PabeanRepository) Create(ctx context.Context, pabean model.MsPabean) (model.MsPabean, error) {
+	if err := r.db.WithContext(ctx).Create(&pabean).Error; err != nil {
+		return model.MsPabean{}, err
+	}
+	return pabean, nil
+}
+
+// Update - ubah jenis dokumen by id
+func (r *PabeanRepository) Update(ctx context.Context, id int, pabean model.MsPabean) (model.MsPabean, error) {
+	existing, err := r.GetById(ctx, id)
+	if err != nil {
+		return model.MsPabean{}, err
+	}
+
+	pabean.Id = existing.Id
+	pabean.CreatedBy = existing.CreatedBy
+	pabean.CreatedDate = existing.CreatedDate
+	if err := r.db.WithContext(ctx).Save(&pabean).Error; err != nil {
+		return model.MsPabean{}, err
+	}
+	return pabean, nil
+}
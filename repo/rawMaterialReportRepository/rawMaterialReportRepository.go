@@ -1,6 +1,8 @@
 package rawMaterialReportRepository
 
 import (
+	"Bea-Cukai/helper/queryGuard"
+	"Bea-Cukai/helper/reportQuery"
 	"Bea-Cukai/model"
 	"context"
 	"fmt"
@@ -26,10 +28,40 @@ type GetReportFilter struct {
 	To       time.Time
 	ItemCode string
 	ItemName string
+	GroupBy  string // "" (gabungan) atau GroupByLocation
+	Location string // filter location_code; otomatis memakai query per lokasi
+	Options  reportQuery.Options
 	Page     int
 	Limit    int
 }
 
+// ReportColumns adalah whitelist kolom untuk parameter sort/filter laporan ini.
+var ReportColumns = reportQuery.Columns{
+	"item_code":      {Expr: "rq.item_code"},
+	"item_name":      {Expr: "rq.item_name"},
+	"unit_code":      {Expr: "rq.unit_code"},
+	"item_type_code": {Expr: "rq.item_type_code"},
+	"location_code":  {Expr: "rq.location_code"},
+	"awal":           {Expr: "rq.awal", Numeric: true},
+	"masuk":          {Expr: "rq.masuk", Numeric: true},
+	"keluar":         {Expr: "rq.keluar", Numeric: true},
+	"peny":           {Expr: "rq.peny", Numeric: true},
+	"akhir":          {Expr: "rq.akhir", Numeric: true},
+	"opname":         {Expr: "rq.opname", Numeric: true},
+	"selisih":        {Expr: "rq.selisih", Numeric: true},
+}
+
+// GroupByLocation memecah laporan menjadi satu baris per item per lokasi.
+const GroupByLocation = "location"
+
+// MaterialWarehouseLocation adalah lokasi movein yang dihitung pada laporan gabungan.
+const MaterialWarehouseLocation = "WH-MAT-2"
+
+// UnassignedLocation adalah bucket per lokasi untuk mutasi yang sumbernya tidak punya
+// kolom lokasi (penerimaan AP, pemakaian RM, penyesuaian, opname wh2) dan movein
+// dengan location_code kosong.
+const UnassignedLocation = "UNASSIGNED"
+
 // getMaxMaterialHarianDate returns the most recent trans_date <= beforeDate.
 func (r *RawMaterialReportRepository) getMaxMaterialHarianDate(ctx context.Context, beforeDate time.Time) (time.Time, error) {
 	var result struct {
@@ -85,9 +117,8 @@ func (r *RawMaterialReportRepository) getBothOpnameDates(ctx context.Context, fr
 	return awal, akhir, nil
 }
 
-// buildBaseQuery membangun query CTE dan slice argumen yang terurut.
-// Pure function — tidak ada DB call, aman untuk unit test.
-func buildBaseQuery(tglInvAwal, tglInvAkhir time.Time, filter GetReportFilter) (string, []interface{}) {
+// itemFilterConditions membangun kondisi WHERE tambahan untuk filter item (alias ms_item = a).
+func itemFilterConditions(filter GetReportFilter) (string, []interface{}) {
 	whereConditions := ""
 	extraArgs := []interface{}{}
 
@@ -99,15 +130,57 @@ func buildBaseQuery(tglInvAwal, tglInvAkhir time.Time, filter GetReportFilter) (
 		whereConditions += " AND a.item_name LIKE ?"
 		extraArgs = append(extraArgs, "%"+filter.ItemName+"%")
 	}
+	return whereConditions, extraArgs
+}
 
-	queryAwal := "(IFNULL(b.awal, 0) + (IFNULL(masuk_awal.trf_in, 0) + IFNULL(movein_awal.movein_after, 0)) - IFNULL(keluar_awal.trf_out, 0) + IFNULL(peny_after_opname.peny, 0))"
-	queryMasuk := "IFNULL(c.masuk, 0) + IFNULL(g.movein, 0)"
-	akhirExpr := fmt.Sprintf("%s + %s - IFNULL(keluar.keluar,0) + IFNULL(e.peny, 0)", queryAwal, queryMasuk)
+// rawMaterialExprs mengembalikan ekspresi awal, masuk, akhir dan opname yang dipakai
+// baik oleh query gabungan maupun query per lokasi.
+func rawMaterialExprs(tglInvAkhir time.Time, filter GetReportFilter) (queryAwal, queryMasuk, akhirExpr, opnameExpr string) {
+	queryAwal = "(IFNULL(b.awal, 0) + (IFNULL(masuk_awal.trf_in, 0) + IFNULL(movein_awal.movein_after, 0)) - IFNULL(keluar_awal.trf_out, 0) + IFNULL(peny_after_opname.peny, 0))"
+	queryMasuk = "IFNULL(c.masuk, 0) + IFNULL(g.movein, 0)"
+	akhirExpr = fmt.Sprintf("%s + %s - IFNULL(keluar.keluar,0) + IFNULL(e.peny, 0)", queryAwal, queryMasuk)
 
-	opnameExpr := "IFNULL(f.opname, 0)"
+	opnameExpr = "IFNULL(f.opname, 0)"
 	if tglInvAkhir.Format("2006-01-02") != filter.To.Format("2006-01-02") {
 		opnameExpr = akhirExpr
 	}
+	return queryAwal, queryMasuk, akhirExpr, opnameExpr
+}
+
+// baseQueryArgs mengembalikan argumen tanggal untuk CTE b..peny_after_opname.
+// Urutan CTE sama antara buildBaseQuery dan buildLocationQuery.
+func baseQueryArgs(tglInvAwal, tglInvAkhir time.Time, filter GetReportFilter) []interface{} {
+	// Window *_awal: mutasi setelah opname awal sampai sehari sebelum periode,
+	// inklusif di kedua ujung [tglInvAwal+1, filter.From-1]. Jika tglInvAwal == filter.From
+	// maka start > end dan BETWEEN tidak mengembalikan baris (memang tidak ada mutasi).
+	afterStart := tglInvAwal.AddDate(0, 0, 1).Format("2006-01-02")
+	afterEnd := filter.From.AddDate(0, 0, -1).Format("2006-01-02")
+
+	// Urutan args harus sesuai dengan urutan ? di query CTE:
+	// b(1) + c(2) + e(2) + f(1) + g(2) + keluar(2) + out_after(2) + in_after(2) + movein_after(2) + peny_after(2) = 18
+	return []interface{}{
+		tglInvAwal.Format("2006-01-02"),  // b:              awal date
+		filter.From.Format("2006-01-02"), // c:              masuk from
+		filter.To.Format("2006-01-02"),   // c:              masuk to
+		filter.From.Format("2006-01-02"), // e:              peny from
+		filter.To.Format("2006-01-02"),   // e:              peny to
+		tglInvAkhir.Format("2006-01-02"), // f:              opname date
+		filter.From.Format("2006-01-02"), // g:              movein from
+		filter.To.Format("2006-01-02"),   // g:              movein to
+		filter.From.Format("2006-01-02"), // keluar from
+		filter.To.Format("2006-01-02"),   // keluar to
+		afterStart, afterEnd,             // keluar_awal
+		afterStart, afterEnd, // masuk_awal
+		afterStart, afterEnd, // movein_awal
+		afterStart, afterEnd, // peny_after_opname
+	}
+}
+
+// buildBaseQuery membangun query CTE dan slice argumen yang terurut.
+// Pure function — tidak ada DB call, aman untuk unit test.
+func buildBaseQuery(tglInvAwal, tglInvAkhir time.Time, filter GetReportFilter) (string, []interface{}) {
+	whereConditions, extraArgs := itemFilterConditions(filter)
+	queryAwal, queryMasuk, akhirExpr, opnameExpr := rawMaterialExprs(tglInvAkhir, filter)
 
 	query := fmt.Sprintf(`
 		WITH b AS (
@@ -145,7 +218,7 @@ func buildBaseQuery(tglInvAwal, tglInvAkhir time.Time, filter GetReportFilter) (
 			FROM tr_inv_movein_head moveinhead
 			INNER JOIN tr_inv_movein_det moveindet ON moveinhead.trans_no = moveindet.trans_no
 			WHERE moveinhead.trans_date >= ? AND moveinhead.trans_date <= ?
-			AND moveindet.location_code = 'WH-MAT-2'
+			AND moveindet.location_code = '%[8]s'
 			GROUP BY item_code
 		),
 		keluar AS (
@@ -161,22 +234,22 @@ func buildBaseQuery(tglInvAwal, tglInvAkhir time.Time, filter GetReportFilter) (
 			FROM tr_inv_rm_head rmhead
 			INNER JOIN tr_inv_rm_det rmdet ON rmhead.trans_no = rmdet.trans_no
 			INNER JOIN tr_ap_inv_det apdet ON rmdet.data_no = apdet.data_no
-			WHERE rmhead.trans_date > ? AND rmhead.trans_date < ?
+			WHERE rmhead.trans_date BETWEEN ? AND ?
 			GROUP BY apdet.item_code
 		),
 		masuk_awal AS (
 			SELECT apdet.item_code, SUM(apdet.qty) AS trf_in
 			FROM tr_ap_inv_head aphead
 			INNER JOIN tr_ap_inv_det apdet ON aphead.trans_no = apdet.trans_no
-			WHERE aphead.in_date > ? AND aphead.in_date < ?
+			WHERE aphead.in_date BETWEEN ? AND ?
 			GROUP BY apdet.item_code
 		),
 		movein_awal AS (
 			SELECT item_code, SUM(qty) AS movein_after
 			FROM tr_inv_movein_head moveinhead
 			INNER JOIN tr_inv_movein_det moveindet ON moveinhead.trans_no = moveindet.trans_no
-			WHERE moveinhead.trans_date > ? AND moveinhead.trans_date < ?
-			AND moveindet.location_code = 'WH-MAT-2'
+			WHERE moveinhead.trans_date BETWEEN ? AND ?
+			AND moveindet.location_code = '%[8]s'
 			GROUP BY item_code
 		),
 		peny_after_opname AS (
@@ -184,58 +257,191 @@ func buildBaseQuery(tglInvAwal, tglInvAkhir time.Time, filter GetReportFilter) (
 			FROM tr_inv_adjust_head a
 			INNER JOIN tr_inv_adjust_det b ON a.trans_no = b.trans_no
 			LEFT JOIN ms_item c ON b.item_code = c.item_code
-			WHERE a.trans_date > ? AND a.trans_date < ? 
+			WHERE a.trans_date BETWEEN ? AND ?
 			AND c.item_group = 'MATERIAL'
 			GROUP BY b.item_code
 		),
 		z AS (
 			SELECT
-				item.item_code, item.item_name, item.unit_code, item.item_type_code, item.item_group,
+				a.item_code, a.item_name, a.unit_code, a.item_type_code, a.item_group,
 				'' AS location_code,
-				%s AS awal,
-				%s AS masuk,
+				%[1]s AS awal,
+				%[2]s AS masuk,
 				IFNULL(keluar.keluar, 0) AS keluar,
 				IFNULL(e.peny, 0) AS peny,
-				%s AS akhir,
-				%s AS opname,
-				(%s) - (%s) AS selisih
-			FROM ms_item item
-			LEFT JOIN b ON item.item_code = b.item_code
-			LEFT JOIN c ON item.item_code = c.item_code
-			LEFT JOIN e ON item.item_code = e.item_code
-			LEFT JOIN f ON item.item_code = f.item_code
-			LEFT JOIN g ON item.item_code = g.item_code
-			LEFT JOIN keluar ON item.item_code = keluar.item_code
-			LEFT JOIN keluar_awal ON item.item_code = keluar_awal.item_code
-			LEFT JOIN masuk_awal ON item.item_code = masuk_awal.item_code
-			LEFT JOIN movein_awal ON item.item_code = movein_awal.item_code
-			LEFT JOIN peny_after_opname ON item.item_code = peny_after_opname.item_code
-			WHERE item.item_group = 'MATERIAL' %s
-			AND item.item_type_code NOT LIKE 'Recycle%%'
+				%[3]s AS akhir,
+				%[4]s AS opname,
+				(%[5]s) - (%[6]s) AS selisih
+			FROM ms_item a
+			LEFT JOIN b ON a.item_code = b.item_code
+			LEFT JOIN c ON a.item_code = c.item_code
+			LEFT JOIN e ON a.item_code = e.item_code
+			LEFT JOIN f ON a.item_code = f.item_code
+			LEFT JOIN g ON a.item_code = g.item_code
+			LEFT JOIN keluar ON a.item_code = keluar.item_code
+			LEFT JOIN keluar_awal ON a.item_code = keluar_awal.item_code
+			LEFT JOIN masuk_awal ON a.item_code = masuk_awal.item_code
+			LEFT JOIN movein_awal ON a.item_code = movein_awal.item_code
+			LEFT JOIN peny_after_opname ON a.item_code = peny_after_opname.item_code
+			WHERE a.item_group = 'MATERIAL' %[7]s
+			AND a.item_type_code NOT LIKE 'Recycle%%'
 		)
 		SELECT * FROM z WHERE z.awal <> 0 OR z.opname <> 0 OR z.masuk <> 0 OR z.akhir <> 0 OR z.peny <> 0
-	`, queryAwal, queryMasuk, akhirExpr, opnameExpr, akhirExpr, opnameExpr, whereConditions)
-
-	// Urutan args harus sesuai dengan urutan ? di query CTE di atas:
-	// b(1) + c(2) + e(2) + f(1) + g(2) + out_after(2) + in_after(2) + movein_after(2) + peny_after(2) = 16
-	baseArgs := []interface{}{
-		tglInvAwal.Format("2006-01-02"),                                   // b:              awal date
-		filter.From.Format("2006-01-02"),                                  // c:              masuk from
-		filter.To.Format("2006-01-02"),                                    // c:              masuk to
-		filter.From.Format("2006-01-02"),                                  // e:              peny from
-		filter.To.Format("2006-01-02"),                                    // e:              peny to
-		tglInvAkhir.Format("2006-01-02"),                                  // f:              opname date
-		filter.From.Format("2006-01-02"),                                  // g:              movein from
-		filter.To.Format("2006-01-02"),                                    // g:              movein to
-		filter.From.Format("2006-01-02"),                                  // keluar from
-		filter.To.Format("2006-01-02"),                                    // keluar to
-		tglInvAwal.Format("2006-01-02"), filter.From.Format("2006-01-02"), // keluar_awal
-		tglInvAwal.Format("2006-01-02"), filter.From.Format("2006-01-02"), // masuk_awal
-		tglInvAwal.Format("2006-01-02"), filter.From.Format("2006-01-02"), // movein_awal
-		tglInvAwal.Format("2006-01-02"), filter.From.Format("2006-01-02"), // peny_after_opname
+	`, queryAwal, queryMasuk, akhirExpr, opnameExpr, akhirExpr, opnameExpr, whereConditions, MaterialWarehouseLocation)
+
+	return query, append(baseQueryArgs(tglInvAwal, tglInvAkhir, filter), extraArgs...)
+}
+
+// buildLocationQuery adalah varian buildBaseQuery yang menghasilkan satu baris per item per lokasi.
+// Lokasi diturunkan dari baris mutasinya:
+//   - movein (g, movein_awal) memakai moveindet.location_code dari semua lokasi, bukan hanya
+//     MaterialWarehouseLocation; location_code kosong masuk UnassignedLocation
+//   - opname harian (b, f), penerimaan AP, pemakaian RM dan penyesuaian tidak punya kolom
+//     lokasi → UnassignedLocation
+//
+// Untuk satu item, baris UnassignedLocation + baris MaterialWarehouseLocation sama dengan baris
+// gabungan di buildBaseQuery; baris lokasi lain adalah movein yang tidak dihitung laporan gabungan.
+// Urutan args identik dengan buildBaseQuery, ditambah filter lokasi di akhir.
+func buildLocationQuery(tglInvAwal, tglInvAkhir time.Time, filter GetReportFilter) (string, []interface{}) {
+	whereConditions, extraArgs := itemFilterConditions(filter)
+	queryAwal, queryMasuk, akhirExpr, opnameExpr := rawMaterialExprs(tglInvAkhir, filter)
+
+	locationCondition := ""
+	if filter.Location != "" {
+		locationCondition = " AND z.location_code = ?"
+		extraArgs = append(extraArgs, filter.Location)
 	}
 
-	return query, append(baseArgs, extraArgs...)
+	moveinLocation := fmt.Sprintf("COALESCE(NULLIF(moveindet.location_code, ''), '%s')", UnassignedLocation)
+
+	query := fmt.Sprintf(`
+		WITH b AS (
+			SELECT b.item_code, '%[8]s' AS location_code, SUM(b.wh2) AS awal
+			FROM tr_inv_material_harian_head a
+			INNER JOIN tr_inv_material_harian_det b ON a.trans_no = b.trans_no
+			WHERE a.trans_date = ?
+			GROUP BY b.item_code
+		),
+		c AS (
+			SELECT item_code, '%[8]s' AS location_code, SUM(qty) AS masuk
+			FROM tr_ap_inv_head a
+			INNER JOIN tr_ap_inv_det b ON a.trans_no = b.trans_no
+			WHERE a.in_date >= ? AND a.in_date <= ?
+			GROUP BY item_code
+		),
+		e AS (
+			SELECT b.item_code, '%[8]s' AS location_code, SUM(qty) AS peny
+			FROM tr_inv_adjust_head a
+			INNER JOIN tr_inv_adjust_det b ON a.trans_no = b.trans_no
+			LEFT JOIN ms_item c ON b.item_code = c.item_code
+			WHERE a.trans_date >= ? AND a.trans_date <= ?
+			AND c.item_group = 'MATERIAL'
+			GROUP BY b.item_code
+		),
+		f AS (
+			SELECT b.item_code, '%[8]s' AS location_code, SUM(b.wh2) AS opname
+			FROM tr_inv_material_harian_head a
+			INNER JOIN tr_inv_material_harian_det b ON a.trans_no = b.trans_no
+			WHERE a.trans_date = ?
+			GROUP BY b.item_code
+		),
+		g AS (
+			SELECT item_code, %[10]s AS location_code, SUM(qty) AS movein
+			FROM tr_inv_movein_head moveinhead
+			INNER JOIN tr_inv_movein_det moveindet ON moveinhead.trans_no = moveindet.trans_no
+			WHERE moveinhead.trans_date >= ? AND moveinhead.trans_date <= ?
+			GROUP BY item_code, %[10]s
+		),
+		keluar AS (
+			SELECT apdet.item_code, '%[8]s' AS location_code, SUM(rmdet.qty) AS keluar
+			FROM tr_inv_rm_head rmhead
+			INNER JOIN tr_inv_rm_det rmdet ON rmhead.trans_no = rmdet.trans_no
+			INNER JOIN tr_ap_inv_det apdet ON rmdet.data_no = apdet.data_no
+			WHERE rmhead.trans_date >= ? AND rmhead.trans_date <= ?
+			GROUP BY apdet.item_code
+		),
+		keluar_awal AS (
+			SELECT apdet.item_code, '%[8]s' AS location_code, SUM(rmdet.qty) AS trf_out
+			FROM tr_inv_rm_head rmhead
+			INNER JOIN tr_inv_rm_det rmdet ON rmhead.trans_no = rmdet.trans_no
+			INNER JOIN tr_ap_inv_det apdet ON rmdet.data_no = apdet.data_no
+			WHERE rmhead.trans_date BETWEEN ? AND ?
+			GROUP BY apdet.item_code
+		),
+		masuk_awal AS (
+			SELECT apdet.item_code, '%[8]s' AS location_code, SUM(apdet.qty) AS trf_in
+			FROM tr_ap_inv_head aphead
+			INNER JOIN tr_ap_inv_det apdet ON aphead.trans_no = apdet.trans_no
+			WHERE aphead.in_date BETWEEN ? AND ?
+			GROUP BY apdet.item_code
+		),
+		movein_awal AS (
+			SELECT item_code, %[10]s AS location_code, SUM(qty) AS movein_after
+			FROM tr_inv_movein_head moveinhead
+			INNER JOIN tr_inv_movein_det moveindet ON moveinhead.trans_no = moveindet.trans_no
+			WHERE moveinhead.trans_date BETWEEN ? AND ?
+			GROUP BY item_code, %[10]s
+		),
+		peny_after_opname AS (
+			SELECT b.item_code, '%[8]s' AS location_code, SUM(b.qty) AS peny
+			FROM tr_inv_adjust_head a
+			INNER JOIN tr_inv_adjust_det b ON a.trans_no = b.trans_no
+			LEFT JOIN ms_item c ON b.item_code = c.item_code
+			WHERE a.trans_date BETWEEN ? AND ?
+			AND c.item_group = 'MATERIAL'
+			GROUP BY b.item_code
+		),
+		loc AS (
+			SELECT item_code, location_code FROM b
+			UNION SELECT item_code, location_code FROM c
+			UNION SELECT item_code, location_code FROM e
+			UNION SELECT item_code, location_code FROM f
+			UNION SELECT item_code, location_code FROM g
+			UNION SELECT item_code, location_code FROM keluar
+			UNION SELECT item_code, location_code FROM keluar_awal
+			UNION SELECT item_code, location_code FROM masuk_awal
+			UNION SELECT item_code, location_code FROM movein_awal
+			UNION SELECT item_code, location_code FROM peny_after_opname
+		),
+		z AS (
+			SELECT
+				a.item_code, a.item_name, a.unit_code, a.item_type_code, a.item_group,
+				loc.location_code,
+				%[1]s AS awal,
+				%[2]s AS masuk,
+				IFNULL(keluar.keluar, 0) AS keluar,
+				IFNULL(e.peny, 0) AS peny,
+				%[3]s AS akhir,
+				%[4]s AS opname,
+				(%[5]s) - (%[6]s) AS selisih
+			FROM loc
+			INNER JOIN ms_item a ON a.item_code = loc.item_code
+			LEFT JOIN b ON loc.item_code = b.item_code AND loc.location_code = b.location_code
+			LEFT JOIN c ON loc.item_code = c.item_code AND loc.location_code = c.location_code
+			LEFT JOIN e ON loc.item_code = e.item_code AND loc.location_code = e.location_code
+			LEFT JOIN f ON loc.item_code = f.item_code AND loc.location_code = f.location_code
+			LEFT JOIN g ON loc.item_code = g.item_code AND loc.location_code = g.location_code
+			LEFT JOIN keluar ON loc.item_code = keluar.item_code AND loc.location_code = keluar.location_code
+			LEFT JOIN keluar_awal ON loc.item_code = keluar_awal.item_code AND loc.location_code = keluar_awal.location_code
+			LEFT JOIN masuk_awal ON loc.item_code = masuk_awal.item_code AND loc.location_code = masuk_awal.location_code
+			LEFT JOIN movein_awal ON loc.item_code = movein_awal.item_code AND loc.location_code = movein_awal.location_code
+			LEFT JOIN peny_after_opname ON loc.item_code = peny_after_opname.item_code AND loc.location_code = peny_after_opname.location_code
+			WHERE a.item_group = 'MATERIAL' %[7]s
+			AND a.item_type_code NOT LIKE 'Recycle%%'
+		)
+		SELECT * FROM z
+		WHERE (z.awal <> 0 OR z.opname <> 0 OR z.masuk <> 0 OR z.akhir <> 0 OR z.peny <> 0)%[9]s
+	`, queryAwal, queryMasuk, akhirExpr, opnameExpr, akhirExpr, opnameExpr, whereConditions, UnassignedLocation, locationCondition, moveinLocation)
+
+	return query, append(baseQueryArgs(tglInvAwal, tglInvAkhir, filter), extraArgs...)
+}
+
+// buildReportQuery memilih query gabungan atau per lokasi sesuai filter.
+func buildReportQuery(tglInvAwal, tglInvAkhir time.Time, filter GetReportFilter) (string, []interface{}) {
+	if filter.GroupBy == GroupByLocation || filter.Location != "" {
+		return buildLocationQuery(tglInvAwal, tglInvAkhir, filter)
+	}
+	return buildBaseQuery(tglInvAwal, tglInvAkhir, filter)
 }
 
 // GetReport mengambil laporan bahan baku dengan kalkulasi inventori kompleks.
@@ -248,7 +454,9 @@ func (r *RawMaterialReportRepository) GetReport(ctx context.Context, filter GetR
 		return nil, 0, err
 	}
 
-	baseQuery, queryArgs := buildBaseQuery(tglInvAwal, tglInvAkhir, filter)
+	baseQuery, queryArgs := buildReportQuery(tglInvAwal, tglInvAkhir, filter)
+	baseQuery, queryArgs = filter.Options.Apply(baseQuery, queryArgs, ReportColumns)
+	orderBy := filter.Options.OrderBy(ReportColumns, reportQuery.DefaultOrder)
 
 	var (
 		results    []model.RawMaterialReportResponse
@@ -269,13 +477,13 @@ func (r *RawMaterialReportRepository) GetReport(ctx context.Context, filter GetR
 		}
 
 		paginatedQuery := fmt.Sprintf(`
-			SELECT inner_q.*, COUNT(*) OVER() AS _total_count
-			FROM (%s) AS inner_q
+			SELECT rq.*, COUNT(*) OVER() AS _total_count
+			FROM (%s) AS rq%s
 			LIMIT %d OFFSET %d
-		`, baseQuery, filter.Limit, offset)
+		`, baseQuery, orderBy, filter.Limit, offset)
 
 		var rows []rowWithCount
-		if err = r.db.WithContext(ctx).Raw(paginatedQuery, queryArgs...).Scan(&rows).Error; err != nil {
+		if err = queryGuard.Scan(ctx, r.db, &rows, paginatedQuery, queryArgs...); err != nil {
 			return nil, 0, err
 		}
 
@@ -287,7 +495,7 @@ func (r *RawMaterialReportRepository) GetReport(ctx context.Context, filter GetR
 			totalCount = rows[0].TotalCount
 		}
 	} else {
-		if err = r.db.WithContext(ctx).Raw(baseQuery, queryArgs...).Scan(&results).Error; err != nil {
+		if err = queryGuard.Scan(ctx, r.db, &results, baseQuery+orderBy, queryArgs...); err != nil {
 			return nil, 0, err
 		}
 		totalCount = int64(len(results))
@@ -295,3 +503,40 @@ func (r *RawMaterialReportRepository) GetReport(ctx context.Context, filter GetR
 
 	return results, totalCount, nil
 }
+
+// GetPeriodBalances mengambil seluruh baris laporan satu periode (tanpa pagination)
+// beserta tanggal opname yang dipakai sebagai dasar saldo awal (tglInvAwal).
+// Dipakai oleh pengecekan kesinambungan saldo antar periode.
+func (r *RawMaterialReportRepository) GetPeriodBalances(ctx context.Context, filter GetReportFilter) ([]model.RawMaterialReportResponse, time.Time, error) {
+	tglInvAwal, tglInvAkhir, err := r.getBothOpnameDates(ctx, filter.From, filter.To)
+	if err != nil {
+		return nil, tglInvAwal, err
+	}
+
+	baseQuery, queryArgs := buildBaseQuery(tglInvAwal, tglInvAkhir, filter)
+
+	var results []model.RawMaterialReportResponse
+	if err = queryGuard.Scan(ctx, r.db, &results, baseQuery, queryArgs...); err != nil {
+		return nil, tglInvAwal, err
+	}
+	return results, tglInvAwal, nil
+}
+
+// GetBaseReport menjalankan buildBaseQuery (satu baris per item, tanpa lokasi) untuk satu periode
+// tanpa pagination, dengan filter Options; sort Options diabaikan.
+// Dipakai mode perbandingan periode (compare=previous|yoy|custom).
+func (r *RawMaterialReportRepository) GetBaseReport(ctx context.Context, filter GetReportFilter) ([]model.RawMaterialReportResponse, error) {
+	tglInvAwal, tglInvAkhir, err := r.getBothOpnameDates(ctx, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+
+	baseQuery, queryArgs := buildBaseQuery(tglInvAwal, tglInvAkhir, filter)
+	baseQuery, queryArgs = filter.Options.Apply(baseQuery, queryArgs, ReportColumns)
+
+	var results []model.RawMaterialReportResponse
+	if err = queryGuard.Scan(ctx, r.db, &results, baseQuery, queryArgs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -76,7 +76,7 @@ func TestBuildBaseQuery_OpnameUsesAkhir_WhenAkhirNotEqualsTo(t *testing.T) {
 	}
 }
 
-// Test 3: jumlah base args harus tepat 16 (tanpa filter item_code/item_name)
+// Test 3: jumlah base args harus tepat 18 (tanpa filter item_code/item_name)
 func TestBuildBaseQuery_ArgsCount_NoFilter(t *testing.T) {
 	tglInvAwal := mustParseDate("2024-01-01")
 	tglInvAkhir := mustParseDate("2024-01-31")
@@ -87,8 +87,8 @@ func TestBuildBaseQuery_ArgsCount_NoFilter(t *testing.T) {
 
 	_, args := buildBaseQuery(tglInvAwal, tglInvAkhir, filter)
 
-	// b(1)+c(2)+e(2)+f(1)+g(2)+out_after(2)+in_after(2)+movein_after(2)+peny_after(2) = 16
-	const wantCount = 16
+	// b(1)+c(2)+e(2)+f(1)+g(2)+keluar(2)+out_after(2)+in_after(2)+movein_after(2)+peny_after(2) = 18
+	const wantCount = 18
 	if len(args) != wantCount {
 		t.Errorf("jumlah args: want %d, got %d", wantCount, len(args))
 	}
@@ -107,7 +107,7 @@ func TestBuildBaseQuery_ArgsCount_WithBothFilters(t *testing.T) {
 
 	_, args := buildBaseQuery(tglInvAwal, tglInvAkhir, filter)
 
-	const wantCount = 18 // 16 base + 2 filter
+	const wantCount = 20 // 18 base + 2 filter
 	if len(args) != wantCount {
 		t.Errorf("jumlah args dengan filter: want %d, got %d", wantCount, len(args))
 	}
@@ -136,14 +136,16 @@ func TestBuildBaseQuery_ArgsOrder(t *testing.T) {
 		"2024-01-31", // [5]  f:              tglInvAkhir
 		"2024-01-15", // [6]  g:              filter.From
 		"2024-01-31", // [7]  g:              filter.To
-		"2024-01-02", // [8]  out_after start (tglInvAwal+1)
-		"2024-01-14", // [9]  out_after end   (filter.From-1)
-		"2024-01-02", // [10] in_after start
-		"2024-01-14", // [11] in_after end
-		"2024-01-02", // [12] movein_after start
-		"2024-01-14", // [13] movein_after end
-		"2024-01-02", // [14] peny_after start
-		"2024-01-14", // [15] peny_after end
+		"2024-01-15", // [8]  keluar:         filter.From
+		"2024-01-31", // [9]  keluar:         filter.To
+		"2024-01-02", // [10] out_after start (tglInvAwal+1)
+		"2024-01-14", // [11] out_after end   (filter.From-1)
+		"2024-01-02", // [12] in_after start
+		"2024-01-14", // [13] in_after end
+		"2024-01-02", // [14] movein_after start
+		"2024-01-14", // [15] movein_after end
+		"2024-01-02", // [16] peny_after start
+		"2024-01-14", // [17] peny_after end
 	}
 
 	if len(args) != len(want) {
@@ -171,20 +173,20 @@ func TestBuildBaseQuery_AfterOpnameDateBoundary(t *testing.T) {
 
 	_, args := buildBaseQuery(tglInvAwal, tglInvAkhir, filter)
 
-	// out_after: args[8] = tglInvAwal+1 = "2024-01-06", args[9] = filter.From-1 = "2024-01-19"
+	// out_after: args[10] = tglInvAwal+1 = "2024-01-06", args[11] = filter.From-1 = "2024-01-19"
 	tests := []struct {
 		idx  int
 		want string
 		desc string
 	}{
-		{8, "2024-01-06", "out_after start (tglInvAwal+1)"},
-		{9, "2024-01-19", "out_after end   (filter.From-1)"},
-		{10, "2024-01-06", "in_after start"},
-		{11, "2024-01-19", "in_after end"},
-		{12, "2024-01-06", "movein_after start"},
-		{13, "2024-01-19", "movein_after end"},
-		{14, "2024-01-06", "peny_after start"},
-		{15, "2024-01-19", "peny_after end"},
+		{10, "2024-01-06", "out_after start (tglInvAwal+1)"},
+		{11, "2024-01-19", "out_after end   (filter.From-1)"},
+		{12, "2024-01-06", "in_after start"},
+		{13, "2024-01-19", "in_after end"},
+		{14, "2024-01-06", "movein_after start"},
+		{15, "2024-01-19", "movein_after end"},
+		{16, "2024-01-06", "peny_after start"},
+		{17, "2024-01-19", "peny_after end"},
 	}
 	for _, tc := range tests {
 		got, ok := args[tc.idx].(string)
@@ -257,8 +259,8 @@ func TestBuildBaseQuery_AfterOpnameEmptyRange_WhenAwalEqualsFrom(t *testing.T) {
 
 	_, args := buildBaseQuery(sameDate, tglInvAkhir, filter)
 
-	afterStart := args[8].(string) // tglInvAwal+1 = "2024-01-16"
-	afterEnd := args[9].(string)   // filter.From-1 = "2024-01-14"
+	afterStart := args[10].(string) // tglInvAwal+1 = "2024-01-16"
+	afterEnd := args[11].(string)   // filter.From-1 = "2024-01-14"
 
 	// afterStart > afterEnd → BETWEEN akan return 0 rows (behavior yang benar)
 	start, _ := time.Parse("2006-01-02", afterStart)
@@ -268,6 +270,103 @@ func TestBuildBaseQuery_AfterOpnameEmptyRange_WhenAwalEqualsFrom(t *testing.T) {
 	}
 }
 
+// ============================================================
+// buildLocationQuery — pure function tests (groupBy=location)
+// ============================================================
+
+func TestBuildReportQuery_UsesLocationQuery_WhenGroupByLocation(t *testing.T) {
+	filter := GetReportFilter{
+		From:    mustParseDate("2024-01-15"),
+		To:      mustParseDate("2024-01-31"),
+		GroupBy: GroupByLocation,
+	}
+
+	query, args := buildReportQuery(mustParseDate("2024-01-01"), mustParseDate("2024-01-31"), filter)
+
+	if !strings.Contains(query, "loc.location_code") {
+		t.Error("expected query per lokasi (loc.location_code)")
+	}
+	if strings.Contains(query, "z.location_code = ?") {
+		t.Error("filter lokasi tidak boleh ada tanpa filter.Location")
+	}
+	if len(args) != 18 {
+		t.Errorf("len(args): want 18, got %d", len(args))
+	}
+}
+
+func TestBuildReportQuery_LocationFilter_IsLastArg(t *testing.T) {
+	filter := GetReportFilter{
+		From:     mustParseDate("2024-01-15"),
+		To:       mustParseDate("2024-01-31"),
+		ItemCode: "MAT",
+		Location: MaterialWarehouseLocation,
+	}
+
+	query, args := buildReportQuery(mustParseDate("2024-01-01"), mustParseDate("2024-01-31"), filter)
+
+	if !strings.Contains(query, "z.location_code = ?") {
+		t.Error("expected filter z.location_code = ?")
+	}
+	if len(args) != 20 {
+		t.Fatalf("len(args): want 20, got %d", len(args))
+	}
+	if args[18] != "%MAT%" {
+		t.Errorf("args[18]: want %%MAT%%, got %v", args[18])
+	}
+	if args[19] != MaterialWarehouseLocation {
+		t.Errorf("args[19]: want %q, got %v", MaterialWarehouseLocation, args[19])
+	}
+}
+
+// Lokasi per lokasi diturunkan dari moveindet.location_code tanpa filter WH-MAT-2;
+// sumber tanpa kolom lokasi masuk bucket UnassignedLocation.
+func TestBuildLocationQuery_DerivesLocationFromMoveinRows(t *testing.T) {
+	filter := GetReportFilter{
+		From:    mustParseDate("2024-01-15"),
+		To:      mustParseDate("2024-01-31"),
+		GroupBy: GroupByLocation,
+	}
+
+	query, _ := buildLocationQuery(mustParseDate("2024-01-01"), mustParseDate("2024-01-31"), filter)
+
+	if strings.Contains(query, "location_code = '"+MaterialWarehouseLocation+"'") {
+		t.Error("query per lokasi tidak boleh memfilter movein ke " + MaterialWarehouseLocation)
+	}
+	moveinLocation := "COALESCE(NULLIF(moveindet.location_code, ''), '" + UnassignedLocation + "')"
+	if strings.Count(query, "GROUP BY item_code, "+moveinLocation) != 2 {
+		t.Error("expected g dan movein_awal dikelompokkan per moveindet.location_code")
+	}
+	if strings.Count(query, "'"+UnassignedLocation+"' AS location_code") != 8 {
+		t.Errorf("expected 8 CTE tanpa kolom lokasi memakai %s", UnassignedLocation)
+	}
+
+	base, _ := buildBaseQuery(mustParseDate("2024-01-01"), mustParseDate("2024-01-31"), filter)
+	if strings.Count(base, "moveindet.location_code = '"+MaterialWarehouseLocation+"'") != 2 {
+		t.Error("laporan gabungan tetap hanya menghitung movein " + MaterialWarehouseLocation)
+	}
+}
+
+func TestBuildLocationQuery_SameDateArgsAsBaseQuery(t *testing.T) {
+	tglInvAwal := mustParseDate("2024-01-01")
+	tglInvAkhir := mustParseDate("2024-01-31")
+	filter := GetReportFilter{
+		From: mustParseDate("2024-01-15"),
+		To:   mustParseDate("2024-01-31"),
+	}
+
+	_, baseArgs := buildBaseQuery(tglInvAwal, tglInvAkhir, filter)
+	_, locArgs := buildLocationQuery(tglInvAwal, tglInvAkhir, filter)
+
+	if len(baseArgs) != len(locArgs) {
+		t.Fatalf("len(args): base %d, location %d", len(baseArgs), len(locArgs))
+	}
+	for i := range baseArgs {
+		if baseArgs[i] != locArgs[i] {
+			t.Errorf("args[%d]: base %v, location %v", i, baseArgs[i], locArgs[i])
+		}
+	}
+}
+
 // ============================================================
 // getBothOpnameDates — DB tests menggunakan sqlmock
 // ============================================================
@@ -0,0 +1,240 @@
+package reconciliationRepository
+
+import (
+	"Bea-Cukai/helper/pabeanType"
+	"Bea-Cukai/helper/queryGuard"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/expenditureProductRepository"
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ---- Constructor ----
+
+type ReconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository(db *gorm.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+// Filter adalah periode dokumen pabean (tgl_pabean) dan filter barang rekonsiliasi.
+type Filter struct {
+	From         time.Time
+	To           time.Time
+	PabeanType   string
+	TransNo      string // hanya inbound (outbound dicocokkan per item, bukan per transaksi)
+	ProductCode  string
+	ProductName  string
+	ProductGroup []string // ms_item.item_group; kosong = semua grup
+}
+
+// buildInboundQuery mencocokkan baris pemasukan (tr_pemasukan_barang) dengan penerimaan gudang
+// (tr_ap_inv_head/det, yang juga dipakai laporan bahan baku) per trans_no + item_code.
+// MySQL tidak punya FULL OUTER JOIN, jadi kunci kedua sisi digabung (UNION) lalu di-LEFT JOIN:
+//   - doc: baris pabean dengan tgl_pabean dalam periode yang jenisnya di ms_pabean berarah IN
+//     atau BOTH (jenis tidak dikenal / dokumen keluar tidak ikut)
+//   - rcv: penerimaan untuk trans_no di doc (tanggal berapa pun) dan penerimaan dengan in_date dalam
+//     periode; penerimaan yang punya baris pabean di luar periode / jenis lain tidak ikut, karena
+//     baris itu direkonsiliasi pada periode dokumennya.
+//
+// Pure function — tidak ada DB call, aman untuk unit test.
+func buildInboundQuery(filter Filter) (string, []interface{}) {
+	from, to := filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02")
+
+	docWhere := "p.tgl_pabean BETWEEN ? AND ? AND mp.direction IN ?"
+	args := []interface{}{from, to, pabeanType.Directions(model.PabeanDirectionIn)}
+	if filter.PabeanType != "" {
+		docWhere += " AND " + pabeanType.NormalizedSQL("p.jenis_pabean") + " = ?"
+		args = append(args, pabeanType.Normalize(filter.PabeanType))
+	}
+	args = append(args, from, to)
+
+	var where []string
+	if filter.TransNo != "" {
+		where = append(where, "k.trans_no = ?")
+		args = append(args, filter.TransNo)
+	}
+	if filter.ProductCode != "" {
+		where = append(where, "k.item_code = ?")
+		args = append(args, filter.ProductCode)
+	}
+	if filter.ProductName != "" {
+		where = append(where, "COALESCE(doc.item_name, i.item_name) LIKE ?")
+		args = append(args, "%"+filter.ProductName+"%")
+	}
+	if len(filter.ProductGroup) > 0 {
+		where = append(where, "i.item_group IN ?")
+		args = append(args, filter.ProductGroup)
+	}
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := `
+		WITH doc AS (
+			SELECT p.trans_no, p.item_code,
+				MAX(p.item_name) AS item_name, MAX(p.pch_unit) AS unit,
+				MAX(p.vendor_code) AS vendor_code, MAX(p.vendor_name) AS vendor_name,
+				MAX(p.jenis_pabean) AS jenis_pabean, MAX(p.no_pabean) AS no_pabean, MIN(p.tgl_pabean) AS tgl_pabean,
+				SUM(p.rcv_qty) AS doc_qty
+			FROM tr_pemasukan_barang p
+			` + pabeanType.Join("p.jenis_pabean") + `
+			WHERE ` + docWhere + `
+			GROUP BY p.trans_no, p.item_code
+		),
+		rcv AS (
+			SELECT a.trans_no, b.item_code, MIN(a.in_date) AS in_date, SUM(b.qty) AS received_qty
+			FROM tr_ap_inv_head a
+			INNER JOIN tr_ap_inv_det b ON a.trans_no = b.trans_no
+			WHERE a.in_date BETWEEN ? AND ? OR a.trans_no IN (SELECT trans_no FROM doc)
+			GROUP BY a.trans_no, b.item_code
+		),
+		k AS (
+			SELECT trans_no, item_code FROM doc
+			UNION
+			SELECT rcv.trans_no, rcv.item_code FROM rcv
+			WHERE NOT EXISTS (
+				SELECT 1 FROM tr_pemasukan_barang x
+				WHERE x.trans_no = rcv.trans_no AND x.item_code = rcv.item_code
+			)
+		)
+		SELECT
+			k.trans_no, k.item_code,
+			COALESCE(doc.item_name, i.item_name, '') AS item_name,
+			COALESCE(i.item_group, '') AS item_group,
+			COALESCE(doc.unit, i.unit_code, '') AS unit,
+			COALESCE(doc.vendor_code, '') AS vendor_code,
+			COALESCE(doc.vendor_name, '') AS vendor_name,
+			(doc.trans_no IS NOT NULL) AS has_document,
+			COALESCE(doc.jenis_pabean, '') AS jenis_pabean,
+			COALESCE(doc.no_pabean, '') AS no_pabean,
+			doc.tgl_pabean,
+			COALESCE(doc.doc_qty, 0) AS doc_qty,
+			(rcv.trans_no IS NOT NULL) AS has_receipt,
+			rcv.in_date,
+			COALESCE(rcv.received_qty, 0) AS received_qty
+		FROM k
+		LEFT JOIN doc ON doc.trans_no = k.trans_no AND doc.item_code = k.item_code
+		LEFT JOIN rcv ON rcv.trans_no = k.trans_no AND rcv.item_code = k.item_code
+		LEFT JOIN ms_item i ON i.item_code = k.item_code
+		` + whereSQL + `
+		ORDER BY COALESCE(doc.tgl_pabean, rcv.in_date), k.trans_no, k.item_code
+	`
+	return query, args
+}
+
+// GetInbound mengembalikan semua baris rekonsiliasi pemasukan periode (tanpa pagination; status
+// dan pagination diterapkan service setelah pencocokan).
+func (r *ReconciliationRepository) GetInbound(ctx context.Context, filter Filter) ([]model.InboundReconciliationLine, error) {
+	query, args := buildInboundQuery(filter)
+
+	var lines []model.InboundReconciliationLine
+	err := queryGuard.Scan(ctx, r.db, &lines, query, args...)
+	return lines, err
+}
+
+// buildOutboundQuery mencocokkan baris pengeluaran (tr_pengeluaran_barang, tgl_pabean) dengan
+// ekspor (tr_export_head/det, tgl_ekspor — sumber keluar laporan barang jadi) dan pengiriman lain
+// (tr_inv_moveout_head/det, trans_date — sumber keluar laporan mesin, scrap dan WIP) per item
+// dalam periode yang sama. Tabel-tabel ini tidak saling mereferensikan nomor transaksi, jadi
+// pencocokan dilakukan per kode barang. Hanya tr_pengeluaran_barang yang kodenya bisa berawalan
+// "1" dan dinormalisasi; ekspor dan pengiriman sudah memakai kode ms_item (kode seperti "1234"
+// tidak diubah). Baris pengeluaran dibatasi ke jenis yang di ms_pabean berarah OUT atau BOTH.
+//
+// Pure function — tidak ada DB call, aman untuk unit test.
+func buildOutboundQuery(filter Filter) (string, []interface{}) {
+	from, to := filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02")
+	docItem := expenditureProductRepository.NormalizedItemCode("p.item_code")
+
+	docWhere := "p.tgl_pabean BETWEEN ? AND ? AND mp.direction IN ?"
+	args := []interface{}{from, to, pabeanType.Directions(model.PabeanDirectionOut)}
+	if filter.PabeanType != "" {
+		docWhere += " AND " + pabeanType.NormalizedSQL("p.jenis_pabean") + " = ?"
+		args = append(args, pabeanType.Normalize(filter.PabeanType))
+	}
+	args = append(args, from, to, from, to)
+
+	var where []string
+	if filter.ProductCode != "" {
+		where = append(where, "k.item_code = ?")
+		args = append(args, filter.ProductCode)
+	}
+	if filter.ProductName != "" {
+		where = append(where, "COALESCE(doc.item_name, i.item_name) LIKE ?")
+		args = append(args, "%"+filter.ProductName+"%")
+	}
+	if len(filter.ProductGroup) > 0 {
+		where = append(where, "i.item_group IN ?")
+		args = append(args, filter.ProductGroup)
+	}
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	query := `
+		WITH doc AS (
+			SELECT ` + docItem + ` AS item_code,
+				MAX(p.item_name) AS item_name, MAX(p.sales_unit) AS unit,
+				GROUP_CONCAT(DISTINCT p.jenis_pabean ORDER BY p.jenis_pabean SEPARATOR ', ') AS jenis_pabean,
+				COUNT(DISTINCT p.jenis_pabean, p.no_pabean) AS doc_count,
+				SUM(p.dlv_qty) AS doc_qty
+			FROM tr_pengeluaran_barang p
+			` + pabeanType.Join("p.jenis_pabean") + `
+			WHERE ` + docWhere + `
+			GROUP BY ` + docItem + `
+		),
+		ekspor AS (
+			SELECT b.no_produk AS item_code, SUM(b.isi_palet) AS exported_qty
+			FROM tr_export_head a
+			INNER JOIN tr_export_det b ON a.trans_no = b.trans_no
+			WHERE a.tgl_ekspor BETWEEN ? AND ?
+			GROUP BY b.no_produk
+		),
+		kirim AS (
+			SELECT b.item_code AS item_code, SUM(b.qty) AS delivered_qty
+			FROM tr_inv_moveout_head a
+			INNER JOIN tr_inv_moveout_det b ON a.trans_no = b.trans_no
+			WHERE a.trans_date BETWEEN ? AND ?
+			GROUP BY b.item_code
+		),
+		k AS (
+			SELECT item_code FROM doc
+			UNION SELECT item_code FROM ekspor
+			UNION SELECT item_code FROM kirim
+		)
+		SELECT
+			k.item_code,
+			COALESCE(doc.item_name, i.item_name, '') AS item_name,
+			COALESCE(i.item_group, '') AS item_group,
+			COALESCE(doc.unit, i.unit_code, '') AS unit,
+			COALESCE(doc.jenis_pabean, '') AS jenis_pabean,
+			COALESCE(doc.doc_count, 0) AS doc_count,
+			COALESCE(doc.doc_qty, 0) AS doc_qty,
+			COALESCE(ekspor.exported_qty, 0) AS exported_qty,
+			COALESCE(kirim.delivered_qty, 0) AS delivered_qty
+		FROM k
+		LEFT JOIN doc ON doc.item_code = k.item_code
+		LEFT JOIN ekspor ON ekspor.item_code = k.item_code
+		LEFT JOIN kirim ON kirim.item_code = k.item_code
+		LEFT JOIN ms_item i ON i.item_code = k.item_code
+		` + whereSQL + `
+		ORDER BY k.item_code
+	`
+	return query, args
+}
+
+// GetOutbound mengembalikan semua baris rekonsiliasi pengeluaran periode (per item, tanpa pagination).
+func (r *ReconciliationRepository) GetOutbound(ctx context.Context, filter Filter) ([]model.OutboundReconciliationLine, error) {
+	query, args := buildOutboundQuery(filter)
+
+	var lines []model.OutboundReconciliationLine
+	err := queryGuard.Scan(ctx, r.db, &lines, query, args...)
+	return lines, err
+}
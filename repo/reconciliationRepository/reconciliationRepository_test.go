@@ -0,0 +1,147 @@
+package reconciliationRepository
+
+import (
+	"Bea-Cukai/helper/pabeanType"
+	"Bea-Cukai/repo/expenditureProductRepository"
+	"context"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open mock: %v", err)
+	}
+	return gormDB, mock
+}
+
+// Urutan args harus mengikuti urutan ? di query: periode dokumen, arah ms_pabean, jenis pabean
+// (dinormalisasi), periode penerimaan, lalu filter barang.
+func TestBuildInboundQuery_ArgsOrder(t *testing.T) {
+	query, args := buildInboundQuery(Filter{
+		From:         time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		PabeanType:   "BC 2.3",
+		ProductCode:  "RM-001",
+		ProductGroup: []string{"MATERIAL"},
+	})
+
+	want := []interface{}{"2026-09-01", "2026-09-30", []string{"IN", "BOTH"}, "23", "2026-09-01", "2026-09-30", "RM-001", []string{"MATERIAL"}}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %v; want %v", args, want)
+	}
+	if got := strings.Count(query, "?"); got != len(args) {
+		t.Errorf("placeholders = %d; want %d", got, len(args))
+	}
+	if !strings.Contains(query, "WHERE k.item_code = ? AND i.item_group IN ?") {
+		t.Errorf("item filters missing from query:\n%s", query)
+	}
+	docWhere := pabeanType.Join("p.jenis_pabean") + "\n\t\t\tWHERE p.tgl_pabean BETWEEN ? AND ? AND mp.direction IN ? AND " +
+		pabeanType.NormalizedSQL("p.jenis_pabean") + " = ?"
+	if !strings.Contains(query, docWhere) {
+		t.Errorf("doc CTE not restricted to inbound ms_pabean types:\n%s", query)
+	}
+}
+
+// Baris pabean dan penerimaan digabung per trans_no + item; sisi yang tidak ada bernilai 0
+// dan ditandai has_document / has_receipt.
+func TestGetInbound_ScansBothSides(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewReconciliationRepository(db)
+
+	tgl := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`WITH doc AS .*FROM tr_pemasukan_barang p.*`+
+		regexp.QuoteMeta("FROM tr_ap_inv_head a INNER JOIN tr_ap_inv_det b ON a.trans_no = b.trans_no")+
+		`.*UNION.*NOT EXISTS.*FROM k LEFT JOIN doc .* LEFT JOIN rcv `).
+		WithArgs("2026-09-01", "2026-09-30", "IN", "BOTH", "2026-09-01", "2026-09-30").
+		WillReturnRows(sqlmock.NewRows([]string{"trans_no", "item_code", "item_name", "has_document", "jenis_pabean", "tgl_pabean", "doc_qty", "has_receipt", "in_date", "received_qty"}).
+			AddRow("AP-001", "RM-001", "Resin", true, "BC 2.3", tgl, "100", false, nil, "0").
+			AddRow("AP-002", "RM-002", "Pigmen", false, "", nil, "0", true, tgl, "25"))
+
+	lines, err := repo.GetInbound(context.Background(), Filter{
+		From: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("len = %d; want 2", len(lines))
+	}
+	if !lines[0].HasDocument || lines[0].HasReceipt || !lines[0].DocQty.Equal(decimal.NewFromInt(100)) || lines[0].InDate != nil {
+		t.Errorf("line 0 = %+v", lines[0])
+	}
+	if lines[1].HasDocument || !lines[1].HasReceipt || lines[1].TglPabean != nil || !lines[1].ReceivedQty.Equal(decimal.NewFromInt(25)) {
+		t.Errorf("line 1 = %+v", lines[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
+
+// Hanya kode barang dokumen pengeluaran yang dinormalisasi (prefix "1" dibuang); kode ekspor dan
+// pengiriman sudah kode ms_item sehingga "1234" tetap "1234". Dokumen dibatasi ke jenis yang di
+// ms_pabean berarah OUT / BOTH, dan filter pabeanType dinormalisasi.
+func TestGetOutbound_NormalizesOnlyDocumentItemCode(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewReconciliationRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT "+expenditureProductRepository.NormalizedItemCode("p.item_code")+" AS item_code")+
+		`.*FROM tr_pengeluaran_barang p `+regexp.QuoteMeta(pabeanType.Join("p.jenis_pabean"))+
+		` WHERE p\.tgl_pabean BETWEEN \? AND \? AND mp\.direction IN \(\?,\?\) AND `+
+		regexp.QuoteMeta(pabeanType.NormalizedSQL("p.jenis_pabean"))+` = \?.*`+
+		regexp.QuoteMeta("SELECT b.no_produk AS item_code, SUM(b.isi_palet) AS exported_qty")+`.*GROUP BY b\.no_produk.*`+
+		regexp.QuoteMeta("SELECT b.item_code AS item_code, SUM(b.qty) AS delivered_qty")+`.*GROUP BY b\.item_code.*`+
+		regexp.QuoteMeta("WHERE k.item_code = ?")).
+		WithArgs("2026-09-01", "2026-09-30", "OUT", "BOTH", "30", "2026-09-01", "2026-09-30", "2026-09-01", "2026-09-30", "1234").
+		WillReturnRows(sqlmock.NewRows([]string{"item_code", "item_name", "jenis_pabean", "doc_count", "doc_qty", "exported_qty", "delivered_qty"}).
+			AddRow("1234", "Produk A", "", 0, "0", "100", "0"))
+
+	lines, err := repo.GetOutbound(context.Background(), Filter{
+		From:        time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:          time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		PabeanType:  "BC 3.0",
+		ProductCode: "1234",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lines) != 1 || lines[0].ItemCode != "1234" || !lines[0].ExportedQty.Equal(decimal.NewFromInt(100)) {
+		t.Fatalf("lines = %+v", lines)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
+
+func TestBuildOutboundQuery_ExportSideKeepsItemCode(t *testing.T) {
+	query, _ := buildOutboundQuery(Filter{From: time.Now(), To: time.Now()})
+	for _, col := range []string{"b.no_produk", "b.item_code"} {
+		if strings.Contains(query, expenditureProductRepository.NormalizedItemCode(col)) {
+			t.Errorf("%s dinormalisasi; kode ekspor / pengiriman harus dipakai apa adanya", col)
+		}
+	}
+	if !strings.Contains(query, expenditureProductRepository.NormalizedItemCode("p.item_code")) {
+		t.Error("kode barang dokumen pengeluaran tidak dinormalisasi")
+	}
+}
@@ -1,6 +1,8 @@
 package rejectScrapReportRepository
 
 import (
+	"Bea-Cukai/helper/queryGuard"
+	"Bea-Cukai/helper/reportQuery"
 	"Bea-Cukai/model"
 	"context"
 	"fmt"
@@ -26,10 +28,27 @@ type GetReportFilter struct {
 	To       time.Time
 	ItemCode string
 	ItemName string
+	Options  reportQuery.Options
 	Page     int
 	Limit    int
 }
 
+// ReportColumns adalah whitelist kolom untuk parameter sort/filter laporan ini.
+// keluar mengikuti perhitungan di GetReport (awal + masuk - opname), bukan kolom keluar SQL.
+var ReportColumns = reportQuery.Columns{
+	"item_code":      {Expr: "rq.item_code"},
+	"item_name":      {Expr: "rq.item_name"},
+	"unit_code":      {Expr: "rq.unit_code"},
+	"item_type_code": {Expr: "rq.item_type_code"},
+	"location_code":  {Expr: "rq.location_code"},
+	"awal":           {Expr: "rq.awal", Numeric: true},
+	"masuk":          {Expr: "rq.masuk", Numeric: true},
+	"keluar":         {Expr: "(rq.awal + rq.masuk - rq.opname)", Numeric: true},
+	"peny":           {Expr: "rq.peny", Numeric: true},
+	"akhir":          {Expr: "rq.akhir", Numeric: true},
+	"opname":         {Expr: "rq.opname", Numeric: true},
+}
+
 // Helper function to get max opname date for SCRAP items
 func (r *RejectScrapReportRepository) getMaxScrapOpnameDate(ctx context.Context, beforeDate time.Time) (time.Time, error) {
 	var result struct {
@@ -165,16 +184,19 @@ func (r *RejectScrapReportRepository) GetReport(ctx context.Context, filter GetR
 	}
 	queryArgs = append(queryArgs, args...)
 
+	// Sort/filter tambahan dari parameter request
+	finalQuery, queryArgs := filter.Options.Apply(baseQuery, queryArgs, ReportColumns)
+
 	// Get total count first
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (%s) as subquery", baseQuery)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (%s) as subquery", finalQuery)
 	var totalCount int64
-	err = r.db.WithContext(ctx).Raw(countQuery, queryArgs...).Scan(&totalCount).Error
+	err = queryGuard.Scan(ctx, r.db, &totalCount, countQuery, queryArgs...)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// Add LIMIT and OFFSET for pagination
-	paginatedQuery := baseQuery
+	paginatedQuery := finalQuery + filter.Options.OrderBy(ReportColumns, reportQuery.DefaultOrder)
 	if filter.Limit > 0 {
 		offset := 0
 		if filter.Page > 1 {
@@ -201,7 +223,7 @@ func (r *RejectScrapReportRepository) GetReport(ctx context.Context, filter GetR
 	}
 
 	var rawResults []RawResult
-	err = r.db.WithContext(ctx).Raw(paginatedQuery, queryArgs...).Scan(&rawResults).Error
+	err = queryGuard.Scan(ctx, r.db, &rawResults, paginatedQuery, queryArgs...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -0,0 +1,98 @@
+package reportAccessLogRepository
+
+import (
+	"Bea-Cukai/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReportAccessLogRepository struct {
+	db *gorm.DB
+}
+
+func NewReportAccessLogRepository(db *gorm.DB) *ReportAccessLogRepository {
+	return &ReportAccessLogRepository{db: db}
+}
+
+// Create - simpan satu log akses laporan
+func (r *ReportAccessLogRepository) Create(log *model.ReportAccessLog) error {
+	return r.db.Create(log).Error
+}
+
+// filteredQuery - query log dengan filter list (tanpa pagination)
+func (r *ReportAccessLogRepository) filteredQuery(req model.ReportAccessLogListRequest) *gorm.DB {
+	query := r.db.Model(&model.ReportAccessLog{})
+
+	if req.UserId != "" {
+		query = query.Where("user_id = ?", req.UserId)
+	}
+	if req.Username != "" {
+		query = query.Where("username LIKE ?", "%"+req.Username+"%")
+	}
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+	if req.ReportType != "" {
+		query = query.Where("report_type = ?", req.ReportType)
+	}
+
+	// Date range filter
+	if req.StartDate != "" {
+		if startDate, err := time.Parse("2006-01-02", req.StartDate); err == nil {
+			query = query.Where("created_at >= ?", startDate)
+		}
+	}
+	if req.EndDate != "" {
+		if endDate, err := time.Parse("2006-01-02", req.EndDate); err == nil {
+			// Add 1 day to include the end date
+			query = query.Where("created_at < ?", endDate.Add(24*time.Hour))
+		}
+	}
+
+	return query
+}
+
+// GetAll - get logs with filtering and pagination (terbaru lebih dulu)
+func (r *ReportAccessLogRepository) GetAll(req model.ReportAccessLogListRequest) ([]model.ReportAccessLog, int64, error) {
+	var logs []model.ReportAccessLog
+	var total int64
+
+	query := r.filteredQuery(req)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if req.Page > 0 && req.Limit > 0 {
+		offset := (req.Page - 1) * req.Limit
+		query = query.Offset(offset).Limit(req.Limit)
+	}
+
+	if err := query.Order("created_at DESC").Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+// Stream - baca semua log yang cocok dengan filter lewat cursor (untuk export)
+func (r *ReportAccessLogRepository) Stream(req model.ReportAccessLogListRequest, fn func(model.ReportAccessLog) error) error {
+	query := r.filteredQuery(req).Order("created_at DESC")
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var log model.ReportAccessLog
+		if err := query.ScanRows(rows, &log); err != nil {
+			return err
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
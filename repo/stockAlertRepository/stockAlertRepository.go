@@ -0,0 +1,272 @@
+package stockAlertRepository
+
+import (
+	"Bea-Cukai/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Hasil UpsertAlert.
+const (
+	UpsertCreated   = "created"
+	UpsertUpdated   = "updated"
+	UpsertReopened  = "reopened"
+	UpsertUnchanged = "unchanged"
+)
+
+type StockAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewStockAlertRepository(db *gorm.DB) *StockAlertRepository {
+	return &StockAlertRepository{
+		db: db,
+	}
+}
+
+// ---- Rules ----
+
+// GetRules - get alert rules, optionally only active ones
+func (r *StockAlertRepository) GetRules(activeOnly bool) ([]model.StockAlertRule, error) {
+	var rules []model.StockAlertRule
+
+	query := r.db.Model(&model.StockAlertRule{})
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	if err := query.Order("item_group ASC, id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// CreateRule - create new alert rule
+func (r *StockAlertRepository) CreateRule(req model.StockAlertRuleRequest) (model.StockAlertRule, error) {
+	rule := model.StockAlertRule{
+		ItemGroup:        req.ItemGroup,
+		RuleType:         req.RuleType,
+		ThresholdPercent: req.ThresholdPercent,
+		IsActive:         true,
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	// Select eksplisit agar is_active=false tidak diganti default kolom
+	if err := r.db.Select("ItemGroup", "RuleType", "ThresholdPercent", "IsActive").Create(&rule).Error; err != nil {
+		return model.StockAlertRule{}, err
+	}
+	return rule, nil
+}
+
+// UpdateRule - update alert rule by id
+func (r *StockAlertRepository) UpdateRule(id int, req model.StockAlertRuleRequest) (model.StockAlertRule, error) {
+	var rule model.StockAlertRule
+	if err := r.db.Where("id = ?", id).First(&rule).Error; err != nil {
+		return model.StockAlertRule{}, err
+	}
+
+	rule.ItemGroup = req.ItemGroup
+	rule.RuleType = req.RuleType
+	rule.ThresholdPercent = req.ThresholdPercent
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := r.db.Save(&rule).Error; err != nil {
+		return model.StockAlertRule{}, err
+	}
+	return rule, nil
+}
+
+// DeleteRule - delete alert rule by id; alert yang sudah tercatat tetap disimpan
+func (r *StockAlertRepository) DeleteRule(id int) error {
+	result := r.db.Where("id = ?", id).Delete(&model.StockAlertRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ---- Alerts ----
+
+// UpsertAlert menyimpan pelanggaran rule. Kunci: rule_id + item_code + period_from, sehingga
+// evaluasi harian dalam satu bulan memperbarui baris yang sama (period_to ikut diperbarui).
+//   - belum ada                  → insert status open
+//   - sudah ada, angka sama      → tidak diubah (alert resolved tetap resolved)
+//   - sudah ada, resolved, beda  → nilai diperbarui dan status kembali open
+//   - sudah ada, lainnya, beda   → nilai diperbarui, status tetap
+func (r *StockAlertRepository) UpsertAlert(alert model.StockAlert) (string, error) {
+	existing, err := r.findAlert(alert)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		alert.Status = model.AlertStatusOpen
+		// ON DUPLICATE KEY: dua evaluasi yang berjalan bersamaan tidak gagal di uq_rule_item_period;
+		// yang kalah (RowsAffected 0) lanjut ke jalur update.
+		result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&alert)
+		if result.Error != nil {
+			return "", result.Error
+		}
+		if result.RowsAffected > 0 {
+			return UpsertCreated, nil
+		}
+		existing, err = r.findAlert(alert)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	outcome := AlertOutcome(existing, alert)
+	if outcome == UpsertUnchanged {
+		return outcome, nil
+	}
+
+	updates := map[string]interface{}{
+		"item_name": alert.ItemName,
+		"unit_code": alert.UnitCode,
+		"period_to": alert.PeriodTo.Format("2006-01-02"),
+		"awal":      alert.Awal,
+		"akhir":     alert.Akhir,
+		"opname":    alert.Opname,
+		"selisih":   alert.Selisih,
+		"message":   alert.Message,
+	}
+	if outcome == UpsertReopened {
+		updates["status"] = model.AlertStatusOpen
+		updates["resolved_by"] = ""
+		updates["resolved_at"] = nil
+	}
+
+	if err := r.db.Model(&existing).Updates(updates).Error; err != nil {
+		return "", err
+	}
+	return outcome, nil
+}
+
+// findAlert mencari alert dengan kunci rule_id + item_code + period_from.
+func (r *StockAlertRepository) findAlert(alert model.StockAlert) (model.StockAlert, error) {
+	var existing model.StockAlert
+	err := r.db.
+		Where("rule_id = ? AND item_code = ? AND period_from = ?",
+			alert.RuleId, alert.ItemCode, alert.PeriodFrom.Format("2006-01-02")).
+		First(&existing).Error
+	return existing, err
+}
+
+// AlertOutcome menentukan hasil upsert untuk alert yang sudah ada. Alert hanya dianggap
+// berubah bila angka saldonya berubah, sehingga resolve manual tidak dibuka lagi oleh
+// evaluasi berikutnya selama angkanya sama.
+func AlertOutcome(existing, alert model.StockAlert) string {
+	changed := !existing.Awal.Equal(alert.Awal) ||
+		!existing.Akhir.Equal(alert.Akhir) ||
+		!existing.Opname.Equal(alert.Opname) ||
+		!existing.Selisih.Equal(alert.Selisih)
+
+	switch {
+	case !changed:
+		return UpsertUnchanged
+	case existing.Status == model.AlertStatusResolved:
+		return UpsertReopened
+	default:
+		return UpsertUpdated
+	}
+}
+
+// GetAll - get alerts with filtering and pagination
+func (r *StockAlertRepository) GetAll(req model.StockAlertListRequest) ([]model.StockAlert, int64, error) {
+	var alerts []model.StockAlert
+	var total int64
+
+	query := r.db.Model(&model.StockAlert{})
+
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.ItemGroup != "" {
+		query = query.Where("item_group = ?", req.ItemGroup)
+	}
+	if req.RuleType != "" {
+		query = query.Where("rule_type = ?", req.RuleType)
+	}
+	if req.ItemCode != "" {
+		query = query.Where("item_code LIKE ?", "%"+req.ItemCode+"%")
+	}
+
+	// Periode alert yang beririsan dengan rentang from..to
+	if req.From != "" {
+		if from, err := time.Parse("2006-01-02", req.From); err == nil {
+			query = query.Where("period_to >= ?", from.Format("2006-01-02"))
+		}
+	}
+	if req.To != "" {
+		if to, err := time.Parse("2006-01-02", req.To); err == nil {
+			query = query.Where("period_from <= ?", to.Format("2006-01-02"))
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if req.Page > 0 && req.Limit > 0 {
+		offset := (req.Page - 1) * req.Limit
+		query = query.Offset(offset).Limit(req.Limit)
+	}
+
+	if err := query.Order("created_at DESC, id DESC").Find(&alerts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return alerts, total, nil
+}
+
+// Acknowledge - tandai alert sudah dilihat; alert resolved tidak bisa di-acknowledge
+func (r *StockAlertRepository) Acknowledge(id int, username, note string) (model.StockAlert, error) {
+	var alert model.StockAlert
+	if err := r.db.Where("id = ?", id).First(&alert).Error; err != nil {
+		return model.StockAlert{}, err
+	}
+	if alert.Status == model.AlertStatusResolved {
+		return model.StockAlert{}, errors.New("alert already resolved")
+	}
+
+	now := time.Now()
+	alert.Status = model.AlertStatusAcknowledged
+	alert.AcknowledgedBy = username
+	alert.AcknowledgedAt = &now
+	if note != "" {
+		alert.Note = note
+	}
+
+	if err := r.db.Save(&alert).Error; err != nil {
+		return model.StockAlert{}, err
+	}
+	return alert, nil
+}
+
+// Resolve - tandai alert selesai ditindaklanjuti
+func (r *StockAlertRepository) Resolve(id int, username, note string) (model.StockAlert, error) {
+	var alert model.StockAlert
+	if err := r.db.Where("id = ?", id).First(&alert).Error; err != nil {
+		return model.StockAlert{}, err
+	}
+
+	now := time.Now()
+	alert.Status = model.AlertStatusResolved
+	alert.ResolvedBy = username
+	alert.ResolvedAt = &now
+	if note != "" {
+		alert.Note = note
+	}
+
+	if err := r.db.Save(&alert).Error; err != nil {
+		return model.StockAlert{}, err
+	}
+	return alert, nil
+}
@@ -0,0 +1,74 @@
+package stockAlertRepository
+
+import (
+	"Bea-Cukai/model"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open mock: %v", err)
+	}
+	return gormDB, mock
+}
+
+const findAlertQuery = "SELECT \\* FROM `stock_alert` WHERE rule_id = \\? AND item_code = \\? AND period_from = \\?"
+
+// Dua sync bersamaan: insert kalah di uq_rule_item_period (0 rows affected), baris yang
+// dibuat sync lain dibaca ulang lalu diperbarui, bukan gagal dengan duplicate key.
+func TestUpsertAlert_ConcurrentInsertFallsBackToUpdate(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewStockAlertRepository(db)
+
+	mock.ExpectQuery(findAlertQuery).
+		WithArgs(7, "MAT-01", "2026-09-01", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `stock_alert` .* ON DUPLICATE KEY UPDATE").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(findAlertQuery).
+		WithArgs(7, "MAT-01", "2026-09-01", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "awal", "akhir", "opname", "selisih"}).
+			AddRow(11, model.AlertStatusOpen, "0", "-1", "0", "1"))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `stock_alert` SET .*`period_to`=\\?.* WHERE `id` = \\?").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	outcome, err := repo.UpsertAlert(model.StockAlert{
+		RuleId:     7,
+		ItemCode:   "MAT-01",
+		PeriodFrom: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		PeriodTo:   time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC),
+		Akhir:      decimal.NewFromInt(-4),
+		Selisih:    decimal.NewFromInt(4),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outcome != UpsertUpdated {
+		t.Errorf("outcome = %s; want %s", outcome, UpsertUpdated)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
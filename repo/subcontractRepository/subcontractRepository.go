@@ -0,0 +1,103 @@
+package subcontractRepository
+
+import (
+	"Bea-Cukai/helper/pabeanType"
+	"Bea-Cukai/helper/queryGuard"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/expenditureProductRepository"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ---- Constructor ----
+
+type SubcontractRepository struct {
+	db *gorm.DB
+}
+
+func NewSubcontractRepository(db *gorm.DB) *SubcontractRepository {
+	return &SubcontractRepository{db: db}
+}
+
+// Filter memilih dokumen subkontrak sampai AsOf. Semua dokumen sebelum AsOf ikut dibaca, karena
+// pengembalian dipasangkan FIFO ke pengiriman terlama.
+type Filter struct {
+	AsOf              time.Time
+	SubcontractorCode string
+	ItemCode          string
+	ItemGroup         []string // ms_item.item_group; kosong = semua grup
+}
+
+// Kode master ms_pabean (pabean_code, lihat pabean.txt). jenis_pabean teks bebas ("BC 2.6.1",
+// "200", ...) di-resolve ke master lewat pabeanJoin lalu dicocokkan dengan mp.pabean_code.
+const (
+	shipmentPabeanCode = "200" // BC2.6.1: pengeluaran ke subkontraktor
+	returnPabeanCode   = "262" // BC2.6.2: pemasukan kembali dari subkontraktor
+)
+
+// pabeanJoin menggabungkan master ms_pabean (alias mp) untuk jenis dokumen baris.
+var pabeanJoin = pabeanType.Join("p.jenis_pabean")
+
+// returnSubcontractorCode adalah kode subkontraktor BC 2.6.2 dalam kode customer BC 2.6.1:
+// vendor_code dipetakan lewat ms_subcontractor_map (alias sm), atau dipakai apa adanya bila tidak
+// terdaftar. Butuh subcontractorMapJoin.
+const returnSubcontractorCode = "COALESCE(sm.cust_code, p.vendor_code)"
+
+// subcontractorMapJoin menggabungkan pemetaan kode vendor ke kode customer subkontraktor.
+var subcontractorMapJoin = "LEFT JOIN " + model.SubcontractorMap{}.TableName() + " sm ON sm.vendor_code = p.vendor_code"
+
+// GetShipments mengembalikan baris BC 2.6.1 (tr_pengeluaran_barang) sampai AsOf, urut tanggal dokumen.
+func (r *SubcontractRepository) GetShipments(ctx context.Context, filter Filter) ([]model.SubcontractMovement, error) {
+	itemCode := expenditureProductRepository.NormalizedItemCode("p.item_code")
+	var shipments []model.SubcontractMovement
+	err := queryGuard.Run(ctx, r.db, func(tx *gorm.DB) error {
+		query := tx.Table(model.ExpenditureProduct{}.TableName()+" AS p").
+			Select(`p.jenis_pabean, p.no_pabean, p.tgl_pabean, p.trans_no,
+				p.cust_code AS subcontractor_code, p.cust_name AS subcontractor_name,
+				`+itemCode+` AS item_code, p.item_name, p.sales_unit AS unit, p.dlv_qty AS qty`).
+			Joins(pabeanJoin).
+			Joins("LEFT JOIN ms_item i ON i.item_code = "+itemCode).
+			Where("mp.pabean_code = ?", shipmentPabeanCode)
+		query = applyFilter(query, filter, "p.cust_code", itemCode)
+		return query.Order("p.tgl_pabean ASC, p.no_pabean ASC, p.idx ASC").Scan(&shipments).Error
+	})
+	return shipments, err
+}
+
+// GetReturns mengembalikan baris BC 2.6.2 (tr_pemasukan_barang) sampai AsOf, urut tanggal dokumen.
+// subcontractor_code adalah kode customer hasil pemetaan (lihat returnSubcontractorCode) agar
+// sama dengan kunci GetShipments; filter SubcontractorCode juga memakai kode customer.
+func (r *SubcontractRepository) GetReturns(ctx context.Context, filter Filter) ([]model.SubcontractMovement, error) {
+	var returns []model.SubcontractMovement
+	err := queryGuard.Run(ctx, r.db, func(tx *gorm.DB) error {
+		query := tx.Table(model.EntryProduct{}.TableName()+" AS p").
+			Select(`p.jenis_pabean, p.no_pabean, p.tgl_pabean, p.trans_no,
+				`+returnSubcontractorCode+` AS subcontractor_code, p.vendor_name AS subcontractor_name,
+				p.item_code, p.item_name, p.pch_unit AS unit, p.rcv_qty AS qty`).
+			Joins(subcontractorMapJoin).
+			Joins(pabeanJoin).
+			Joins("LEFT JOIN ms_item i ON i.item_code = p.item_code").
+			Where("mp.pabean_code = ?", returnPabeanCode)
+		query = applyFilter(query, filter, returnSubcontractorCode, "p.item_code")
+		return query.Order("p.tgl_pabean ASC, p.no_pabean ASC, p.idx ASC").Scan(&returns).Error
+	})
+	return returns, err
+}
+
+// applyFilter menerapkan filter yang sama ke kedua sisi; partyCol / itemCol adalah kolom
+// subkontraktor dan kode barang (sudah dinormalisasi) tabel tersebut.
+func applyFilter(query *gorm.DB, filter Filter, partyCol, itemCol string) *gorm.DB {
+	query = query.Where("p.tgl_pabean <= ?", filter.AsOf.Format("2006-01-02"))
+	if filter.SubcontractorCode != "" {
+		query = query.Where(partyCol+" = ?", filter.SubcontractorCode)
+	}
+	if filter.ItemCode != "" {
+		query = query.Where(itemCol+" = ?", filter.ItemCode)
+	}
+	if len(filter.ItemGroup) > 0 {
+		query = query.Where("i.item_group IN ?", filter.ItemGroup)
+	}
+	return query
+}
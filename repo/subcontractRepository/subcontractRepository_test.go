@@ -0,0 +1,94 @@
+package subcontractRepository
+
+import (
+	"Bea-Cukai/repo/expenditureProductRepository"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open mock: %v", err)
+	}
+	return gormDB, mock
+}
+
+// BC 2.6.1 dibaca dari tr_pengeluaran_barang dengan jenis_pabean teks bebas yang di-resolve ke
+// master ms_pabean dan kode barang pengeluaran tanpa awalan "1", agar bisa dipasangkan dengan BC 2.6.2.
+func TestGetShipments_NormalizedPabeanTypeAndItemCode(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewSubcontractRepository(db)
+
+	itemCode := regexp.QuoteMeta(expenditureProductRepository.NormalizedItemCode("p.item_code"))
+	mock.ExpectQuery(`SELECT p\.jenis_pabean.*p\.cust_code AS subcontractor_code.*`+itemCode+` AS item_code.*`+
+		`FROM tr_pengeluaran_barang AS p `+regexp.QuoteMeta(pabeanJoin)+` LEFT JOIN ms_item i ON i\.item_code = `+itemCode+` `+
+		`WHERE mp\.pabean_code = \? AND p\.tgl_pabean <= \? `+
+		`AND p\.cust_code = \? AND \(`+itemCode+` = \?\) ORDER BY p\.tgl_pabean ASC`).
+		WithArgs(shipmentPabeanCode, "2026-09-30", "SUB-01", "RM-001").
+		WillReturnRows(sqlmock.NewRows([]string{"jenis_pabean", "no_pabean", "tgl_pabean", "subcontractor_code", "item_code", "qty"}).
+			AddRow("BC 2.6.1", "000200", time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), "SUB-01", "RM-001", "100"))
+
+	shipments, err := repo.GetShipments(context.Background(), Filter{
+		AsOf:              time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		SubcontractorCode: "SUB-01",
+		ItemCode:          "RM-001",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(shipments) != 1 || shipments[0].NoPabean != "000200" || !shipments[0].Qty.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("shipments = %+v", shipments)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
+
+// Vendor BC 2.6.2 dipetakan ke kode customer lewat ms_subcontractor_map, termasuk filter subkontraktor.
+func TestGetReturns_MapsVendorToSubcontractor(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewSubcontractRepository(db)
+
+	code := regexp.QuoteMeta(returnSubcontractorCode)
+	mock.ExpectQuery(`SELECT p\.jenis_pabean.*`+code+` AS subcontractor_code.*p\.rcv_qty AS qty `+
+		`FROM tr_pemasukan_barang AS p `+regexp.QuoteMeta(subcontractorMapJoin)+` `+regexp.QuoteMeta(pabeanJoin)+
+		` LEFT JOIN ms_item i ON i\.item_code = p\.item_code `+
+		`WHERE mp\.pabean_code = \? AND p\.tgl_pabean <= \? `+
+		`AND `+code+` = \? AND i\.item_group IN \(\?\) ORDER BY p\.tgl_pabean ASC`).
+		WithArgs(returnPabeanCode, "2026-09-30", "SUB-01", "MATERIAL").
+		WillReturnRows(sqlmock.NewRows([]string{"no_pabean", "subcontractor_code", "item_code", "qty"}))
+
+	returns, err := repo.GetReturns(context.Background(), Filter{
+		AsOf:              time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		SubcontractorCode: "SUB-01",
+		ItemGroup:         []string{"MATERIAL"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(returns) != 0 {
+		t.Errorf("returns = %+v; want empty", returns)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
@@ -1,6 +1,8 @@
 package wipPositionReportRepository
 
 import (
+	"Bea-Cukai/helper/queryGuard"
+	"Bea-Cukai/helper/reportQuery"
 	"Bea-Cukai/model"
 	"context"
 	"fmt"
@@ -26,10 +28,21 @@ type GetReportFilter struct {
 	TglAkhir time.Time
 	ItemCode string
 	ItemName string
+	Options  reportQuery.Options
 	Page     int
 	Limit    int
 }
 
+// ReportColumns adalah whitelist kolom untuk parameter sort/filter laporan ini.
+// jumlah pada response berasal dari kolom awal.
+var ReportColumns = reportQuery.Columns{
+	"item_code":      {Expr: "rq.item_code"},
+	"item_name":      {Expr: "rq.item_name"},
+	"unit_code":      {Expr: "rq.unit_code"},
+	"item_type_code": {Expr: "rq.item_type_code"},
+	"jumlah":         {Expr: "rq.awal", Numeric: true},
+}
+
 // Helper function to get max inventory opname date
 func (r *WipPositionReportRepository) getMaxOpnameDate(ctx context.Context, beforeDate time.Time) (time.Time, error) {
 	var result struct {
@@ -154,16 +167,19 @@ func (r *WipPositionReportRepository) GetReport(ctx context.Context, filter GetR
 	}
 	queryArgs = append(queryArgs, args...)
 
+	// Sort/filter tambahan dari parameter request
+	filteredQuery, queryArgs := filter.Options.Apply(fmt.Sprintf("SELECT * FROM (%s) as subquery WHERE subquery.awal <> 0", baseQuery), queryArgs, ReportColumns)
+
 	// Get total count first
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (%s) as subquery WHERE subquery.awal <> 0", baseQuery)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (%s) as counted", filteredQuery)
 	var totalCount int64
-	err = r.db.WithContext(ctx).Raw(countQuery, queryArgs...).Scan(&totalCount).Error
+	err = queryGuard.Scan(ctx, r.db, &totalCount, countQuery, queryArgs...)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// Add LIMIT and OFFSET for pagination
-	finalQuery := fmt.Sprintf("SELECT * FROM (%s) as subquery WHERE subquery.awal <> 0", baseQuery)
+	finalQuery := filteredQuery + filter.Options.OrderBy(ReportColumns, reportQuery.DefaultOrder)
 	if filter.Limit > 0 {
 		offset := 0
 		if filter.Page > 1 {
@@ -188,7 +204,7 @@ func (r *WipPositionReportRepository) GetReport(ctx context.Context, filter GetR
 		Opname       decimal.Decimal `gorm:"column:opname"`
 	}
 
-	err = r.db.WithContext(ctx).Raw(finalQuery, queryArgs...).Scan(&rawResults).Error
+	err = queryGuard.Scan(ctx, r.db, &rawResults, finalQuery, queryArgs...)
 	if err != nil {
 		return nil, 0, err
 	}
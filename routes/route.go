@@ -2,45 +2,81 @@ package routes
 
 import (
 	"Bea-Cukai/controller/auxiliaryMaterialReportController"
+	"Bea-Cukai/controller/companyProfileController"
+	"Bea-Cukai/controller/continuityCheckController"
 	"Bea-Cukai/controller/entryProductController"
 	"Bea-Cukai/controller/expenditureProductController"
+	"Bea-Cukai/controller/exchangeRateController"
+	"Bea-Cukai/controller/exportDocumentController"
+	"Bea-Cukai/controller/exportJobController"
 	"Bea-Cukai/controller/finishedProductReportController"
 	"Bea-Cukai/controller/itemGroupController"
+	"Bea-Cukai/controller/lotTraceController"
+	"Bea-Cukai/controller/lpjBundleController"
 	"Bea-Cukai/controller/machineToolReportController"
 	"Bea-Cukai/controller/pabeanController"
+	"Bea-Cukai/controller/pabeanDocumentController"
 	"Bea-Cukai/controller/productController"
 	"Bea-Cukai/controller/rawMaterialReportController"
+	"Bea-Cukai/controller/reconciliationController"
 	"Bea-Cukai/controller/rejectScrapReportController"
+	"Bea-Cukai/controller/reportAccessLogController"
+	"Bea-Cukai/controller/reportCacheController"
+	"Bea-Cukai/controller/stockAlertController"
+	"Bea-Cukai/controller/subcontractController"
 	"Bea-Cukai/controller/syncController"
 	"Bea-Cukai/controller/transactionLogController"
 	"Bea-Cukai/controller/userController"
 	"Bea-Cukai/controller/userLogController"
 	"Bea-Cukai/controller/wipPositionReportController"
+	"Bea-Cukai/helper/reportCache"
+	"Bea-Cukai/helper/reportExport"
 	"Bea-Cukai/middleware"
 	"Bea-Cukai/repo/auxiliaryMaterialReportRepository"
+	"Bea-Cukai/repo/companyProfileRepository"
 	"Bea-Cukai/repo/entryProductRepository"
 	"Bea-Cukai/repo/expenditureProductRepository"
+	"Bea-Cukai/repo/exchangeRateRepository"
+	"Bea-Cukai/repo/exportDocumentRepository"
+	"Bea-Cukai/repo/exportJobRepository"
 	"Bea-Cukai/repo/finishedProductReportRepository"
 	"Bea-Cukai/repo/itemGroupRepository"
+	"Bea-Cukai/repo/lotTraceRepository"
 	"Bea-Cukai/repo/machineToolReportRepository"
+	"Bea-Cukai/repo/pabeanDocumentRepository"
 	"Bea-Cukai/repo/pabeanRepository"
 	"Bea-Cukai/repo/productRepository"
 	"Bea-Cukai/repo/rawMaterialReportRepository"
+	"Bea-Cukai/repo/reconciliationRepository"
 	"Bea-Cukai/repo/rejectScrapReportRepository"
+	"Bea-Cukai/repo/reportAccessLogRepository"
+	"Bea-Cukai/repo/stockAlertRepository"
+	"Bea-Cukai/repo/subcontractRepository"
 	"Bea-Cukai/repo/transactionLogRepository"
 	"Bea-Cukai/repo/userLogRepository"
 	"Bea-Cukai/repo/userRepository"
 	"Bea-Cukai/repo/wipPositionReportRepository"
 	"Bea-Cukai/service/auxiliaryMaterialReportService"
+	"Bea-Cukai/service/companyProfileService"
+	"Bea-Cukai/service/continuityCheckService"
 	"Bea-Cukai/service/entryProductService"
 	"Bea-Cukai/service/expenditureProductService"
+	"Bea-Cukai/service/exchangeRateService"
+	"Bea-Cukai/service/exportDocumentService"
+	"Bea-Cukai/service/exportJobService"
 	"Bea-Cukai/service/finishedProductReportService"
 	"Bea-Cukai/service/itemGroupService"
+	"Bea-Cukai/service/lotTraceService"
 	"Bea-Cukai/service/machineToolReportService"
+	"Bea-Cukai/service/pabeanDocumentService"
 	"Bea-Cukai/service/pabeanService"
 	"Bea-Cukai/service/productService"
 	"Bea-Cukai/service/rawMaterialReportService"
+	"Bea-Cukai/service/reconciliationService"
 	"Bea-Cukai/service/rejectScrapReportService"
+	"Bea-Cukai/service/reportAccessLogService"
+	"Bea-Cukai/service/stockAlertService"
+	"Bea-Cukai/service/subcontractService"
 	"Bea-Cukai/service/transactionLogService"
 	"Bea-Cukai/service/userLogService"
 	"Bea-Cukai/service/userService"
@@ -87,6 +123,7 @@ func NewRoute(db *gorm.DB) *gin.Engine {
 	entryProductRepository := entryProductRepository.NewEntryProductRepository(db)
 	expenditureProductRepository := expenditureProductRepository.NewExpenditureProductRepository(db)
 	pabeanRepository := pabeanRepository.NewPabeanRepository(db)
+	pabeanDocumentRepository := pabeanDocumentRepository.NewPabeanDocumentRepository(db)
 	itemGroupRepository := itemGroupRepository.NewItemGroupRepository(db)
 	productRepository := productRepository.NewProductRepository(db)
 	wipPositionReportRepository := wipPositionReportRepository.NewWipPositionReportRepository(db)
@@ -95,6 +132,18 @@ func NewRoute(db *gorm.DB) *gin.Engine {
 	machineToolReportRepository := machineToolReportRepository.NewMachineToolReportRepository(db)
 	rejectScrapReportRepository := rejectScrapReportRepository.NewRejectScrapReportRepository(db)
 	auxiliaryMaterialReportRepository := auxiliaryMaterialReportRepository.NewAuxiliaryMaterialReportRepository(db)
+	stockAlertRepository := stockAlertRepository.NewStockAlertRepository(db)
+	companyProfileRepository := companyProfileRepository.NewCompanyProfileRepository(db)
+	exportJobRepository := exportJobRepository.NewExportJobRepository(db)
+	exportDocumentRepository := exportDocumentRepository.NewExportDocumentRepository(db)
+	reportAccessLogRepository := reportAccessLogRepository.NewReportAccessLogRepository(db)
+	exchangeRateRepository := exchangeRateRepository.NewExchangeRateRepository(db)
+	reconciliationRepository := reconciliationRepository.NewReconciliationRepository(db)
+	lotTraceRepository := lotTraceRepository.NewLotTraceRepository(db)
+	subcontractRepository := subcontractRepository.NewSubcontractRepository(db)
+
+	// Report cache (REPORT_CACHE_STORE=memory|file|off)
+	reportCache := reportCache.NewFromEnv()
 
 	// Services
 	userService := userService.NewUserService(userRepository, userLogRepository)
@@ -103,31 +152,68 @@ func NewRoute(db *gorm.DB) *gin.Engine {
 	entryProductService := entryProductService.NewEntryProductService(entryProductRepository)
 	expenditureProductService := expenditureProductService.NewExpenditureProductService(expenditureProductRepository)
 	pabeanService := pabeanService.NewPabeanService(pabeanRepository)
+	pabeanDocumentService := pabeanDocumentService.NewPabeanDocumentService(pabeanDocumentRepository)
 	itemGroupService := itemGroupService.NewItemGroupService(itemGroupRepository)
 	productService := productService.NewProductService(productRepository)
 	wipPositionReportService := wipPositionReportService.NewWipPositionReportService(wipPositionReportRepository)
-	rawMaterialReportService := rawMaterialReportService.NewRawMaterialReportService(rawMaterialReportRepository)
-	finishedProductReportService := finishedProductReportService.NewFinishedProductReportService(finishedProductReportRepository)
+	rawMaterialReportService := rawMaterialReportService.NewRawMaterialReportService(rawMaterialReportRepository, reportCache)
+	finishedProductReportService := finishedProductReportService.NewFinishedProductReportService(finishedProductReportRepository, reportCache)
 	machineToolReportService := machineToolReportService.NewMachineToolReportService(machineToolReportRepository)
 	rejectScrapReportService := rejectScrapReportService.NewRejectScrapReportService(rejectScrapReportRepository)
 	auxiliaryMaterialReportService := auxiliaryMaterialReportService.NewAuxiliaryMaterialReportService(auxiliaryMaterialReportRepository)
+	continuityCheckService := continuityCheckService.NewContinuityCheckService(rawMaterialReportRepository, finishedProductReportRepository)
+	companyProfileService := companyProfileService.NewCompanyProfileService(companyProfileRepository)
+	exportJobService := exportJobService.NewExportJobService(exportJobRepository)
+	exportDocumentService := exportDocumentService.NewExportDocumentService(exportDocumentRepository)
+	reportAccessLogService := reportAccessLogService.NewReportAccessLogService(reportAccessLogRepository)
+	exchangeRateService := exchangeRateService.NewExchangeRateService(exchangeRateRepository)
+	reconciliationService := reconciliationService.NewReconciliationService(reconciliationRepository)
+	lotTraceService := lotTraceService.NewLotTraceService(lotTraceRepository)
+	subcontractService := subcontractService.NewSubcontractService(subcontractRepository)
+	stockAlertService := stockAlertService.NewStockAlertService(stockAlertRepository, rawMaterialReportRepository, finishedProductReportRepository, machineToolReportRepository, rejectScrapReportRepository)
 
 	// Controllers
 	userController := userController.NewUserController(userService)
 	userLogController := userLogController.NewUserLogController(userLogService)
 	transactionLogController := transactionLogController.NewTransactionLogController(transactionLogService)
-	entryProductController := entryProductController.NewEntryProductController(entryProductService)
-	expenditureProductController := expenditureProductController.NewExpenditureProductController(expenditureProductService)
+	entryProductController := entryProductController.NewEntryProductController(entryProductService, companyProfileService)
+	expenditureProductController := expenditureProductController.NewExpenditureProductController(expenditureProductService, companyProfileService)
 	pabeanController := pabeanController.NewPabeanController(pabeanService)
+	pabeanDocumentController := pabeanDocumentController.NewPabeanDocumentController(pabeanDocumentService, companyProfileService)
 	itemGroupController := itemGroupController.NewItemGroupController(itemGroupService)
 	productController := productController.NewProductController(productService)
-	wipPositionReportController := wipPositionReportController.NewWipPositionReportController(wipPositionReportService)
-	rawMaterialReportController := rawMaterialReportController.NewRawMaterialReportController(rawMaterialReportService)
-	finishedProductReportController := finishedProductReportController.NewFinishedProductReportController(finishedProductReportService)
-	machineToolReportController := machineToolReportController.NewMachineToolReportController(machineToolReportService)
-	rejectScrapReportController := rejectScrapReportController.NewRejectScrapReportController(rejectScrapReportService)
-	auxiliaryMaterialReportController := auxiliaryMaterialReportController.NewAuxiliaryMaterialReportController(auxiliaryMaterialReportService)
-	syncController := syncController.NewSyncController()
+	wipPositionReportController := wipPositionReportController.NewWipPositionReportController(wipPositionReportService, companyProfileService)
+	rawMaterialReportController := rawMaterialReportController.NewRawMaterialReportController(rawMaterialReportService, companyProfileService)
+	finishedProductReportController := finishedProductReportController.NewFinishedProductReportController(finishedProductReportService, companyProfileService)
+	machineToolReportController := machineToolReportController.NewMachineToolReportController(machineToolReportService, companyProfileService)
+	rejectScrapReportController := rejectScrapReportController.NewRejectScrapReportController(rejectScrapReportService, companyProfileService)
+	auxiliaryMaterialReportController := auxiliaryMaterialReportController.NewAuxiliaryMaterialReportController(auxiliaryMaterialReportService, companyProfileService)
+	continuityCheckController := continuityCheckController.NewContinuityCheckController(continuityCheckService)
+	stockAlertController := stockAlertController.NewStockAlertController(stockAlertService)
+	syncController := syncController.NewSyncController(stockAlertService, reportCache)
+	reportCacheController := reportCacheController.NewReportCacheController(reportCache)
+	companyProfileController := companyProfileController.NewCompanyProfileController(companyProfileService)
+	exportJobController := exportJobController.NewExportJobController(exportJobService)
+	exportDocumentController := exportDocumentController.NewExportDocumentController(exportDocumentService)
+	reportAccessLogController := reportAccessLogController.NewReportAccessLogController(reportAccessLogService, companyProfileService)
+	exchangeRateController := exchangeRateController.NewExchangeRateController(exchangeRateService)
+	reconciliationController := reconciliationController.NewReconciliationController(reconciliationService, companyProfileService)
+	lotTraceController := lotTraceController.NewLotTraceController(lotTraceService, companyProfileService)
+	subcontractController := subcontractController.NewSubcontractController(subcontractService, companyProfileService)
+	lpjBundleController := lpjBundleController.NewLpjBundleController(
+		entryProductController,
+		expenditureProductController,
+		rawMaterialReportController,
+		finishedProductReportController,
+		wipPositionReportController,
+		machineToolReportController,
+		rejectScrapReportController,
+		auxiliaryMaterialReportController,
+		companyProfileService,
+	)
+
+	// Semua file export diberi Document ID dan SHA-256-nya dicatat (POST /exports/verify)
+	reportExport.SetRegistrar(exportDocumentService)
 
 	app := gin.Default()
 
@@ -139,6 +225,9 @@ func NewRoute(db *gorm.DB) *gin.Engine {
 	// 2) Global preflight OK (aman walau cors middleware sudah handle)
 	app.OPTIONS("/*any", func(c *gin.Context) { c.Status(204) })
 
+	// 3) Log akses laporan: semua GET /report/*, /auxiliary-material, /pabean-documents dan .../export
+	app.Use(middleware.ReportAudit(reportAccessLogService))
+
 	/* API Routes */
 	// auth Routes
 	auth := app.Group("/auth")
@@ -179,61 +268,128 @@ func NewRoute(db *gorm.DB) *gin.Engine {
 	}
 
 	// Report: EntryProduct analytics
-	reportEntryProduct := app.Group("/report/entry-products")
+	reportEntryProduct := app.Group("/report/entry-products", middleware.ReportTimeout("entry-products"))
 	{
 		reportEntryProduct.GET("", entryProductController.GetReport)
+		reportEntryProduct.GET("/summary", entryProductController.GetSummary)
 		reportEntryProduct.GET("/export", entryProductController.ExportExcel)
 	}
 
 	// Report: ExpenditureProduct analytics
-	reportExpenditureProduct := app.Group("/report/expenditure-products")
+	reportExpenditureProduct := app.Group("/report/expenditure-products", middleware.ReportTimeout("expenditure-products"))
 	{
 		reportExpenditureProduct.GET("", expenditureProductController.GetReport)
+		reportExpenditureProduct.GET("/summary", expenditureProductController.GetSummary)
 		reportExpenditureProduct.GET("/export", expenditureProductController.ExportExcel)
 	}
 
 	// Report: WIP Position
-	reportWipPosition := app.Group("/report/wip-position")
+	reportWipPosition := app.Group("/report/wip-position", middleware.ReportTimeout("wip-position"))
 	{
 		reportWipPosition.GET("", wipPositionReportController.GetReport)
 		reportWipPosition.GET("/export", wipPositionReportController.ExportExcel)
 	}
 
 	// Report: Raw Material
-	reportRawMaterial := app.Group("/report/raw-material")
+	reportRawMaterial := app.Group("/report/raw-material", middleware.ReportTimeout("raw-material"))
 	{
 		reportRawMaterial.GET("", rawMaterialReportController.GetReport)
 		reportRawMaterial.GET("/export", rawMaterialReportController.ExportExcel)
 	}
 
 	// Report: Finished Product
-	reportFinishedProduct := app.Group("/report/finished-product")
+	reportFinishedProduct := app.Group("/report/finished-product", middleware.ReportTimeout("finished-product"))
 	{
 		reportFinishedProduct.GET("", finishedProductReportController.GetReport)
 		reportFinishedProduct.GET("/export", finishedProductReportController.ExportExcel)
 	}
 
 	// Report: Machine and Tool
-	reportMachineTool := app.Group("/report/machine-tool")
+	reportMachineTool := app.Group("/report/machine-tool", middleware.ReportTimeout("machine-tool"))
 	{
 		reportMachineTool.GET("", machineToolReportController.GetReport)
 		reportMachineTool.GET("/export", machineToolReportController.ExportExcel)
 	}
 
 	// Report: Reject and Scrap
-	reportRejectScrap := app.Group("/report/reject-scrap-product")
+	reportRejectScrap := app.Group("/report/reject-scrap-product", middleware.ReportTimeout("reject-scrap-product"))
 	{
 		reportRejectScrap.GET("", rejectScrapReportController.GetReport)
 		reportRejectScrap.GET("/export", rejectScrapReportController.ExportExcel)
 	}
 
 	// Report: Auxiliary Material
-	reportAuxiliaryMaterial := app.Group("/auxiliary-material")
+	reportAuxiliaryMaterial := app.Group("/auxiliary-material", middleware.ReportTimeout("auxiliary-material"))
 	{
 		reportAuxiliaryMaterial.GET("", auxiliaryMaterialReportController.GetReport)
 		reportAuxiliaryMaterial.GET("/export", auxiliaryMaterialReportController.ExportExcel)
 	}
 
+	// Report: Bundel semua LPJ satu periode (cover + satu sheet per laporan, atau ZIP CSV)
+	reportLpjBundle := app.Group("/report/lpj-bundle", middleware.ReportTimeout("lpj-bundle"))
+	{
+		reportLpjBundle.GET("/export", lpjBundleController.Export)
+	}
+
+	// Report: Opening-balance continuity check (akhir N vs awal N+1)
+	reportContinuity := app.Group("/report/continuity-check", middleware.ReportTimeout("continuity-check"))
+	{
+		reportContinuity.GET("", continuityCheckController.Check)
+	}
+
+	// Report: Rekonsiliasi dokumen pabean dengan mutasi gudang
+	reportReconciliation := app.Group("/report/reconciliation", middleware.ReportTimeout("reconciliation"))
+	{
+		reportReconciliation.GET("/inbound", reconciliationController.GetInbound)
+		reportReconciliation.GET("/inbound/export", reconciliationController.ExportInbound)
+		reportReconciliation.GET("/outbound", reconciliationController.GetOutbound)
+		reportReconciliation.GET("/outbound/export", reconciliationController.ExportOutbound)
+	}
+
+	// Report: Telusur lot bahan baku (dokumen pemasukan -> penerimaan -> pemakaian)
+	reportTraceability := app.Group("/report/traceability", middleware.ReportTimeout("traceability"))
+	{
+		reportTraceability.GET("/lots", lotTraceController.GetLots)
+		reportTraceability.GET("/origin", lotTraceController.GetOrigin)
+		reportTraceability.GET("/lot-balance", lotTraceController.GetLotBalance)
+		reportTraceability.GET("/lot-balance/export", lotTraceController.ExportLotBalance)
+		reportTraceability.GET("/aging", lotTraceController.GetAging)
+		reportTraceability.GET("/aging/export", lotTraceController.ExportAging)
+		reportTraceability.GET("/aging/documents", lotTraceController.GetAgingDocuments)
+		reportTraceability.GET("/aging/documents/export", lotTraceController.ExportAgingDocuments)
+	}
+
+	// Report: Saldo barang subkontrak (BC 2.6.1 keluar -> BC 2.6.2 kembali)
+	reportSubcontract := app.Group("/report/subcontract", middleware.ReportTimeout("subcontract"))
+	{
+		reportSubcontract.GET("", subcontractController.GetOutstanding)
+		reportSubcontract.GET("/export", subcontractController.ExportOutstanding)
+	}
+
+	// Pabean documents: satu dokumen (header + baris + total) pemasukan / pengeluaran
+	pabeanDocuments := app.Group("/pabean-documents", middleware.ReportTimeout("pabean-documents"))
+	{
+		pabeanDocuments.GET("", pabeanDocumentController.GetList)
+		pabeanDocuments.GET("/export", pabeanDocumentController.ExportExcel)
+		pabeanDocuments.GET("/:jenis/:no", pabeanDocumentController.GetDocument)
+	}
+
+	// Alerts: Negative-stock and variance alerts
+	alerts := app.Group("/alerts")
+	{
+		alerts.Use(middleware.Authentication())
+		{
+			alerts.GET("", stockAlertController.GetAll)
+			alerts.POST("/evaluate", stockAlertController.Evaluate)
+			alerts.PUT("/:id/acknowledge", stockAlertController.Acknowledge)
+			alerts.PUT("/:id/resolve", stockAlertController.Resolve)
+			alerts.GET("/rules", stockAlertController.GetRules)
+			alerts.POST("/rules", stockAlertController.CreateRule)
+			alerts.PUT("/rules/:id", stockAlertController.UpdateRule)
+			alerts.DELETE("/rules/:id", stockAlertController.DeleteRule)
+		}
+	}
+
 	// Pabean: Master pabean document
 	pabean := app.Group("/pabean")
 	{
@@ -274,5 +430,45 @@ func NewRoute(db *gorm.DB) *gin.Engine {
 		}
 	}
 
+	// Exports: Background export jobs; download via signed URL (tanpa JWT); verifikasi file export
+	exports := app.Group("/exports")
+	{
+		exports.GET("/:id/download", exportJobController.Download)
+
+		exports.Use(middleware.Authentication())
+		{
+			exports.POST("", exportJobController.Create)
+			exports.POST("/verify", exportDocumentController.Verify)
+			exports.GET("", exportJobController.GetMine)
+			exports.GET("/:id", exportJobController.GetById)
+		}
+	}
+
+	// Admin: Report cache (stats & purge), company profile (kop export), export job audit, log akses laporan, kurs
+	admin := app.Group("/admin")
+	{
+		admin.Use(middleware.Authentication(), middleware.Authorization(userRepository, middleware.AdminLevel))
+		{
+			admin.GET("/report-cache", reportCacheController.GetStats)
+			admin.DELETE("/report-cache", reportCacheController.Purge)
+			admin.GET("/company-profile", companyProfileController.Get)
+			admin.PUT("/company-profile", companyProfileController.Update)
+			admin.GET("/exports", exportJobController.GetAll)
+			admin.GET("/report-access-logs", reportAccessLogController.GetAll)
+			admin.GET("/report-access-logs/export", reportAccessLogController.ExportExcel)
+			admin.GET("/exchange-rates", exchangeRateController.GetAll)
+			admin.GET("/exchange-rates/:id", exchangeRateController.GetById)
+			admin.POST("/exchange-rates", exchangeRateController.Create)
+			admin.POST("/exchange-rates/import", exchangeRateController.Import)
+			admin.PUT("/exchange-rates/:id", exchangeRateController.Update)
+			admin.DELETE("/exchange-rates/:id", exchangeRateController.Delete)
+			admin.POST("/pabean", pabeanController.Create)
+			admin.PUT("/pabean/:id", pabeanController.Update)
+		}
+	}
+
+	// Worker export job menjalankan endpoint export di atas lewat router ini
+	exportJobService.Start(app)
+
 	return app
 }
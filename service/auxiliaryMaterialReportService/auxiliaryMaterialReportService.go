@@ -22,8 +22,6 @@ func NewAuxiliaryMaterialReportService(repo *auxiliaryMaterialReportRepository.A
 // ==========================
 
 // GetReport retrieves auxiliary material report with filters and pagination
-func (s *AuxiliaryMaterialReportService) GetReport(filter auxiliaryMaterialReportRepository.GetReportFilter) ([]model.AuxiliaryMaterialReportResponse, int64, error) {
-	ctx := context.Background()
-	
+func (s *AuxiliaryMaterialReportService) GetReport(ctx context.Context, filter auxiliaryMaterialReportRepository.GetReportFilter) ([]model.AuxiliaryMaterialReportResponse, int64, error) {
 	return s.repo.GetReport(ctx, filter)
 }
@@ -0,0 +1,35 @@
+package companyProfileService
+
+import (
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/companyProfileRepository"
+	"log"
+)
+
+// CompanyProfileService menyediakan profil perusahaan untuk kop dan tanda tangan export.
+
+type CompanyProfileService struct {
+	repo *companyProfileRepository.CompanyProfileRepository
+}
+
+func NewCompanyProfileService(repo *companyProfileRepository.CompanyProfileRepository) *CompanyProfileService {
+	return &CompanyProfileService{repo: repo}
+}
+
+func (s *CompanyProfileService) Get() (model.CompanyProfile, error) {
+	return s.repo.Get()
+}
+
+// GetForExport tidak pernah gagal: bila profil tidak bisa dibaca, export tetap jalan dengan profil default.
+func (s *CompanyProfileService) GetForExport() model.CompanyProfile {
+	profile, err := s.repo.Get()
+	if err != nil {
+		log.Printf("fail to get company profile, using default: %v", err)
+		return model.DefaultCompanyProfile()
+	}
+	return profile
+}
+
+func (s *CompanyProfileService) Update(req model.CompanyProfileRequest, username string) (model.CompanyProfile, error) {
+	return s.repo.Save(req, username)
+}
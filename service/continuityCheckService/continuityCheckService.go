@@ -0,0 +1,191 @@
+package continuityCheckService
+
+import (
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/finishedProductReportRepository"
+	"Bea-Cukai/repo/rawMaterialReportRepository"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ContinuityCheckService memvalidasi bahwa saldo akhir suatu periode sama dengan
+// saldo awal periode berikutnya untuk laporan yang menurunkan saldo awal dari opname.
+
+type ContinuityCheckService struct {
+	rawMaterialRepo     *rawMaterialReportRepository.RawMaterialReportRepository
+	finishedProductRepo *finishedProductReportRepository.FinishedProductReportRepository
+}
+
+func NewContinuityCheckService(
+	rawMaterialRepo *rawMaterialReportRepository.RawMaterialReportRepository,
+	finishedProductRepo *finishedProductReportRepository.FinishedProductReportRepository,
+) *ContinuityCheckService {
+	return &ContinuityCheckService{
+		rawMaterialRepo:     rawMaterialRepo,
+		finishedProductRepo: finishedProductRepo,
+	}
+}
+
+// periodSnapshot adalah hasil satu bulan: saldo per item + tanggal opname dasar saldo awal.
+type periodSnapshot struct {
+	From       time.Time
+	To         time.Time
+	OpnameDate time.Time
+	Balances   map[string]model.PeriodBalance
+}
+
+// ==========================
+// Business Operations
+// ==========================
+
+// CheckContinuity menelusuri bulan-bulan berurutan dan mengembalikan setiap item
+// dimana akhir(N) ≠ awal(N+1).
+func (s *ContinuityCheckService) CheckContinuity(ctx context.Context, req model.ContinuityCheckRequest) (model.ContinuityCheckResponse, error) {
+	months := monthStarts(req.FromMonth, req.ToMonth)
+	if len(months) < 2 {
+		return model.ContinuityCheckResponse{}, fmt.Errorf("at least two months are required to check continuity")
+	}
+
+	snapshots := make([]periodSnapshot, 0, len(months))
+	for _, start := range months {
+		end := start.AddDate(0, 1, -1)
+		snap, err := s.getSnapshot(ctx, req, start, end)
+		if err != nil {
+			return model.ContinuityCheckResponse{}, fmt.Errorf("period %s: %w", start.Format("2006-01"), err)
+		}
+		snapshots = append(snapshots, snap)
+	}
+
+	breaks := []model.ContinuityBreak{}
+	for i := 0; i+1 < len(snapshots); i++ {
+		breaks = append(breaks, compareSnapshots(snapshots[i], snapshots[i+1])...)
+	}
+
+	return model.ContinuityCheckResponse{
+		ReportType:     req.ReportType,
+		From:           months[0].Format("2006-01"),
+		To:             months[len(months)-1].Format("2006-01"),
+		PeriodsChecked: len(snapshots),
+		BreakCount:     len(breaks),
+		Breaks:         breaks,
+	}, nil
+}
+
+func (s *ContinuityCheckService) getSnapshot(ctx context.Context, req model.ContinuityCheckRequest, from, to time.Time) (periodSnapshot, error) {
+	snap := periodSnapshot{From: from, To: to, Balances: map[string]model.PeriodBalance{}}
+
+	switch req.ReportType {
+	case model.ContinuityReportRawMaterial:
+		rows, opnameDate, err := s.rawMaterialRepo.GetPeriodBalances(ctx, rawMaterialReportRepository.GetReportFilter{
+			From:     from,
+			To:       to,
+			ItemCode: req.ItemCode,
+			ItemName: req.ItemName,
+		})
+		if err != nil {
+			return snap, err
+		}
+		snap.OpnameDate = opnameDate
+		for _, row := range rows {
+			snap.Balances[row.ItemCode] = model.PeriodBalance{
+				ItemCode: row.ItemCode, ItemName: row.ItemName, UnitCode: row.UnitCode,
+				Awal: row.Awal, Akhir: row.Akhir,
+			}
+		}
+	case model.ContinuityReportFinishedProduct:
+		rows, opnameDate, err := s.finishedProductRepo.GetPeriodBalances(ctx, finishedProductReportRepository.GetReportFilter{
+			From:     from,
+			To:       to,
+			ItemCode: req.ItemCode,
+			ItemName: req.ItemName,
+		})
+		if err != nil {
+			return snap, err
+		}
+		snap.OpnameDate = opnameDate
+		for _, row := range rows {
+			snap.Balances[row.ItemCode] = model.PeriodBalance{
+				ItemCode: row.ItemCode, ItemName: row.ItemName, UnitCode: row.UnitCode,
+				Awal: row.Awal, Akhir: row.Akhir,
+			}
+		}
+	default:
+		return snap, fmt.Errorf("unsupported report type %q", req.ReportType)
+	}
+
+	return snap, nil
+}
+
+// compareSnapshots membandingkan akhir periode N dengan awal periode N+1.
+// Item yang hanya muncul di salah satu sisi dianggap bersaldo 0 di sisi lainnya.
+func compareSnapshots(cur, next periodSnapshot) []model.ContinuityBreak {
+	codes := make(map[string]struct{}, len(cur.Balances)+len(next.Balances))
+	for code := range cur.Balances {
+		codes[code] = struct{}{}
+	}
+	for code := range next.Balances {
+		codes[code] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(codes))
+	for code := range codes {
+		sorted = append(sorted, code)
+	}
+	sort.Strings(sorted)
+
+	breaks := []model.ContinuityBreak{}
+	for _, code := range sorted {
+		a, okA := cur.Balances[code]
+		b, okB := next.Balances[code]
+
+		akhir := decimal.Zero
+		if okA {
+			akhir = a.Akhir
+		}
+		nextAwal := decimal.Zero
+		if okB {
+			nextAwal = b.Awal
+		}
+		if akhir.Equal(nextAwal) {
+			continue
+		}
+
+		info := a
+		if !okA {
+			info = b
+		}
+		breaks = append(breaks, model.ContinuityBreak{
+			ItemCode:       code,
+			ItemName:       info.ItemName,
+			UnitCode:       info.UnitCode,
+			Period:         cur.From.Format("2006-01"),
+			PeriodFrom:     cur.From.Format("2006-01-02"),
+			PeriodTo:       cur.To.Format("2006-01-02"),
+			OpnameDate:     cur.OpnameDate.Format("2006-01-02"),
+			Akhir:          akhir,
+			NextPeriod:     next.From.Format("2006-01"),
+			NextPeriodFrom: next.From.Format("2006-01-02"),
+			NextPeriodTo:   next.To.Format("2006-01-02"),
+			NextOpnameDate: next.OpnameDate.Format("2006-01-02"),
+			NextAwal:       nextAwal,
+			Selisih:        nextAwal.Sub(akhir),
+		})
+	}
+	return breaks
+}
+
+// monthStarts mengembalikan hari pertama setiap bulan dari bulan `from` s.d. bulan `to` (inklusif).
+func monthStarts(from, to time.Time) []time.Time {
+	start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	months := []time.Time{}
+	for m := start; !m.After(end); m = m.AddDate(0, 1, 0) {
+		months = append(months, m)
+	}
+	return months
+}
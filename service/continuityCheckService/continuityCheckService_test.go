@@ -0,0 +1,104 @@
+package continuityCheckService
+
+import (
+	"Bea-Cukai/model"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func d(v int64) decimal.Decimal { return decimal.NewFromInt(v) }
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestMonthStarts(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     []string
+	}{
+		{"satu bulan", "2026-03-01", "2026-03-31", []string{"2026-03-01"}},
+		{"tanggal tengah bulan dibulatkan ke awal bulan", "2026-01-15", "2026-03-10", []string{"2026-01-01", "2026-02-01", "2026-03-01"}},
+		{"lintas tahun", "2025-11-01", "2026-02-01", []string{"2025-11-01", "2025-12-01", "2026-01-01", "2026-02-01"}},
+		{"to sebelum from", "2026-05-01", "2026-04-01", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := monthStarts(date(tc.from), date(tc.to))
+			if len(got) != len(tc.want) {
+				t.Fatalf("len = %d; want %d (%v)", len(got), len(tc.want), got)
+			}
+			for i, w := range tc.want {
+				if got[i].Format("2006-01-02") != w {
+					t.Errorf("[%d] = %s; want %s", i, got[i].Format("2006-01-02"), w)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	cur := periodSnapshot{
+		From: date("2026-01-01"), To: date("2026-01-31"), OpnameDate: date("2025-12-31"),
+		Balances: map[string]model.PeriodBalance{
+			"A": {ItemCode: "A", ItemName: "Item A", Awal: d(5), Akhir: d(10)}, // sama dengan awal berikutnya
+			"B": {ItemCode: "B", ItemName: "Item B", Awal: d(0), Akhir: d(7)},  // awal berikutnya 4
+			"C": {ItemCode: "C", ItemName: "Item C", Awal: d(3), Akhir: d(3)},  // tidak ada di periode berikutnya
+			"Z": {ItemCode: "Z", ItemName: "Item Z", Awal: d(1), Akhir: d(0)},  // akhir 0, tidak ada berikutnya
+		},
+	}
+	next := periodSnapshot{
+		From: date("2026-02-01"), To: date("2026-02-28"), OpnameDate: date("2026-01-31"),
+		Balances: map[string]model.PeriodBalance{
+			"A": {ItemCode: "A", Awal: d(10), Akhir: d(12)},
+			"B": {ItemCode: "B", Awal: d(4), Akhir: d(4)},
+			"D": {ItemCode: "D", ItemName: "Item D", Awal: d(2), Akhir: d(2)}, // muncul baru dengan saldo awal
+		},
+	}
+
+	breaks := compareSnapshots(cur, next)
+	if len(breaks) != 3 {
+		t.Fatalf("len(breaks) = %d; want 3 (%+v)", len(breaks), breaks)
+	}
+
+	want := []struct {
+		code           string
+		name           string
+		akhir, awal, s int64
+	}{
+		{"B", "Item B", 7, 4, -3},
+		{"C", "Item C", 3, 0, -3},
+		{"D", "Item D", 0, 2, 2},
+	}
+	for i, w := range want {
+		b := breaks[i]
+		if b.ItemCode != w.code || b.ItemName != w.name {
+			t.Errorf("[%d] item = %s/%s; want %s/%s", i, b.ItemCode, b.ItemName, w.code, w.name)
+		}
+		if !b.Akhir.Equal(d(w.akhir)) || !b.NextAwal.Equal(d(w.awal)) || !b.Selisih.Equal(d(w.s)) {
+			t.Errorf("[%d] %s akhir/next_awal/selisih = %s/%s/%s; want %d/%d/%d",
+				i, w.code, b.Akhir, b.NextAwal, b.Selisih, w.akhir, w.awal, w.s)
+		}
+	}
+
+	b := breaks[0]
+	if b.Period != "2026-01" || b.NextPeriod != "2026-02" || b.OpnameDate != "2025-12-31" || b.NextOpnameDate != "2026-01-31" {
+		t.Errorf("period info = %+v", b)
+	}
+}
+
+func TestCompareSnapshots_NoBreaks(t *testing.T) {
+	cur := periodSnapshot{Balances: map[string]model.PeriodBalance{"A": {Akhir: decimal.RequireFromString("1.50")}}}
+	next := periodSnapshot{Balances: map[string]model.PeriodBalance{"A": {Awal: decimal.RequireFromString("1.5")}}}
+
+	if breaks := compareSnapshots(cur, next); len(breaks) != 0 {
+		t.Errorf("breaks = %+v; want none", breaks)
+	}
+}
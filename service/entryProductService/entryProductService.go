@@ -22,7 +22,29 @@ func NewEntryProductService(entryProductRepo *entryProductRepository.EntryProduc
 // ==========================
 
 // GetReport retrieves entry products with filters and pagination
-func (s *EntryProductService) GetReport(filter entryProductRepository.GetReportFilter) ([]model.EntryProduct, int64, error) {
-	ctx := context.Background()
+func (s *EntryProductService) GetReport(ctx context.Context, filter entryProductRepository.GetReportFilter) ([]model.EntryProduct, int64, error) {
 	return s.entryProductRepo.GetReport(ctx, filter)
 }
+
+// StreamReport streams all rows matching filter to fn (export); see repository StreamReport
+func (s *EntryProductService) StreamReport(ctx context.Context, filter entryProductRepository.GetReportFilter, fn func(model.EntryProduct) error) error {
+	return s.entryProductRepo.StreamReport(ctx, filter, fn)
+}
+
+// GetSummary groups entry products matching filter by groupBy (document/line counts, quantity per unit,
+// amount per currency) plus the overall total; see repository GetSummary
+func (s *EntryProductService) GetSummary(ctx context.Context, filter entryProductRepository.GetReportFilter, groupBy string, limit int) ([]model.CustomsSummary, model.CustomsSummary, error) {
+	return s.entryProductRepo.GetSummary(ctx, filter, groupBy, limit)
+}
+
+// GetTotals sums net_amount per currency plus its IDR conversion for all entry products matching filter;
+// see repository GetTotals
+func (s *EntryProductService) GetTotals(ctx context.Context, filter entryProductRepository.GetReportFilter) ([]model.CurrencyTotal, error) {
+	return s.entryProductRepo.GetTotals(ctx, filter)
+}
+
+// GetPabeanValidation counts entry products matching filter whose jenis_pabean is unknown in ms_pabean or
+// has the wrong direction; see repository GetPabeanValidation
+func (s *EntryProductService) GetPabeanValidation(ctx context.Context, filter entryProductRepository.GetReportFilter) ([]model.PabeanValidationCount, error) {
+	return s.entryProductRepo.GetPabeanValidation(ctx, filter)
+}
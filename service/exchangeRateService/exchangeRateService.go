@@ -0,0 +1,137 @@
+package exchangeRateService
+
+import (
+	"Bea-Cukai/helper/exchangeRate"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/exchangeRateRepository"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ExchangeRateService mengelola tabel kurs (mis. NDPBM mingguan) yang dipakai laporan
+// pemasukan / pengeluaran untuk mengonversi nilai ke IDR.
+
+var ErrBaseCurrency = fmt.Errorf("%s is the base currency and needs no exchange rate", model.BaseCurrency)
+
+type ExchangeRateService struct {
+	repo *exchangeRateRepository.ExchangeRateRepository
+}
+
+func NewExchangeRateService(repo *exchangeRateRepository.ExchangeRateRepository) *ExchangeRateService {
+	return &ExchangeRateService{repo: repo}
+}
+
+// ==========================
+// Business Operations
+// ==========================
+
+func (s *ExchangeRateService) GetAll(req model.ExchangeRateListRequest) ([]model.ExchangeRate, int64, error) {
+	req.CurrCode = strings.ToUpper(strings.TrimSpace(req.CurrCode))
+	return s.repo.GetAll(req)
+}
+
+func (s *ExchangeRateService) GetById(id int) (model.ExchangeRate, error) {
+	return s.repo.GetById(id)
+}
+
+func (s *ExchangeRateService) Create(req model.ExchangeRateRequest, username string) (model.ExchangeRate, error) {
+	rate, err := toModel(req)
+	if err != nil {
+		return model.ExchangeRate{}, err
+	}
+	rate.CreatedBy, rate.UpdatedBy = username, username
+	return s.repo.Create(rate)
+}
+
+func (s *ExchangeRateService) Update(id int, req model.ExchangeRateRequest, username string) (model.ExchangeRate, error) {
+	rate, err := toModel(req)
+	if err != nil {
+		return model.ExchangeRate{}, err
+	}
+	rate.UpdatedBy = username
+	return s.repo.Update(id, rate)
+}
+
+func (s *ExchangeRateService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+// ImportCSV membaca CSV kurs (lihat exchangeRate.ParseCSV) dan menyimpannya (upsert per
+// curr_code + valid_from). Semua baris divalidasi lebih dulu: bila ada baris yang salah tidak
+// ada yang disimpan dan result.Errors berisi baris-baris tersebut.
+func (s *ExchangeRateService) ImportCSV(r io.Reader, username string) (model.ExchangeRateImportResult, error) {
+	result := model.ExchangeRateImportResult{Errors: []model.ExchangeRateImportError{}}
+
+	rows, rowErrs, err := exchangeRate.ParseCSV(r)
+	if err != nil {
+		return result, err
+	}
+	result.Errors = append(result.Errors, rowErrs...)
+
+	rates := make([]model.ExchangeRate, 0, len(rows))
+	seen := map[string]int{}
+	for _, row := range rows {
+		rate, err := toModel(row.Request)
+		if err != nil {
+			result.Errors = append(result.Errors, model.ExchangeRateImportError{Line: row.Line, Error: err.Error()})
+			continue
+		}
+		key := rate.CurrCode + "|" + rate.ValidFrom.Format("2006-01-02")
+		if line, ok := seen[key]; ok {
+			result.Errors = append(result.Errors, model.ExchangeRateImportError{
+				Line:  row.Line,
+				Error: fmt.Sprintf("duplicate %s rate for %s (already on line %d)", rate.CurrCode, rate.ValidFrom.Format("2006-01-02"), line),
+			})
+			continue
+		}
+		seen[key] = row.Line
+		rate.CreatedBy, rate.UpdatedBy = username, username
+		rates = append(rates, rate)
+	}
+
+	if len(result.Errors) > 0 || len(rates) == 0 {
+		return result, nil
+	}
+	result.Created, result.Updated, err = s.repo.Upsert(rates)
+	return result, err
+}
+
+// toModel memvalidasi request dan mengubahnya menjadi model (curr_code huruf besar).
+func toModel(req model.ExchangeRateRequest) (model.ExchangeRate, error) {
+	curr := strings.ToUpper(strings.TrimSpace(req.CurrCode))
+	if len(curr) != 3 {
+		return model.ExchangeRate{}, fmt.Errorf("invalid curr_code %q (use ISO 4217, e.g. USD)", req.CurrCode)
+	}
+	if curr == model.BaseCurrency {
+		return model.ExchangeRate{}, ErrBaseCurrency
+	}
+	if !req.Rate.IsPositive() {
+		return model.ExchangeRate{}, errors.New("rate must be greater than 0")
+	}
+
+	validFrom, err := time.Parse("2006-01-02", strings.TrimSpace(req.ValidFrom))
+	if err != nil {
+		return model.ExchangeRate{}, fmt.Errorf("invalid valid_from %q (use YYYY-MM-DD)", req.ValidFrom)
+	}
+	rate := model.ExchangeRate{
+		CurrCode:  curr,
+		Rate:      req.Rate,
+		ValidFrom: validFrom,
+		Source:    strings.TrimSpace(req.Source),
+		Notes:     strings.TrimSpace(req.Notes),
+	}
+	if v := strings.TrimSpace(req.ValidTo); v != "" {
+		validTo, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return model.ExchangeRate{}, fmt.Errorf("invalid valid_to %q (use YYYY-MM-DD)", req.ValidTo)
+		}
+		if validTo.Before(validFrom) {
+			return model.ExchangeRate{}, errors.New("valid_to must not be before valid_from")
+		}
+		rate.ValidTo = &validTo
+	}
+	return rate, nil
+}
@@ -22,7 +22,29 @@ func NewExpenditureProductService(expenditureProductRepo *expenditureProductRepo
 // ==========================
 
 // GetReport retrieves expenditure products with filters and pagination
-func (s *ExpenditureProductService) GetReport(filter expenditureProductRepository.GetReportFilter) ([]model.ExpenditureProduct, int64, error) {
-	ctx := context.Background()
+func (s *ExpenditureProductService) GetReport(ctx context.Context, filter expenditureProductRepository.GetReportFilter) ([]model.ExpenditureProduct, int64, error) {
 	return s.expenditureProductRepo.GetReport(ctx, filter)
 }
+
+// StreamReport streams all rows matching filter to fn (export); see repository StreamReport
+func (s *ExpenditureProductService) StreamReport(ctx context.Context, filter expenditureProductRepository.GetReportFilter, fn func(model.ExpenditureProduct) error) error {
+	return s.expenditureProductRepo.StreamReport(ctx, filter, fn)
+}
+
+// GetSummary groups expenditure products matching filter by groupBy (document/line counts, quantity per unit,
+// amount per currency) plus the overall total; see repository GetSummary
+func (s *ExpenditureProductService) GetSummary(ctx context.Context, filter expenditureProductRepository.GetReportFilter, groupBy string, limit int) ([]model.CustomsSummary, model.CustomsSummary, error) {
+	return s.expenditureProductRepo.GetSummary(ctx, filter, groupBy, limit)
+}
+
+// GetTotals sums net_amount per currency plus its IDR conversion for all expenditure products matching filter;
+// see repository GetTotals
+func (s *ExpenditureProductService) GetTotals(ctx context.Context, filter expenditureProductRepository.GetReportFilter) ([]model.CurrencyTotal, error) {
+	return s.expenditureProductRepo.GetTotals(ctx, filter)
+}
+
+// GetPabeanValidation counts expenditure products matching filter whose jenis_pabean is unknown in ms_pabean or
+// has the wrong direction; see repository GetPabeanValidation
+func (s *ExpenditureProductService) GetPabeanValidation(ctx context.Context, filter expenditureProductRepository.GetReportFilter) ([]model.PabeanValidationCount, error) {
+	return s.expenditureProductRepo.GetPabeanValidation(ctx, filter)
+}
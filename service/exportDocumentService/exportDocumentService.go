@@ -0,0 +1,101 @@
+package exportDocumentService
+
+import (
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/exportDocumentRepository"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ExportDocumentService mencatat setiap file export (Document ID + SHA-256) dan memverifikasi
+// file yang diunggah kembali, mis. file yang diserahkan ke auditor Bea Cukai.
+// Dipasang sebagai reportExport.Registrar sehingga semua endpoint export ikut tercatat.
+type ExportDocumentService struct {
+	repo *exportDocumentRepository.ExportDocumentRepository
+}
+
+func NewExportDocumentService(repo *exportDocumentRepository.ExportDocumentRepository) *ExportDocumentService {
+	return &ExportDocumentService{repo: repo}
+}
+
+// Register mencatat file export beserta user, filter dan IP peminta.
+func (s *ExportDocumentService) Register(ctx *gin.Context, sig reportExport.Signature) error {
+	filters := map[string]string{}
+	for k, v := range ctx.Request.URL.Query() {
+		if k != "format" && len(v) > 0 {
+			filters[k] = strings.Join(v, ",")
+		}
+	}
+
+	doc := model.ExportDocument{
+		Id:         sig.DocumentId,
+		Sha256:     sig.Sha256,
+		FileName:   sig.FileName,
+		FileSize:   sig.Size,
+		Format:     string(sig.Format),
+		ReportPath: ctx.Request.URL.Path,
+		Filters:    filters,
+		IpAddress:  ctx.ClientIP(),
+	}
+
+	r := reportExport.RequesterOf(ctx)
+	doc.RequestedById, doc.RequestedBy, doc.ExportJobId = r.Id, r.Username, r.ExportJobId
+
+	return s.repo.Create(&doc)
+}
+
+// GetById - export document by Document ID
+func (s *ExportDocumentService) GetById(id string) (model.ExportDocument, error) {
+	return s.repo.GetById(id)
+}
+
+// Verify mencocokkan file dengan register export. Document ID diambil dari documentId
+// (dikirim user) atau dari metadata file (xlsx/zip); bila ada dan terdaftar, hash file harus
+// sama dengan hash yang dicatat. Tanpa Document ID (csv/pdf) file dicocokkan lewat hash saja.
+func (s *ExportDocumentService) Verify(file io.ReaderAt, size int64, documentId string) (model.ExportVerifyResponse, error) {
+	hash, _, err := reportExport.HashFile(io.NewSectionReader(file, 0, size))
+	if err != nil {
+		return model.ExportVerifyResponse{}, err
+	}
+
+	res := model.ExportVerifyResponse{
+		Result:     model.ExportVerifyUnknown,
+		Sha256:     hash,
+		FileSize:   size,
+		DocumentId: strings.TrimSpace(documentId),
+	}
+	if res.DocumentId == "" {
+		res.DocumentId = reportExport.DocumentIdOf(file, size)
+	}
+
+	if res.DocumentId != "" {
+		doc, err := s.repo.GetById(res.DocumentId)
+		switch {
+		case err == nil:
+			res.Document = &doc
+			if doc.Sha256 == hash {
+				res.Valid, res.Result = true, model.ExportVerifyMatch
+			} else {
+				res.Result = model.ExportVerifyModified
+			}
+			return res, nil
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return res, err
+		}
+	}
+
+	doc, err := s.repo.GetBySha256(hash)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return res, nil
+	}
+	if err != nil {
+		return res, err
+	}
+	res.Valid, res.Result, res.Document = true, model.ExportVerifyMatch, &doc
+	return res, nil
+}
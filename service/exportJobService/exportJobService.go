@@ -0,0 +1,278 @@
+package exportJobService
+
+import (
+	"Bea-Cukai/helper"
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/helper/signedUrl"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/exportJobRepository"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExportJobService mengantrikan export laporan dan menjalankannya di background.
+// Worker menjalankan ulang endpoint export laporan (GET .../export) secara in-process
+// dengan filter yang disimpan di job, sehingga validasi, kolom dan format file identik
+// dengan export langsung. Hasilnya disimpan di EXPORT_DIR dan diunduh lewat signed URL.
+
+// ReportPaths memetakan report_type ke endpoint export laporan.
+var ReportPaths = map[string]string{
+	"entry-products":          "/report/entry-products/export",
+	"expenditure-products":    "/report/expenditure-products/export",
+	"wip-position":            "/report/wip-position/export",
+	"raw-material":            "/report/raw-material/export",
+	"finished-product":        "/report/finished-product/export",
+	"machine-tool":            "/report/machine-tool/export",
+	"reject-scrap-product":    "/report/reject-scrap-product/export",
+	"auxiliary-material":      "/auxiliary-material/export",
+	"lot-balance":             "/report/traceability/lot-balance/export",
+	"lot-aging":               "/report/traceability/aging/export",
+	"lot-aging-documents":     "/report/traceability/aging/documents/export",
+	"lpj-bundle":              "/report/lpj-bundle/export",
+	"pabean-documents":        "/pabean-documents/export",
+	"reconciliation-inbound":  "/report/reconciliation/inbound/export",
+	"reconciliation-outbound": "/report/reconciliation/outbound/export",
+	"subcontract":             "/report/subcontract/export",
+}
+
+var (
+	ErrUnknownReportType = fmt.Errorf("report_type must be one of: %s", strings.Join(ReportTypes(), ", "))
+	ErrQueueFull         = errors.New("export queue is full, try again later")
+	ErrNotReady          = errors.New("export is not ready for download")
+	ErrFileExpired       = errors.New("export file has expired")
+)
+
+// ReportTypes mengembalikan report_type yang didukung (terurut).
+func ReportTypes() []string {
+	types := make([]string, 0, len(ReportPaths))
+	for t := range ReportPaths {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+type ExportJobService struct {
+	repo *exportJobRepository.ExportJobRepository
+
+	handler    http.Handler
+	queue      chan string
+	startOnce  sync.Once
+	dir        string
+	workers    int
+	jobTimeout time.Duration
+	retention  time.Duration
+	urlTTL     time.Duration
+	cleanupInt time.Duration
+	signingKey []byte
+}
+
+// NewExportJobService membuat service dari environment:
+//
+//	EXPORT_DIR                        direktori file hasil export (default tmp/exports)
+//	EXPORT_WORKERS                    jumlah worker paralel (default 2)
+//	EXPORT_QUEUE_SIZE                 kapasitas antrian (default 100)
+//	EXPORT_JOB_TIMEOUT_MINUTES        batas waktu satu job (default 30)
+//	EXPORT_RETENTION_HOURS            umur file sebelum dihapus (default 24)
+//	EXPORT_URL_TTL_MINUTES            masa berlaku signed download URL (default 60)
+//	EXPORT_CLEANUP_INTERVAL_MINUTES   interval retention cleanup (default 30)
+//	EXPORT_SIGNING_KEY                key HMAC signed URL (default SECRETKEY)
+func NewExportJobService(repo *exportJobRepository.ExportJobRepository) *ExportJobService {
+	dir := helper.GetEnv("EXPORT_DIR")
+	if dir == "" {
+		dir = "tmp/exports"
+	}
+	key := helper.GetEnv("EXPORT_SIGNING_KEY")
+	if key == "" {
+		key = helper.SECRETKEY
+	}
+
+	return &ExportJobService{
+		repo:       repo,
+		queue:      make(chan string, envInt("EXPORT_QUEUE_SIZE", 100)),
+		dir:        dir,
+		workers:    max(envInt("EXPORT_WORKERS", 2), 1),
+		jobTimeout: time.Duration(envInt("EXPORT_JOB_TIMEOUT_MINUTES", 30)) * time.Minute,
+		retention:  time.Duration(envInt("EXPORT_RETENTION_HOURS", 24)) * time.Hour,
+		urlTTL:     time.Duration(envInt("EXPORT_URL_TTL_MINUTES", 60)) * time.Minute,
+		cleanupInt: time.Duration(max(envInt("EXPORT_CLEANUP_INTERVAL_MINUTES", 30), 1)) * time.Minute,
+		signingKey: []byte(key),
+	}
+}
+
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(helper.GetEnv(key)); err == nil && v >= 0 {
+		return v
+	}
+	return def
+}
+
+// Start memasang handler aplikasi (router gin) yang dipakai worker untuk menjalankan
+// endpoint export, lalu menyalakan worker dan retention cleanup. Hanya berlaku sekali.
+func (s *ExportJobService) Start(handler http.Handler) {
+	s.startOnce.Do(func() {
+		s.handler = handler
+		for i := 0; i < s.workers; i++ {
+			go s.worker()
+		}
+		go s.cleanupLoop()
+	})
+}
+
+// ==========================
+// Jobs
+// ==========================
+
+// Enqueue mencatat job baru dan memasukkannya ke antrian.
+func (s *ExportJobService) Enqueue(req model.ExportJobRequest, userId, username, ip string) (model.ExportJob, error) {
+	if _, ok := ReportPaths[req.ReportType]; !ok {
+		return model.ExportJob{}, ErrUnknownReportType
+	}
+	parseFormat := reportExport.ParseFormat
+	if req.ReportType == "lpj-bundle" {
+		parseFormat = reportExport.ParseBundleFormat
+	}
+	format, err := parseFormat(req.Format)
+	if err != nil {
+		return model.ExportJob{}, err
+	}
+
+	filters := map[string]string{}
+	for k, v := range req.Filters {
+		if k != "format" && strings.TrimSpace(v) != "" {
+			filters[k] = strings.TrimSpace(v)
+		}
+	}
+
+	id, err := newJobId()
+	if err != nil {
+		return model.ExportJob{}, err
+	}
+	job := model.ExportJob{
+		Id:            id,
+		ReportType:    req.ReportType,
+		Format:        string(format),
+		Filters:       filters,
+		Status:        model.ExportStatusQueued,
+		RequestedById: userId,
+		RequestedBy:   username,
+		IpAddress:     ip,
+	}
+	if err := s.repo.Create(&job); err != nil {
+		return model.ExportJob{}, err
+	}
+
+	select {
+	case s.queue <- job.Id:
+	default:
+		s.finish(&job, ErrQueueFull)
+		return job, ErrQueueFull
+	}
+	return job, nil
+}
+
+// Get mengembalikan job milik userId beserta signed download URL bila sudah selesai.
+func (s *ExportJobService) Get(id, userId string) (model.ExportJobResponse, error) {
+	job, err := s.repo.GetById(id)
+	if err != nil {
+		return model.ExportJobResponse{}, err
+	}
+	// job user lain diperlakukan seperti tidak ada
+	if job.RequestedById != userId {
+		return model.ExportJobResponse{}, gorm.ErrRecordNotFound
+	}
+	return s.response(job), nil
+}
+
+// GetAll - daftar job; requestedById kosong = semua user (admin)
+func (s *ExportJobService) GetAll(requestedById string, req model.ExportJobListRequest) ([]model.ExportJobResponse, int64, map[string]interface{}, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	jobs, total, err := s.repo.GetAll(requestedById, req)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	res := make([]model.ExportJobResponse, len(jobs))
+	for i, job := range jobs {
+		res[i] = s.response(job)
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	meta := map[string]interface{}{
+		"page":        req.Page,
+		"limit":       req.Limit,
+		"total":       total,
+		"total_pages": totalPages,
+	}
+	return res, total, meta, nil
+}
+
+// Download memverifikasi signed URL dan mengembalikan job yang file-nya siap dikirim.
+func (s *ExportJobService) Download(id, expires, signature string) (model.ExportJob, error) {
+	if err := signedUrl.Verify(s.signingKey, id, expires, signature, time.Now()); err != nil {
+		return model.ExportJob{}, err
+	}
+
+	job, err := s.repo.GetById(id)
+	if err != nil {
+		return model.ExportJob{}, err
+	}
+	switch job.Status {
+	case model.ExportStatusDone:
+	case model.ExportStatusExpired:
+		return job, ErrFileExpired
+	default:
+		return job, ErrNotReady
+	}
+	if _, err := os.Stat(job.FilePath); err != nil {
+		return job, ErrFileExpired
+	}
+
+	if err := s.repo.RecordDownload(job.Id); err != nil {
+		log.Printf("export job %s: fail to record download: %v", job.Id, err)
+	}
+	return job, nil
+}
+
+// response menambahkan signed download URL; berlaku urlTTL tapi tidak melewati expires_at file.
+func (s *ExportJobService) response(job model.ExportJob) model.ExportJobResponse {
+	res := model.ExportJobResponse{ExportJob: job}
+	if job.Status != model.ExportStatusDone {
+		return res
+	}
+
+	expires := time.Now().Add(s.urlTTL).Truncate(time.Second)
+	if job.ExpiresAt != nil && job.ExpiresAt.Before(expires) {
+		expires = *job.ExpiresAt
+	}
+	res.DownloadUrl = fmt.Sprintf("/exports/%s/download?expires=%d&signature=%s",
+		job.Id, expires.Unix(), signedUrl.Sign(s.signingKey, job.Id, expires))
+	res.DownloadUrlExpiresAt = &expires
+	return res
+}
+
+func newJobId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
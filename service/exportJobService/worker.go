@@ -0,0 +1,226 @@
+package exportJobService
+
+import (
+	"Bea-Cukai/helper/reportExport"
+	"Bea-Cukai/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ==========================
+// Worker
+// ==========================
+
+func (s *ExportJobService) worker() {
+	for id := range s.queue {
+		s.run(id)
+	}
+}
+
+func (s *ExportJobService) run(id string) {
+	job, err := s.repo.GetById(id)
+	if err != nil {
+		log.Printf("export job %s: %v", id, err)
+		return
+	}
+	if job.Status != model.ExportStatusQueued {
+		return
+	}
+
+	now := time.Now()
+	job.Status = model.ExportStatusRunning
+	job.StartedAt = &now
+	if err := s.repo.Save(&job); err != nil {
+		log.Printf("export job %s: %v", id, err)
+		return
+	}
+
+	s.finish(&job, s.render(&job))
+}
+
+// finish menyimpan hasil akhir job: done (dengan expires_at) atau failed.
+func (s *ExportJobService) finish(job *model.ExportJob, err error) {
+	now := time.Now()
+	job.FinishedAt = &now
+	if err != nil {
+		job.Status = model.ExportStatusFailed
+		job.Error = err.Error()
+	} else {
+		expires := now.Add(s.retention)
+		job.Status = model.ExportStatusDone
+		job.ExpiresAt = &expires
+	}
+	if err := s.repo.Save(job); err != nil {
+		log.Printf("export job %s: fail to save result: %v", job.Id, err)
+	}
+}
+
+// render menjalankan endpoint export laporan dan menulis responsnya ke file di EXPORT_DIR.
+func (s *ExportJobService) render(job *model.ExportJob) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, job.Id+"."+job.Format)
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	for k, v := range job.Filters {
+		query.Set(k, v)
+	}
+	query.Set("format", job.Format)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.jobTimeout)
+	defer cancel()
+	// endpoint laporan tidak memakai JWT; user dititipkan untuk register export (Document ID)
+	ctx = reportExport.WithRequester(ctx, reportExport.Requester{
+		Id:          job.RequestedById,
+		Username:    job.RequestedBy,
+		ExportJobId: job.Id,
+		IpAddress:   job.IpAddress,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ReportPaths[job.ReportType]+"?"+query.Encode(), nil)
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	req.Header.Set("X-Request-ID", "export-"+job.Id)
+	req.RemoteAddr = job.IpAddress
+
+	w := &fileResponseWriter{file: file, header: http.Header{}}
+	s.handler.ServeHTTP(w, req)
+
+	closeErr := file.Close()
+	if err := errors.Join(w.result(), w.writeErr, closeErr); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	job.FilePath = path
+	job.FileSize = w.written
+	job.FileName = filepath.Base(path)
+	job.DocumentId = w.header.Get("X-Document-Id")
+	if _, params, err := mime.ParseMediaType(w.header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		job.FileName = params["filename"]
+	}
+	return nil
+}
+
+// fileResponseWriter menampung respons endpoint export: body 200 ditulis ke file,
+// body error (JSON apiresponse) disimpan untuk pesan error job.
+type fileResponseWriter struct {
+	file     *os.File
+	header   http.Header
+	status   int
+	written  int64
+	errBody  []byte
+	writeErr error
+}
+
+func (w *fileResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *fileResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *fileResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.status != http.StatusOK {
+		if len(w.errBody) < 4096 {
+			w.errBody = append(w.errBody, b...)
+		}
+		return len(b), nil
+	}
+
+	n, err := w.file.Write(b)
+	w.written += int64(n)
+	if err != nil && w.writeErr == nil {
+		w.writeErr = err
+	}
+	return n, err
+}
+
+// result menerjemahkan status respons menjadi error job.
+func (w *fileResponseWriter) result() error {
+	if w.status == http.StatusOK || (w.status == 0 && w.written > 0) {
+		return nil
+	}
+
+	var body struct {
+		Message string `json:"message"`
+		Error   *struct {
+			Code    string `json:"code"`
+			Details string `json:"details"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(w.errBody, &body) != nil || body.Message == "" {
+		return fmt.Errorf("export failed with status %d", w.status)
+	}
+	msg := body.Message
+	if body.Error != nil && body.Error.Code != "" {
+		msg = body.Error.Code + ": " + msg
+	}
+	if body.Error != nil && body.Error.Details != "" {
+		msg += " (" + body.Error.Details + ")"
+	}
+	return errors.New(msg)
+}
+
+// ==========================
+// Retention cleanup
+// ==========================
+
+func (s *ExportJobService) cleanupLoop() {
+	ticker := time.NewTicker(s.cleanupInt)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.Cleanup()
+	}
+}
+
+// Cleanup menghapus file export yang melewati expires_at (record job tetap disimpan dengan
+// status expired) dan menggagalkan job yang tertinggal di antrian, misalnya karena restart.
+func (s *ExportJobService) Cleanup() {
+	now := time.Now()
+
+	jobs, err := s.repo.GetExpired(now)
+	if err != nil {
+		log.Printf("export cleanup: %v", err)
+		return
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		if err := os.Remove(job.FilePath); err != nil && !os.IsNotExist(err) {
+			log.Printf("export cleanup %s: %v", job.Id, err)
+			continue
+		}
+		job.Status = model.ExportStatusExpired
+		job.FilePath = ""
+		if err := s.repo.Save(job); err != nil {
+			log.Printf("export cleanup %s: %v", job.Id, err)
+		}
+	}
+
+	stale := now.Add(-(s.jobTimeout + s.cleanupInt))
+	if n, err := s.repo.FailStale(stale, "export job interrupted (server restarted or timed out)"); err != nil {
+		log.Printf("export cleanup: %v", err)
+	} else if n > 0 {
+		log.Printf("export cleanup: %d stale job(s) marked as failed", n)
+	}
+}
@@ -1,28 +1,102 @@
 package finishedProductReportService
 
 import (
+	"Bea-Cukai/helper/reportCache"
+	"Bea-Cukai/helper/reportCompare"
 	"Bea-Cukai/model"
 	"Bea-Cukai/repo/finishedProductReportRepository"
 	"context"
+	"time"
 )
 
 // FinishedProductReportService sits on top of the finishedProductReportRepository and exposes use-case oriented APIs
 // for finished product reporting.
 
+// CacheReportType adalah prefix key cache untuk laporan ini.
+const CacheReportType = "finished_product"
+
 type FinishedProductReportService struct {
-	repo *finishedProductReportRepository.FinishedProductReportRepository
+	repo  *finishedProductReportRepository.FinishedProductReportRepository
+	cache *reportCache.Cache
 }
 
-func NewFinishedProductReportService(repo *finishedProductReportRepository.FinishedProductReportRepository) *FinishedProductReportService {
-	return &FinishedProductReportService{repo: repo}
+// NewFinishedProductReportService - cache boleh nil (tanpa cache).
+func NewFinishedProductReportService(repo *finishedProductReportRepository.FinishedProductReportRepository, cache *reportCache.Cache) *FinishedProductReportService {
+	return &FinishedProductReportService{repo: repo, cache: cache}
 }
 
 // ==========================
 // Business Operations
 // ==========================
 
-// GetReport retrieves finished product report with filters and pagination
-func (s *FinishedProductReportService) GetReport(filter finishedProductReportRepository.GetReportFilter) ([]model.FinishedProductReportResponse, int64, error) {
-	ctx := context.Background()
-	return s.repo.GetReport(ctx, filter)
+// GetReport retrieves finished product report with filters and pagination.
+// Bila cache aktif, hasil lengkap (tanpa pagination) di-cache sekali lalu setiap halaman dipotong dari situ.
+func (s *FinishedProductReportService) GetReport(ctx context.Context, filter finishedProductReportRepository.GetReportFilter) ([]model.FinishedProductReportResponse, int64, error) {
+	if s.cache == nil {
+		return s.repo.GetReport(ctx, filter)
+	}
+
+	full := filter
+	full.Page, full.Limit = 0, 0
+
+	rows, _, err := reportCache.GetOrLoad(s.cache, s.cache.Key(CacheReportType, full), func() ([]model.FinishedProductReportResponse, error) {
+		res, _, err := s.repo.GetReport(ctx, full)
+		return res, err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return reportCache.Page(rows, filter.Page, filter.Limit), int64(len(rows)), nil
+}
+
+// Compare membandingkan laporan periode filter dengan periode baseFrom..baseTo (filter item sama)
+// per item: masuk, keluar dan akhir beserta selisih absolut dan persentase, terurut sesuai
+// filter.Options.Sort (kolom reportCompare.Columns; default perubahan saldo akhir terbesar).
+func (s *FinishedProductReportService) Compare(ctx context.Context, filter finishedProductReportRepository.GetReportFilter, baseFrom, baseTo time.Time) ([]model.ReportComparison, error) {
+	current, err := s.baseReport(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	filter.From, filter.To = baseFrom, baseTo
+	previous, err := s.baseReport(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := reportCompare.Compare(compareItems(current), compareItems(previous))
+	reportCompare.Sort(rows, filter.Options.Sort)
+	return rows, nil
+}
+
+// baseReport adalah laporan per item (tanpa lokasi dan pagination) satu periode, di-cache bila cache aktif.
+func (s *FinishedProductReportService) baseReport(ctx context.Context, filter finishedProductReportRepository.GetReportFilter) ([]model.FinishedProductReportResponse, error) {
+	filter.Options.Sort = nil
+	filter.GroupBy, filter.Location = "", ""
+	filter.Page, filter.Limit = 0, 0
+
+	load := func() ([]model.FinishedProductReportResponse, error) {
+		return s.repo.GetBaseReport(ctx, filter)
+	}
+	if s.cache == nil {
+		return load()
+	}
+	rows, _, err := reportCache.GetOrLoad(s.cache, s.cache.Key(CacheReportType+"_base", filter), load)
+	return rows, err
+}
+
+func compareItems(rows []model.FinishedProductReportResponse) []reportCompare.Item {
+	items := make([]reportCompare.Item, len(rows))
+	for i, r := range rows {
+		items[i] = reportCompare.Item{
+			ItemCode:     r.ItemCode,
+			ItemName:     r.ItemName,
+			UnitCode:     r.UnitCode,
+			ItemTypeCode: r.ItemTypeCode,
+			ItemGroup:    r.ItemGroup,
+			Masuk:        r.Masuk,
+			Keluar:       r.Keluar,
+			Akhir:        r.Akhir,
+		}
+	}
+	return items
 }
@@ -21,7 +21,6 @@ func NewItemGroupService(itemGroupRepo *itemGroupRepository.ItemGroupRepository)
 // ==========================
 
 // GetAll retrieves all item groups
-func (s *ItemGroupService) GetAll() ([]model.ItemGroup, error) {
-	ctx := context.Background()
+func (s *ItemGroupService) GetAll(ctx context.Context) ([]model.ItemGroup, error) {
 	return s.itemGroupRepo.GetAll(ctx)
 }
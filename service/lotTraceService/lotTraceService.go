@@ -0,0 +1,207 @@
+package lotTraceService
+
+import (
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/lotTraceRepository"
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// LotTraceService menelusuri bahan baku per lot penerimaan: dari dokumen pabean pemasukan ke
+// pemakaian (maju) dan dari stok suatu item ke dokumen asalnya (mundur).
+
+type LotTraceService struct {
+	repo *lotTraceRepository.LotTraceRepository
+}
+
+func NewLotTraceService(repo *lotTraceRepository.LotTraceRepository) *LotTraceService {
+	return &LotTraceService{repo: repo}
+}
+
+// ==========================
+// Business Operations
+// ==========================
+
+// GetLots mengembalikan lot yang cocok (mis. per no_pabean atau data_no) beserta semua pemakaiannya.
+func (s *LotTraceService) GetLots(ctx context.Context, filter lotTraceRepository.Filter) ([]model.Lot, int64, error) {
+	lots, totalCount, err := s.repo.GetLots(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.attachConsumptions(ctx, lots, filter.AsOf); err != nil {
+		return nil, 0, err
+	}
+	return lots, totalCount, nil
+}
+
+// GetOrigin mengembalikan dokumen asal stok itemCode pada date: lot yang diterima sampai date dan
+// masih bersisa setelah pemakaian sampai date, urut penerimaan.
+func (s *LotTraceService) GetOrigin(ctx context.Context, itemCode string, date time.Time) (model.LotOrigin, error) {
+	lots, _, err := s.repo.GetLots(ctx, lotTraceRepository.Filter{
+		AsOf:          date,
+		ItemCode:      itemCode,
+		OnlyRemaining: true,
+	})
+	if err != nil {
+		return model.LotOrigin{}, err
+	}
+
+	origin := model.LotOrigin{ItemCode: itemCode, Date: date.Format("2006-01-02"), Lots: lots}
+	for _, lot := range lots {
+		origin.RemainingQty = origin.RemainingQty.Add(lot.RemainingQty)
+	}
+	if origin.Lots == nil {
+		origin.Lots = []model.Lot{}
+	}
+	return origin, nil
+}
+
+// GetLotBalance mengembalikan saldo lot per dokumen pabean pemasukan (tanpa rincian pemakaian).
+func (s *LotTraceService) GetLotBalance(ctx context.Context, filter lotTraceRepository.Filter) ([]model.Lot, error) {
+	return s.repo.GetLotBalance(ctx, filter)
+}
+
+// GetAging mengembalikan lot bahan baku impor yang masih bersisa pada filter.AsOf beserta umurnya
+// (hari sejak penerimaan) dan ringkasan per kelompok umur.
+func (s *LotTraceService) GetAging(ctx context.Context, filter lotTraceRepository.Filter) ([]model.LotAging, model.AgingSummary, error) {
+	lots, err := s.repo.GetAging(ctx, filter)
+	if err != nil {
+		return nil, model.AgingSummary{}, err
+	}
+
+	summary := newAgingSummary()
+	for i := range lots {
+		lots[i].AgeDays = apiRequest.DaysBetween(lots[i].InDate, filter.AsOf)
+		lots[i].AgingBucket = agingBucket(lots[i].AgeDays)
+		summary.add(lots[i])
+	}
+	if lots == nil {
+		lots = []model.LotAging{}
+	}
+	return lots, summary.result(), nil
+}
+
+// GetAgingDocuments mengembalikan umur sisa bahan baku per dokumen pabean pemasukan, urut
+// penerimaan terlama.
+func (s *LotTraceService) GetAgingDocuments(ctx context.Context, filter lotTraceRepository.Filter) ([]model.AgingDocument, model.AgingSummary, error) {
+	lots, summary, err := s.GetAging(ctx, filter)
+	if err != nil {
+		return nil, model.AgingSummary{}, err
+	}
+
+	docs := []model.AgingDocument{}
+	byKey := map[string]int{}
+	for _, lot := range lots {
+		key := lot.JenisPabean + "|" + lot.NoPabean
+		i, ok := byKey[key]
+		if !ok {
+			// lot urut in_date, jadi lot pertama sebuah dokumen adalah yang terlama
+			docs = append(docs, model.AgingDocument{
+				JenisPabean:  lot.JenisPabean,
+				NoPabean:     lot.NoPabean,
+				TglPabean:    lot.TglPabean,
+				VendorName:   lot.VendorName,
+				OldestInDate: lot.InDate,
+				MaxAgeDays:   lot.AgeDays,
+			})
+			i = len(docs) - 1
+			byKey[key] = i
+		}
+
+		doc := &docs[i]
+		doc.LotCount++
+		addAging(&doc.RemainingQty, lot.AgingBucket, lot.RemainingQty)
+		if lot.RemainingValueIdr == nil {
+			doc.MissingValueCount++
+		} else {
+			addAging(&doc.RemainingValueIdr, lot.AgingBucket, *lot.RemainingValueIdr)
+		}
+	}
+	return docs, summary, nil
+}
+
+// attachConsumptions mengisi Lot.Consumptions dengan satu query untuk semua lot.
+func (s *LotTraceService) attachConsumptions(ctx context.Context, lots []model.Lot, asOf time.Time) error {
+	dataNos := make([]string, len(lots))
+	byDataNo := make(map[string]int, len(lots))
+	for i, lot := range lots {
+		dataNos[i] = lot.DataNo
+		byDataNo[lot.DataNo] = i
+	}
+
+	consumptions, err := s.repo.GetConsumptions(ctx, dataNos, asOf)
+	if err != nil {
+		return err
+	}
+	for _, c := range consumptions {
+		if i, ok := byDataNo[c.DataNo]; ok {
+			lots[i].Consumptions = append(lots[i].Consumptions, c)
+		}
+	}
+	return nil
+}
+
+// agingBucket mengelompokkan umur lot (hari) ke model.AgingBuckets.
+func agingBucket(days int) string {
+	switch {
+	case days <= 90:
+		return model.AgingBucket0To90
+	case days <= 180:
+		return model.AgingBucket91To180
+	case days <= 365:
+		return model.AgingBucket181To365
+	default:
+		return model.AgingBucketOver365
+	}
+}
+
+// addAging menambahkan v ke kelompok umur bucket dan ke total.
+func addAging(a *model.AgingAmounts, bucket string, v decimal.Decimal) {
+	switch bucket {
+	case model.AgingBucket0To90:
+		a.Days0To90 = a.Days0To90.Add(v)
+	case model.AgingBucket91To180:
+		a.Days91To180 = a.Days91To180.Add(v)
+	case model.AgingBucket181To365:
+		a.Days181To365 = a.Days181To365.Add(v)
+	default:
+		a.Over365 = a.Over365.Add(v)
+	}
+	a.Total = a.Total.Add(v)
+}
+
+// agingSummaryBuilder mengakumulasi model.AgingSummary per kelompok umur.
+type agingSummaryBuilder struct {
+	summary model.AgingSummary
+	index   map[string]int
+}
+
+func newAgingSummary() *agingSummaryBuilder {
+	b := &agingSummaryBuilder{index: make(map[string]int, len(model.AgingBuckets))}
+	for i, bucket := range model.AgingBuckets {
+		b.summary.Buckets = append(b.summary.Buckets, model.AgingBucketSummary{Bucket: bucket})
+		b.index[bucket] = i
+	}
+	return b
+}
+
+func (b *agingSummaryBuilder) add(lot model.LotAging) {
+	bucket := &b.summary.Buckets[b.index[lot.AgingBucket]]
+	bucket.LotCount++
+	b.summary.LotCount++
+	if lot.RemainingValueIdr == nil {
+		bucket.MissingValueCount++
+		b.summary.MissingValueCount++
+		return
+	}
+	bucket.RemainingValueIdr = bucket.RemainingValueIdr.Add(*lot.RemainingValueIdr)
+	b.summary.RemainingValueIdr = b.summary.RemainingValueIdr.Add(*lot.RemainingValueIdr)
+}
+
+func (b *agingSummaryBuilder) result() model.AgingSummary {
+	b.summary.Complete = b.summary.MissingValueCount == 0
+	return b.summary
+}
@@ -0,0 +1,116 @@
+package lotTraceService
+
+import (
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/lotTraceRepository"
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func d(v string) decimal.Decimal { return decimal.RequireFromString(v) }
+
+func TestAgingBucket(t *testing.T) {
+	tests := []struct {
+		days int
+		want string
+	}{
+		{0, model.AgingBucket0To90},
+		{90, model.AgingBucket0To90},
+		{91, model.AgingBucket91To180},
+		{180, model.AgingBucket91To180},
+		{181, model.AgingBucket181To365},
+		{365, model.AgingBucket181To365},
+		{366, model.AgingBucketOver365},
+	}
+	for _, tc := range tests {
+		if got := agingBucket(tc.days); got != tc.want {
+			t.Errorf("agingBucket(%d) = %s; want %s", tc.days, got, tc.want)
+		}
+	}
+}
+
+func TestAddAging(t *testing.T) {
+	var a model.AgingAmounts
+	addAging(&a, model.AgingBucket0To90, d("1"))
+	addAging(&a, model.AgingBucket91To180, d("2"))
+	addAging(&a, model.AgingBucket181To365, d("3"))
+	addAging(&a, model.AgingBucketOver365, d("4"))
+	addAging(&a, model.AgingBucket91To180, d("0.5"))
+
+	want := model.AgingAmounts{Days0To90: d("1"), Days91To180: d("2.5"), Days181To365: d("3"), Over365: d("4"), Total: d("10.5")}
+	if !a.Days0To90.Equal(want.Days0To90) || !a.Days91To180.Equal(want.Days91To180) ||
+		!a.Days181To365.Equal(want.Days181To365) || !a.Over365.Equal(want.Over365) || !a.Total.Equal(want.Total) {
+		t.Errorf("amounts = %+v; want %+v", a, want)
+	}
+}
+
+// Lot di batas 90/91, 180/181 dan 365/366 hari masuk kelompok yang benar, dan dokumen mengambil
+// umur lot terlamanya; lot tanpa nilai IDR hanya dihitung di MissingValueCount.
+func TestGetAgingDocuments(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("gorm.Open mock: %v", err)
+	}
+	svc := NewLotTraceService(lotTraceRepository.NewLotTraceRepository(db))
+
+	asOf := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	ago := func(days int) time.Time { return asOf.AddDate(0, 0, -days) }
+
+	mock.ExpectQuery(`SELECT d\.data_no`).
+		WillReturnRows(sqlmock.NewRows([]string{"data_no", "in_date", "jenis_pabean", "no_pabean", "remaining_qty", "remaining_value_idr"}).
+			AddRow("1", ago(366), "BC 2.3", "A", "1", "100").
+			AddRow("2", ago(365), "BC 2.3", "B", "2", nil).
+			AddRow("3", ago(181), "BC 2.3", "A", "3", "10").
+			AddRow("4", ago(180), "BC 2.3", "B", "4", "20").
+			AddRow("5", ago(91), "BC 2.3", "A", "5", "30").
+			AddRow("6", ago(90), "BC 2.3", "B", "6", "40"))
+
+	docs, summary, err := svc.GetAgingDocuments(context.Background(), lotTraceRepository.Filter{AsOf: asOf})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("len = %d; want 2", len(docs))
+	}
+
+	a, b := docs[0], docs[1]
+	if a.NoPabean != "A" || a.LotCount != 3 || a.MaxAgeDays != 366 || a.MissingValueCount != 0 {
+		t.Errorf("doc A = %+v", a)
+	}
+	if !a.RemainingQty.Over365.Equal(d("1")) || !a.RemainingQty.Days181To365.Equal(d("3")) ||
+		!a.RemainingQty.Days91To180.Equal(d("5")) || !a.RemainingQty.Days0To90.IsZero() || !a.RemainingValueIdr.Total.Equal(d("140")) {
+		t.Errorf("doc A amounts = %+v / %+v", a.RemainingQty, a.RemainingValueIdr)
+	}
+	if b.NoPabean != "B" || b.LotCount != 3 || b.MaxAgeDays != 365 || b.MissingValueCount != 1 {
+		t.Errorf("doc B = %+v", b)
+	}
+	if !b.RemainingQty.Days181To365.Equal(d("2")) || !b.RemainingQty.Days91To180.Equal(d("4")) ||
+		!b.RemainingQty.Days0To90.Equal(d("6")) || !b.RemainingValueIdr.Days181To365.IsZero() || !b.RemainingValueIdr.Total.Equal(d("60")) {
+		t.Errorf("doc B amounts = %+v / %+v", b.RemainingQty, b.RemainingValueIdr)
+	}
+
+	if summary.LotCount != 6 || summary.MissingValueCount != 1 || summary.Complete || !summary.RemainingValueIdr.Equal(d("200")) {
+		t.Errorf("summary = %+v", summary)
+	}
+	wantCounts := map[string]int{model.AgingBucket0To90: 1, model.AgingBucket91To180: 2, model.AgingBucket181To365: 2, model.AgingBucketOver365: 1}
+	for _, bucket := range summary.Buckets {
+		if bucket.LotCount != wantCounts[bucket.Bucket] {
+			t.Errorf("bucket %s lot_count = %d; want %d", bucket.Bucket, bucket.LotCount, wantCounts[bucket.Bucket])
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
@@ -22,7 +22,6 @@ func NewMachineToolReportService(repo *machineToolReportRepository.MachineToolRe
 // ==========================
 
 // GetReport retrieves machine and tool report with filters and pagination
-func (s *MachineToolReportService) GetReport(filter machineToolReportRepository.GetReportFilter) ([]model.MachineToolReportResponse, int64, error) {
-	ctx := context.Background()
+func (s *MachineToolReportService) GetReport(ctx context.Context, filter machineToolReportRepository.GetReportFilter) ([]model.MachineToolReportResponse, int64, error) {
 	return s.repo.GetReport(ctx, filter)
 }
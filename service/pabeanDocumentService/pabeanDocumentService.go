@@ -0,0 +1,186 @@
+package pabeanDocumentService
+
+import (
+	"Bea-Cukai/helper"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/pabeanDocumentRepository"
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// PabeanDocumentService menyusun baris tr_pemasukan_barang / tr_pengeluaran_barang menjadi
+// dokumen pabean: header (jenis, nomor, tanggal, pengirim/penerima, nomor bukti), baris barang
+// dan total kuantitas per satuan serta nilai per mata uang.
+
+// Default jumlah dokumen maksimal satu export (satu sheet per dokumen).
+const defaultMaxExportDocuments = 200
+
+var ErrTooManyDocuments = errors.New("too many documents for one export, narrow the filter")
+
+type PabeanDocumentService struct {
+	repo               *pabeanDocumentRepository.PabeanDocumentRepository
+	maxExportDocuments int
+}
+
+// NewPabeanDocumentService membaca PABEAN_DOCUMENT_EXPORT_MAX (jumlah dokumen maksimal
+// satu export, default 200).
+func NewPabeanDocumentService(repo *pabeanDocumentRepository.PabeanDocumentRepository) *PabeanDocumentService {
+	maxDocs := defaultMaxExportDocuments
+	if v, err := strconv.Atoi(helper.GetEnv("PABEAN_DOCUMENT_EXPORT_MAX")); err == nil && v > 0 {
+		maxDocs = v
+	}
+	return &PabeanDocumentService{repo: repo, maxExportDocuments: maxDocs}
+}
+
+// ==========================
+// Business Operations
+// ==========================
+
+// MaxExportDocuments adalah jumlah dokumen maksimal satu export.
+func (s *PabeanDocumentService) MaxExportDocuments() int {
+	return s.maxExportDocuments
+}
+
+// GetList mengembalikan header dan total dokumen (tanpa baris barang) dengan pagination per dokumen.
+func (s *PabeanDocumentService) GetList(ctx context.Context, filter pabeanDocumentRepository.ListFilter) ([]model.PabeanDocument, int64, error) {
+	keys, totalCount, err := s.repo.ListKeys(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	docs, err := s.load(ctx, keys)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range docs {
+		docs[i].Lines = nil
+	}
+	return docs, totalCount, nil
+}
+
+// GetDocument mengembalikan dokumen jenis/no lengkap dengan baris barangnya. direction kosong
+// mencari di pemasukan dan pengeluaran; hasil lebih dari satu berarti nomor yang sama dipakai di
+// kedua arah (mis. BC 2.7), hasil kosong berarti dokumen tidak ditemukan.
+func (s *PabeanDocumentService) GetDocument(ctx context.Context, direction, jenisPabean, noPabean string) ([]model.PabeanDocument, error) {
+	directions := pabeanDocumentRepository.Directions
+	if direction != "" {
+		directions = []string{direction}
+	}
+
+	var docs []model.PabeanDocument
+	key := []model.PabeanDocumentKey{{JenisPabean: jenisPabean, NoPabean: noPabean}}
+	for _, d := range directions {
+		lines, err := s.repo.GetLines(ctx, d, key)
+		if err != nil {
+			return nil, err
+		}
+		if len(lines) > 0 {
+			docs = append(docs, buildDocument(d, lines))
+		}
+	}
+	return docs, nil
+}
+
+// GetForExport mengembalikan semua dokumen yang cocok dengan filter lengkap dengan baris barangnya.
+// Lebih dari MaxExportDocuments dokumen ditolak dengan ErrTooManyDocuments (totalCount tetap diisi).
+func (s *PabeanDocumentService) GetForExport(ctx context.Context, filter pabeanDocumentRepository.ListFilter) ([]model.PabeanDocument, int64, error) {
+	filter.Page, filter.Limit = 1, s.maxExportDocuments
+	keys, totalCount, err := s.repo.ListKeys(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if totalCount > int64(s.maxExportDocuments) {
+		return nil, totalCount, ErrTooManyDocuments
+	}
+	docs, err := s.load(ctx, keys)
+	return docs, totalCount, err
+}
+
+// load membaca baris barang semua dokumen keys (satu query per arah) dan menyusunnya
+// menjadi dokumen dengan urutan keys.
+func (s *PabeanDocumentService) load(ctx context.Context, keys []model.PabeanDocumentKey) ([]model.PabeanDocument, error) {
+	byDirection := map[string][]model.PabeanDocumentKey{}
+	for _, k := range keys {
+		byDirection[k.Direction] = append(byDirection[k.Direction], k)
+	}
+
+	type docKey struct{ direction, jenis, no string }
+	lines := map[docKey][]model.PabeanDocumentLine{}
+	for _, direction := range pabeanDocumentRepository.Directions {
+		if len(byDirection[direction]) == 0 {
+			continue
+		}
+		rows, err := s.repo.GetLines(ctx, direction, byDirection[direction])
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			k := docKey{direction, row.JenisPabean, row.NoPabean}
+			lines[k] = append(lines[k], row)
+		}
+	}
+
+	docs := make([]model.PabeanDocument, 0, len(keys))
+	for _, k := range keys {
+		if rows := lines[docKey{k.Direction, k.JenisPabean, k.NoPabean}]; len(rows) > 0 {
+			docs = append(docs, buildDocument(k.Direction, rows))
+		}
+	}
+	return docs, nil
+}
+
+// buildDocument menyusun header dan total dari baris-baris satu dokumen.
+func buildDocument(direction string, lines []model.PabeanDocumentLine) model.PabeanDocument {
+	first := lines[0]
+	doc := model.PabeanDocument{
+		Direction:       direction,
+		JenisPabean:     first.JenisPabean,
+		NoPabean:        first.NoPabean,
+		TglPabean:       first.TglPabean,
+		CounterpartCode: first.CounterpartCode,
+		CounterpartName: first.CounterpartName,
+		TransNos:        []string{},
+		DeliveryNos:     []string{},
+		LineCount:       len(lines),
+		Lines:           lines,
+	}
+
+	seenTrans, seenDelivery := map[string]bool{}, map[string]bool{}
+	quantities, amounts := map[string]decimal.Decimal{}, map[string]decimal.Decimal{}
+	for _, l := range lines {
+		if l.TglPabean.Before(doc.TglPabean) {
+			doc.TglPabean = l.TglPabean
+		}
+		if l.TransNo != "" && !seenTrans[l.TransNo] {
+			seenTrans[l.TransNo] = true
+			doc.TransNos = append(doc.TransNos, l.TransNo)
+		}
+		if l.DeliveryNo != "" && !seenDelivery[l.DeliveryNo] {
+			seenDelivery[l.DeliveryNo] = true
+			doc.DeliveryNos = append(doc.DeliveryNos, l.DeliveryNo)
+		}
+		if !l.TransDate.IsZero() && (doc.TransDate == nil || l.TransDate.Before(*doc.TransDate)) {
+			t := l.TransDate
+			doc.TransDate = &t
+		}
+		quantities[l.Unit] = quantities[l.Unit].Add(l.Qty)
+		amounts[l.CurrCode] = amounts[l.CurrCode].Add(l.NetAmount)
+	}
+
+	doc.Quantities = make([]model.QuantityTotal, 0, len(quantities))
+	for unit, qty := range quantities {
+		doc.Quantities = append(doc.Quantities, model.QuantityTotal{Unit: unit, Quantity: qty})
+	}
+	sort.Slice(doc.Quantities, func(i, j int) bool { return doc.Quantities[i].Unit < doc.Quantities[j].Unit })
+
+	doc.Amounts = make([]model.AmountTotal, 0, len(amounts))
+	for curr, amount := range amounts {
+		doc.Amounts = append(doc.Amounts, model.AmountTotal{CurrCode: curr, Amount: amount})
+	}
+	sort.Slice(doc.Amounts, func(i, j int) bool { return doc.Amounts[i].CurrCode < doc.Amounts[j].CurrCode })
+
+	return doc
+}
@@ -4,6 +4,10 @@ import (
 	"Bea-Cukai/model"
 	"Bea-Cukai/repo/pabeanRepository"
 	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 )
 
 // PabeanService sits on top of the pabeanRepository and exposes use-case oriented APIs.
@@ -21,7 +25,66 @@ func NewPabeanService(pabeanRepo *pabeanRepository.PabeanRepository) *PabeanServ
 // ==========================
 
 // GetAll retrieves all pabean documents
-func (s *PabeanService) GetAll() ([]model.MsPabean, error) {
-	ctx := context.Background()
+func (s *PabeanService) GetAll(ctx context.Context) ([]model.MsPabean, error) {
 	return s.pabeanRepo.GetAll(ctx)
 }
+
+// Create menyimpan jenis dokumen baru beserta aturannya (arah, penangguhan, kolom LPJ).
+func (s *PabeanService) Create(ctx context.Context, req model.MsPabeanRequest, username string) (model.MsPabean, error) {
+	pabean, err := toModel(req)
+	if err != nil {
+		return model.MsPabean{}, err
+	}
+	now := time.Now()
+	pabean.CreatedBy, pabean.CreatedDate = username, now
+	pabean.UpdatedBy, pabean.UpdatedDate = username, now
+	return s.pabeanRepo.Create(ctx, pabean)
+}
+
+// Update mengubah jenis dokumen by id.
+func (s *PabeanService) Update(ctx context.Context, id int, req model.MsPabeanRequest, username string) (model.MsPabean, error) {
+	pabean, err := toModel(req)
+	if err != nil {
+		return model.MsPabean{}, err
+	}
+	pabean.UpdatedBy, pabean.UpdatedDate = username, time.Now()
+	return s.pabeanRepo.Update(ctx, id, pabean)
+}
+
+// toModel memvalidasi request dan mengubahnya menjadi model; kolom LPJ disimpan dipisah koma
+// dalam huruf besar.
+func toModel(req model.MsPabeanRequest) (model.MsPabean, error) {
+	direction := strings.ToUpper(strings.TrimSpace(req.Direction))
+	if direction != "" && !slices.Contains(model.PabeanDirections, direction) {
+		return model.MsPabean{}, fmt.Errorf("invalid direction %q (use one of: %s)", req.Direction, strings.Join(model.PabeanDirections, ", "))
+	}
+	lpjColumns, err := normalizeList("lpj_columns", req.LpjColumns, model.PabeanLpjColumns)
+	if err != nil {
+		return model.MsPabean{}, err
+	}
+
+	return model.MsPabean{
+		PabeanCode:    strings.TrimSpace(req.PabeanCode),
+		PabeanName:    strings.TrimSpace(req.PabeanName),
+		Notes:         strings.TrimSpace(req.Notes),
+		Direction:     direction,
+		DutySuspended: req.DutySuspended,
+		LpjColumns:    lpjColumns,
+	}, nil
+}
+
+// normalizeList memvalidasi values terhadap allowed dan menggabungkannya dipisah koma (tanpa duplikat).
+func normalizeList(field string, values, allowed []string) (string, error) {
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.ToUpper(strings.TrimSpace(v))
+		if v == "" || slices.Contains(list, v) {
+			continue
+		}
+		if !slices.Contains(allowed, v) {
+			return "", fmt.Errorf("invalid %s value %q (use any of: %s)", field, v, strings.Join(allowed, ", "))
+		}
+		list = append(list, v)
+	}
+	return strings.Join(list, ","), nil
+}
@@ -1,28 +1,102 @@
 package rawMaterialReportService
 
 import (
+	"Bea-Cukai/helper/reportCache"
+	"Bea-Cukai/helper/reportCompare"
 	"Bea-Cukai/model"
 	"Bea-Cukai/repo/rawMaterialReportRepository"
 	"context"
+	"time"
 )
 
 // RawMaterialReportService sits on top of the rawMaterialReportRepository and exposes use-case oriented APIs
 // for raw material reporting.
 
+// CacheReportType adalah prefix key cache untuk laporan ini.
+const CacheReportType = "raw_material"
+
 type RawMaterialReportService struct {
-	repo *rawMaterialReportRepository.RawMaterialReportRepository
+	repo  *rawMaterialReportRepository.RawMaterialReportRepository
+	cache *reportCache.Cache
 }
 
-func NewRawMaterialReportService(repo *rawMaterialReportRepository.RawMaterialReportRepository) *RawMaterialReportService {
-	return &RawMaterialReportService{repo: repo}
+// NewRawMaterialReportService - cache boleh nil (tanpa cache).
+func NewRawMaterialReportService(repo *rawMaterialReportRepository.RawMaterialReportRepository, cache *reportCache.Cache) *RawMaterialReportService {
+	return &RawMaterialReportService{repo: repo, cache: cache}
 }
 
 // ==========================
 // Business Operations
 // ==========================
 
-// GetReport retrieves raw material report with filters and pagination
-func (s *RawMaterialReportService) GetReport(filter rawMaterialReportRepository.GetReportFilter) ([]model.RawMaterialReportResponse, int64, error) {
-	ctx := context.Background()
-	return s.repo.GetReport(ctx, filter)
+// GetReport retrieves raw material report with filters and pagination.
+// Bila cache aktif, hasil lengkap (tanpa pagination) di-cache sekali lalu setiap halaman dipotong dari situ.
+func (s *RawMaterialReportService) GetReport(ctx context.Context, filter rawMaterialReportRepository.GetReportFilter) ([]model.RawMaterialReportResponse, int64, error) {
+	if s.cache == nil {
+		return s.repo.GetReport(ctx, filter)
+	}
+
+	full := filter
+	full.Page, full.Limit = 0, 0
+
+	rows, _, err := reportCache.GetOrLoad(s.cache, s.cache.Key(CacheReportType, full), func() ([]model.RawMaterialReportResponse, error) {
+		res, _, err := s.repo.GetReport(ctx, full)
+		return res, err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return reportCache.Page(rows, filter.Page, filter.Limit), int64(len(rows)), nil
+}
+
+// Compare membandingkan laporan periode filter dengan periode baseFrom..baseTo (filter item sama)
+// per item: masuk, keluar dan akhir beserta selisih absolut dan persentase, terurut sesuai
+// filter.Options.Sort (kolom reportCompare.Columns; default perubahan saldo akhir terbesar).
+func (s *RawMaterialReportService) Compare(ctx context.Context, filter rawMaterialReportRepository.GetReportFilter, baseFrom, baseTo time.Time) ([]model.ReportComparison, error) {
+	current, err := s.baseReport(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	filter.From, filter.To = baseFrom, baseTo
+	previous, err := s.baseReport(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := reportCompare.Compare(compareItems(current), compareItems(previous))
+	reportCompare.Sort(rows, filter.Options.Sort)
+	return rows, nil
+}
+
+// baseReport adalah laporan per item (tanpa lokasi dan pagination) satu periode, di-cache bila cache aktif.
+func (s *RawMaterialReportService) baseReport(ctx context.Context, filter rawMaterialReportRepository.GetReportFilter) ([]model.RawMaterialReportResponse, error) {
+	filter.Options.Sort = nil
+	filter.GroupBy, filter.Location = "", ""
+	filter.Page, filter.Limit = 0, 0
+
+	load := func() ([]model.RawMaterialReportResponse, error) {
+		return s.repo.GetBaseReport(ctx, filter)
+	}
+	if s.cache == nil {
+		return load()
+	}
+	rows, _, err := reportCache.GetOrLoad(s.cache, s.cache.Key(CacheReportType+"_base", filter), load)
+	return rows, err
+}
+
+func compareItems(rows []model.RawMaterialReportResponse) []reportCompare.Item {
+	items := make([]reportCompare.Item, len(rows))
+	for i, r := range rows {
+		items[i] = reportCompare.Item{
+			ItemCode:     r.ItemCode,
+			ItemName:     r.ItemName,
+			UnitCode:     r.UnitCode,
+			ItemTypeCode: r.ItemTypeCode,
+			ItemGroup:    r.ItemGroup,
+			Masuk:        r.Masuk,
+			Keluar:       r.Keluar,
+			Akhir:        r.Akhir,
+		}
+	}
+	return items
 }
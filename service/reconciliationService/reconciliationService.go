@@ -0,0 +1,149 @@
+package reconciliationService
+
+import (
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/reconciliationRepository"
+	"context"
+	"slices"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReconciliationService mencocokkan baris dokumen pabean dengan mutasi gudang dan
+// mengklasifikasikan setiap baris (lihat model.Reconciliation*).
+
+type ReconciliationService struct {
+	repo *reconciliationRepository.ReconciliationRepository
+}
+
+func NewReconciliationService(repo *reconciliationRepository.ReconciliationRepository) *ReconciliationService {
+	return &ReconciliationService{repo: repo}
+}
+
+// ==========================
+// Business Operations
+// ==========================
+
+// Inbound merekonsiliasi dokumen pemasukan dengan penerimaan gudang. Summary dihitung dari semua
+// baris; lines hanya berisi status yang diminta (opts.Status).
+func (s *ReconciliationService) Inbound(ctx context.Context, filter reconciliationRepository.Filter, opts model.ReconciliationOptions) ([]model.InboundReconciliationLine, model.ReconciliationSummary, error) {
+	lines, err := s.repo.GetInbound(ctx, filter)
+	if err != nil {
+		return nil, model.ReconciliationSummary{}, err
+	}
+
+	summary := newSummary(model.InboundReconciliationStatuses)
+	filtered := make([]model.InboundReconciliationLine, 0, len(lines))
+	for _, line := range lines {
+		classifyInbound(&line, opts)
+		if summary.add(line.Status, opts) {
+			filtered = append(filtered, line)
+		}
+	}
+	return filtered, summary.ReconciliationSummary, nil
+}
+
+// Outbound merekonsiliasi dokumen pengeluaran dengan ekspor dan pengiriman per item dalam
+// periode. Summary dihitung dari semua baris; lines hanya berisi status yang diminta.
+func (s *ReconciliationService) Outbound(ctx context.Context, filter reconciliationRepository.Filter, opts model.ReconciliationOptions) ([]model.OutboundReconciliationLine, model.ReconciliationSummary, error) {
+	lines, err := s.repo.GetOutbound(ctx, filter)
+	if err != nil {
+		return nil, model.ReconciliationSummary{}, err
+	}
+
+	summary := newSummary(model.OutboundReconciliationStatuses)
+	filtered := make([]model.OutboundReconciliationLine, 0, len(lines))
+	for _, line := range lines {
+		classifyOutbound(&line, opts)
+		if summary.add(line.Status, opts) {
+			filtered = append(filtered, line)
+		}
+	}
+	return filtered, summary.ReconciliationSummary, nil
+}
+
+// summaryBuilder menghitung model.ReconciliationSummary sambil menyaring status.
+type summaryBuilder struct {
+	model.ReconciliationSummary
+}
+
+func newSummary(statuses []string) *summaryBuilder {
+	b := &summaryBuilder{model.ReconciliationSummary{ByStatus: map[string]int{}, Complete: true}}
+	for _, status := range statuses {
+		b.ByStatus[status] = 0
+	}
+	return b
+}
+
+// add mencatat satu baris dan mengembalikan true bila statusnya termasuk opts.Status.
+func (b *summaryBuilder) add(status string, opts model.ReconciliationOptions) bool {
+	b.LineCount++
+	b.ByStatus[status]++
+	if status != model.ReconciliationMatched {
+		b.Complete = false
+	}
+	return len(opts.Status) == 0 || slices.Contains(opts.Status, status)
+}
+
+// classifyInbound mengisi hasil pencocokan satu baris.
+func classifyInbound(line *model.InboundReconciliationLine, opts model.ReconciliationOptions) {
+	line.QtyDiff = line.ReceivedQty.Sub(line.DocQty)
+
+	switch {
+	case !line.HasReceipt:
+		line.Status = model.ReconciliationNotReceived
+		line.NotReceivedQty = line.DocQty
+		return
+	case !line.HasDocument:
+		line.Status = model.ReconciliationNoDocument
+		line.UndocumentedQty = line.ReceivedQty
+		return
+	}
+
+	if line.QtyDiff.Abs().GreaterThan(opts.QtyTolerance) {
+		line.QtyMismatch = true
+		if line.QtyDiff.IsNegative() {
+			line.NotReceivedQty = line.QtyDiff.Neg()
+		} else {
+			line.UndocumentedQty = line.QtyDiff
+		}
+	}
+	if line.TglPabean != nil && line.InDate != nil {
+		days := apiRequest.DaysBetween(*line.TglPabean, *line.InDate)
+		line.DateDiffDays = &days
+		line.DateMismatch = days < 0 || days > opts.DateToleranceDays
+	}
+
+	switch {
+	case line.QtyMismatch:
+		line.Status = model.ReconciliationQtyMismatch
+	case line.DateMismatch:
+		line.Status = model.ReconciliationDateMismatch
+	default:
+		line.Status = model.ReconciliationMatched
+	}
+}
+
+// classifyOutbound mengisi hasil pencocokan satu item.
+func classifyOutbound(line *model.OutboundReconciliationLine, opts model.ReconciliationOptions) {
+	line.ShippedQty = line.ExportedQty.Add(line.DeliveredQty)
+	line.QtyDiff = line.ShippedQty.Sub(line.DocQty)
+	if line.QtyDiff.IsNegative() {
+		line.NotShippedQty = line.QtyDiff.Neg()
+	} else {
+		line.UndocumentedQty = line.QtyDiff
+	}
+
+	switch {
+	case line.QtyDiff.Abs().LessThanOrEqual(opts.QtyTolerance):
+		line.Status = model.ReconciliationMatched
+		line.NotShippedQty, line.UndocumentedQty = decimal.Zero, decimal.Zero
+	case line.ShippedQty.IsZero():
+		line.Status = model.ReconciliationNotShipped
+	case line.DocQty.IsZero():
+		line.Status = model.ReconciliationNoDocument
+	default:
+		line.Status = model.ReconciliationQtyMismatch
+	}
+}
@@ -0,0 +1,111 @@
+package reconciliationService
+
+import (
+	"Bea-Cukai/model"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func d(v string) decimal.Decimal { return decimal.RequireFromString(v) }
+
+func date(day int) *time.Time {
+	t := time.Date(2026, 9, day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestClassifyInbound(t *testing.T) {
+	opts := model.ReconciliationOptions{QtyTolerance: d("0.5"), DateToleranceDays: 3}
+
+	tests := []struct {
+		name        string
+		line        model.InboundReconciliationLine
+		status      string
+		notReceived string
+		undoc       string
+	}{
+		{"belum diterima",
+			model.InboundReconciliationLine{HasDocument: true, DocQty: d("10"), TglPabean: date(1)},
+			model.ReconciliationNotReceived, "10", "0"},
+		{"diterima tanpa dokumen",
+			model.InboundReconciliationLine{HasReceipt: true, ReceivedQty: d("4"), InDate: date(2)},
+			model.ReconciliationNoDocument, "0", "4"},
+		{"selisih tepat di toleransi",
+			model.InboundReconciliationLine{HasDocument: true, HasReceipt: true, DocQty: d("10"), ReceivedQty: d("9.5"), TglPabean: date(1), InDate: date(1)},
+			model.ReconciliationMatched, "0", "0"},
+		{"kurang diterima melewati toleransi",
+			model.InboundReconciliationLine{HasDocument: true, HasReceipt: true, DocQty: d("10"), ReceivedQty: d("9.49"), TglPabean: date(1), InDate: date(1)},
+			model.ReconciliationQtyMismatch, "0.51", "0"},
+		{"lebih diterima melewati toleransi",
+			model.InboundReconciliationLine{HasDocument: true, HasReceipt: true, DocQty: d("10"), ReceivedQty: d("11"), TglPabean: date(1), InDate: date(1)},
+			model.ReconciliationQtyMismatch, "0", "1"},
+		{"qty mismatch didahulukan dari tanggal",
+			model.InboundReconciliationLine{HasDocument: true, HasReceipt: true, DocQty: d("10"), ReceivedQty: d("8"), TglPabean: date(1), InDate: date(10)},
+			model.ReconciliationQtyMismatch, "2", "0"},
+		{"diterima tepat di batas hari",
+			model.InboundReconciliationLine{HasDocument: true, HasReceipt: true, DocQty: d("10"), ReceivedQty: d("10"), TglPabean: date(1), InDate: date(4)},
+			model.ReconciliationMatched, "0", "0"},
+		{"diterima lewat batas hari",
+			model.InboundReconciliationLine{HasDocument: true, HasReceipt: true, DocQty: d("10"), ReceivedQty: d("10"), TglPabean: date(1), InDate: date(5)},
+			model.ReconciliationDateMismatch, "0", "0"},
+		{"diterima sebelum tgl_pabean",
+			model.InboundReconciliationLine{HasDocument: true, HasReceipt: true, DocQty: d("10"), ReceivedQty: d("10"), TglPabean: date(5), InDate: date(4)},
+			model.ReconciliationDateMismatch, "0", "0"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			line := tc.line
+			classifyInbound(&line, opts)
+			if line.Status != tc.status {
+				t.Errorf("status = %s; want %s", line.Status, tc.status)
+			}
+			if !line.NotReceivedQty.Equal(d(tc.notReceived)) || !line.UndocumentedQty.Equal(d(tc.undoc)) {
+				t.Errorf("not_received/undocumented = %s/%s; want %s/%s", line.NotReceivedQty, line.UndocumentedQty, tc.notReceived, tc.undoc)
+			}
+		})
+	}
+}
+
+func TestClassifyOutbound(t *testing.T) {
+	opts := model.ReconciliationOptions{QtyTolerance: d("1")}
+
+	tests := []struct {
+		name       string
+		line       model.OutboundReconciliationLine
+		status     string
+		notShipped string
+		undoc      string
+	}{
+		{"ekspor + pengiriman sama dengan dokumen",
+			model.OutboundReconciliationLine{DocQty: d("10"), ExportedQty: d("6"), DeliveredQty: d("4")},
+			model.ReconciliationMatched, "0", "0"},
+		{"selisih tepat di toleransi",
+			model.OutboundReconciliationLine{DocQty: d("10"), ExportedQty: d("9")},
+			model.ReconciliationMatched, "0", "0"},
+		{"selisih melewati toleransi",
+			model.OutboundReconciliationLine{DocQty: d("10"), ExportedQty: d("8.9")},
+			model.ReconciliationQtyMismatch, "1.1", "0"},
+		{"belum dikirim",
+			model.OutboundReconciliationLine{DocQty: d("10")},
+			model.ReconciliationNotShipped, "10", "0"},
+		{"dikirim tanpa dokumen",
+			model.OutboundReconciliationLine{DeliveredQty: d("5")},
+			model.ReconciliationNoDocument, "0", "5"},
+		{"tanpa dokumen di dalam toleransi",
+			model.OutboundReconciliationLine{DeliveredQty: d("1")},
+			model.ReconciliationMatched, "0", "0"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			line := tc.line
+			classifyOutbound(&line, opts)
+			if line.Status != tc.status {
+				t.Errorf("status = %s; want %s", line.Status, tc.status)
+			}
+			if !line.NotShippedQty.Equal(d(tc.notShipped)) || !line.UndocumentedQty.Equal(d(tc.undoc)) {
+				t.Errorf("not_shipped/undocumented = %s/%s; want %s/%s", line.NotShippedQty, line.UndocumentedQty, tc.notShipped, tc.undoc)
+			}
+		})
+	}
+}
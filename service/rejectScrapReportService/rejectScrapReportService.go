@@ -22,7 +22,6 @@ func NewRejectScrapReportService(repo *rejectScrapReportRepository.RejectScrapRe
 // ==========================
 
 // GetReport retrieves reject and scrap report with filters and pagination
-func (s *RejectScrapReportService) GetReport(filter rejectScrapReportRepository.GetReportFilter) ([]model.RejectScrapReportResponse, int64, error) {
-	ctx := context.Background()
+func (s *RejectScrapReportService) GetReport(ctx context.Context, filter rejectScrapReportRepository.GetReportFilter) ([]model.RejectScrapReportResponse, int64, error) {
 	return s.repo.GetReport(ctx, filter)
 }
@@ -0,0 +1,60 @@
+package reportAccessLogService
+
+import (
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/reportAccessLogRepository"
+	"log"
+)
+
+type ReportAccessLogService struct {
+	repo *reportAccessLogRepository.ReportAccessLogRepository
+}
+
+func NewReportAccessLogService(repo *reportAccessLogRepository.ReportAccessLogRepository) *ReportAccessLogService {
+	return &ReportAccessLogService{repo: repo}
+}
+
+// Record menyimpan log akses laporan di background agar respons laporan tidak menunggu insert;
+// kegagalan hanya dicatat di log server.
+func (s *ReportAccessLogService) Record(entry model.ReportAccessLog) {
+	go func() {
+		if err := s.repo.Create(&entry); err != nil {
+			log.Printf("report access log %s %s: %v", entry.Action, entry.Path, err)
+		}
+	}()
+}
+
+// GetAll - get report access logs with filtering and pagination
+func (s *ReportAccessLogService) GetAll(req model.ReportAccessLogListRequest) ([]model.ReportAccessLog, int64, map[string]interface{}, error) {
+	// Set defaults for pagination
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	logs, total, err := s.repo.GetAll(req)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	// Calculate pagination metadata
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+
+	meta := map[string]interface{}{
+		"page":        req.Page,
+		"limit":       req.Limit,
+		"total_count": total,
+		"total_pages": totalPages,
+		"has_next":    req.Page < totalPages,
+		"has_prev":    req.Page > 1,
+	}
+
+	return logs, total, meta, nil
+}
+
+// Stream - semua log yang cocok dengan filter, untuk export
+func (s *ReportAccessLogService) Stream(req model.ReportAccessLogListRequest, fn func(model.ReportAccessLog) error) error {
+	return s.repo.Stream(req, fn)
+}
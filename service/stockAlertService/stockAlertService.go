@@ -0,0 +1,315 @@
+package stockAlertService
+
+import (
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/finishedProductReportRepository"
+	"Bea-Cukai/repo/machineToolReportRepository"
+	"Bea-Cukai/repo/rawMaterialReportRepository"
+	"Bea-Cukai/repo/rejectScrapReportRepository"
+	"Bea-Cukai/repo/stockAlertRepository"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// StockAlertService mengevaluasi rule alert stok terhadap laporan LPJ per item group
+// dan menyimpan setiap pelanggaran sebagai alert.
+
+// Item group yang punya laporan saldo (awal/akhir/opname) sehingga bisa dievaluasi.
+const (
+	ItemGroupMaterial = "MATERIAL"
+	ItemGroupProduct  = "PRODUCT"
+	ItemGroupMesin    = "MESIN"
+	ItemGroupScrap    = "SCRAP"
+)
+
+var ErrUnsupportedItemGroup = errors.New("item group has no balance report (supported: MATERIAL, PRODUCT, MESIN, SCRAP)")
+
+// Laporan mesin selalu mengisi selisih 0, jadi rule selisih_percent untuk MESIN tidak akan
+// pernah terpicu dan ditolak saat dibuat / diubah.
+var ErrSelisihUnsupported = errors.New("selisih_percent rule is not supported for MESIN (machine report has no selisih)")
+
+type StockAlertService struct {
+	alertRepo           *stockAlertRepository.StockAlertRepository
+	rawMaterialRepo     *rawMaterialReportRepository.RawMaterialReportRepository
+	finishedProductRepo *finishedProductReportRepository.FinishedProductReportRepository
+	machineToolRepo     *machineToolReportRepository.MachineToolReportRepository
+	rejectScrapRepo     *rejectScrapReportRepository.RejectScrapReportRepository
+}
+
+func NewStockAlertService(
+	alertRepo *stockAlertRepository.StockAlertRepository,
+	rawMaterialRepo *rawMaterialReportRepository.RawMaterialReportRepository,
+	finishedProductRepo *finishedProductReportRepository.FinishedProductReportRepository,
+	machineToolRepo *machineToolReportRepository.MachineToolReportRepository,
+	rejectScrapRepo *rejectScrapReportRepository.RejectScrapReportRepository,
+) *StockAlertService {
+	return &StockAlertService{
+		alertRepo:           alertRepo,
+		rawMaterialRepo:     rawMaterialRepo,
+		finishedProductRepo: finishedProductRepo,
+		machineToolRepo:     machineToolRepo,
+		rejectScrapRepo:     rejectScrapRepo,
+	}
+}
+
+// balanceRow adalah baris laporan yang dinormalisasi ke decimal untuk evaluasi rule.
+type balanceRow struct {
+	ItemCode string
+	ItemName string
+	UnitCode string
+	Awal     decimal.Decimal
+	Akhir    decimal.Decimal
+	Opname   decimal.Decimal
+	Selisih  decimal.Decimal
+}
+
+// ==========================
+// Rules
+// ==========================
+
+func (s *StockAlertService) GetRules() ([]model.StockAlertRule, error) {
+	return s.alertRepo.GetRules(false)
+}
+
+func (s *StockAlertService) CreateRule(req model.StockAlertRuleRequest) (model.StockAlertRule, error) {
+	if err := validateRule(req); err != nil {
+		return model.StockAlertRule{}, err
+	}
+	return s.alertRepo.CreateRule(req)
+}
+
+func (s *StockAlertService) UpdateRule(id int, req model.StockAlertRuleRequest) (model.StockAlertRule, error) {
+	if err := validateRule(req); err != nil {
+		return model.StockAlertRule{}, err
+	}
+	return s.alertRepo.UpdateRule(id, req)
+}
+
+func (s *StockAlertService) DeleteRule(id int) error {
+	return s.alertRepo.DeleteRule(id)
+}
+
+func validateRule(req model.StockAlertRuleRequest) error {
+	switch req.ItemGroup {
+	case ItemGroupMaterial, ItemGroupProduct, ItemGroupMesin, ItemGroupScrap:
+	default:
+		return ErrUnsupportedItemGroup
+	}
+	if req.RuleType == model.AlertRuleSelisihPercent {
+		if req.ItemGroup == ItemGroupMesin {
+			return ErrSelisihUnsupported
+		}
+		if req.ThresholdPercent == nil || req.ThresholdPercent.IsNegative() {
+			return errors.New("threshold_percent must be >= 0 for selisih_percent rule")
+		}
+	}
+	return nil
+}
+
+// ==========================
+// Alerts
+// ==========================
+
+func (s *StockAlertService) GetAll(req model.StockAlertListRequest) ([]model.StockAlert, int64, map[string]interface{}, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	alerts, total, err := s.alertRepo.GetAll(req)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	meta := map[string]interface{}{
+		"page":        req.Page,
+		"limit":       req.Limit,
+		"total_count": total,
+		"total_pages": totalPages,
+		"has_next":    req.Page < totalPages,
+		"has_prev":    req.Page > 1,
+	}
+	return alerts, total, meta, nil
+}
+
+func (s *StockAlertService) Acknowledge(id int, username, note string) (model.StockAlert, error) {
+	return s.alertRepo.Acknowledge(id, username, note)
+}
+
+func (s *StockAlertService) Resolve(id int, username, note string) (model.StockAlert, error) {
+	return s.alertRepo.Resolve(id, username, note)
+}
+
+// ==========================
+// Evaluation
+// ==========================
+
+// EvaluateCurrentPeriod mengevaluasi bulan berjalan (tanggal 1 s.d. hari ini, Asia/Jakarta).
+// Dipanggil setelah sync. Alert dikunci pada period_from, jadi sync harian memperbarui alert bulan yang sama.
+func (s *StockAlertService) EvaluateCurrentPeriod(ctx context.Context) (model.StockAlertEvaluation, error) {
+	loc := apiRequest.Location()
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	return s.Evaluate(ctx, from, to)
+}
+
+// Evaluate menjalankan semua rule aktif untuk periode from..to.
+// Setiap item group hanya di-query sekali walaupun punya beberapa rule.
+func (s *StockAlertService) Evaluate(ctx context.Context, from, to time.Time) (model.StockAlertEvaluation, error) {
+	result := model.StockAlertEvaluation{
+		PeriodFrom:    from.Format("2006-01-02"),
+		PeriodTo:      to.Format("2006-01-02"),
+		SkippedGroups: []string{},
+	}
+
+	rules, err := s.alertRepo.GetRules(true)
+	if err != nil {
+		return result, err
+	}
+
+	rowsByGroup := map[string][]balanceRow{}
+	for _, rule := range rules {
+		rows, ok := rowsByGroup[rule.ItemGroup]
+		if !ok {
+			rows, err = s.getBalanceRows(ctx, rule.ItemGroup, from, to)
+			if errors.Is(err, ErrUnsupportedItemGroup) {
+				result.SkippedGroups = append(result.SkippedGroups, rule.ItemGroup)
+				rowsByGroup[rule.ItemGroup] = nil
+				continue
+			}
+			if err != nil {
+				return result, fmt.Errorf("item group %s: %w", rule.ItemGroup, err)
+			}
+			rowsByGroup[rule.ItemGroup] = rows
+		}
+
+		result.RulesEvaluated++
+		for _, row := range rows {
+			message, violated := checkRule(rule, row)
+			if !violated {
+				continue
+			}
+			result.Violations++
+
+			outcome, err := s.alertRepo.UpsertAlert(model.StockAlert{
+				RuleId:     rule.Id,
+				RuleType:   rule.RuleType,
+				ItemGroup:  rule.ItemGroup,
+				ItemCode:   row.ItemCode,
+				ItemName:   row.ItemName,
+				UnitCode:   row.UnitCode,
+				PeriodFrom: from,
+				PeriodTo:   to,
+				Awal:       row.Awal,
+				Akhir:      row.Akhir,
+				Opname:     row.Opname,
+				Selisih:    row.Selisih,
+				Message:    message,
+			})
+			if err != nil {
+				return result, err
+			}
+			switch outcome {
+			case stockAlertRepository.UpsertCreated:
+				result.Created++
+			case stockAlertRepository.UpsertReopened:
+				result.Reopened++
+			case stockAlertRepository.UpsertUnchanged:
+				result.Unchanged++
+			default:
+				result.Updated++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// checkRule mengembalikan pesan pelanggaran dan true bila row melanggar rule.
+func checkRule(rule model.StockAlertRule, row balanceRow) (string, bool) {
+	switch rule.RuleType {
+	case model.AlertRuleNegativeAkhir:
+		if row.Akhir.IsNegative() {
+			return fmt.Sprintf("saldo akhir negatif (%s)", row.Akhir.String()), true
+		}
+	case model.AlertRuleSelisihPercent:
+		// Persentase tidak bisa dihitung terhadap saldo akhir 0.
+		if rule.ThresholdPercent == nil || row.Selisih.IsZero() || row.Akhir.IsZero() {
+			return "", false
+		}
+		pct := row.Selisih.Abs().Mul(decimal.NewFromInt(100)).Div(row.Akhir.Abs())
+		if pct.GreaterThan(*rule.ThresholdPercent) {
+			return fmt.Sprintf("selisih %s%% dari saldo akhir (batas %s%%)", pct.StringFixed(2), rule.ThresholdPercent.String()), true
+		}
+	case model.AlertRuleMissingOpname:
+		if row.Opname.IsZero() && row.Akhir.IsPositive() {
+			return fmt.Sprintf("stok opname tidak ada, saldo akhir %s", row.Akhir.String()), true
+		}
+	}
+	return "", false
+}
+
+// getBalanceRows mengambil seluruh baris laporan (tanpa pagination) untuk item group.
+func (s *StockAlertService) getBalanceRows(ctx context.Context, itemGroup string, from, to time.Time) ([]balanceRow, error) {
+	rows := []balanceRow{}
+
+	switch itemGroup {
+	case ItemGroupMaterial:
+		res, _, err := s.rawMaterialRepo.GetPeriodBalances(ctx, rawMaterialReportRepository.GetReportFilter{From: from, To: to})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range res {
+			rows = append(rows, balanceRow{r.ItemCode, r.ItemName, r.UnitCode, r.Awal, r.Akhir, r.Opname, r.Selisih})
+		}
+	case ItemGroupProduct:
+		res, _, err := s.finishedProductRepo.GetPeriodBalances(ctx, finishedProductReportRepository.GetReportFilter{From: from, To: to})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range res {
+			rows = append(rows, balanceRow{r.ItemCode, r.ItemName, r.UnitCode, r.Awal, r.Akhir, r.Opname, r.Selisih})
+		}
+	case ItemGroupMesin:
+		res, _, err := s.machineToolRepo.GetReport(ctx, machineToolReportRepository.GetReportFilter{From: from, To: to})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range res {
+			rows = append(rows, stringBalanceRow(r.ItemCode, r.ItemName, r.UnitCode, r.Awal, r.Akhir, r.Opname, r.Selisih))
+		}
+	case ItemGroupScrap:
+		res, _, err := s.rejectScrapRepo.GetReport(ctx, rejectScrapReportRepository.GetReportFilter{From: from, To: to})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range res {
+			rows = append(rows, stringBalanceRow(r.ItemCode, r.ItemName, r.UnitCode, r.Awal, r.Akhir, r.Opname, r.Selisih))
+		}
+	default:
+		return nil, ErrUnsupportedItemGroup
+	}
+
+	return rows, nil
+}
+
+// stringBalanceRow mengonversi laporan yang nilai angkanya berupa string; nilai tidak valid dianggap 0.
+func stringBalanceRow(itemCode, itemName, unitCode, awal, akhir, opname, selisih string) balanceRow {
+	parse := func(v string) decimal.Decimal {
+		d, err := decimal.NewFromString(v)
+		if err != nil {
+			return decimal.Zero
+		}
+		return d
+	}
+	return balanceRow{itemCode, itemName, unitCode, parse(awal), parse(akhir), parse(opname), parse(selisih)}
+}
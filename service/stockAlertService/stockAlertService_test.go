@@ -0,0 +1,155 @@
+package stockAlertService
+
+import (
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/rawMaterialReportRepository"
+	"Bea-Cukai/repo/stockAlertRepository"
+	"context"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func d(v string) decimal.Decimal { return decimal.RequireFromString(v) }
+
+func TestCheckRule(t *testing.T) {
+	threshold := d("5")
+	selisih := model.StockAlertRule{RuleType: model.AlertRuleSelisihPercent, ThresholdPercent: &threshold}
+
+	tests := []struct {
+		name string
+		rule model.StockAlertRule
+		row  balanceRow
+		want bool
+	}{
+		{"akhir negatif", model.StockAlertRule{RuleType: model.AlertRuleNegativeAkhir}, balanceRow{Akhir: d("-0.01")}, true},
+		{"akhir nol bukan negatif", model.StockAlertRule{RuleType: model.AlertRuleNegativeAkhir}, balanceRow{Akhir: d("0")}, false},
+		{"selisih tepat di batas", selisih, balanceRow{Akhir: d("100"), Selisih: d("-5")}, false},
+		{"selisih melewati batas", selisih, balanceRow{Akhir: d("100"), Selisih: d("5.01")}, true},
+		{"selisih dengan akhir nol tidak dievaluasi", selisih, balanceRow{Akhir: d("0"), Selisih: d("1")}, false},
+		{"tanpa selisih", selisih, balanceRow{Akhir: d("0"), Selisih: d("0")}, false},
+		{"selisih tanpa threshold", model.StockAlertRule{RuleType: model.AlertRuleSelisihPercent}, balanceRow{Akhir: d("1"), Selisih: d("1")}, false},
+		{"opname kosong dengan saldo", model.StockAlertRule{RuleType: model.AlertRuleMissingOpname}, balanceRow{Akhir: d("3"), Opname: d("0")}, true},
+		{"opname kosong tanpa saldo", model.StockAlertRule{RuleType: model.AlertRuleMissingOpname}, balanceRow{Akhir: d("0"), Opname: d("0")}, false},
+		{"opname ada", model.StockAlertRule{RuleType: model.AlertRuleMissingOpname}, balanceRow{Akhir: d("3"), Opname: d("2")}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			message, got := checkRule(tc.rule, tc.row)
+			if got != tc.want {
+				t.Fatalf("violated = %v; want %v (%q)", got, tc.want, message)
+			}
+			if got && message == "" {
+				t.Error("pelanggaran tanpa pesan")
+			}
+		})
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	threshold := d("5")
+	tests := []struct {
+		name    string
+		req     model.StockAlertRuleRequest
+		wantErr error
+	}{
+		{"selisih bahan baku", model.StockAlertRuleRequest{ItemGroup: ItemGroupMaterial, RuleType: model.AlertRuleSelisihPercent, ThresholdPercent: &threshold}, nil},
+		{"selisih mesin ditolak", model.StockAlertRuleRequest{ItemGroup: ItemGroupMesin, RuleType: model.AlertRuleSelisihPercent, ThresholdPercent: &threshold}, ErrSelisihUnsupported},
+		{"saldo negatif mesin", model.StockAlertRuleRequest{ItemGroup: ItemGroupMesin, RuleType: model.AlertRuleNegativeAkhir}, nil},
+		{"grup tanpa laporan", model.StockAlertRuleRequest{ItemGroup: "WIP", RuleType: model.AlertRuleNegativeAkhir}, ErrUnsupportedItemGroup},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateRule(tc.req); err != tc.wantErr {
+				t.Errorf("err = %v; want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestAlertOutcome(t *testing.T) {
+	existing := model.StockAlert{Awal: d("10"), Akhir: d("-2"), Opname: d("0"), Selisih: d("2")}
+	same := model.StockAlert{Awal: d("10.00"), Akhir: d("-2"), Opname: d("0"), Selisih: d("2")}
+	changed := model.StockAlert{Awal: d("10"), Akhir: d("-3"), Opname: d("0"), Selisih: d("3")}
+
+	tests := []struct {
+		name   string
+		status string
+		alert  model.StockAlert
+		want   string
+	}{
+		{"resolved, angka sama tetap resolved", model.AlertStatusResolved, same, stockAlertRepository.UpsertUnchanged},
+		{"resolved, angka berubah dibuka lagi", model.AlertStatusResolved, changed, stockAlertRepository.UpsertReopened},
+		{"acknowledged, angka berubah", model.AlertStatusAcknowledged, changed, stockAlertRepository.UpsertUpdated},
+		{"open, angka sama", model.AlertStatusOpen, same, stockAlertRepository.UpsertUnchanged},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := existing
+			e.Status = tc.status
+			if got := stockAlertRepository.AlertOutcome(e, tc.alert); got != tc.want {
+				t.Errorf("outcome = %s; want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+// Evaluasi harian di bulan yang sama: alert baru dibuat, alert yang sudah resolved dengan
+// angka sama tidak dibuka lagi.
+func TestEvaluate_UpsertOutcomes(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("gorm.Open mock: %v", err)
+	}
+	svc := NewStockAlertService(stockAlertRepository.NewStockAlertRepository(db),
+		rawMaterialReportRepository.NewRawMaterialReportRepository(db), nil, nil, nil)
+
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT \\* FROM `stock_alert_rule` WHERE is_active = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "item_group", "rule_type"}).
+			AddRow(7, ItemGroupMaterial, model.AlertRuleNegativeAkhir))
+	mock.ExpectQuery(`MAX\(CASE WHEN trans_date`).
+		WillReturnRows(sqlmock.NewRows([]string{"tgl_awal", "tgl_akhir"}).AddRow("2026-08-31", "2026-08-31"))
+	mock.ExpectQuery(`WITH b AS`).
+		WillReturnRows(sqlmock.NewRows([]string{"item_code", "item_name", "awal", "akhir", "opname", "selisih"}).
+			AddRow("MAT-NEW", "Baru", "0", "-1", "0", "1").
+			AddRow("MAT-OLD", "Lama", "5", "-2", "0", "2"))
+
+	// MAT-NEW: belum ada → insert
+	mock.ExpectQuery("SELECT \\* FROM `stock_alert` WHERE rule_id = \\? AND item_code = \\? AND period_from = \\?").
+		WithArgs(7, "MAT-NEW", "2026-09-01", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `stock_alert` .* ON DUPLICATE KEY UPDATE").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// MAT-OLD: sudah resolved dari sync kemarin dengan angka sama → tidak ada UPDATE
+	mock.ExpectQuery("SELECT \\* FROM `stock_alert` WHERE rule_id = \\? AND item_code = \\? AND period_from = \\?").
+		WithArgs(7, "MAT-OLD", "2026-09-01", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "period_to", "awal", "akhir", "opname", "selisih"}).
+			AddRow(3, model.AlertStatusResolved, time.Date(2026, 9, 14, 0, 0, 0, 0, time.UTC), "5", "-2", "0", "2"))
+
+	res, err := svc.Evaluate(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Violations != 2 || res.Created != 1 || res.Unchanged != 1 || res.Reopened != 0 || res.Updated != 0 {
+		t.Errorf("evaluation = %+v; want 2 violations, 1 created, 1 unchanged", res)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package subcontractService
+
+import (
+	"Bea-Cukai/helper/apiRequest"
+	"Bea-Cukai/model"
+	"Bea-Cukai/repo/subcontractRepository"
+	"context"
+	"slices"
+
+	"github.com/shopspring/decimal"
+)
+
+// SubcontractService memasangkan pengiriman subkontrak (BC 2.6.1) dengan pengembaliannya
+// (BC 2.6.2) dan menghitung sisa yang belum kembali per pengiriman.
+
+type SubcontractService struct {
+	repo *subcontractRepository.SubcontractRepository
+}
+
+func NewSubcontractService(repo *subcontractRepository.SubcontractRepository) *SubcontractService {
+	return &SubcontractService{repo: repo}
+}
+
+// ==========================
+// Business Operations
+// ==========================
+
+// Outstanding mengembalikan pengiriman subkontrak sampai opts.AsOf beserta pengembalian dan sisanya.
+// Dokumen BC 2.6.2 tidak mereferensikan BC 2.6.1, jadi pengembalian dialokasikan FIFO ke pengiriman
+// terlama dengan subkontraktor dan item yang sama. Kode subkontraktor pengembalian sudah dipetakan
+// ke kode customer oleh repo (ms_subcontractor_map); kode yang tetap tidak punya pengiriman sama
+// sekali dilaporkan di summary.UnknownSubcontractors. Summary dihitung dari semua pengiriman;
+// shipments hanya berisi status yang diminta (opts.Status).
+func (s *SubcontractService) Outstanding(ctx context.Context, filter subcontractRepository.Filter, opts model.SubcontractOptions) ([]model.SubcontractShipment, model.SubcontractSummary, error) {
+	sent, err := s.repo.GetShipments(ctx, filter)
+	if err != nil {
+		return nil, model.SubcontractSummary{}, err
+	}
+	returns, err := s.repo.GetReturns(ctx, filter)
+	if err != nil {
+		return nil, model.SubcontractSummary{}, err
+	}
+
+	shipments, unmatched := pairReturns(sent, returns)
+
+	summary := model.SubcontractSummary{
+		ByStatus:              make(map[string]int, len(model.SubcontractStatuses)),
+		UnmatchedReturns:      unmatched,
+		UnknownSubcontractors: unknownSubcontractors(sent, returns),
+	}
+	for _, status := range model.SubcontractStatuses {
+		summary.ByStatus[status] = 0
+	}
+
+	filtered := make([]model.SubcontractShipment, 0, len(shipments))
+	for _, shipment := range shipments {
+		classify(&shipment, opts)
+		summary.ShipmentCount++
+		summary.ByStatus[shipment.Status]++
+		if shipment.Late {
+			summary.LateCount++
+		}
+		if len(opts.Status) == 0 || slices.Contains(opts.Status, shipment.Status) {
+			filtered = append(filtered, shipment)
+		}
+	}
+	return filtered, summary, nil
+}
+
+// pairReturns mengalokasikan setiap pengembalian (urut tanggal) ke pengiriman terlama yang masih
+// bersisa dengan subkontraktor + item sama dan tanggal tidak setelah pengembalian. Kuantitas yang
+// tidak teralokasi dikembalikan sebagai unmatched.
+func pairReturns(sent, returns []model.SubcontractMovement) ([]model.SubcontractShipment, []model.SubcontractMovement) {
+	shipments := make([]model.SubcontractShipment, len(sent))
+	queues := map[string][]int{}
+	for i, m := range sent {
+		shipments[i] = model.SubcontractShipment{
+			SubcontractMovement: m,
+			OutstandingQty:      m.Qty,
+			Returns:             []model.SubcontractReturn{},
+		}
+		if m.Qty.IsPositive() {
+			key := pairKey(m)
+			queues[key] = append(queues[key], i)
+		}
+	}
+
+	unmatched := []model.SubcontractMovement{}
+	for _, ret := range returns {
+		left := ret.Qty
+		key := pairKey(ret)
+		queue := queues[key]
+		for len(queue) > 0 && left.IsPositive() {
+			shipment := &shipments[queue[0]]
+			if shipment.TglPabean.After(ret.TglPabean) {
+				break
+			}
+			qty := decimal.Min(left, shipment.OutstandingQty)
+			shipment.Returns = append(shipment.Returns, model.SubcontractReturn{
+				NoPabean:  ret.NoPabean,
+				TglPabean: ret.TglPabean,
+				TransNo:   ret.TransNo,
+				Qty:       qty,
+			})
+			shipment.ReturnedQty = shipment.ReturnedQty.Add(qty)
+			shipment.OutstandingQty = shipment.OutstandingQty.Sub(qty)
+			left = left.Sub(qty)
+			if !shipment.OutstandingQty.IsPositive() {
+				queue = queue[1:]
+			}
+		}
+		queues[key] = queue
+
+		if left.IsPositive() {
+			ret.Qty = left
+			unmatched = append(unmatched, ret)
+		}
+	}
+	return shipments, unmatched
+}
+
+// classify mengisi jatuh tempo, lama terbuka dan status satu pengiriman.
+func classify(shipment *model.SubcontractShipment, opts model.SubcontractOptions) {
+	shipment.DueDate = shipment.TglPabean.AddDate(0, 0, opts.AllowedDays)
+
+	if shipment.OutstandingQty.IsPositive() {
+		shipment.DaysOpen = apiRequest.DaysBetween(shipment.TglPabean, opts.AsOf)
+		shipment.Late = shipment.DaysOpen > opts.AllowedDays
+		shipment.Status = model.SubcontractOpen
+		if shipment.Late {
+			shipment.Status = model.SubcontractOverdue
+		}
+		return
+	}
+
+	lastReturn := shipment.TglPabean
+	if n := len(shipment.Returns); n > 0 {
+		lastReturn = shipment.Returns[n-1].TglPabean
+	}
+	shipment.DaysOpen = apiRequest.DaysBetween(shipment.TglPabean, lastReturn)
+	shipment.Late = shipment.DaysOpen > opts.AllowedDays
+	shipment.Status = model.SubcontractReturned
+}
+
+// unknownSubcontractors mengembalikan kode subkontraktor pengembalian (urut, unik) yang tidak
+// punya satu pun pengiriman, yaitu pengembalian yang pasti tidak bisa dipasangkan.
+func unknownSubcontractors(sent, returns []model.SubcontractMovement) []string {
+	known := make(map[string]bool, len(sent))
+	for _, m := range sent {
+		known[m.SubcontractorCode] = true
+	}
+	unknown := []string{}
+	for _, ret := range returns {
+		if !known[ret.SubcontractorCode] && !slices.Contains(unknown, ret.SubcontractorCode) {
+			unknown = append(unknown, ret.SubcontractorCode)
+		}
+	}
+	slices.Sort(unknown)
+	return unknown
+}
+
+// pairKey adalah kunci pemasangan: kode subkontraktor + kode barang.
+func pairKey(m model.SubcontractMovement) string {
+	return m.SubcontractorCode + "|" + m.ItemCode
+}
@@ -0,0 +1,141 @@
+package subcontractService
+
+import (
+	"Bea-Cukai/model"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func d(v string) decimal.Decimal { return decimal.RequireFromString(v) }
+
+func day(month, d int) time.Time { return time.Date(2026, time.Month(month), d, 0, 0, 0, 0, time.UTC) }
+
+func movement(no string, date time.Time, qty string) model.SubcontractMovement {
+	return model.SubcontractMovement{NoPabean: no, TglPabean: date, SubcontractorCode: "SUB-01", ItemCode: "RM-001", Qty: d(qty)}
+}
+
+func TestPairReturns(t *testing.T) {
+	type want struct {
+		returned, outstanding string
+		returns               int
+	}
+	tests := []struct {
+		name      string
+		sent      []model.SubcontractMovement
+		returns   []model.SubcontractMovement
+		want      []want
+		unmatched []string // qty pengembalian yang tidak teralokasi
+	}{
+		{
+			name:    "pengembalian sebagian",
+			sent:    []model.SubcontractMovement{movement("S1", day(6, 1), "100")},
+			returns: []model.SubcontractMovement{movement("R1", day(7, 1), "40")},
+			want:    []want{{"40", "60", 1}},
+		},
+		{
+			name:      "pengembalian sebelum pengiriman",
+			sent:      []model.SubcontractMovement{movement("S1", day(6, 1), "100")},
+			returns:   []model.SubcontractMovement{movement("R1", day(5, 31), "10")},
+			want:      []want{{"0", "100", 0}},
+			unmatched: []string{"10"},
+		},
+		{
+			name:      "pengembalian melebihi pengiriman",
+			sent:      []model.SubcontractMovement{movement("S1", day(6, 1), "100")},
+			returns:   []model.SubcontractMovement{movement("R1", day(7, 1), "130")},
+			want:      []want{{"100", "0", 1}},
+			unmatched: []string{"30"},
+		},
+		{
+			name:    "pengiriman qty nol dilewati",
+			sent:    []model.SubcontractMovement{movement("S0", day(6, 1), "0"), movement("S1", day(6, 2), "50")},
+			returns: []model.SubcontractMovement{movement("R1", day(7, 1), "20")},
+			want:    []want{{"0", "0", 0}, {"20", "30", 1}},
+		},
+		{
+			name:    "FIFO ke pengiriman terlama",
+			sent:    []model.SubcontractMovement{movement("S1", day(6, 1), "30"), movement("S2", day(6, 2), "30")},
+			returns: []model.SubcontractMovement{movement("R1", day(7, 1), "50")},
+			want:    []want{{"30", "0", 1}, {"20", "10", 1}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			shipments, unmatched := pairReturns(tc.sent, tc.returns)
+			if len(shipments) != len(tc.want) {
+				t.Fatalf("len = %d; want %d", len(shipments), len(tc.want))
+			}
+			for i, w := range tc.want {
+				s := shipments[i]
+				if !s.ReturnedQty.Equal(d(w.returned)) || !s.OutstandingQty.Equal(d(w.outstanding)) || len(s.Returns) != w.returns {
+					t.Errorf("shipment %s = returned %s, outstanding %s, %d returns; want %s, %s, %d",
+						s.NoPabean, s.ReturnedQty, s.OutstandingQty, len(s.Returns), w.returned, w.outstanding, w.returns)
+				}
+			}
+			if len(unmatched) != len(tc.unmatched) {
+				t.Fatalf("unmatched = %+v; want %v", unmatched, tc.unmatched)
+			}
+			for i, qty := range tc.unmatched {
+				if !unmatched[i].Qty.Equal(d(qty)) {
+					t.Errorf("unmatched[%d] qty = %s; want %s", i, unmatched[i].Qty, qty)
+				}
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	opts := model.SubcontractOptions{AsOf: day(7, 31), AllowedDays: 30}
+	returnedOn := func(date time.Time) []model.SubcontractReturn {
+		return []model.SubcontractReturn{{TglPabean: date, Qty: d("10")}}
+	}
+
+	tests := []struct {
+		name     string
+		shipment model.SubcontractShipment
+		status   string
+		daysOpen int
+		late     bool
+	}{
+		{"sisa, tepat di batas hari", model.SubcontractShipment{SubcontractMovement: movement("S", day(7, 1), "10"), OutstandingQty: d("10")},
+			model.SubcontractOpen, 30, false},
+		{"sisa, lewat batas hari", model.SubcontractShipment{SubcontractMovement: movement("S", day(6, 30), "10"), OutstandingQty: d("10")},
+			model.SubcontractOverdue, 31, true},
+		{"kembali semua tepat di batas hari", model.SubcontractShipment{SubcontractMovement: movement("S", day(6, 1), "10"), Returns: returnedOn(day(7, 1))},
+			model.SubcontractReturned, 30, false},
+		{"kembali semua terlambat", model.SubcontractShipment{SubcontractMovement: movement("S", day(6, 1), "10"), Returns: returnedOn(day(7, 2))},
+			model.SubcontractReturned, 31, true},
+		{"qty nol tanpa pengembalian", model.SubcontractShipment{SubcontractMovement: movement("S", day(6, 1), "0")},
+			model.SubcontractReturned, 0, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := tc.shipment
+			classify(&s, opts)
+			if s.Status != tc.status || s.DaysOpen != tc.daysOpen || s.Late != tc.late {
+				t.Errorf("status/days/late = %s/%d/%v; want %s/%d/%v", s.Status, s.DaysOpen, s.Late, tc.status, tc.daysOpen, tc.late)
+			}
+			if !s.DueDate.Equal(s.TglPabean.AddDate(0, 0, opts.AllowedDays)) {
+				t.Errorf("due date = %s", s.DueDate)
+			}
+		})
+	}
+}
+
+// Pengembalian dari kode yang tidak pernah menerima pengiriman (vendor belum dipetakan) dilaporkan.
+func TestUnknownSubcontractors(t *testing.T) {
+	sent := []model.SubcontractMovement{movement("S1", day(6, 1), "10")}
+	mapped := movement("R1", day(7, 1), "5")
+	unmapped := movement("R2", day(7, 1), "5")
+	unmapped.SubcontractorCode = "V-SUB-02"
+	other := unmapped
+	other.SubcontractorCode = "V-SUB-01"
+
+	got := unknownSubcontractors(sent, []model.SubcontractMovement{mapped, unmapped, other, unmapped})
+	if want := []string{"V-SUB-01", "V-SUB-02"}; !slices.Equal(got, want) {
+		t.Errorf("unknown = %v; want %v", got, want)
+	}
+}
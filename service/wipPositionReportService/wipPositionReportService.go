@@ -22,7 +22,6 @@ func NewWipPositionReportService(wipRepo *wipPositionReportRepository.WipPositio
 // ==========================
 
 // GetReport retrieves WIP position report with filters and pagination
-func (s *WipPositionReportService) GetReport(filter wipPositionReportRepository.GetReportFilter) ([]model.WipPositionReportResponse, int64, error) {
-	ctx := context.Background()
+func (s *WipPositionReportService) GetReport(ctx context.Context, filter wipPositionReportRepository.GetReportFilter) ([]model.WipPositionReportResponse, int64, error) {
 	return s.wipRepo.GetReport(ctx, filter)
 }